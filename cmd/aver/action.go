@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"aver/pkg/actions"
+	"aver/pkg/ghaction"
+)
+
+// githubAction is set by --github-action, when aver is the entrypoint of
+// its own action
+var githubAction bool
+
+// Inputs of action.yml that map onto flags of the same name
+var (
+	actionBoolInputs  = []string{"ignore-sha", "ignore-minor", "releases", "include-prereleases", "notes", "notify", "comment-pr", "check-run", "commit-status", "check-inputs", "check-commands", "warn-personal-actions", "strict"}
+	actionValueInputs = []string{"min-release-age", "baseline", "api-url", "max-api-requests", "sha-compare"}
+)
+
+// githubActionArgs adds the flags set by action inputs to args
+func githubActionArgs(args []string) []string {
+	// Docker actions don't see $GITHUB_TOKEN unless it's passed in
+	if token := ghaction.Input("token"); token != "" && os.Getenv("GITHUB_TOKEN") == "" {
+		_ = os.Setenv("GITHUB_TOKEN", token)
+	}
+	return append(args, ghaction.Args(actionBoolInputs, actionValueInputs)...)
+}
+
+// reportToActions annotates each finding in its workflow file and writes
+// the job summary
+func reportToActions(result actions.CheckResult) {
+	for _, o := range result.Outdated {
+		fmt.Println(ghaction.Annotation{
+			Level:   "warning",
+			File:    o.File,
+			Line:    o.Line,
+			Title:   "Outdated action",
+			Message: fmt.Sprintf("%s@%s can be updated to %s", o.Name, o.CurrentVersion, o.LatestVersion),
+		})
+	}
+	for _, s := range result.SHAPinned {
+		fmt.Println(ghaction.Annotation{
+			Level:   "warning",
+			File:    s.File,
+			Line:    s.Line,
+			Title:   "SHA-pinned action behind",
+			Message: s.Describe(),
+		})
+	}
+	for _, f := range result.Inputs {
+		fmt.Println(ghaction.Annotation{
+			Level:   "warning",
+			File:    f.File,
+			Line:    f.Line,
+			Title:   "Problem with a step input",
+			Message: f.Message,
+		})
+	}
+	for _, d := range result.Commands {
+		fmt.Println(ghaction.Annotation{
+			Level:   "warning",
+			File:    d.File,
+			Line:    d.Line,
+			Title:   "Deprecated workflow command",
+			Message: d.Message(),
+		})
+	}
+	for _, t := range result.Typosquats {
+		fmt.Println(ghaction.Annotation{
+			Level:   "warning",
+			File:    t.File,
+			Line:    t.Line,
+			Title:   "Possible typosquat",
+			Message: t.Message(),
+		})
+	}
+	for _, f := range result.Forks {
+		fmt.Println(ghaction.Annotation{
+			Level:   "warning",
+			File:    f.File,
+			Line:    f.Line,
+			Title:   "Suspicious fork",
+			Message: f.Message(),
+		})
+	}
+
+	if err := ghaction.AppendSummary(ghaction.Markdown(result, githubRepoURL)); err != nil {
+		warn("could not write the job summary:", err)
+	}
+}
+
+// writeOutputs sets step outputs for later steps: counts of each kind of
+// finding, and the --json report without whitespace
+func writeOutputs(result actions.CheckResult) {
+	data, err := json.Marshal(newJSONOutput(result))
+	if err != nil {
+		fatal(err.Error())
+	}
+	outputs := []struct{ name, value string }{
+		{"outdated_count", fmt.Sprint(len(result.Outdated))},
+		{"sha_behind_count", fmt.Sprint(len(result.SHAPinned))},
+		{"unchecked_count", fmt.Sprint(len(result.Unchecked))},
+		{"json", string(data)},
+	}
+	for _, output := range outputs {
+		if err := ghaction.SetOutput(output.name, output.value); err != nil {
+			warn("could not set output:", err)
+			return
+		}
+	}
+}
+
+// failAction returns the exit code of a run with findings, failing the
+// step with a one-line message unless the fail-on-outdated input is
+// "false". The caller exits, so that --strict still applies.
+func failAction(result actions.CheckResult) int {
+	if ghaction.Input("fail-on-outdated") == "false" {
+		return exitOK
+	}
+	msg := plural(len(result.Outdated), "outdated action")
+	if n := len(result.SHAPinned); n > 0 {
+		msg += fmt.Sprintf(" and %s behind the default branch", plural(n, "SHA-pinned action"))
+	}
+	if n := len(result.Inputs); n > 0 {
+		msg += fmt.Sprintf(", and %s with step inputs", plural(n, "problem"))
+	}
+	if n := len(result.Commands); n > 0 {
+		msg += fmt.Sprintf(", and %s", plural(n, "deprecated workflow command"))
+	}
+	fmt.Println(ghaction.Error(msg + "; see the job summary for details"))
+	return exitOutdated
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"aver/pkg/actions"
+	"aver/pkg/badge"
+)
+
+// runBadge implements `aver badge`, which checks the project and writes an
+// SVG badge of the result
+func runBadge(args []string) {
+	output, _ := flagValue(args, "-o", "--output", "-output", "output")
+	sess := newSession(args)
+
+	refs, err := sess.references()
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+	result, err := actions.NewChecker(checkOptions(args, sess)...).Check(context.Background(), refs)
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+	for _, warning := range result.Warnings {
+		warn(warning)
+	}
+
+	svg := badge.Result(result)
+	if output == "" || output == "-" {
+		_, _ = os.Stdout.Write(svg)
+		return
+	}
+	if err := os.WriteFile(output, svg, 0o644); err != nil {
+		fatal(err.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"aver/pkg/cache"
+)
+
+const cacheUsageText = `Usage:
+  aver cache <command> [options]
+
+Commands:
+  stats          Show how many responses are cached, their size and age
+  clear          Delete every cached response
+  path           Print the cache directory
+
+Options:
+  --cache-dir DIR  Use DIR instead of the default cache directory
+  --json           Print stats as JSON`
+
+// runCache implements the cache subcommand
+func runCache(args []string) {
+	if len(args) == 0 || hasFlag(args, "help", "--help", "-h") {
+		fmt.Println(cacheUsageText)
+		os.Exit(exitOK)
+	}
+
+	dir, _ := flagValue(args, "--cache-dir", "-cache-dir", "cache-dir")
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			fatal(err.Error())
+		}
+	}
+	c := cache.New(dir, 0)
+
+	switch args[0] {
+	case "path":
+		fmt.Println(c.Dir)
+	case "stats":
+		stats, err := c.Stats()
+		if err != nil {
+			fatal(err.Error())
+		}
+		if hasFlag(args, "--json", "-json", "json") {
+			data, err := json.MarshalIndent(struct {
+				Dir string `json:"dir"`
+				cache.Stats
+			}{c.Dir, stats}, "", "  ")
+			if err != nil {
+				fatal(err.Error())
+			}
+			fmt.Println(string(data))
+			return
+		}
+		printCacheStats(c, stats)
+	case "clear":
+		removed, err := c.Clear()
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Printf("Removed %d cached responses from %s\n", removed, c.Dir)
+	default:
+		fatal(fmt.Sprintf("unknown cache command %q; run `aver cache help`", args[0]))
+	}
+}
+
+func printCacheStats(c *cache.Cache, stats cache.Stats) {
+	fmt.Printf("Directory:  %s\n", c.Dir)
+	fmt.Printf("Entries:    %d (%d fresh, %d stale; fresh for %s)\n",
+		stats.Entries, stats.Fresh, stats.Entries-stats.Fresh, c.TTL)
+	fmt.Printf("Size:       %s\n", humanBytes(stats.Bytes))
+	if stats.Entries > 0 {
+		fmt.Printf("Oldest:     %s (%s ago)\n", stats.Oldest.Format(time.DateTime), humanAge(stats.Oldest))
+		fmt.Printf("Newest:     %s (%s ago)\n", stats.Newest.Format(time.DateTime), humanAge(stats.Newest))
+	}
+}
+
+// humanBytes formats n like "1.2 MB"
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// humanAge formats the time since t, rounded to a sensible unit
+func humanAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return age.Round(time.Second).String()
+	case age < time.Hour:
+		return age.Round(time.Minute).String()
+	default:
+		return age.Round(time.Hour).String()
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/ghaction"
+)
+
+// commitTarget is the commit --check-run and --commit-status report on
+type commitTarget struct {
+	repo string
+	sha  string
+}
+
+// commitTargetFlags finds the commit to report on from --report-repo and
+// --sha, falling back to the commit the workflow runs for
+func commitTargetFlags(args []string) commitTarget {
+	target := commitTarget{repo: os.Getenv("GITHUB_REPOSITORY"), sha: ghaction.HeadSHA()}
+	if repo, _ := flagValue(args, "--report-repo", "-report-repo", "report-repo"); repo != "" {
+		target.repo = repo
+	}
+	if sha, _ := flagValue(args, "--sha", "-sha", "sha"); sha != "" {
+		target.sha = sha
+	}
+	if target.sha == "" || strings.Count(target.repo, "/") != 1 {
+		fatal("--check-run and --commit-status need a commit: run them in a workflow or pass --report-repo OWNER/REPO and --sha SHA")
+	}
+	return target
+}
+
+// reportCheckRun reports the result as a check run with an annotation on
+// each finding
+func reportCheckRun(checker *actions.Checker, target commitTarget, result actions.CheckResult) {
+	run := actions.NewCheckRun(target.sha, result, ghaction.Markdown(result, githubRepoURL))
+	created, err := checker.ReportCheckRun(context.Background(), target.repo, run)
+	if err != nil {
+		warnf("could not create a check run on %s@%s: %v", target.repo, shortSHA(target.sha), err)
+		return
+	}
+	if created.HTMLURL != "" {
+		notef("Created check run %s", created.HTMLURL)
+	}
+}
+
+// reportCommitStatus reports the result as an "aver" commit status
+func reportCommitStatus(checker *actions.Checker, target commitTarget, result actions.CheckResult) {
+	status := actions.CommitStatus{State: "success", Description: "All actions are up to date", Context: "aver"}
+	if !result.UpToDate() {
+		status.State = "failure"
+		status.Description = plural(len(result.Outdated), "outdated action")
+		if n := len(result.SHAPinned); n > 0 {
+			status.Description += ", " + plural(n, "SHA pin") + " behind"
+		}
+	}
+	// Link to the workflow run, where the full report is
+	if server, repo, id := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"); server != "" && repo != "" && id != "" {
+		status.TargetURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, id)
+	}
+
+	if err := checker.ReportStatus(context.Background(), target.repo, target.sha, status); err != nil {
+		warnf("could not set a commit status on %s@%s: %v", target.repo, shortSHA(target.sha), err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/ghaction"
+)
+
+// commentMarker identifies aver's comment among the others on a pull request
+const commentMarker = "<!-- aver -->"
+
+// pullRequest is where --comment-pr posts
+type pullRequest struct {
+	repo   string
+	number int
+}
+
+// pullRequestFlags finds the pull request to comment on from --report-repo
+// and --pr, falling back to the pull request that triggered the workflow
+func pullRequestFlags(args []string) pullRequest {
+	repo, _ := flagValue(args, "--report-repo", "-report-repo", "report-repo")
+	number, _ := flagValue(args, "--pr", "-pr", "pr")
+
+	pr := pullRequest{repo: os.Getenv("GITHUB_REPOSITORY")}
+	if envRepo, envNumber, ok := ghaction.PullRequest(); ok {
+		pr = pullRequest{repo: envRepo, number: envNumber}
+	}
+	if repo != "" {
+		pr.repo = repo
+	}
+	if number != "" {
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 {
+			fatal(fmt.Sprintf("invalid --pr %q", number))
+		}
+		pr.number = n
+	}
+	if pr.number == 0 || strings.Count(pr.repo, "/") != 1 {
+		fatal("--comment-pr needs a pull request: run it in a pull_request workflow or pass --report-repo OWNER/REPO and --pr NUMBER")
+	}
+	return pr
+}
+
+// commentOnPR posts the report on the pull request, editing aver's earlier
+// comment rather than adding another. Up-to-date runs only edit an
+// existing comment, so clean pull requests stay quiet.
+func commentOnPR(checker *actions.Checker, pr pullRequest, result actions.CheckResult) {
+	body := commentMarker + "\n" + ghaction.Markdown(result, githubRepoURL)
+	comment, err := checker.UpsertComment(context.Background(), pr.repo, pr.number, commentMarker, body, result.UpToDate())
+	if err != nil {
+		warnf("could not comment on %s#%d: %v", pr.repo, pr.number, err)
+		return
+	}
+	if comment.HTMLURL != "" {
+		notef("Commented on %s", comment.HTMLURL)
+	}
+}
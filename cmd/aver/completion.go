@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/completion"
+	"aver/pkg/fix"
+)
+
+// completionSpec describes aver's command line for completion scripts.
+// Flags that take a value must also be in valueFlags.
+var completionSpec = completion.Spec{
+	Program: "aver",
+	Commands: []completion.Command{
+		{Name: "check", Help: "Check the given workflow files"},
+		{Name: "cache", Help: "Inspect or clear the response cache", Args: []string{"stats", "clear", "path"}},
+		{Name: "fix", Help: "Update outdated actions in the workflows"},
+		{Name: "lock", Help: "Record the commit every tag and branch points at"},
+		{Name: "verify", Help: "Fail if a tag or branch moved since aver lock"},
+		{Name: "history", Help: "List the findings of earlier runs"},
+		{Name: "trend", Help: "Chart outdated and unpinned actions over time"},
+		{Name: "badge", Help: "Write an SVG badge"},
+		{Name: "watch", Help: "Re-check periodically, notifying on changes"},
+		{Name: "install-hooks", Help: "Check workflows in a git hook"},
+		{Name: "init", Help: "Write a Dependabot or Renovate configuration", Args: []string{"dependabot", "renovate"}},
+		{Name: "doctor", Help: "Check the token, API, rate limit, workflows and cache"},
+		{Name: "serve", Help: "Serve checks as a JSON API"},
+		{Name: "completion", Help: "Print a shell completion script", Args: completion.Shells},
+		{Name: "self-update", Help: "Replace aver with the latest release"},
+		{Name: "help", Help: "Print the help message"},
+		{Name: "version", Help: "Print the version of aver"},
+	},
+	Flags: []completion.Flag{
+		{Name: "json", Help: "Output results as JSON"},
+		{Name: "format", Help: "Output format", Arg: completion.ArgChoice, Choices: append([]string{"table", "json", rdjsonFormat}, sbomFormats...)},
+		{Name: "ignore-sha", Help: "Ignore SHA-pinned actions"},
+		{Name: "sha-compare", Help: "Compare SHA pins with the branch, tags or both", Arg: completion.ArgChoice, Choices: actions.SHACompareModes},
+		{Name: "ignore-minor", Help: "Only check major version differences"},
+		{Name: "releases", Help: "Take the latest version from published releases"},
+		{Name: "include-prereleases", Help: "Recommend prerelease versions"},
+		{Name: "min-release-age", Help: "Skip versions published less than AGE ago", Arg: completion.ArgValue},
+		{Name: "notes", Help: "Print release notes between versions"},
+		{Name: "group-by", Help: "Group the tables", Arg: completion.ArgChoice, Choices: actions.Groupings},
+		{Name: "owner", Help: "Only report the actions OWNER publishes", Arg: completion.ArgValue},
+		{Name: "sort", Help: "Order findings", Arg: completion.ArgChoice, Choices: actions.SortOrders},
+		{Name: "dedupe", Help: "Print identical findings in several files once"},
+		{Name: "stats", Help: "Print a summary of the check"},
+		{Name: "workflow-dir", Help: "Also check the workflows in DIR", Arg: completion.ArgDir},
+		{Name: "exclude", Help: "Leave out workflow files matching GLOB", Arg: completion.ArgValue},
+		{Name: "only", Help: "Only check the actions named NAME", Arg: completion.ArgAction},
+		{Name: "skip", Help: "Leave out the actions named NAME", Arg: completion.ArgAction},
+		{Name: "recursive", Short: "r", Help: "Check every .github/workflows directory in the project"},
+		{Name: "include-submodules", Help: "Also check the workflows of git submodules"},
+		{Name: "strict-parse", Help: "Fail on a workflow that can't be parsed"},
+		{Name: "strict", Help: "Exit with status 2 after any warning, dynamic ref or unchecked action"},
+		{Name: "check-inputs", Help: "Check step inputs against each action's action.yml"},
+		{Name: "check-commands", Help: "Report deprecated workflow commands in run: scripts"},
+		{Name: "warn-personal-actions", Help: "Warn about actions owned by personal accounts"},
+		{Name: "track-tags", Help: "Warn about tags that moved since the last run"},
+		{Name: "state", Help: "Where to remember tag commits between runs", Arg: completion.ArgFile},
+		{Name: "baseline", Help: "Only report findings missing from a saved report", Arg: completion.ArgFile},
+		{Name: "github-action", Help: "Run as a GitHub Action"},
+		{Name: "repo", Help: "Check a repository's workflows through the API", Arg: completion.ArgValue},
+		{Name: "ref", Help: "The branch, tag or commit of --repo to check", Arg: completion.ArgValue},
+		{Name: "sha", Help: "The commit to report to", Arg: completion.ArgValue},
+		{Name: "org", Help: "Check every repository in ORG", Arg: completion.ArgValue},
+		{Name: "repos-file", Help: "Check the repositories listed in FILE", Arg: completion.ArgFile},
+		{Name: "comment-pr", Help: "Post the results as a pull request comment"},
+		{Name: "report-repo", Help: "The repository to comment on or report to", Arg: completion.ArgValue},
+		{Name: "pr", Help: "The pull request to comment on", Arg: completion.ArgValue},
+		{Name: "check-run", Help: "Report the results as a check run"},
+		{Name: "commit-status", Help: "Report the results as a commit status"},
+		{Name: "record-history", Help: "Record the run for aver history and aver trend"},
+		{Name: "history", Help: "Where run summaries are recorded", Arg: completion.ArgFile},
+		{Name: "notify", Help: "Send the results to the configured notifications"},
+		{Name: "quiet", Short: "q", Help: "Only print errors to stderr"},
+		{Name: "verbose", Help: "Also log skipped actions, failed checks and cache hits"},
+		{Name: "debug", Help: "Also log API requests and how versions were chosen"},
+		{Name: "api-url", Help: "GitHub API root or GitHub Enterprise Server hostname", Arg: completion.ArgValue},
+		{Name: "ca-cert", Help: "Trust the PEM CA certificates in FILE", Arg: completion.ArgFile},
+		{Name: "insecure", Help: "Skip TLS certificate verification"},
+		{Name: "offline", Help: "Answer only from the cache"},
+		{Name: "no-cache", Help: "Fetch fresh data, ignoring the cache"},
+		{Name: "cache-dir", Help: "Cache location", Arg: completion.ArgDir},
+		{Name: "max-api-requests", Help: "Send at most N API requests", Arg: completion.ArgValue},
+		{Name: "days", Help: "Days of history to chart (aver trend)", Arg: completion.ArgValue},
+		{Name: "output", Short: "o", Help: "File to write", Arg: completion.ArgFile},
+		{Name: "interval", Help: "How often to check (aver watch, aver init dependabot)", Arg: completion.ArgValue},
+		{Name: "pre-commit", Help: "Install a pre-commit hook instead (aver install-hooks)"},
+		{Name: "force", Help: "Replace an existing hook or file, or a development build"},
+		{Name: "unify", Help: "Pin each action used at several versions to one (aver fix)"},
+		{Name: "dry-run", Help: "Show what would change without writing (aver fix)"},
+		{Name: "pin-sha", Help: "Pin updated actions to the commit of their new version (aver fix)"},
+		{Name: "strategy", Help: "Largest update to make (aver fix)", Arg: completion.ArgChoice, Choices: fix.Strategies},
+		{Name: "commit", Help: "Commit the changes to each action separately (aver fix)"},
+		{Name: "branch", Help: "Commit the changes on a new branch (aver fix)", Arg: completion.ArgValue},
+		{Name: "check", Help: "Only report whether a newer release exists (aver self-update)"},
+		{Name: "addr", Help: "Address to serve on (aver serve)", Arg: completion.ArgValue},
+		{Name: "help", Short: "h", Help: "Print the help message"},
+		{Name: "version", Short: "v", Help: "Print the version of aver"},
+	},
+	Actions: "aver completion actions",
+}
+
+// runCompletion implements `aver completion bash|zsh|fish`, and
+// `aver completion actions`, which the scripts run to complete action names
+func runCompletion(args []string) {
+	if len(args) == 0 {
+		fatal("usage: aver completion " + strings.Join(completion.Shells, "|"))
+	}
+	if args[0] == "actions" {
+		printActionNames()
+		return
+	}
+	script, err := completion.Script(args[0], completionSpec)
+	if err != nil {
+		fatal(err.Error())
+	}
+	fmt.Print(script)
+}
+
+// printActionNames prints the actions the project in the working directory
+// uses, once each. It runs on every completion, so it stays off the network
+// and quietly prints nothing outside a project.
+func printActionNames() {
+	root, err := actions.FindProjectRoot(".")
+	if err != nil {
+		return
+	}
+	refs, err := actions.FindActionReferences(root)
+	if err != nil && !errors.Is(err, &actions.ErrUnparsed{}) {
+		return
+	}
+	var names []string
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		fmt.Println(name)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"aver/pkg/actions"
+	"aver/pkg/doctor"
+)
+
+// doctorMarks label each result
+var doctorMarks = map[doctor.Status]string{doctor.OK: "✓", doctor.Warn: "!", doctor.Fail: "✗"}
+
+// runDoctor implements `aver doctor`, which checks that aver can work here
+// and says how to fix what can't
+func runDoctor(args []string) {
+	sess := newSessionAnywhere(args)
+	checker := actions.NewChecker(sess.opts...)
+	results := doctor.Run(context.Background(), doctor.Env{
+		Host:        sess.host,
+		TokenSource: sess.tokenSource,
+		Offline:     sess.offline,
+		TokenInfo:   checker.TokenInfo,
+		References:  sess.references,
+		CacheDir:    sess.cacheDir,
+	})
+
+	for _, r := range results {
+		fmt.Printf("%s %-10s  %s\n", doctorMarks[r.Status], r.Name, r.Detail)
+		if r.Fix != "" {
+			fmt.Printf("  %-10s  fix: %s\n", "", r.Fix)
+		}
+	}
+	// A failed check means aver can't work here, not that anything is
+	// outdated
+	if doctor.Failed(results) {
+		os.Exit(exitError)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/fix"
+	"aver/pkg/table"
+)
+
+// runFix implements `aver fix`, which updates the outdated actions in the
+// project's workflows to the versions a check recommends. With --unify it
+// pins each action used at several versions to one of them, and with
+// --pin-sha it pins tags' commits rather than the tags and updates SHA pins
+// to the commit of the latest release. --strategy caps how far an update
+// goes, and --commit commits the changes to each action separately, on a
+// new branch with --branch.
+func runFix(args []string) {
+	strategy, _ := flagValue(args, "--strategy", "-strategy", "strategy")
+	if strategy != "" && !slices.Contains(fix.Strategies, strategy) {
+		fatal(fmt.Sprintf("unknown --strategy %q; use %s", strategy, strings.Join(fix.Strategies, ", ")))
+	}
+	opts := fix.Options{
+		Unify:    hasFlag(args, "--unify", "-unify", "unify"),
+		PinSHA:   hasFlag(args, "--pin-sha", "-pin-sha", "pin-sha"),
+		Strategy: strategy,
+	}
+	dryRun := hasFlag(args, "--dry-run", "-dry-run", "dry-run")
+	// No bare form, since "branch" is also a value of --sha-compare
+	branch, _ := flagValue(args, "--branch", "-branch")
+	commit := hasFlag(args, "--commit", "-commit", "commit") || branch != ""
+	jsonOutput := hasFlag(args, "--json", "-json", "json")
+	sess := newSession(args)
+	// SHA pins are only updated when asked: with an explicit --sha-compare
+	// or sha_compare, or with --pin-sha, which compares them by tag since a
+	// pin can only move to a release's commit once its own release is known
+	switch mode, _ := flagValue(args, "--sha-compare", "-sha-compare", "sha-compare"); {
+	case mode != "" || sess.cfg.SHACompare != "":
+	case opts.PinSHA:
+		args = append(args, "--sha-compare", actions.SHACompareTag)
+	default:
+		args = append(args, "--ignore-sha")
+	}
+
+	refs, err := sess.references()
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+	options := checkOptions(args, sess)
+	// Recommend the newest version the strategy allows, not one it rules out
+	if rule, ok := fix.IgnoreRule(strategy); ok {
+		options = append(options, actions.WithIgnoreRules([]actions.IgnoreRule{rule}))
+	}
+	result, err := actions.NewChecker(options...).Check(context.Background(), refs)
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+	for _, warning := range result.Warnings {
+		warn(warning)
+	}
+
+	edits := fix.Plan(result, refs, opts)
+	var fixed fix.Result
+	if commit {
+		fixed, err = fix.CommitEach(sess.root, edits, branch, dryRun)
+	} else {
+		fixed, err = fix.Apply(sess.root, edits, dryRun)
+	}
+	if err != nil {
+		fatal(err.Error())
+	}
+	for _, e := range fixed.Skipped {
+		warnf("%s@%s in %s wasn't updated: line %d doesn't pin it with uses:", e.Name, e.From, e.File, e.Line)
+	}
+
+	if jsonOutput {
+		if fixed.Changes == nil {
+			fixed.Changes = []fix.Change{}
+		}
+		data, err := json.MarshalIndent(fixed, "", "  ")
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if len(fixed.Changes) == 0 {
+		fmt.Println("Nothing to update")
+	} else {
+		printChangesTable(fixed.Changes)
+		verb := "Updated"
+		if dryRun {
+			verb = "Would update"
+		}
+		fmt.Printf("\n%s %s in %s\n", verb, plural(len(fixed.Changes), "reference"), plural(changedFiles(fixed.Changes), "file"))
+	}
+	if len(fixed.Commits) > 0 {
+		on := ""
+		if branch != "" {
+			on = " on " + branch
+		}
+		if dryRun {
+			fmt.Printf("\nWould commit%s:\n", on)
+		} else {
+			fmt.Printf("\nCommitted%s:\n", on)
+		}
+		for _, c := range fixed.Commits {
+			subject, _, _ := strings.Cut(c.Message, "\n")
+			fmt.Printf("  %s %s\n", cmp.Or(shortSHA(c.SHA), "-"), subject)
+		}
+	}
+	if !opts.Unify {
+		if n := unifiable(refs, fixed.Changes); n == 1 {
+			notef("1 action is still pinned to several versions; --unify pins it to one")
+		} else if n > 1 {
+			notef("%d actions are still pinned to several versions; --unify pins each to one", n)
+		}
+	}
+}
+
+// unifiable counts the actions that --unify would pin to one version once
+// changes are made to refs
+func unifiable(refs []actions.ActionReference, changes []fix.Change) int {
+	to := make(map[string]string)
+	for _, c := range changes {
+		to[c.File+"\x00"+c.Name+"@"+c.From] = c.To
+	}
+	fixed := make([]actions.ActionReference, len(refs))
+	for i, ref := range refs {
+		ref.Version = cmp.Or(to[ref.File+"\x00"+ref.Name+"@"+ref.Version], ref.Version)
+		fixed[i] = ref
+	}
+	var n int
+	for _, d := range actions.FindDrift(fixed) {
+		if d.Unified != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func printChangesTable(changes []fix.Change) {
+	t := table.New("File", "Action", "From", "To")
+	for _, c := range changes {
+		t.Cells(
+			table.Text(c.File+":"+strconv.Itoa(c.Line)),
+			linked(githubRepoURL(c.Name), c.Name),
+			table.Text(pinned(c.From, c.FromTag)),
+			table.Text(pinned(c.To, c.Tag)))
+	}
+	_ = t.Write(os.Stdout)
+}
+
+// pinned formats a version a change pins, or a commit and the version it
+// is: "11bd719 (v4.2.2)"
+func pinned(version, tag string) string {
+	if tag == "" {
+		return version
+	}
+	return shortSHA(version) + " (" + tag + ")"
+}
+
+// changedFiles counts the files changes are in
+func changedFiles(changes []fix.Change) int {
+	files := make(map[string]bool)
+	for _, c := range changes {
+		files[c.File] = true
+	}
+	return len(files)
+}
+
+// printDriftTable lists the actions pinned to different versions in
+// different places, with the files that pin each version
+func printDriftTable(drift []actions.VersionDrift) {
+	fmt.Println("Version drift (actions pinned to several versions):")
+	t := table.New("Action", "Version", "Files", "Unify to")
+	for _, d := range drift {
+		name := d.Name
+		if d.Repository != "" {
+			name = d.Repository + ": " + name
+		}
+		unified := cmp.Or(d.Unified, "-")
+		for i, v := range d.Versions {
+			action, to := linked(githubRepoURL(d.Name), name), table.Text(unified)
+			if i > 0 {
+				action, to = table.Text(""), table.Text("")
+			}
+			t.Cells(action, table.Text(v.Version), table.Text(strings.Join(v.Files, ", ")), to)
+		}
+	}
+	_ = t.Write(os.Stdout)
+}
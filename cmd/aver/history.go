@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"aver/pkg/actions"
+	"aver/pkg/state"
+)
+
+// historyPath returns the history file from --history or the default
+func historyPath(args []string) string {
+	if path, _ := flagValue(args, "--history", "-history", "history"); path != "" {
+		return path
+	}
+	path, err := state.DefaultHistoryPath()
+	if err != nil {
+		fatal(err.Error())
+	}
+	return path
+}
+
+// recordHistory reports whether runs are recorded: with --record-history,
+// --history or record_history
+func recordHistory(args []string, sess *session) bool {
+	path, _ := flagValue(args, "--history", "-history", "history")
+	return path != "" || hasFlag(args, "--record-history", "-record-history", "record-history") || sess.cfg.RecordHistory
+}
+
+// recordRun adds a summary of a complete run to the history file
+func recordRun(path, project string, refs []actions.ActionReference, result actions.CheckResult) {
+	run := state.Run{
+		Time:     time.Now().UTC(),
+		Project:  project,
+		Refs:     len(refs),
+		Outdated: len(result.Outdated),
+		Behind:   len(result.SHAPinned),
+	}
+	for _, ref := range refs {
+		if !ref.SHAPinned() {
+			run.Unpinned++
+		}
+	}
+	if err := state.AppendRun(path, run); err != nil {
+		warn("could not record history:", err)
+	}
+}
+
+// projectRuns loads the recorded runs of the project in the directory on
+// the command line or the working directory
+func projectRuns(args []string) []state.Run {
+	root, err := actions.FindProjectRoot(projectDir(args))
+	if err != nil {
+		fatal(err.Error())
+	}
+	runs, err := state.LoadRuns(historyPath(args), root)
+	if err != nil {
+		fatal(err.Error())
+	}
+	return runs
+}
+
+// runHistory implements `aver history`, which lists recorded runs
+func runHistory(args []string) {
+	runs := projectRuns(args)
+
+	if hasFlag(args, "--json", "-json", "json") {
+		if runs == nil {
+			runs = []state.Run{}
+		}
+		data, err := json.MarshalIndent(runs, "", "  ")
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded for this project yet")
+		return
+	}
+
+	fmt.Printf("%-16s  %4s  %8s  %6s  %8s\n", "Time", "Refs", "Outdated", "Behind", "Unpinned")
+	fmt.Printf("%s  %s  %s  %s  %s\n",
+		strings.Repeat("-", 16), strings.Repeat("-", 4), strings.Repeat("-", 8),
+		strings.Repeat("-", 6), strings.Repeat("-", 8))
+	for _, run := range runs {
+		fmt.Printf("%-16s  %4d  %8d  %6d  %8d\n",
+			run.Time.Local().Format("2006-01-02 15:04"), run.Refs, run.Outdated, run.Behind, run.Unpinned)
+	}
+}
+
+// runTrend implements `aver trend`, which charts the last run of each day
+func runTrend(args []string) {
+	days := 30
+	if value, ok := flagValue(args, "--days", "-days", "days"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			fatal(fmt.Sprintf("invalid --days %q", value))
+		}
+		days = n
+	}
+
+	// Keep the last run of each day within the window
+	since := time.Now().AddDate(0, 0, -days)
+	var daily []state.Run
+	for _, run := range projectRuns(args) {
+		if run.Time.Before(since) {
+			continue
+		}
+		if n := len(daily); n > 0 && sameDay(daily[n-1].Time, run.Time) {
+			daily[n-1] = run
+			continue
+		}
+		daily = append(daily, run)
+	}
+	if len(daily) == 0 {
+		fmt.Printf("No runs recorded for this project in the last %d days\n", days)
+		return
+	}
+
+	most := 1
+	for _, run := range daily {
+		most = max(most, run.Outdated+run.Behind)
+	}
+	const barWidth = 40
+	fmt.Printf("%-10s  %8s  %6s  %8s\n", "Date", "Outdated", "Behind", "Unpinned")
+	for _, run := range daily {
+		bar := strings.Repeat("#", run.Outdated*barWidth/most) + strings.Repeat("+", run.Behind*barWidth/most)
+		fmt.Printf("%-10s  %8d  %6d  %8d  %s\n",
+			run.Time.Local().Format(time.DateOnly), run.Outdated, run.Behind, run.Unpinned, bar)
+	}
+
+	first, last := daily[0], daily[len(daily)-1]
+	fmt.Printf("\nSince %s: outdated %s, behind %s, unpinned %s\n",
+		first.Time.Local().Format(time.DateOnly),
+		change(first.Outdated, last.Outdated), change(first.Behind, last.Behind), change(first.Unpinned, last.Unpinned))
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Local().Format(time.DateOnly) == b.Local().Format(time.DateOnly)
+}
+
+// change formats a before and after count, e.g. "12 -> 3 (-9)"
+func change(before, after int) string {
+	return fmt.Sprintf("%d -> %d (%+d)", before, after, after-before)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"aver/pkg/hooks"
+)
+
+// runInstallHooks implements `aver install-hooks`, which installs a git
+// hook that checks workflows before they're pushed (or committed)
+func runInstallHooks(args []string) {
+	name := "pre-push"
+	if hasFlag(args, "--pre-commit", "-pre-commit", "pre-commit") {
+		name = "pre-commit"
+	}
+	force := hasFlag(args, "--force", "-force", "force")
+
+	// git knows where hooks go: core.hooksPath, worktrees and submodules
+	// all move them
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		fatal("not in a git repository (or git isn't installed)")
+	}
+	dir, err := filepath.Abs(strings.TrimSpace(string(out)))
+	if err != nil {
+		fatal(err.Error())
+	}
+
+	path, err := hooks.Install(dir, name, force)
+	if errors.Is(err, hooks.ErrExists) {
+		fatal(fmt.Sprintf("%v; add aver to it yourself or pass --force to replace it", err))
+	}
+	if err != nil {
+		fatal(err.Error())
+	}
+	fmt.Printf("Installed %s hook in %s\n", name, path)
+}
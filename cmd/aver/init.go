@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"aver/pkg/actions"
+	"aver/pkg/config"
+	"aver/pkg/dependabot"
+	"aver/pkg/renovate"
+)
+
+// runInit implements `aver init`, which sets up another update tool to
+// follow aver's settings
+func runInit(args []string) {
+	if len(args) == 0 {
+		fatal("usage: aver init dependabot|renovate [options]")
+	}
+	switch args[0] {
+	case "dependabot":
+		initDependabot(args[1:])
+	case "renovate":
+		initRenovate(args[1:])
+	default:
+		fatal(fmt.Sprintf("unknown tool %q; use dependabot or renovate", args[0]))
+	}
+}
+
+// initDependabot writes or updates the github-actions entry of the
+// project's .github/dependabot.yml
+func initDependabot(args []string) {
+	root, err := actions.FindProjectRoot(projectDir(args))
+	if err != nil {
+		fatal(err.Error())
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		fatal(err.Error())
+	}
+	for _, warning := range cfg.Warnings {
+		warn(warning)
+	}
+
+	settings := dependabot.Settings{
+		Cooldown:    time.Duration(cfg.MinReleaseAge),
+		IgnoreMinor: hasFlag(args, "--ignore-minor", "-ignore-minor", "ignore-minor"),
+	}
+	if value, ok := flagValue(args, "--min-release-age", "-min-release-age", "min-release-age"); ok {
+		if settings.Cooldown, err = config.ParseDuration(value); err != nil {
+			fatal(err.Error())
+		}
+	}
+	if value, ok := flagValue(args, "--interval", "-interval", "interval"); ok {
+		interval, err := config.ParseDuration(value)
+		if err != nil || interval == 0 {
+			fatal(fmt.Sprintf("invalid --interval %q; use e.g. 1d or 1w", value))
+		}
+		settings.Interval = dependabot.Interval(interval)
+	}
+
+	output, _ := flagValue(args, "-o", "--output", "-output", "output")
+	path := output
+	if output == "" || output == "-" {
+		path = filepath.Join(root, dependabot.File)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fatal(err.Error())
+	}
+	data, err := dependabot.Merge(existing, settings)
+	if err != nil {
+		fatal(err.Error())
+	}
+
+	if output == "-" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fatal(err.Error())
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fatal(err.Error())
+	}
+	verb := "Updated"
+	if existing == nil {
+		verb = "Wrote"
+	}
+	fmt.Printf("%s %s\n", verb, path)
+}
+
+// initRenovate prints a Renovate configuration fragment for the project's
+// actions, pinning them if most already are
+func initRenovate(args []string) {
+	sess := newSession(args)
+	refs, err := sess.references()
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+
+	settings := renovate.Settings{
+		Pin:           actions.PinsCommits(refs),
+		MinReleaseAge: time.Duration(sess.cfg.MinReleaseAge),
+		IgnoreMinor:   hasFlag(args, "--ignore-minor", "-ignore-minor", "ignore-minor"),
+		Prereleases:   sess.cfg.IncludePrereleases,
+	}
+	if value, ok := flagValue(args, "--min-release-age", "-min-release-age", "min-release-age"); ok {
+		if settings.MinReleaseAge, err = config.ParseDuration(value); err != nil {
+			fatal(err.Error())
+		}
+	}
+	data, err := renovate.Marshal(settings)
+	if err != nil {
+		fatal(err.Error())
+	}
+
+	output, _ := flagValue(args, "-o", "--output", "-output", "output")
+	if output == "" || output == "-" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	// Renovate reads one file; merging into it is left to the user
+	if _, err := os.Stat(output); err == nil && !hasFlag(args, "--force", "-force", "force") {
+		fatal(fmt.Sprintf("%s already exists; merge the output of aver init renovate into it or pass --force to replace it", output))
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		fatal(err.Error())
+	}
+	fmt.Printf("Wrote %s\n", output)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"aver/pkg/actions"
+	"aver/pkg/table"
+)
+
+// checkInputs adds the problems with the inputs the project's steps pass
+// to result, checked against the metadata of each action
+func checkInputs(checker *actions.Checker, sess *session, refs []actions.ActionReference, result *actions.CheckResult) error {
+	steps, err := actions.FindSteps(sess.root, refs)
+	if err != nil {
+		return err
+	}
+	// Steps name their actions the way the references found in the same
+	// workflows do
+	stepRefs := make([]actions.ActionReference, len(steps))
+	for i, step := range steps {
+		stepRefs[i] = step.Ref
+	}
+	for i, ref := range actions.QualifyActions(stepRefs, sess.cfg.DefaultActionsURL) {
+		steps[i].Ref = ref
+	}
+
+	inputs, err := checker.CheckInputs(context.Background(), steps)
+	if err != nil {
+		return err
+	}
+	result.Inputs = inputs.Findings
+	result.Warnings = append(result.Warnings, inputs.Warnings...)
+	return nil
+}
+
+func printInputsTable(inputs []actions.InputFinding) {
+	t := table.New("File", "Action", "Input", "Problem")
+	for _, f := range inputs {
+		t.Cells(
+			table.Text(f.File+":"+strconv.Itoa(f.Line)),
+			linked(githubRepoURL(f.Name), f.Name+"@"+f.Version),
+			table.Text(f.Input),
+			table.Text(inputProblem(f)))
+	}
+	_ = t.Write(os.Stdout)
+}
+
+func printCommandsTable(commands []actions.DeprecatedCommand) {
+	t := table.New("File", "Command", "Use instead")
+	for _, d := range commands {
+		t.Row(d.File+":"+strconv.Itoa(d.Line), "::"+d.Command, d.UseInstead)
+	}
+	_ = t.Write(os.Stdout)
+}
+
+// inputProblem describes what's wrong with an input in a few words
+func inputProblem(f actions.InputFinding) string {
+	switch {
+	case f.Suggestion != "":
+		return fmt.Sprintf("%s; did you mean %s?", f.Problem, f.Suggestion)
+	case f.Deprecation != "":
+		return fmt.Sprintf("%s: %s", f.Problem, f.Deprecation)
+	}
+	return f.Problem
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/lock"
+	"aver/pkg/table"
+)
+
+// resolveRefs resolves every tag and branch used in the project's workflows
+func resolveRefs(sess *session) actions.ResolveResult {
+	refs, err := sess.references()
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+	result, err := actions.NewChecker(sess.opts...).Resolve(context.Background(), refs)
+	if err != nil {
+		fatal(describeError(err, sess.authenticated))
+	}
+	for _, warning := range result.Warnings {
+		warn(warning)
+	}
+	return result
+}
+
+// runLock implements `aver lock`, which records the commit every tag and
+// branch resolves to
+func runLock(args []string) {
+	sess := newSession(args)
+	result := resolveRefs(sess)
+
+	path := filepath.Join(sess.root, lock.File)
+	if err := lock.New(result.Refs).Save(path); err != nil {
+		fatal(err.Error())
+	}
+	fmt.Printf("Locked %d refs in %s\n", len(result.Refs), path)
+}
+
+// runVerify implements `aver verify`, which fails if a tag or branch no
+// longer resolves to the commit in the lockfile
+func runVerify(args []string) {
+	jsonOutput := hasFlag(args, "--json", "-json", "json")
+	sess := newSession(args)
+
+	path := filepath.Join(sess.root, lock.File)
+	locked, err := lock.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fatal(fmt.Sprintf("%s not found; run `aver lock` to create it", path))
+	}
+	if err != nil {
+		fatal(err.Error())
+	}
+
+	mismatches := locked.Verify(resolveRefs(sess).Refs)
+	if jsonOutput {
+		if mismatches == nil {
+			mismatches = []lock.Mismatch{}
+		}
+		data, err := json.MarshalIndent(struct {
+			Mismatches []lock.Mismatch `json:"mismatches"`
+		}{mismatches}, "", "  ")
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Println(string(data))
+	} else if len(mismatches) > 0 {
+		fmt.Printf("Refs that don't match %s:\n", lock.File)
+		printMismatchTable(mismatches)
+		fmt.Println("\nIf these changes are expected, run `aver lock` to update the lockfile.")
+	}
+
+	if len(mismatches) > 0 {
+		os.Exit(exitOutdated)
+	}
+	os.Exit(exitOK)
+}
+
+func printMismatchTable(mismatches []lock.Mismatch) {
+	t := table.New("Ref", "Locked SHA", "Current SHA")
+	for _, m := range mismatches {
+		repo, _, _ := strings.Cut(m.Ref, "@")
+		t.Cells(
+			table.Text(m.Ref),
+			table.Text(lockedSHA(m)),
+			linked(githubCommitURL(repo, m.Current), shortSHA(m.Current)))
+	}
+	_ = t.Write(os.Stdout)
+}
+
+// lockedSHA formats the locked side of a mismatch
+func lockedSHA(m lock.Mismatch) string {
+	if m.Locked == "" {
+		return "(not locked)"
+	}
+	return shortSHA(m.Locked)
+}
@@ -1,20 +1,41 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"aver/pkg/actions"
+	"aver/pkg/auth"
+	"aver/pkg/buildinfo"
+	"aver/pkg/cache"
+	"aver/pkg/config"
+	"aver/pkg/dependabot"
+	"aver/pkg/ghaction"
+	"aver/pkg/state"
+	"aver/pkg/table"
 )
 
-// Version info set by goreleaser ldflags
+// Version info set by goreleaser ldflags; main fills in what's unset from
+// the build information Go embeds
 var (
-	version = "dev"
-	commit  = "none"
-	date    = "unknown"
+	version = buildinfo.DevVersion
+	commit  = buildinfo.NoCommit
+	date    = buildinfo.UnknownDate
 )
 
 // Exit codes
@@ -25,22 +46,191 @@ const (
 )
 
 func fatal(msg string) {
+	if githubAction {
+		fmt.Println(ghaction.Error(msg))
+		os.Exit(exitError)
+	}
 	fmt.Fprintln(os.Stderr, "error:", msg)
 	os.Exit(exitError)
 }
 
+// describeError turns a checker error into an actionable message.
+// authenticated reports whether a GitHub token was in use.
+func describeError(err error, authenticated bool) string {
+	var (
+		rateLimited   *actions.ErrRateLimited
+		overBudget    *actions.ErrOverBudget
+		notAccessible *actions.ErrRepoNotAccessible
+		network       *actions.ErrNetwork
+		unparsed      *actions.ErrUnparsed
+		parse         *actions.ErrParse
+	)
+	switch {
+	case errors.As(err, &rateLimited) && rateLimited.Secondary:
+		// A token doesn't help here; fewer requests at once do
+		msg := "GitHub API secondary rate limit exceeded by sending requests too quickly"
+		if !rateLimited.Reset.IsZero() {
+			msg += fmt.Sprintf("; retry after %s", rateLimited.Reset.Format(time.Kitchen))
+		}
+		return msg
+	case errors.As(err, &rateLimited):
+		msg := "GitHub API rate limit exceeded"
+		if !rateLimited.Reset.IsZero() {
+			msg += fmt.Sprintf("; it resets at %s", rateLimited.Reset.Format(time.Kitchen))
+		}
+		if !authenticated {
+			msg += ". Set GITHUB_TOKEN or run `gh auth login` to raise the limit"
+		}
+		return msg
+	case errors.As(err, &overBudget):
+		msg := fmt.Sprintf("this check needs at least %d GitHub API requests but only %d are left; the rate limit resets at %s",
+			overBudget.Needed, overBudget.Remaining, overBudget.Reset.Format(time.Kitchen))
+		if !authenticated {
+			msg += ". Set GITHUB_TOKEN or run `gh auth login` to raise the limit"
+		}
+		return msg
+	case errors.As(err, &network):
+		return fmt.Sprintf("could not reach the GitHub API: %v", network.Err)
+	case errors.As(err, &notAccessible) && notAccessible.Hint() != "":
+		return fmt.Sprintf("%v; %s", err, notAccessible.Hint())
+	case errors.As(err, &unparsed) && len(unparsed.Errs) > 1:
+		msgs := make([]string, len(unparsed.Errs))
+		for i, e := range unparsed.Errs {
+			msgs[i] = fmt.Sprintf("could not parse %s: %v", e.Source, e.Err)
+		}
+		return strings.Join(msgs, "\n")
+	case errors.As(err, &parse):
+		return fmt.Sprintf("could not parse %s: %v", parse.Source, parse.Err)
+	}
+	return err.Error()
+}
+
 const usageText = `aver: GitHub Actions version checker
 
 Usage:
-  aver [options]
+  aver [options] [DIR] [FILE...]
+                          Check the workflows of the project in DIR (default:
+                          the working directory), or only the given files
+  aver check [options] [FILE...]
+                          The same, for editors and hooks checking given files;
+                          "-" reads a workflow from stdin
+  aver cache stats|clear|path
+  aver fix [--unify] [--pin-sha] [--strategy S] [--commit] [--branch B]
+           [--dry-run] [options]
+                          Update outdated actions in the workflows to the
+                          recommended versions, and SHA pins to the commit of
+                          the latest release; --unify also pins each action
+                          used at several versions to the newest of them,
+                          --pin-sha pins by commit instead of by tag, and
+                          --strategy patch|minor|major caps the updates made;
+                          --commit makes a git commit for each action, and
+                          --branch B makes them on a new branch B (no push)
+  aver lock [options]     Record the commit every tag and branch points at
+  aver verify [options]   Fail if a tag or branch moved since aver lock
+  aver history [--json]   List the findings of earlier runs in this project
+  aver trend [--days N]   Chart outdated and unpinned actions over time
+  aver badge [-o FILE]    Write an SVG badge like "actions: 3 outdated"
+  aver watch [--interval D] Re-check every D (default 24h), notifying on changes
+  aver install-hooks [--pre-commit] [--force]
+                          Check workflows in a git pre-push (or pre-commit) hook
+  aver init dependabot [--interval D] [--ignore-minor] [-o FILE]
+                          Write the github-actions entry of .github/dependabot.yml
+                          from aver's settings, such as min_release_age
+  aver init renovate [--ignore-minor] [-o FILE]
+                          Print a Renovate configuration for the project's actions
+  aver doctor             Check the token, API, rate limit, workflows and cache
+  aver serve [--addr A]   Serve checks as a JSON API on A (default localhost:8080)
+  aver completion bash|zsh|fish
+                          Print a shell completion script
+  aver self-update [--check] [--force]
+                          Replace aver with the latest release, verifying its
+                          checksum; --check only reports whether there is one
 
 Options:
   help           Print this help message
   version        Print the version of aver
   --json         Output results as JSON
+  --format F     Output results as a table (default), json, rdjson diagnostics
+                 for reviewdog, or a cyclonedx or spdx bill of materials of
+                 every action
   --ignore-sha   Ignore SHA-pinned actions
+  --sha-compare M  Compare SHA pins with their default branch (branch, the
+                 default), the latest release after the version they're
+                 tagged as (tag), or both
   --ignore-minor Only check major version differences
-  --quiet        Suppress progress indicator
+  --releases     Take the latest version from published releases, not tags
+  --include-prereleases  Recommend prerelease versions such as v5.0.0-rc.1
+  --min-release-age AGE  Skip versions published less than AGE ago (e.g. 7d)
+  --notes        Print release notes between current and latest versions
+  --group-by G   Group the tables by file (default), action or owner
+  --owner OWNER  Only report the findings about actions OWNER publishes, e.g.
+                 actions or my-org, though every action is checked; may be
+                 repeated
+  --sort S       Order findings by severity, commits (behind) or age
+  --dedupe       Print identical findings in several files once, with the
+                 number of files (and the list of them in JSON)
+  --stats        Print a summary of workflows, pins, outdated actions by
+                 severity, API requests and time, and the workflows and
+                 steps that use each action (in JSON output too)
+  --workflow-dir DIR  Also check the workflows in DIR (relative to the project
+                 root); may be given more than once
+  --exclude GLOB Leave out workflow files matching GLOB, e.g.
+                 '.github/workflows/experimental-*.yml'; may be repeated
+  --only NAME    Only check (and fix) the action or repository NAME, e.g.
+                 actions/checkout or my-org/*; may be repeated
+  --skip NAME    Leave out the action or repository NAME, e.g. a flaky
+                 my-org/deploy or other-org/*; may be repeated
+  --recursive    Check every .github/workflows directory in the project, e.g.
+                 of vendored subprojects, not just the root's
+  --include-submodules  Also check the workflows of the git submodules in
+                 .gitmodules, reported under each submodule's path
+  --strict-parse Fail on a workflow that can't be parsed, instead of warning
+                 and checking the rest
+  --strict       Exit with status 2 after any warning (e.g. skipped repositories
+                 or workflows), dynamic ref or action that wasn't checked
+  --check-inputs Check the with: inputs of each step against the action's
+                 action.yml, reporting ones it doesn't declare or has deprecated,
+                 and required ones that are missing
+  --check-commands  Report run: scripts that use deprecated workflow commands
+                 such as ::set-output
+  --warn-personal-actions  Warn about actions whose repository a personal
+                 account owns rather than an organization
+  --track-tags   Remember tag commits between runs and warn about moved tags
+  --state FILE   Where to remember them (implies --track-tags)
+  --baseline FILE  Only report findings missing from FILE, a saved --json report
+  --github-action  Run as a GitHub Action: read INPUT_* variables, annotate
+                 findings and write the job summary and step outputs
+  --repo OWNER/REPO  Check a repository's workflows through the API instead of
+                 the local project, at --ref REF (default: its default branch)
+  --org ORG      Check the workflows of every unarchived repository in ORG,
+                 reporting the findings by repository
+  --repos-file FILE  Check the repositories listed in FILE, one owner/name per
+                 line or a JSON array, reporting the findings by repository
+  --comment-pr   Post the results as a comment on the pull request, editing it
+                 on later runs; takes --report-repo OWNER/REPO and --pr NUMBER
+                 outside pull_request workflows
+  --check-run    Report the results as a check run with annotations on the commit
+                 (--report-repo OWNER/REPO and --sha SHA outside workflows)
+  --commit-status  Report the results as an "aver" commit status instead
+  --record-history  Record a summary of the run for aver history and aver
+                 trend (or set record_history in .aver.yml)
+  --history FILE Record run summaries in FILE instead of the default history
+                 file (implies --record-history)
+  --notify       Send the results to the notifications configured in your user
+                 config
+  -q, --quiet    Only print errors to stderr: no warnings, progress or status
+                 lines
+  --verbose      Also log skipped actions, failed checks and cache hits, and
+                 end with API usage by category and the rate limit left
+  --debug        Also log API requests, rate limits and how versions were chosen
+  --api-url URL  GitHub API root or GitHub Enterprise Server hostname
+  --ca-cert FILE Trust the PEM CA certificates in FILE (e.g. for a proxy)
+  --insecure     Skip TLS certificate verification
+  --offline      Answer only from the cache and list actions it can't check
+  --max-api-requests N  Send at most N API requests, listing the actions left
+                 over as not checked
+  --no-cache     Fetch fresh data, ignoring (but refreshing) the cache
+  --cache-dir DIR  Cache location, e.g. a cache copied from another machine
 
 Check GitHub Actions versions in the current project.
 
@@ -54,7 +244,16 @@ Examples:
   aver --json         Output as JSON
   aver --ignore-sha   Ignore SHA-pinned actions
   aver --ignore-minor Only report major version updates
-  aver --quiet        Run without progress indicator
+  aver --quiet        Print nothing to stderr but errors
+  aver --debug        Show why an action was skipped
+  aver --api-url ghes.example.com  Check against GitHub Enterprise Server
+  aver ~/src/app      Check the project in ~/src/app without cd-ing there
+  aver --repo cli/cli Check another repository without cloning it
+  aver --org my-org   Check every repository of an organization
+  aver --offline      Check without network access using cached data
+  aver cache stats    Show the size and age of the response cache
+  aver verify --json  Check workflows against aver.lock
+  aver fix --dry-run  Show what updating the outdated actions would change
   aver help           Show this help message`
 
 func shortSHA(sha string) string {
@@ -78,16 +277,135 @@ func repoFromAction(name string) string {
 	return name
 }
 
+// webURL is the root of links in table output. It points at the GitHub
+// Enterprise Server instance when one is configured.
+var webURL = "https://github.com"
+
+// routes send some actions to other hosts; their links follow them
+var routes []actions.Route
+
+// webRoot returns the web root of the host an action is resolved against
+func webRoot(name string) string {
+	if route, ok := actions.MatchRoute(routes, name); ok {
+		return actions.WebURL(route.BaseURL)
+	}
+	return webURL
+}
+
 func githubRepoURL(name string) string {
-	return fmt.Sprintf("https://github.com/%s", repoFromAction(name))
+	if actions.IsOrb(name) {
+		return actions.OrbURL(name)
+	}
+	if actions.IsPipe(name) || actions.IsDockerImage(name) {
+		return actions.PipeURL(name)
+	}
+	if actions.IsAzureTask(name) {
+		return actions.AzureTaskURL(name, "")
+	}
+	if url, ok := actions.ForgeRepoURL(name); ok {
+		return url
+	}
+	return fmt.Sprintf("%s/%s", webRoot(name), repoFromAction(name))
 }
 
 func githubCommitURL(name, sha string) string {
-	return fmt.Sprintf("https://github.com/%s/commit/%s", repoFromAction(name), sha)
+	if actions.IsGitLabComponent(name) {
+		return fmt.Sprintf("%s/-/commit/%s", githubRepoURL(name), sha)
+	}
+	return fmt.Sprintf("%s/commit/%s", githubRepoURL(name), sha)
 }
 
 func githubTagURL(name, tag string) string {
-	return fmt.Sprintf("https://github.com/%s/releases/tag/%s", repoFromAction(name), tag)
+	if actions.IsOrb(name) {
+		return fmt.Sprintf("%s?version=%s", actions.OrbURL(name), tag)
+	}
+	if actions.IsAzureTask(name) {
+		return actions.AzureTaskURL(name, tag)
+	}
+	if actions.IsGitLabComponent(name) {
+		return fmt.Sprintf("%s/-/releases/%s", githubRepoURL(name), tag)
+	}
+	return fmt.Sprintf("%s/releases/tag/%s", githubRepoURL(name), tag)
+}
+
+// configRoutes builds routes from the config's hosts map. If lookupTokens
+// is set, each host gets the token stored for it.
+func configRoutes(cfg *config.Config, apiURL string, lookupTokens bool) ([]actions.Route, error) {
+	var routes []actions.Route
+	for prefix, hostURL := range cfg.Hosts {
+		if err := actions.ValidatePrefix(prefix); err != nil {
+			return nil, err
+		}
+		baseURL, err := actions.NormalizeBaseURL(hostURL)
+		if err != nil {
+			return nil, err
+		}
+		route := actions.Route{Prefix: prefix, BaseURL: baseURL}
+		if lookupTokens {
+			token, source, err := auth.Token(cfg.TokenCommand, apiHost(baseURL))
+			if err != nil {
+				return nil, err
+			}
+			// $GITHUB_TOKEN and token_command don't say which host they're
+			// for, so they only go to the main host; on a GHES runner sending
+			// the GHES token to github.com would fail every request
+			if apiHost(baseURL) == apiHost(apiURL) ||
+				(source != auth.SourceGitHubToken && source != auth.SourceGHToken && source != auth.SourceCommand) {
+				route.Token = token
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// configTagRules compiles the config's tag rules
+func configTagRules(cfg *config.Config) (map[string]actions.TagRule, error) {
+	rules := make(map[string]actions.TagRule, len(cfg.Tags))
+	for prefix, rule := range cfg.Tags {
+		r := actions.TagRule{StripPrefix: rule.StripPrefix}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag pattern for %s: %w", prefix, err)
+			}
+			r.Pattern = re
+		}
+		rules[prefix] = r
+	}
+	return rules, nil
+}
+
+// dependabotIgnores reads the ignore rules of the project's Dependabot
+// configuration if dependabot_ignores is set. They only describe the local
+// project, so they aren't applied to repositories checked through the API.
+func dependabotIgnores(args []string, sess *session) ([]actions.IgnoreRule, error) {
+	if !sess.cfg.DependabotIgnores {
+		return nil, nil
+	}
+	for _, flag := range []string{"repo", "org", "repos-file"} {
+		if _, ok := flagValue(args, "--"+flag, "-"+flag, flag); ok {
+			return nil, nil
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(sess.root, dependabot.File))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dependabot.IgnoreRules(data)
+}
+
+// apiHost returns the hostname tokens are looked up for, e.g. "github.com"
+// for "https://api.github.com"
+func apiHost(apiURL string) string {
+	u, err := url.Parse(actions.WebURL(apiURL))
+	if err != nil || u.Host == "" {
+		return auth.DefaultHost
+	}
+	return u.Host
 }
 
 func printSHATable(shaPinned []actions.SHAPinnedAction) {
@@ -95,129 +413,325 @@ func printSHATable(shaPinned []actions.SHAPinnedAction) {
 		return
 	}
 
+	// With --sha-compare tag or both, the release each pin is tagged as
+	tagged := slices.ContainsFunc(shaPinned, func(a actions.SHAPinnedAction) bool { return a.CurrentTag != "" })
 	headers := []string{"File", "Action", "Current SHA", "Latest SHA", "Branch", "Behind"}
-
-	widths := make([]int, len(headers))
-	for i, h := range headers {
-		widths[i] = len(h)
+	if tagged {
+		headers = append(headers, "Release")
 	}
-
+	t := table.New(headers...)
 	for _, a := range shaPinned {
-		if len(a.File) > widths[0] {
-			widths[0] = len(a.File)
-		}
-		if len(a.Name) > widths[1] {
-			widths[1] = len(a.Name)
+		cells := []table.Cell{
+			table.Text(fileColumn(a.File, a.Files)),
+			linked(githubRepoURL(a.Name), a.Name),
+			linked(githubCommitURL(a.Name, a.CurrentSHA), shortSHA(a.CurrentSHA)),
+			linked(githubCommitURL(a.Name, a.LatestSHA), shortSHA(a.LatestSHA)),
+			table.Text(cmp.Or(a.DefaultBranch, "-")),
+			table.Text(fmt.Sprint(a.CommitsBehind)),
 		}
-		currentShort := shortSHA(a.CurrentSHA)
-		latestShort := shortSHA(a.LatestSHA)
-		if len(currentShort) > widths[2] {
-			widths[2] = len(currentShort)
+		if tagged {
+			cells = append(cells, table.Text(releaseColumn(a)))
 		}
-		if len(latestShort) > widths[3] {
-			widths[3] = len(latestShort)
-		}
-		if len(a.DefaultBranch) > widths[4] {
-			widths[4] = len(a.DefaultBranch)
-		}
-		behindStr := fmt.Sprintf("%d", a.CommitsBehind)
-		if len(behindStr) > widths[5] {
-			widths[5] = len(behindStr)
+		t.Cells(cells...)
+	}
+	_ = t.Write(os.Stdout)
+}
+
+// releaseColumn formats the release a SHA pin is tagged as and the newer
+// one out, e.g. "v4.1.0 → v4.2.2", or "-" if it isn't tagged
+func releaseColumn(a actions.SHAPinnedAction) string {
+	switch {
+	case a.CurrentTag == "":
+		return "-"
+	case a.LatestTag == "":
+		return a.CurrentTag
+	}
+	return a.CurrentTag + " → " + a.LatestTag
+}
+
+func printOutdatedTable(outdated []actions.OutdatedAction) {
+	if len(outdated) == 0 {
+		return
+	}
+
+	t := table.New("File", "Action", "Current", "Published", "Latest", "Published", "Behind", "Commits", "Latest SHA")
+	for _, a := range outdated {
+		// Short like the SHA table's; JSON and aver fix --pin-sha have the
+		// full SHA
+		latestSHA := table.Text("-")
+		if a.LatestSHA != "" {
+			latestSHA = linked(githubCommitURL(a.Name, a.LatestSHA), shortSHA(a.LatestSHA))
 		}
+		t.Cells(
+			table.Text(fileColumn(a.File, a.Files)),
+			linked(githubRepoURL(a.Name), a.Name),
+			linked(githubTagURL(a.Name, a.CurrentVersion), a.CurrentVersion),
+			table.Text(publishedDate(a.CurrentPublished)),
+			linked(githubTagURL(a.Name, a.LatestVersion), a.LatestVersion),
+			table.Text(publishedDate(a.LatestPublished)),
+			table.Text(daysBehind(a)),
+			table.Text(commitsBehind(a)),
+			latestSHA)
 	}
+	_ = t.Write(os.Stdout)
+}
 
-	fmt.Printf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s\n",
-		widths[0], headers[0],
-		widths[1], headers[1],
-		widths[2], headers[2],
-		widths[3], headers[3],
-		widths[4], headers[4],
-		widths[5], headers[5])
+// linked is a table cell that links text to url in terminals that support
+// hyperlinks
+func linked(url, text string) table.Cell {
+	return table.Cell{Text: text, Wrap: func(s string) string { return hyperlink(url, s) }}
+}
 
-	fmt.Printf("%s  %s  %s  %s  %s  %s\n",
-		strings.Repeat("-", widths[0]),
-		strings.Repeat("-", widths[1]),
-		strings.Repeat("-", widths[2]),
-		strings.Repeat("-", widths[3]),
-		strings.Repeat("-", widths[4]),
-		strings.Repeat("-", widths[5]))
+// fileColumn formats the File column: the file, or how many files share a
+// finding merged by --dedupe
+func fileColumn(file string, files []string) string {
+	if len(files) > 1 {
+		return fmt.Sprintf("%d files", len(files))
+	}
+	return file
+}
 
-	for _, a := range shaPinned {
-		actionLink := hyperlink(githubRepoURL(a.Name), fmt.Sprintf("%-*s", widths[1], a.Name))
-		currentLink := hyperlink(githubCommitURL(a.Name, a.CurrentSHA), fmt.Sprintf("%-*s", widths[2], shortSHA(a.CurrentSHA)))
-		latestLink := hyperlink(githubCommitURL(a.Name, a.LatestSHA), fmt.Sprintf("%-*s", widths[3], shortSHA(a.LatestSHA)))
+// commitsBehind formats how many commits the latest version is ahead, or
+// "-" if unknown
+func commitsBehind(a actions.OutdatedAction) string {
+	if a.CommitsBehind == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", a.CommitsBehind)
+}
 
-		fmt.Printf("%-*s  %s  %s  %s  %-*s  %-*d\n",
-			widths[0], a.File,
-			actionLink,
-			currentLink,
-			latestLink,
-			widths[4], a.DefaultBranch,
-			widths[5], a.CommitsBehind)
+// publishedDate formats a publish date for the table, or "-" if unknown
+func publishedDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
 	}
+	return t.Format(time.DateOnly)
 }
 
-func printOutdatedTable(outdated []actions.OutdatedAction) {
-	if len(outdated) == 0 {
-		return
+// daysBehind formats how much older the current version is than the latest
+// and how many releases came out in between, e.g. "151 days, 6 releases"
+func daysBehind(a actions.OutdatedAction) string {
+	var parts []string
+	if !a.CurrentPublished.IsZero() && !a.LatestPublished.IsZero() {
+		parts = append(parts, plural(a.DaysBehind, "day"))
+	}
+	if a.ReleasesBehind > 0 {
+		parts = append(parts, plural(a.ReleasesBehind, "release"))
 	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
 
-	headers := []string{"File", "Action", "Current", "Latest"}
+// strictProblems describes what fails a --strict run: warnings, dynamic
+// refs and actions that weren't checked, or "" if there's nothing
+func strictProblems(result actions.CheckResult) string {
+	var problems []string
+	if warnings > 0 {
+		problems = append(problems, plural(warnings, "warning"))
+	}
+	if n := len(result.Dynamic); n > 0 {
+		problems = append(problems, plural(n, "dynamic ref"))
+	}
+	if n := len(result.Unchecked); n > 0 {
+		problems = append(problems, plural(n, "action")+" not checked")
+	}
+	return strings.Join(problems, ", ")
+}
 
-	widths := make([]int, len(headers))
-	for i, h := range headers {
-		widths[i] = len(h)
+// plural formats a count of things, e.g. "1 day" or "6 days"
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
 	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
 
+// maxNoteLines caps how much of each release's notes is printed
+const maxNoteLines = 8
+
+// printNotes prints a condensed changelog for each outdated action
+func printNotes(outdated []actions.OutdatedAction) {
+	if len(outdated) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Release notes:")
+	seen := make(map[string]bool)
 	for _, a := range outdated {
-		if len(a.File) > widths[0] {
-			widths[0] = len(a.File)
+		// The same upgrade in several workflows only needs its notes once
+		key := a.Name + "@" + a.CurrentVersion + "..." + a.LatestVersion
+		if seen[key] {
+			continue
 		}
-		if len(a.Name) > widths[1] {
-			widths[1] = len(a.Name)
+		seen[key] = true
+
+		fmt.Printf("%s %s -> %s\n", hyperlink(githubRepoURL(a.Name), a.Name), a.CurrentVersion, a.LatestVersion)
+		if len(a.Notes) == 0 {
+			fmt.Println("  No published releases")
+			continue
 		}
-		if len(a.CurrentVersion) > widths[2] {
-			widths[2] = len(a.CurrentVersion)
+		for _, release := range a.Notes {
+			fmt.Printf("  %s", hyperlink(githubTagURL(a.Name, release.TagName), release.TagName))
+			if !release.PublishedAt.IsZero() {
+				fmt.Printf(" (%s)", release.PublishedAt.Format(time.DateOnly))
+			}
+			fmt.Println()
+			lines := condenseNotes(release.Body)
+			for i, line := range lines {
+				if i == maxNoteLines {
+					fmt.Printf("    ... %d more lines\n", len(lines)-maxNoteLines)
+					break
+				}
+				fmt.Printf("    %s\n", line)
+			}
 		}
-		if len(a.LatestVersion) > widths[3] {
-			widths[3] = len(a.LatestVersion)
+	}
+}
+
+// condenseNotes drops blank lines, headings and comparison links from a
+// release body and shortens long lines
+func condenseNotes(body string) []string {
+	var lines []string
+	for line := range strings.Lines(body) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--") ||
+			strings.HasPrefix(line, "**Full Changelog**") {
+			continue
 		}
+		if runes := []rune(line); len(runes) > 100 {
+			line = string(runes[:97]) + "..."
+		}
+		lines = append(lines, line)
 	}
+	return lines
+}
 
-	fmt.Printf("%-*s  %-*s  %-*s  %-*s\n",
-		widths[0], headers[0],
-		widths[1], headers[1],
-		widths[2], headers[2],
-		widths[3], headers[3])
+// printGroups prints the findings under a heading for each action or owner
+// they're grouped by, or as one set of tables when grouped by file
+func printGroups(result actions.CheckResult, groupBy string, notes bool) {
+	if groupBy == "" || groupBy == actions.GroupByFile {
+		printFindings(result, notes)
+		return
+	}
+	for i, group := range result.GroupBy(groupBy) {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(group.Key)
+		fmt.Println(strings.Repeat("-", table.Width(group.Key)))
+		printFindings(group.Result, notes)
+	}
+}
 
-	fmt.Printf("%s  %s  %s  %s\n",
-		strings.Repeat("-", widths[0]),
-		strings.Repeat("-", widths[1]),
-		strings.Repeat("-", widths[2]),
-		strings.Repeat("-", widths[3]))
+// printFindings prints the tables of outdated, behind, unchecked and
+// dynamic actions, of problems with step inputs and of deprecated workflow
+// commands
+func printFindings(result actions.CheckResult, notes bool) {
+	if len(result.Outdated) > 0 {
+		fmt.Println("Outdated actions:")
+		printOutdatedTable(result.Outdated)
+	}
+	if len(result.SHAPinned) > 0 {
+		if len(result.Outdated) > 0 {
+			fmt.Println()
+		}
+		fmt.Println("SHA-pinned actions behind default branch:")
+		printSHATable(result.SHAPinned)
+	}
+	if len(result.Inputs) > 0 {
+		if len(result.Outdated) > 0 || len(result.SHAPinned) > 0 {
+			fmt.Println()
+		}
+		fmt.Println("Problems with step inputs:")
+		printInputsTable(result.Inputs)
+	}
+	if len(result.Commands) > 0 {
+		if len(result.Outdated) > 0 || len(result.SHAPinned) > 0 || len(result.Inputs) > 0 {
+			fmt.Println()
+		}
+		fmt.Println("Deprecated workflow commands:")
+		printCommandsTable(result.Commands)
+	}
+	if notes {
+		printNotes(result.Outdated)
+	}
+	if len(result.Unchecked) > 0 {
+		fmt.Println()
+		fmt.Println("Actions not checked:")
+		printUncheckedTable(result.Unchecked)
+	}
+	if len(result.Dynamic) > 0 {
+		fmt.Println()
+		printDynamicTable(result.Dynamic)
+	}
+}
 
-	for _, a := range outdated {
-		actionLink := hyperlink(githubRepoURL(a.Name), fmt.Sprintf("%-*s", widths[1], a.Name))
-		currentLink := hyperlink(githubTagURL(a.Name, a.CurrentVersion), fmt.Sprintf("%-*s", widths[2], a.CurrentVersion))
-		latestLink := hyperlink(githubTagURL(a.Name, a.LatestVersion), fmt.Sprintf("%-*s", widths[3], a.LatestVersion))
+// printDynamicTable lists the references to expressions, which can run any
+// version without the workflow changing
+func printDynamicTable(dynamic []actions.DynamicRef) {
+	fmt.Println("Actions with dynamic refs (not pinned, not checked):")
+	t := table.New("File", "Action", "Ref")
+	for _, d := range dynamic {
+		t.Row(fileColumn(d.File, d.Files), d.Name, d.Ref)
+	}
+	_ = t.Write(os.Stdout)
+}
 
-		fmt.Printf("%-*s  %s  %s  %s\n",
-			widths[0], a.File,
-			actionLink,
-			currentLink,
-			latestLink)
+func printUncheckedTable(unchecked []actions.UncheckedAction) {
+	t := table.New("File", "Action", "Version", "Reason")
+	for _, a := range unchecked {
+		t.Cells(
+			table.Text(fileColumn(a.File, a.Files)),
+			linked(githubRepoURL(a.Name), a.Name),
+			table.Text(a.Version),
+			table.Text(a.Reason))
 	}
+	_ = t.Write(os.Stdout)
 }
 
 type jsonOutput struct {
-	Outdated  []actions.OutdatedAction  `json:"outdated"`
-	SHAPinned []actions.SHAPinnedAction `json:"sha_pinned"`
+	Outdated   []actions.OutdatedAction    `json:"outdated"`
+	SHAPinned  []actions.SHAPinnedAction   `json:"sha_pinned"`
+	Unchecked  []actions.UncheckedAction   `json:"unchecked,omitempty"`
+	Moved      []actions.MovedTag          `json:"moved,omitempty"`
+	Dynamic    []actions.DynamicRef        `json:"dynamic,omitempty"`
+	Inputs     []actions.InputFinding      `json:"inputs,omitempty"`
+	Commands   []actions.DeprecatedCommand `json:"deprecated_commands,omitempty"`
+	Typosquats []actions.Typosquat         `json:"typosquats,omitempty"`
+	Forks      []actions.SuspiciousFork    `json:"suspicious_forks,omitempty"`
+	Drift      []actions.VersionDrift      `json:"version_drift,omitempty"`
+	Owners     map[string]string           `json:"owners,omitempty"`
+	Stats      *actions.Stats              `json:"stats,omitempty"`
 }
 
-func printJSON(result actions.CheckResult) error {
+// printJSON prints result, with its summary if stats is set
+func printJSON(result actions.CheckResult, stats bool) error {
+	// File names like "<stdin>" are printed as is
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	output := newJSONOutput(result)
+	if stats {
+		output.Stats = &result.Stats
+	}
+	return enc.Encode(output)
+}
+
+func newJSONOutput(result actions.CheckResult) jsonOutput {
 	output := jsonOutput{
-		Outdated:  result.Outdated,
-		SHAPinned: result.SHAPinned,
+		Outdated:   result.Outdated,
+		SHAPinned:  result.SHAPinned,
+		Unchecked:  result.Unchecked,
+		Moved:      result.Moved,
+		Dynamic:    result.Dynamic,
+		Inputs:     result.Inputs,
+		Commands:   result.Commands,
+		Typosquats: result.Typosquats,
+		Forks:      result.Forks,
+		Drift:      result.Drift,
+		Owners:     result.Owners,
 	}
 	if output.Outdated == nil {
 		output.Outdated = []actions.OutdatedAction{}
@@ -225,12 +739,7 @@ func printJSON(result actions.CheckResult) error {
 	if output.SHAPinned == nil {
 		output.SHAPinned = []actions.SHAPinnedAction{}
 	}
-	data, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return err
-	}
-	fmt.Println(string(data))
-	return nil
+	return output
 }
 
 // Remaining spinner and other utility functions from the original implementation...
@@ -263,12 +772,12 @@ func (s *spinner) start() {
 				// Clear the spinner
 				fmt.Fprint(os.Stderr, "\r\033[K")
 				return
-			case name := <-s.action:
-				s.current = name
+			case msg := <-s.action:
+				s.current = msg
 			default:
 				msg := "Checking actions..."
 				if s.current != "" {
-					msg = fmt.Sprintf("Checking %s...", s.current)
+					msg = s.current
 				}
 				fmt.Fprintf(os.Stderr, "\r\033[K%s %s", s.frames[i%len(s.frames)], msg)
 				i++
@@ -278,14 +787,29 @@ func (s *spinner) start() {
 	}()
 }
 
-func (s *spinner) update(action string) {
+// update sets the message shown next to the spinner
+func (s *spinner) update(msg string) {
 	select {
-	case s.action <- action:
+	case s.action <- msg:
 	default:
 		// Don't block if channel is full
 	}
 }
 
+// onEvent updates the spinner message from checker progress events
+func (s *spinner) onEvent(e actions.Event) {
+	switch e := e.(type) {
+	case actions.Started:
+		s.update(fmt.Sprintf("Checking %s...", e.Ref.Name))
+	case actions.RateLimited:
+		if e.Reset.IsZero() {
+			s.update("Rate limited by GitHub...")
+		} else {
+			s.update(fmt.Sprintf("Rate limited by GitHub until %s...", e.Reset.Format(time.Kitchen)))
+		}
+	}
+}
+
 func (s *spinner) finish() {
 	close(s.stop)
 	<-s.stopped
@@ -296,7 +820,7 @@ func printHelp() {
 }
 
 func printVersion() {
-	fmt.Printf("aver version %s (commit: %s, built: %s)\n", version, commit, date)
+	fmt.Printf("aver version %s\n", buildinfo.Info{Version: version, Commit: commit, Date: date})
 }
 
 func hasFlag(args []string, flags ...string) bool {
@@ -310,6 +834,95 @@ func hasFlag(args []string, flags ...string) bool {
 	return false
 }
 
+// hookCacheTTL is how long cached responses stay fresh when checking files
+// named on the command line, as pre-commit hooks do
+const hookCacheTTL = 24 * time.Hour
+
+// valueFlags take the next argument as their value
+var valueFlags = []string{"min-release-age", "state", "baseline", "history", "api-url", "ca-cert", "cache-dir", "report-repo", "repo", "ref", "org", "repos-file", "workflow-dir", "exclude", "pr", "sha", "o", "output", "addr", "interval", "days", "format", "group-by", "sort", "max-api-requests", "sha-compare", "strategy", "branch", "only", "skip", "owner"}
+
+// workflowFiles returns the YAML files named on the command line, and "-"
+// if a workflow is to be read from stdin
+func workflowFiles(args []string) []string {
+	var files []string
+	for i, arg := range args {
+		if i > 0 && slices.Contains(valueFlags, strings.TrimLeft(args[i-1], "-")) {
+			continue
+		}
+		if ext := filepath.Ext(arg); ((ext == ".yml" || ext == ".yaml") && !strings.HasPrefix(arg, "-")) || arg == "-" {
+			files = append(files, arg)
+		}
+	}
+	return files
+}
+
+// projectDir returns the directory named on the command line, as in
+// `aver /path/to/repo`, or else the working directory
+func projectDir(args []string) string {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") || (i > 0 && slices.Contains(valueFlags, strings.TrimLeft(args[i-1], "-"))) {
+			continue
+		}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			dir, err := filepath.Abs(arg)
+			if err != nil {
+				fatal(err.Error())
+			}
+			return dir
+		}
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		fatal(err.Error())
+	}
+	return dir
+}
+
+// flagValue returns the value of the first of flags found in args, given as
+// either "--flag value" or "--flag=value"
+func flagValue(args []string, flags ...string) (string, bool) {
+	for i, arg := range args {
+		for _, flag := range flags {
+			if arg == flag && i+1 < len(args) {
+				return args[i+1], true
+			}
+			if value, ok := strings.CutPrefix(arg, flag+"="); ok {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// flagValues returns the value of every use of a repeatable flag
+func flagValues(args []string, flags ...string) []string {
+	var values []string
+	for i := 0; i < len(args); i++ {
+		for _, flag := range flags {
+			if args[i] == flag && i+1 < len(args) {
+				values = append(values, args[i+1])
+				i++
+				break
+			}
+			if value, ok := strings.CutPrefix(args[i], flag+"="); ok {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+	return values
+}
+
+// remoteTarget returns the repository --repo checks through the API instead
+// of the local project, and the branch, tag or commit --ref checks it at.
+// --report-repo and --sha don't choose what's checked: they name where
+// --comment-pr and --check-run report.
+func remoteTarget(args []string) (repo, ref string) {
+	repo, _ = flagValue(args, "--repo", "-repo", "repo")
+	ref, _ = flagValue(args, "--ref", "-ref", "ref")
+	return repo, ref
+}
+
 // isTerminal returns true if the file is a terminal
 func isTerminal(f *os.File) bool {
 	stat, err := f.Stat()
@@ -319,87 +932,687 @@ func isTerminal(f *os.File) bool {
 	return (stat.Mode() & os.ModeCharDevice) != 0
 }
 
+// session is what every command that talks to GitHub shares: the project,
+// its config and the options for a Checker
+type session struct {
+	dir           string // The working directory
+	root          string // The project root
+	cfg           *config.Config
+	opts          []actions.Option
+	authenticated bool   // Whether a token or GitHub App is in use
+	tokenSource   string // Where the token came from, if there is one
+	host          string // The GitHub host, e.g. github.com
+	cacheDir      string // Where responses are cached, if anywhere
+	offline       bool
+	workflowDirs  []string // Directories searched for workflows besides .github/workflows
+	recursive     bool     // Whether every .github/workflows in the project is searched
+	submodules    bool     // Whether the workflows of git submodules are searched too
+	exclude       []string // Globs of workflow files to leave out
+	only          []string // Actions or owner globs to check, if not all
+	skip          []string // Actions or owner globs to leave out
+	strictParse   bool     // Whether a workflow that doesn't parse is fatal
+}
+
+// references returns the actions used by the project's workflows
+func (s *session) references() ([]actions.ActionReference, error) {
+	find := actions.FindActionReferences
+	if s.recursive {
+		find = actions.FindAllActionReferences
+	}
+	dirs := s.workflowDirs
+	if s.submodules {
+		subDirs, err := actions.SubmoduleWorkflowDirs(s.root)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(slices.Clone(dirs), subDirs...)
+	}
+	refs, err := find(s.dir, dirs...)
+	if err = s.skipUnparsed(err); err != nil {
+		return nil, err
+	}
+	return s.prepare(refs)
+}
+
+// skipUnparsed warns about each workflow that couldn't be parsed and
+// carries on with the rest, or keeps the error with --strict-parse
+func (s *session) skipUnparsed(err error) error {
+	var unparsed *actions.ErrUnparsed
+	if s.strictParse || !errors.As(err, &unparsed) {
+		return err
+	}
+	for _, e := range unparsed.Errs {
+		warnf("skipping %s: %v", e.Source, e.Err)
+	}
+	return nil
+}
+
+// prepare drops excluded workflows and the actions --only and --skip leave
+// out, and names the actions of Forgejo and Gitea workflows after their
+// default actions URL
+func (s *session) prepare(refs []actions.ActionReference) ([]actions.ActionReference, error) {
+	refs, err := actions.Exclude(refs, s.exclude)
+	if err != nil {
+		return nil, err
+	}
+	refs, unmatched := actions.Only(refs, s.only)
+	for _, name := range unmatched {
+		warnf("--only %s matches no action in the workflows", name)
+	}
+	refs = actions.Skip(refs, s.skip)
+	refs = actions.QualifyActions(refs, s.cfg.DefaultActionsURL)
+	return actions.ExpandGitLabHost(refs, cmp.Or(s.cfg.GitLabHost, os.Getenv("CI_SERVER_FQDN"))), nil
+}
+
+// newSession applies the flags every command that talks to GitHub accepts:
+// API URL, TLS settings, tokens, the cache and verbose logging. It's fatal
+// outside a project.
+func newSession(args []string) *session {
+	return openSession(args, true)
+}
+
+// newSessionAnywhere is newSession for commands that don't read the
+// project's workflows, like aver serve, or that report a missing project
+// themselves, like aver doctor. Outside a project, .aver.yml is read from
+// the working directory.
+func newSessionAnywhere(args []string) *session {
+	return openSession(args, false)
+}
+
+func openSession(args []string, needsProject bool) *session {
+	apiURL, _ := flagValue(args, "--api-url", "-api-url", "api-url")
+	caCert, _ := flagValue(args, "--ca-cert", "-ca-cert", "ca-cert")
+	insecure := hasFlag(args, "--insecure", "-insecure", "insecure")
+	offline := hasFlag(args, "--offline", "-offline", "offline")
+	noCache := hasFlag(args, "--no-cache", "-no-cache", "no-cache")
+	cacheDir, _ := flagValue(args, "--cache-dir", "-cache-dir", "cache-dir")
+
+	if offline && noCache {
+		fatal("--offline and --no-cache can't be used together")
+	}
+
+	dir := projectDir(args)
+
+	root, err := actions.FindProjectRoot(dir)
+	if err != nil {
+		if needsProject {
+			fatal(err.Error())
+		}
+		root = dir
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		fatal(err.Error())
+	}
+	for _, warning := range cfg.Warnings {
+		warn(warning)
+	}
+
+	// The API URL comes from the flag, then the config file, then
+	// $GITHUB_API_URL, which Actions sets on GitHub Enterprise Server
+	for _, candidate := range []string{cfg.APIURL, os.Getenv("GITHUB_API_URL")} {
+		if apiURL == "" {
+			apiURL = candidate
+		}
+	}
+	if apiURL != "" {
+		if apiURL, err = actions.NormalizeBaseURL(apiURL); err != nil {
+			fatal(err.Error())
+		}
+		webURL = actions.WebURL(apiURL)
+	}
+
+	// Proxies come from HTTP(S)_PROXY; certificate settings from flags or
+	// the user config
+	if caCert == "" {
+		caCert = cfg.CACert
+	}
+	insecure = insecure || cfg.Insecure
+	var httpClient *http.Client
+	if caCert != "" || insecure {
+		transport, err := actions.NewTransport(caCert, insecure)
+		if err != nil {
+			fatal(err.Error())
+		}
+		httpClient = &http.Client{Transport: transport}
+		if insecure {
+			warn("TLS certificate verification is disabled")
+		}
+	}
+
+	// Offline runs never send requests, so they don't need credentials
+	var token, tokenSource string
+	var app *auth.AppTokenSource
+	if !offline {
+		token, tokenSource, err = auth.Token(cfg.TokenCommand, apiHost(apiURL))
+		if err != nil {
+			fatal(err.Error())
+		}
+		app, err = auth.AppFromEnv()
+		if err != nil {
+			fatal(err.Error())
+		}
+		if app != nil {
+			app.BaseURL = apiURL
+			app.Client = httpClient
+			tokenSource = "GitHub App " + app.AppID
+		}
+	}
+	if routes, err = configRoutes(cfg, apiURL, !offline); err != nil {
+		fatal(err.Error())
+	}
+	authenticated := token != "" || app != nil
+
+	opts := []actions.Option{actions.WithToken(token)}
+	if apiURL != "" {
+		opts = append(opts, actions.WithBaseURL(apiURL))
+	}
+	if len(routes) > 0 {
+		opts = append(opts, actions.WithRoutes(routes...))
+	}
+	if httpClient != nil {
+		opts = append(opts, actions.WithHTTPClient(httpClient))
+	}
+	if app != nil {
+		opts = append(opts, actions.WithTokenSource(app))
+	}
+	if cacheDir == "" {
+		cacheDir, _ = cache.DefaultDir()
+	}
+	if cacheDir != "" {
+		opts = append(opts, actions.WithCacheDir(cacheDir))
+	}
+	if offline {
+		opts = append(opts, actions.WithOffline(true))
+	}
+	if noCache {
+		opts = append(opts, actions.WithRefresh(true))
+	}
+	if level >= levelVerbose {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level.logLevel()}))
+		opts = append(opts, actions.WithLogger(logger))
+		if tokenSource == "" {
+			logger.Debug("no GitHub token found, requests are unauthenticated")
+		} else {
+			logger.Debug("using GitHub token", "source", tokenSource)
+		}
+	}
+
+	return &session{
+		dir:           dir,
+		root:          root,
+		cfg:           cfg,
+		opts:          opts,
+		authenticated: authenticated,
+		tokenSource:   tokenSource,
+		host:          apiHost(apiURL),
+		cacheDir:      cacheDir,
+		offline:       offline,
+		workflowDirs:  flagValues(args, "--workflow-dir", "-workflow-dir", "workflow-dir"),
+		recursive:     hasFlag(args, "--recursive", "-recursive", "recursive", "-r"),
+		submodules:    hasFlag(args, "--include-submodules", "-include-submodules", "include-submodules"),
+		exclude:       append(flagValues(args, "--exclude", "-exclude", "exclude"), cfg.Exclude...),
+		only:          flagValues(args, "--only", "-only", "only"),
+		skip:          flagValues(args, "--skip", "-skip", "skip"),
+		strictParse:   hasFlag(args, "--strict-parse", "-strict-parse", "strict-parse"),
+	}
+}
+
+// checkOptions applies the flags and settings that decide which versions
+// are recommended
+func checkOptions(args []string, sess *session) []actions.Option {
+	ignoreSHA := hasFlag(args, "--ignore-sha", "-ignore-sha", "ignore-sha")
+	ignoreMinor := hasFlag(args, "--ignore-minor", "-ignore-minor", "ignore-minor")
+	releases := hasFlag(args, "--releases", "-releases", "releases")
+	prereleases := hasFlag(args, "--include-prereleases", "-include-prereleases", "include-prereleases")
+	minReleaseAge, _ := flagValue(args, "--min-release-age", "-min-release-age", "min-release-age")
+	notes := hasFlag(args, "--notes", "-notes", "notes")
+	shaCompare, _ := flagValue(args, "--sha-compare", "-sha-compare", "sha-compare")
+	cfg := sess.cfg
+
+	minAge := time.Duration(cfg.MinReleaseAge)
+	if minReleaseAge != "" {
+		var err error
+		if minAge, err = config.ParseDuration(minReleaseAge); err != nil {
+			fatal(err.Error())
+		}
+	}
+	tagRules, err := configTagRules(cfg)
+	if err != nil {
+		fatal(err.Error())
+	}
+	ignoreRules, err := dependabotIgnores(args, sess)
+	if err != nil {
+		fatal(err.Error())
+	}
+	shaCompare = cmp.Or(shaCompare, cfg.SHACompare, actions.SHACompareBranch)
+	if !slices.Contains(actions.SHACompareModes, shaCompare) {
+		fatal(fmt.Sprintf("unknown --sha-compare %q; use %s", shaCompare, strings.Join(actions.SHACompareModes, ", ")))
+	}
+
+	return append(slices.Clone(sess.opts),
+		actions.WithIgnoreSHA(ignoreSHA),
+		actions.WithIgnoreMinor(ignoreMinor),
+		actions.WithReleases(releases || cfg.Releases),
+		actions.WithPrereleases(prereleases),
+		actions.WithPrereleasesFor(cfg.IncludePrereleases...),
+		actions.WithTagRules(tagRules),
+		actions.WithIgnoreRules(ignoreRules),
+		actions.WithMinReleaseAge(minAge),
+		actions.WithReleaseNotes(notes),
+		actions.WithSHACompare(shaCompare),
+	)
+}
+
 func main() {
 	args := os.Args[1:]
+	level = verbosityFlags(args)
+	build := buildinfo.Read(buildinfo.Info{Version: version, Commit: commit, Date: date})
+	version, commit, date = build.Version, build.Commit, build.Date
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "cache":
+			runCache(args[1:])
+			return
+		case "lock":
+			runLock(args[1:])
+			return
+		case "fix":
+			runFix(args[1:])
+			return
+		case "verify":
+			runVerify(args[1:])
+			return
+		case "history":
+			runHistory(args[1:])
+			return
+		case "trend":
+			runTrend(args[1:])
+			return
+		case "badge":
+			runBadge(args[1:])
+			return
+		case "serve":
+			runServe(args[1:])
+			return
+		case "watch":
+			runWatch(args[1:])
+			return
+		case "install-hooks":
+			runInstallHooks(args[1:])
+			return
+		case "init":
+			runInit(args[1:])
+			return
+		case "doctor":
+			runDoctor(args[1:])
+			return
+		case "completion":
+			runCompletion(args[1:])
+			return
+		case "self-update":
+			runSelfUpdate(args[1:])
+			return
+		case "check":
+			// The default command, spelled out for editor integrations
+			// and hooks: `aver check FILE...`
+			args = args[1:]
+		}
+	}
+
+	if hasFlag(args, "--github-action", "-github-action", "github-action") {
+		githubAction = true
+		args = githubActionArgs(args)
+	}
 
 	// Handle help and version flags
 	if hasFlag(args, "help", "--help", "-h") {
 		printHelp()
 		os.Exit(0)
 	}
-	if hasFlag(args, "version", "--version", "-v") {
+	if hasFlag(args, "version", "--version", "-version", "-v") {
 		printVersion()
 		os.Exit(0)
 	}
 
+	start := time.Now()
 	jsonOutput := hasFlag(args, "--json", "-json", "json")
-	ignoreSHA := hasFlag(args, "--ignore-sha", "-ignore-sha", "ignore-sha")
-	ignoreMinor := hasFlag(args, "--ignore-minor", "-ignore-minor", "ignore-minor")
-	quiet := hasFlag(args, "--quiet", "-quiet", "quiet", "-q")
+	strict := hasFlag(args, "--strict", "-strict", "strict")
+	format, _ := flagValue(args, "--format", "-format", "format")
+	switch {
+	case format == "" || format == "table":
+		format = ""
+	case format == "json":
+		jsonOutput, format = true, ""
+	case format != rdjsonFormat && !slices.Contains(sbomFormats, format):
+		fatal(fmt.Sprintf("unknown --format %q; use table, json, rdjson or %s", format, strings.Join(sbomFormats, ", ")))
+	}
+	// Machine-readable output is quiet unless asked otherwise
+	machine := jsonOutput || format != ""
+	notes := hasFlag(args, "--notes", "-notes", "notes")
+	statePath, _ := flagValue(args, "--state", "-state", "state")
+	trackTags := statePath != "" || hasFlag(args, "--track-tags", "-track-tags", "track-tags")
+	baselinePath, _ := flagValue(args, "--baseline", "-baseline", "baseline")
+	sendNotify := hasFlag(args, "--notify", "-notify", "notify")
+	stats := hasFlag(args, "--stats", "-stats", "stats")
+	dedupe := hasFlag(args, "--dedupe", "-dedupe", "dedupe")
+	inputs := hasFlag(args, "--check-inputs", "-check-inputs", "check-inputs")
+	commands := hasFlag(args, "--check-commands", "-check-commands", "check-commands")
+	personal := hasFlag(args, "--warn-personal-actions", "-warn-personal-actions", "warn-personal-actions")
+	groupBy, _ := flagValue(args, "--group-by", "-group-by", "group-by")
+	if groupBy != "" && !slices.Contains(actions.Groupings, groupBy) {
+		fatal(fmt.Sprintf("unknown --group-by %q; use %s", groupBy, strings.Join(actions.Groupings, ", ")))
+	}
+	// No bare form, since "owner" is also a value of --group-by
+	owners := flagValues(args, "--owner", "-owner")
+	sortBy, _ := flagValue(args, "--sort", "-sort", "sort")
+	if sortBy != "" && !slices.Contains(actions.SortOrders, sortBy) {
+		fatal(fmt.Sprintf("unknown --sort %q; use %s", sortBy, strings.Join(actions.SortOrders, ", ")))
+	}
 
-	dir, err := os.Getwd()
-	if err != nil {
-		fatal(err.Error())
+	var maxRequests int64
+	if value, ok := flagValue(args, "--max-api-requests", "-max-api-requests", "max-api-requests"); ok {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 {
+			fatal(fmt.Sprintf("invalid --max-api-requests %q", value))
+		}
+		maxRequests = n
 	}
 
-	actionRefs, err := actions.FindActionReferences(dir)
-	if err != nil {
-		fatal(err.Error())
+	var pr pullRequest
+	if hasFlag(args, "--comment-pr", "-comment-pr", "comment-pr") {
+		pr = pullRequestFlags(args)
+	}
+
+	checkRun := hasFlag(args, "--check-run", "-check-run", "check-run")
+	commitStatus := hasFlag(args, "--commit-status", "-commit-status", "commit-status")
+	var target commitTarget
+	if checkRun || commitStatus {
+		target = commitTargetFlags(args)
 	}
 
-	opts := actions.CheckOptions{
-		IgnoreSHA:   ignoreSHA,
-		IgnoreMinor: ignoreMinor,
+	// Files passed by a pre-commit hook are checked on their own, preferring
+	// cached data so the hook stays quick
+	files := workflowFiles(args)
+	remote, remoteRef := remoteTarget(args)
+	org, _ := flagValue(args, "--org", "-org", "org")
+	reposFile, _ := flagValue(args, "--repos-file", "-repos-file", "repos-file")
+	fleet := org != "" || reposFile != ""
+
+	// Only the project's own workflows need a project
+	sess := openSession(args, remote == "" && !fleet && len(files) == 0)
+	authenticated := sess.authenticated
+	// Listing the workflows of other repositories costs requests too, so
+	// every Checker shares one count
+	usage := &actions.Usage{Max: maxRequests}
+	opts := append(checkOptions(args, sess), actions.WithUsage(usage))
+	var lister *actions.Checker
+	var actionRefs []actions.ActionReference
+	var scanWarnings []string
+	var err error
+	switch {
+	case (remote != "" || fleet) && len(files) > 0:
+		fatal("--repo, --org and --repos-file can't be combined with workflow files")
+	case (remote != "" && fleet) || (org != "" && reposFile != ""):
+		fatal("only one of --repo, --org and --repos-file can be given")
+	case fleet:
+		lister = actions.NewChecker(opts...)
+		actionRefs, scanWarnings, err = scanRepos(lister, org, reposFile, level == levelQuiet || machine)
+	case remote != "":
+		// Another repository's workflows are read through the API
+		lister = actions.NewChecker(opts...)
+		actionRefs, err = lister.RepoReferences(context.Background(), remote, remoteRef)
+		err = sess.skipUnparsed(err)
+	case len(files) > 0:
+		actionRefs, err = actions.FileReferences(sess.root, files)
+		err = sess.skipUnparsed(err)
+	default:
+		actionRefs, err = sess.references()
+	}
+	if err == nil && (remote != "" || fleet || len(files) > 0) {
+		actionRefs, err = sess.prepare(actionRefs)
+	}
+	if err != nil {
+		fatal(describeError(err, authenticated))
+	}
+	if len(files) > 0 {
+		opts = append(opts, actions.WithCacheTTL(hookCacheTTL))
+	}
+	if inputs && (remote != "" || fleet) {
+		warn("--check-inputs only checks local workflows; ignoring it")
+		inputs = false
+	}
+	if commands && (remote != "" || fleet) {
+		warn("--check-commands only checks local workflows; ignoring it")
+		commands = false
 	}
 
-	// Start spinner unless quiet mode, JSON output, or non-TTY stderr
+	// Start spinner unless another verbosity than normal, JSON or SBOM
+	// output, or non-TTY stderr
 	var spin *spinner
-	if !quiet && !jsonOutput && isTerminal(os.Stderr) {
+	if level == levelNormal && !machine && isTerminal(os.Stderr) {
 		spin = newSpinner()
-		opts.OnProgress = spin.update
-		spin.start()
+		opts = append(opts, actions.WithProgress(spin.onEvent))
 	}
 
-	upToDate, result, err := actions.CheckActionVersions(actionRefs, opts)
+	// Tags seen on earlier runs, to notice ones that have been moved. A
+	// state file that can't be read only costs those warnings.
+	st := &state.State{Tags: make(map[string]string)}
+	if trackTags {
+		if statePath == "" {
+			if statePath, err = state.DefaultPath(); err != nil {
+				fatal(err.Error())
+			}
+		}
+		if loaded, err := state.Load(statePath); err != nil {
+			warnf("%v; starting with no known tags", err)
+		} else {
+			st = loaded
+		}
+		opts = append(opts, actions.WithKnownTags(st.Tags))
+	}
+
+	checker := actions.NewChecker(opts...)
+	preflight(checker, actionRefs, authenticated, maxRequests)
+	if spin != nil {
+		spin.start()
+	}
+	result, err := checker.Check(context.Background(), actionRefs)
+	if err == nil && inputs {
+		err = checkInputs(checker, sess, actionRefs, &result)
+	}
+	if err == nil && commands {
+		result.Commands, err = actions.FindDeprecatedCommands(sess.root, actionRefs)
+	}
+	if err == nil {
+		err = checkLookalikes(checker, actionRefs, &result)
+	}
+	result.Drift = actions.FindDrift(actionRefs)
+	if err == nil && (personal || sess.cfg.WarnPersonalActions) {
+		err = checkOwners(checker, actionRefs, &result)
+	}
 
 	// Stop spinner before any output
 	if spin != nil {
 		spin.finish()
 	}
 	if err != nil {
-		fatal(err.Error())
+		fatal(describeError(err, authenticated))
+	}
+	result.Stats.SetDuration(time.Since(start))
+	result.Stats.APIRequests = usage.Requests()
+	// Verbose runs end with what the API was asked, including by the
+	// reports below
+	exit := func(code int) {
+		if level >= levelVerbose {
+			printUsage(usage, checker, lister)
+		}
+		// With --strict, anything that kept the workflows from being fully
+		// evaluated fails the run like an error
+		if problems := strictProblems(result); strict && problems != "" {
+			msg := "--strict: " + problems
+			if githubAction {
+				fmt.Println(ghaction.Error(msg))
+			} else {
+				fmt.Fprintln(os.Stderr, "error:", msg)
+			}
+			code = exitError
+		}
+		os.Exit(code)
 	}
 
 	// Print warnings to stderr
+	result.Warnings = append(scanWarnings, result.Warnings...)
 	for _, warning := range result.Warnings {
-		fmt.Fprintln(os.Stderr, "warning:", warning)
+		warn(warning)
+	}
+	for _, m := range result.Moved {
+		warnf("%s@%s in %s moved from %s to %s since the last run",
+			m.Name, m.Tag, m.File, shortSHA(m.OldSHA), shortSHA(m.NewSHA))
 	}
 
-	if upToDate {
-		if jsonOutput {
-			if err := printJSON(result); err != nil {
-				fatal(err.Error())
-			}
+	if trackTags {
+		maps.Copy(st.Tags, result.Resolved)
+		if err := st.Save(statePath); err != nil {
+			warn("could not save state:", err)
 		}
-		os.Exit(exitOK)
 	}
 
-	if jsonOutput {
-		if err := printJSON(result); err != nil {
+	// Runs with unchecked actions would make the trend look better than it
+	// is, and runs on a few files or another repository don't describe the
+	// project
+	if recordHistory(args, sess) && len(result.Unchecked) == 0 && len(files) == 0 && remote == "" && !fleet {
+		recordRun(historyPath(args), sess.root, actionRefs, result)
+	}
+
+	// Findings already in the baseline are accepted debt; only new ones
+	// are reported and fail the run
+	if baselinePath != "" {
+		baseline, err := actions.LoadBaseline(baselinePath)
+		if err != nil {
+			fatal(describeError(err, authenticated))
+		}
+		var known int
+		result, known = baseline.Filter(result)
+		if known > 0 {
+			notef("%d findings are already in the baseline and not shown", known)
+		}
+	}
+
+	// Everything is checked, but only the findings about the actions of
+	// --owner are reported and fail the run
+	result = result.ByOwner(owners)
+
+	// Every report lists the findings in the --sort order
+	if sortBy != "" {
+		result = result.Sort(sortBy)
+	}
+
+	if sendNotify {
+		if list := notifiers(sess.cfg); len(list) > 0 {
+			sendNotifications(context.Background(), list, sess, result)
+		} else {
+			warn("--notify was given but no notifications are configured")
+		}
+	}
+
+	if githubAction {
+		reportToActions(result)
+	}
+	if githubAction || ghaction.Running() {
+		writeOutputs(result)
+	}
+	if pr.number != 0 {
+		commentOnPR(checker, pr, result)
+	}
+	if checkRun {
+		reportCheckRun(checker, target, result)
+	}
+	if commitStatus {
+		reportCommitStatus(checker, target, result)
+	}
+
+	// A bill of materials lists every dependency, outdated or not; the exit
+	// status still reports whether any are. reviewdog diagnostics point at
+	// every line, so neither is deduplicated.
+	if format != "" {
+		var err error
+		if format == rdjsonFormat {
+			err = printRDJSON(result)
+		} else {
+			err = printSBOM(format, sbomProject(sess, remote, org, reposFile), actionRefs, result)
+		}
+		if err != nil {
 			fatal(err.Error())
 		}
-	} else {
-		if len(result.Outdated) > 0 {
-			fmt.Println("Outdated actions:")
-			printOutdatedTable(result.Outdated)
+		if result.UpToDate() {
+			exit(exitOK)
+		}
+		if githubAction {
+			exit(failAction(result))
+		}
+		exit(exitOutdated)
+	}
+
+	// Only the printed report is deduplicated; annotations and comments
+	// above still point at every file
+	if dedupe {
+		result = result.Dedupe()
+	}
+
+	if result.UpToDate() {
+		if jsonOutput {
+			if err := printJSON(result, stats); err != nil {
+				fatal(err.Error())
+			}
+			exit(exitOK)
+		}
+		if len(result.Unchecked) > 0 {
+			fmt.Println("Actions not checked:")
+			printUncheckedTable(result.Unchecked)
+			if len(result.Dynamic) > 0 || len(result.Drift) > 0 || stats {
+				fmt.Println()
+			}
+		}
+		if len(result.Dynamic) > 0 {
+			printDynamicTable(result.Dynamic)
+			if len(result.Drift) > 0 || stats {
+				fmt.Println()
+			}
 		}
-		if len(result.SHAPinned) > 0 {
-			if len(result.Outdated) > 0 {
+		if len(result.Drift) > 0 {
+			printDriftTable(result.Drift)
+			if stats {
 				fmt.Println()
 			}
-			fmt.Println("SHA-pinned actions behind default branch:")
-			printSHATable(result.SHAPinned)
 		}
+		if stats {
+			printStats(result.Stats)
+		}
+		exit(exitOK)
+	}
+
+	switch {
+	case jsonOutput:
+		if err := printJSON(result, stats); err != nil {
+			fatal(err.Error())
+		}
+	case fleet:
+		printByRepository(result, groupBy, notes)
+	default:
+		printGroups(result, groupBy, notes)
+	}
+	if len(result.Drift) > 0 && !jsonOutput {
+		fmt.Println()
+		printDriftTable(result.Drift)
+	}
+	if stats && !jsonOutput {
+		fmt.Println()
+		printStats(result.Stats)
 	}
-	os.Exit(exitOutdated)
-}
\ No newline at end of file
+	if githubAction {
+		exit(failAction(result))
+	}
+	exit(exitOutdated)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func TestRemoteTarget(t *testing.T) {
+	for _, tt := range []struct {
+		args      []string
+		repo, ref string
+	}{
+		// --report-repo and --sha name where reports go; the local workflows
+		// are still checked
+		{[]string{"--comment-pr", "--report-repo", "o/r", "--pr", "5"}, "", ""},
+		{[]string{"--check-run", "--report-repo", "o/r", "--sha", "abc123"}, "", ""},
+		{[]string{"--repo", "cli/cli", "--ref", "v2.60.0"}, "cli/cli", "v2.60.0"},
+		{[]string{"--comment-pr", "--report-repo", "o/r", "--pr", "5", "--repo=cli/cli"}, "cli/cli", ""},
+	} {
+		repo, ref := remoteTarget(tt.args)
+		if repo != tt.repo || ref != tt.ref {
+			t.Errorf("remoteTarget(%q) = %q, %q, want %q, %q", tt.args, repo, ref, tt.repo, tt.ref)
+		}
+	}
+}
+
+func TestProjectDir(t *testing.T) {
+	dir, other := t.TempDir(), t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{nil, wd},
+		{[]string{dir, "--json"}, dir},
+		// The values of flags that take one aren't the project, even when
+		// they're directories
+		{[]string{"--cache-dir", other, dir}, dir},
+		{[]string{"--exclude", other, dir}, dir},
+		{[]string{"--cache-dir=" + other, dir}, dir},
+		{[]string{"--cache-dir", other}, wd},
+		{[]string{"-"}, wd},
+		{[]string{"ci.yml"}, wd},
+	} {
+		if got := projectDir(tt.args); got != tt.want {
+			t.Errorf("projectDir(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestWorkflowFiles(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want []string
+	}{
+		{nil, nil},
+		{[]string{".github/workflows/ci.yml", "release.yaml", "--json"}, []string{".github/workflows/ci.yml", "release.yaml"}},
+		{[]string{"-"}, []string{"-"}},
+		{[]string{"--state", "state.yml", "ci.yml"}, []string{"ci.yml"}},
+		{[]string{"--exclude", "old.yml", "--output=report.yml"}, nil},
+		{[]string{"README.md", "."}, nil},
+	} {
+		if got := workflowFiles(tt.args); !slices.Equal(got, tt.want) {
+			t.Errorf("workflowFiles(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestMain runs aver itself when AVER_TEST_ARGS is set, for runAver
+func TestMain(m *testing.M) {
+	if args := os.Getenv("AVER_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"aver"}, strings.Fields(args)...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runAver runs aver with args in dir, offline with an empty cache and no
+// user config or state, and returns its stdout
+func runAver(t *testing.T, dir, args string) ([]byte, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"AVER_TEST_ARGS="+args+" --offline --cache-dir "+filepath.Join(t.TempDir(), "cache"),
+		"XDG_CONFIG_HOME="+t.TempDir(), "XDG_STATE_HOME="+t.TempDir(), "GITHUB_ACTIONS=", "GITHUB_OUTPUT=", "GITHUB_STEP_SUMMARY=")
+	return cmd.Output()
+}
+
+// TestCheckFiles runs `aver check FILE` in a directory that isn't a
+// project: only the named file is read, and there's no project root to
+// find. Offline with an empty cache, its action is listed as unchecked
+// rather than looked up.
+func TestCheckFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, action := range map[string]string{"ci.yml": "actions/checkout@v4", "release.yml": "actions/setup-go@v5"} {
+		workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: " + action + "\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(workflow), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runAver(t, dir, "check ci.yml --json")
+	if err != nil {
+		t.Fatalf("aver check: %v\n%s", err, out)
+	}
+	var report struct {
+		Unchecked []actions.UncheckedAction `json:"unchecked"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("decoding %s: %v", out, err)
+	}
+	if len(report.Unchecked) != 1 || report.Unchecked[0].File != "ci.yml" || report.Unchecked[0].Name != "actions/checkout" {
+		t.Errorf("unchecked = %+v, want only actions/checkout in ci.yml", report.Unchecked)
+	}
+}
+
+func TestStrictProblems(t *testing.T) {
+	t.Cleanup(func() { warnings, level = 0, levelNormal })
+	dynamic := []actions.DynamicRef{{}}
+	unchecked := []actions.UncheckedAction{{}, {}}
+	for _, tt := range []struct {
+		warnings int
+		result   actions.CheckResult
+		want     string
+	}{
+		{0, actions.CheckResult{}, ""},
+		{1, actions.CheckResult{}, "1 warning"},
+		{0, actions.CheckResult{Dynamic: dynamic}, "1 dynamic ref"},
+		{0, actions.CheckResult{Unchecked: unchecked}, "2 actions not checked"},
+		{3, actions.CheckResult{Dynamic: dynamic, Unchecked: unchecked}, "3 warnings, 1 dynamic ref, 2 actions not checked"},
+	} {
+		warnings = tt.warnings
+		if got := strictProblems(tt.result); got != tt.want {
+			t.Errorf("strictProblems with %d warnings and %+v = %q, want %q", tt.warnings, tt.result, got, tt.want)
+		}
+	}
+
+	// Warnings a quiet run doesn't print still count
+	warnings, level = 0, levelQuiet
+	warn("hidden")
+	if got := strictProblems(actions.CheckResult{}); got != "1 warning" {
+		t.Errorf("strictProblems after a quiet warning = %q, want %q", got, "1 warning")
+	}
+}
+
+// TestStrictExit checks that --strict fails a run whose only problem is an
+// action that couldn't be checked
+func TestStrictExit(t *testing.T) {
+	dir := t.TempDir()
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n"
+	if err := os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(workflow), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		args string
+		code int
+	}{
+		{"check ci.yml --quiet", 0},
+		{"check ci.yml --quiet --strict", 2},
+	} {
+		_, err := runAver(t, dir, tt.args)
+		code := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("aver %s exited %d, want %d", tt.args, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"aver/pkg/actions"
+	"aver/pkg/config"
+	"aver/pkg/notify"
+)
+
+// notifiers builds the notifiers configured in the user config and the
+// environment
+func notifiers(cfg *config.Config) []notify.Notifier {
+	var list []notify.Notifier
+
+	slack := cfg.Notify.Slack
+	if url := os.Getenv("AVER_SLACK_WEBHOOK_URL"); url != "" {
+		if slack == nil {
+			slack = &config.SlackNotify{}
+		}
+		slack.WebhookURL = url
+	}
+	if slack != nil {
+		if slack.WebhookURL == "" {
+			warn("notify.slack has no webhook_url; set it or $AVER_SLACK_WEBHOOK_URL")
+		} else {
+			list = append(list, &notify.Slack{WebhookURL: slack.WebhookURL, Threshold: slack.Threshold, RepoURL: githubRepoURL})
+		}
+	}
+
+	for i, hook := range cfg.Notify.Webhooks {
+		if hook.URL == "" {
+			warnf("notify.webhooks[%d] has no url", i)
+			continue
+		}
+		webhook := &notify.Webhook{URL: config.ExpandEnv(hook.URL), Header: http.Header{}, Threshold: hook.Threshold}
+		for key, value := range hook.Headers {
+			webhook.Header.Set(key, config.ExpandEnv(value))
+		}
+		if hook.Template != "" {
+			tmpl, err := notify.ParseTemplate(hook.Template)
+			if err != nil {
+				fatal(fmt.Sprintf("invalid template in notify.webhooks[%d]: %v", i, err))
+			}
+			webhook.Template = tmpl
+		}
+		list = append(list, webhook)
+	}
+	return list
+}
+
+// sendNotifications reports a run to each notifier. Failures are warnings,
+// so a broken webhook doesn't hide the results.
+func sendNotifications(ctx context.Context, list []notify.Notifier, sess *session, result actions.CheckResult) {
+	report := notify.Report{Project: filepath.Base(sess.root), Result: result}
+	for _, n := range list {
+		if err := n.Notify(ctx, report); err != nil {
+			warn("could not send notification:", err)
+		}
+	}
+}
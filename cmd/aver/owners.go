@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"aver/pkg/actions"
+)
+
+// checkLookalikes records the actions named like popular ones in result,
+// those a typo away and forks, and warns about each
+func checkLookalikes(checker *actions.Checker, refs []actions.ActionReference, result *actions.CheckResult) error {
+	result.Typosquats = actions.FindTyposquats(refs)
+	for _, t := range result.Typosquats {
+		result.Warnings = append(result.Warnings, findingLocation(t.Repository, t.File, t.Line)+": "+t.Message())
+	}
+
+	forks, err := checker.SuspiciousForks(context.Background(), refs)
+	if err != nil {
+		return err
+	}
+	result.Forks = forks.Forks
+	result.Warnings = append(result.Warnings, forks.Warnings...)
+	for _, f := range forks.Forks {
+		result.Warnings = append(result.Warnings, findingLocation(f.Repository, f.File, f.Line)+": "+f.Message())
+	}
+	return nil
+}
+
+// findingLocation is where a finding is, for warnings: the file and line,
+// after the repository in scans of several
+func findingLocation(repository, file string, line int) string {
+	location := file
+	if line > 0 {
+		location += ":" + strconv.Itoa(line)
+	}
+	if repository != "" {
+		location = repository + ": " + location
+	}
+	return location
+}
+
+// checkOwners records who owns each action's repository in result and
+// warns about those personal accounts own
+func checkOwners(checker *actions.Checker, refs []actions.ActionReference, result *actions.CheckResult) error {
+	owners, err := checker.Owners(context.Background(), refs)
+	if err != nil {
+		return err
+	}
+	result.Owners = owners.Types
+	result.Warnings = append(result.Warnings, owners.Warnings...)
+	for _, ref := range owners.Personal {
+		file := ref.File
+		if ref.Repository != "" {
+			file = ref.Repository + ": " + file
+		}
+		owner, _, _ := strings.Cut(ref.Name, "/")
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("%s (%s) is owned by the personal account %s, not an organization", ref.Name, file, owner))
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+
+	"aver/pkg/actions"
+	"aver/pkg/rdjson"
+)
+
+// rdjsonFormat is the --format value that prints reviewdog diagnostics
+const rdjsonFormat = "rdjson"
+
+// printRDJSON prints the findings in result as reviewdog diagnostics
+func printRDJSON(result actions.CheckResult) error {
+	data, err := rdjson.New(result, rdjson.Source{Name: "aver", URL: "https://github.com/llimllib/aver"}).JSON()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
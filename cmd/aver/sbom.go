@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"aver/pkg/actions"
+	"aver/pkg/sbom"
+)
+
+// sbomFormats are the --format values that print a bill of materials
+var sbomFormats = []string{"cyclonedx", "spdx"}
+
+// printSBOM prints the dependencies of refs as a bill of materials in
+// format, naming it after project
+func printSBOM(format, project string, refs []actions.ActionReference, result actions.CheckResult) error {
+	bom := sbom.New(project, refs, result, githubRepoURL)
+	bom.Tool, bom.ToolVersion, bom.Created = "aver", version, time.Now()
+
+	var data []byte
+	var err error
+	switch format {
+	case "cyclonedx":
+		data, err = bom.CycloneDX()
+	case "spdx":
+		data, err = bom.SPDX()
+	default:
+		return fmt.Errorf("unknown SBOM format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// sbomProject names what a bill of materials describes: the repository or
+// organization checked through the API, or the local project's directory
+func sbomProject(sess *session, remote, org, reposFile string) string {
+	switch {
+	case remote != "":
+		return remote
+	case org != "":
+		return org
+	case reposFile != "":
+		return filepath.Base(reposFile)
+	}
+	return filepath.Base(sess.root)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/table"
+)
+
+// scanRepos reads the workflows of every unarchived repository in org
+// (`aver --org`) or of those listed in reposFile (`aver --repos-file`),
+// returning their references and warnings about the repositories that
+// couldn't be read
+func scanRepos(checker *actions.Checker, org, reposFile string, quiet bool) ([]actions.ActionReference, []string, error) {
+	ctx := context.Background()
+	var repos []string
+	var err error
+	if org != "" {
+		repos, err = checker.OrgRepos(ctx, org)
+	} else {
+		repos, err = actions.LoadRepoList(reposFile)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !quiet {
+		notef("Reading the workflows of %d repositories", len(repos))
+	}
+	scan, err := checker.ScanRepos(ctx, repos)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scan.Refs, scan.Warnings, nil
+}
+
+// printByRepository prints the findings of a scan under a heading for each
+// repository that has any
+func printByRepository(result actions.CheckResult, groupBy string, notes bool) {
+	for i, group := range result.ByRepository() {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(group.Repository)
+		fmt.Println(strings.Repeat("=", table.Width(group.Repository)))
+		printGroups(group.Result, groupBy, notes)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"aver/pkg/actions"
+	"aver/pkg/auth"
+	"aver/pkg/config"
+	"aver/pkg/selfupdate"
+)
+
+// runSelfUpdate implements `aver self-update`, which replaces this binary
+// with the latest release from GitHub
+func runSelfUpdate(args []string) {
+	checkOnly := hasFlag(args, "--check", "-check", "check")
+	force := hasFlag(args, "--force", "-force", "force")
+
+	// Releases are always on github.com, so only the user config matters:
+	// a token raises the rate limit, and a CA certificate gets through
+	// proxies that intercept TLS
+	cfg, err := config.Load(".")
+	if err != nil {
+		fatal(err.Error())
+	}
+	updater := &selfupdate.Updater{}
+	if token, _, err := auth.Token(cfg.TokenCommand, auth.DefaultHost); err == nil {
+		updater.Token = token
+	}
+	caCert, _ := flagValue(args, "--ca-cert", "-ca-cert", "ca-cert")
+	if caCert == "" {
+		caCert = cfg.CACert
+	}
+	insecure := hasFlag(args, "--insecure", "-insecure", "insecure") || cfg.Insecure
+	if caCert != "" || insecure {
+		transport, err := actions.NewTransport(caCert, insecure)
+		if err != nil {
+			fatal(err.Error())
+		}
+		updater.Client = &http.Client{Transport: transport}
+		if insecure {
+			warn("TLS certificate verification is disabled")
+		}
+	}
+
+	ctx := context.Background()
+	release, err := updater.Latest(ctx)
+	if err != nil {
+		fatal("could not find the latest release: " + err.Error())
+	}
+	latest := release.Version()
+	newer := selfupdate.Newer(latest, version)
+
+	if checkOnly {
+		switch {
+		case newer:
+			fmt.Printf("aver %s is available; you have %s. Run `aver self-update` to install it.\n", latest, version)
+			os.Exit(exitOutdated)
+		case selfupdate.IsRelease(version):
+			fmt.Printf("aver %s is the latest release\n", version)
+		default:
+			fmt.Printf("aver %s is the latest release; you have development build %s\n", latest, version)
+		}
+		return
+	}
+
+	if !force {
+		if !selfupdate.IsRelease(version) {
+			fatal(fmt.Sprintf("aver %s is a development build; use --force to replace it with release %s", version, latest))
+		}
+		if !newer {
+			notef("aver %s is the latest release", version)
+			return
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fatal("could not find the aver executable: " + err.Error())
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		fatal("could not find the aver executable: " + err.Error())
+	}
+	if how := selfupdate.Managed(exe); how != "" {
+		fatal(fmt.Sprintf("%s was installed by a package manager; update it with %s", exe, how))
+	}
+
+	binary, err := updater.Download(ctx, release, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		fatal(err.Error())
+	}
+	if err := selfupdate.Replace(exe, binary); err != nil {
+		fatal(fmt.Sprintf("could not replace %s: %v", exe, err))
+	}
+	notef("Updated aver from %s to %s", version, latest)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"aver/pkg/actions"
+	"aver/pkg/server"
+)
+
+// runServe implements `aver serve`, which answers check requests over HTTP
+// until it's killed
+func runServe(args []string) {
+	addr, _ := flagValue(args, "--addr", "-addr", "addr")
+	if addr == "" {
+		addr = "localhost:8080"
+	}
+	sess := newSessionAnywhere(args)
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level.logLevel()}))
+
+	s := &server.Server{
+		Checker: actions.NewChecker(checkOptions(args, sess)...),
+		Logger:  logger,
+	}
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Checking a large repository with a cold cache can take a while
+		WriteTimeout: 5 * time.Minute,
+		IdleTimeout:  2 * time.Minute,
+	}
+
+	notef("Listening on http://%s", addr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		fatal(err.Error())
+	}
+}
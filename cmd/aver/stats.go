@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"aver/pkg/actions"
+	"aver/pkg/table"
+)
+
+// printStats prints the --stats summary of a check after its tables
+func printStats(s actions.Stats) {
+	rows := [][2]string{
+		{"Workflows scanned", fmt.Sprint(s.Workflows)},
+		{"Unique actions", fmt.Sprintf("%d (%d references)", s.Actions, s.References)},
+		{"Pinned", pinnedSummary(s)},
+		{"Outdated", outdatedSummary(s.Outdated)},
+		{"API requests", fmt.Sprint(s.APIRequests)},
+		{"Time", s.Duration.Round(time.Millisecond).String()},
+	}
+	fmt.Println("Summary:")
+	for _, row := range rows {
+		fmt.Printf("  %-18s %s\n", row[0], row[1])
+	}
+	if len(s.Usage) > 0 {
+		fmt.Println()
+		printUsageTable(s.Usage)
+	}
+}
+
+// printUsageTable prints how many workflows and steps use each action, most
+// used first, to size the work of upgrading it
+func printUsageTable(usage []actions.ActionUsage) {
+	t := table.New("Action", "Workflows", "Steps", "Versions")
+	for _, u := range usage {
+		t.Cells(
+			linked(githubRepoURL(u.Name), u.Name),
+			table.Text(fmt.Sprint(u.Workflows)),
+			table.Text(fmt.Sprint(u.Steps)),
+			table.Text(strings.Join(u.Versions, ", ")))
+	}
+	_ = t.Write(os.Stdout)
+}
+
+// pinnedSummary describes how references are pinned, e.g. "3 by SHA, 5 by
+// tag, 0 by branch", adding expressions only when there are some
+func pinnedSummary(s actions.Stats) string {
+	summary := fmt.Sprintf("%d by SHA, %d by tag, %d by branch", s.SHAPinned, s.TagPinned, s.BranchPinned)
+	if s.Dynamic > 0 {
+		summary += fmt.Sprintf(", %d by expression", s.Dynamic)
+	}
+	return summary
+}
+
+// outdatedSummary describes outdated counts, e.g. "3 (1 major, 2 minor)"
+func outdatedSummary(o actions.Severity) string {
+	if o.Total() == 0 {
+		return "0"
+	}
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		name string
+	}{{o.Major, "major"}, {o.Minor, "minor"}, {o.Patch, "patch"}, {o.Other, "other"}} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", p.n, p.name))
+		}
+	}
+	return fmt.Sprintf("%d (%s)", o.Total(), strings.Join(parts, ", "))
+}
+
+// printUsage prints the API requests and cache hits of a run by category,
+// and the rate limit left on the main API host, for tuning large scans
+func printUsage(usage *actions.Usage, checkers ...*actions.Checker) {
+	fmt.Fprintln(os.Stderr, "API usage:")
+	var requests, cached int
+	for _, c := range usage.Categories() {
+		fmt.Fprintf(os.Stderr, "  %-12s %5d requests, %5d cached\n", c.Category, c.Requests, c.Cached)
+		requests, cached = requests+c.Requests, cached+c.Cached
+	}
+	fmt.Fprintf(os.Stderr, "  %-12s %5d requests, %5d cached\n", "total", requests, cached)
+	for _, c := range checkers {
+		if c == nil {
+			continue
+		}
+		if rate, ok := c.RateLimit(); ok {
+			fmt.Fprintf(os.Stderr, "  %-12s %d of %d left, resets at %s\n",
+				"rate limit", rate.Remaining, rate.Limit, rate.Reset.Local().Format(time.TimeOnly))
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "  %-12s unknown (no API responses)\n", "rate limit")
+}
+
+// preflight stops a check that would run out of API requests before it
+// starts, and warns if outdated actions could use up what's left, or if
+// --max-api-requests will leave actions unchecked. Failing to read the rate
+// limit isn't fatal: the check reports any real problem.
+func preflight(checker *actions.Checker, refs []actions.ActionReference, authenticated bool, maxRequests int64) {
+	estimate, rate, err := checker.Preflight(context.Background(), refs)
+	if errors.Is(err, &actions.ErrOverBudget{}) {
+		fatal(describeError(err, authenticated))
+	}
+	if level >= levelVerbose {
+		fmt.Fprintf(os.Stderr, "Estimated %d to %d API requests for %d repositories and %d SHA pins\n",
+			estimate.Requests, estimate.MaxRequests, estimate.Repositories, estimate.SHAPins)
+	}
+	if maxRequests > 0 && int64(estimate.Requests) > maxRequests {
+		warnf("this check needs at least %d API requests, more than --max-api-requests %d; some actions won't be checked",
+			estimate.Requests, maxRequests)
+	}
+	if err == nil && rate.Limit > 0 && estimate.MaxRequests > rate.Remaining && time.Now().Before(rate.Reset) {
+		warnf("this check could need up to %d GitHub API requests but only %d are left until %s",
+			estimate.MaxRequests, rate.Remaining, rate.Reset.Local().Format(time.Kitchen))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// verbosity is how much aver writes to stderr
+type verbosity int
+
+const (
+	levelQuiet   verbosity = iota // Errors only
+	levelNormal                   // Also warnings, progress and what was done
+	levelVerbose                  // Also skipped actions, failed checks and cache hits
+	levelDebug                    // Also every API request and how versions were chosen
+)
+
+// level is the verbosity of the run, read from the flags before any
+// command runs
+var level = levelNormal
+
+// verbosityFlags reads -q/--quiet, --verbose and --debug. The most verbose
+// one given wins. -v is --version, not --verbose.
+func verbosityFlags(args []string) verbosity {
+	switch {
+	case hasFlag(args, "--debug", "-debug", "debug"):
+		return levelDebug
+	case hasFlag(args, "--verbose", "-verbose", "verbose"):
+		return levelVerbose
+	case hasFlag(args, "--quiet", "-quiet", "quiet", "-q"):
+		return levelQuiet
+	}
+	return levelNormal
+}
+
+// logLevel is the slog level that shows what level calls for: Info
+// messages when verbose, and Debug ones too when debugging
+func (v verbosity) logLevel() slog.Level {
+	switch v {
+	case levelQuiet:
+		return slog.LevelError
+	case levelNormal:
+		return slog.LevelWarn
+	case levelVerbose:
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+// warnings counts the warnings of the run, printed or not, for --strict
+var warnings int
+
+// warn prints a warning to stderr unless the run is quiet
+func warn(a ...any) {
+	warnings++
+	if level > levelQuiet {
+		fmt.Fprintln(os.Stderr, append([]any{"warning:"}, a...)...)
+	}
+}
+
+// warnf is warn with a format
+func warnf(format string, a ...any) {
+	warn(fmt.Sprintf(format, a...))
+}
+
+// notef prints what was done, like "Commented on URL", to stderr unless the
+// run is quiet
+func notef(format string, a ...any) {
+	if level > levelQuiet {
+		fmt.Fprintf(os.Stderr, format+"\n", a...)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestVerbosityFlags(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want verbosity
+	}{
+		{nil, levelNormal},
+		{[]string{"-q"}, levelQuiet},
+		{[]string{"--quiet"}, levelQuiet},
+		{[]string{"--verbose"}, levelVerbose},
+		{[]string{"--debug"}, levelDebug},
+		// The most verbose flag wins, wherever it is
+		{[]string{"--debug", "--verbose"}, levelDebug},
+		{[]string{"--verbose", "--debug"}, levelDebug},
+		{[]string{"-q", "--verbose"}, levelVerbose},
+		{[]string{"--quiet", "--debug"}, levelDebug},
+		// -v is --version
+		{[]string{"-v"}, levelNormal},
+	} {
+		if got := verbosityFlags(tt.args); got != tt.want {
+			t.Errorf("verbosityFlags(%q) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	for v, want := range map[verbosity]slog.Level{
+		levelQuiet:   slog.LevelError,
+		levelNormal:  slog.LevelWarn,
+		levelVerbose: slog.LevelInfo,
+		levelDebug:   slog.LevelDebug,
+	} {
+		if got := v.logLevel(); got != want {
+			t.Errorf("verbosity %d: logLevel() = %v, want %v", v, got, want)
+		}
+	}
+}
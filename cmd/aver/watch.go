@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"aver/pkg/actions"
+	"aver/pkg/cache"
+	"aver/pkg/config"
+	"aver/pkg/notify"
+)
+
+// runWatch implements `aver watch`, which re-checks the project on a
+// schedule until interrupted and notifies when the findings change
+func runWatch(args []string) {
+	interval := 24 * time.Hour
+	if value, ok := flagValue(args, "--interval", "-interval", "interval"); ok {
+		d, err := config.ParseDuration(value)
+		if err != nil || d < time.Minute {
+			fatal(fmt.Sprintf("invalid --interval %q; use e.g. 24h or 1d, at least 1m", value))
+		}
+		interval = d
+	}
+	sess := newSession(args)
+
+	list := notifiers(sess.cfg)
+	if len(list) == 0 {
+		warn("no notifications are configured; changes will only be printed")
+	}
+
+	// Keep responses for half the interval, so other runs on this machine
+	// reuse them while every scheduled check still sees fresh data
+	opts := append(checkOptions(args, sess), actions.WithCacheTTL(max(cache.DefaultTTL, interval/2)))
+	checker := actions.NewChecker(opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var last *actions.CheckResult
+	for {
+		if result, ok := watchOnce(ctx, checker, sess); ok {
+			// The first check always notifies, so the watch starts from a
+			// known state
+			status := "unchanged"
+			switch {
+			case last == nil:
+				status = "first check"
+			case notify.Changed(*last, result):
+				status = "changed"
+			}
+			if status != "unchanged" {
+				sendNotifications(ctx, list, sess, result)
+			}
+			notef("%s: %d outdated, %d behind (%s)",
+				time.Now().Format(time.DateTime), len(result.Outdated), len(result.SHAPinned), status)
+			last = &result
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// watchOnce runs one scheduled check. Failures are printed and skipped, so
+// a network blip doesn't stop the watch.
+func watchOnce(ctx context.Context, checker *actions.Checker, sess *session) (actions.CheckResult, bool) {
+	// Workflows are read again each time, since they may have been edited
+	refs, err := sess.references()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", describeError(err, sess.authenticated))
+		return actions.CheckResult{}, false
+	}
+	result, err := checker.Check(ctx, refs)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, "error:", describeError(err, sess.authenticated))
+		}
+		return actions.CheckResult{}, false
+	}
+	for _, warning := range result.Warnings {
+		warn(warning)
+	}
+	return result, true
+}
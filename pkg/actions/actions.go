@@ -1,16 +1,21 @@
 package actions
 
 import (
-	"encoding/json"
+	"bytes"
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,39 +24,220 @@ type ActionReference struct {
 	Name    string
 	Version string
 	File    string
+	Line    int // Where the reference first appears in File, if known
+	// Uses is how many uses: in File name the action at Version, which
+	// are reported as one reference; 0 means one
+	Uses int
+	// Repository is the repository File was fetched from when scanning
+	// several repositories, or empty for the local project
+	Repository string
+}
+
+// SHAPinned reports whether the reference is pinned to a commit SHA rather
+// than a tag or branch
+func (a ActionReference) SHAPinned() bool {
+	return isSHA(a.Version)
+}
+
+// Dynamic reports whether the reference is built from a ${{ }} expression,
+// like owner/repo@${{ inputs.version }}, so what runs is only known when
+// the workflow does
+func (a ActionReference) Dynamic() bool {
+	return strings.Contains(a.Name, "${{") || strings.Contains(a.Version, "${{")
 }
 
 type OutdatedAction struct {
+	Repository     string `json:"repository,omitempty"`
 	File           string `json:"file"`
 	Name           string `json:"action"`
 	CurrentVersion string `json:"current"`
 	LatestVersion  string `json:"latest"`
+	// LatestSHA is the commit LatestVersion's tag points at, for pinning
+	// to it by SHA; empty if the forge doesn't say
+	LatestSHA string `json:"latest_sha,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	// Files lists every file with this finding when identical findings
+	// were merged by Dedupe
+	Files []string `json:"files,omitempty"`
+	// When each version was published, if known
+	CurrentPublished time.Time `json:"current_published,omitzero"`
+	LatestPublished  time.Time `json:"latest_published,omitzero"`
+	// DaysBehind is how many days older the current version is than the
+	// latest
+	DaysBehind int `json:"days_behind,omitempty"`
+	// CommitsBehind is how many commits the latest version's tag is ahead
+	// of the current one's
+	CommitsBehind int `json:"commits_behind,omitempty"`
+	// ReleasesBehind is how many releases were published after the current
+	// version, up to and including the latest
+	ReleasesBehind int `json:"releases_behind,omitempty"`
+	// Notes are the releases after the current version up to the latest,
+	// newest first, when release notes were requested
+	Notes []GitHubRelease `json:"notes,omitempty"`
+}
+
+// MovedTag is a pinned tag that points at a different commit than it did
+// on an earlier run
+type MovedTag struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Name       string `json:"action"`
+	Tag        string `json:"tag"`
+	OldSHA     string `json:"old_sha"`
+	NewSHA     string `json:"new_sha"`
 }
 
 type SHAPinnedAction struct {
+	Repository    string `json:"repository,omitempty"`
 	File          string `json:"file"`
 	Name          string `json:"action"`
 	CurrentSHA    string `json:"current_sha"`
 	LatestSHA     string `json:"latest_sha"`
 	CommitsBehind int    `json:"commits_behind"`
-	DefaultBranch string `json:"default_branch"`  // Added to show the branch the latest SHA is from
+	DefaultBranch string `json:"default_branch"` // Added to show the branch the latest SHA is from
+	Line          int    `json:"line,omitempty"`
+	// CurrentTag is the release CurrentSHA is tagged as, and LatestTag the
+	// newer version it can be updated to, at LatestTagSHA, when SHA pins
+	// are compared by tag (WithSHACompare)
+	CurrentTag   string `json:"current_tag,omitempty"`
+	LatestTag    string `json:"latest_tag,omitempty"`
+	LatestTagSHA string `json:"latest_tag_sha,omitempty"`
+	// Files lists every file with this finding, as in OutdatedAction
+	Files []string `json:"files,omitempty"`
+}
+
+// Describe says how far behind the pin is: the newer release after the one
+// it's tagged as, commits on its default branch or both, as in
+// "actions/checkout@8e5e7e5 (v4.1.0) is 57 commits behind main (a1b2c3d);
+// v4.2.2 is out (11bd719)"
+func (s SHAPinnedAction) Describe() string {
+	pinned := s.Name + "@" + shortSHA(s.CurrentSHA)
+	if s.CurrentTag != "" {
+		pinned += " (" + s.CurrentTag + ")"
+	}
+	var message string
+	if s.DefaultBranch != "" {
+		commits := "commits"
+		if s.CommitsBehind == 1 {
+			commits = "commit"
+		}
+		message = fmt.Sprintf("%s is %d %s behind %s%s", pinned, s.CommitsBehind, commits, s.DefaultBranch, shaSuffix(s.LatestSHA))
+	}
+	if s.LatestTag != "" {
+		if message == "" {
+			return fmt.Sprintf("%s can be updated to %s%s", pinned, s.LatestTag, shaSuffix(s.LatestTagSHA))
+		}
+		message += fmt.Sprintf("; %s is out%s", s.LatestTag, shaSuffix(s.LatestTagSHA))
+	}
+	return message
+}
+
+// shaSuffix is " (abc1234)" for a known commit, or ""
+func shaSuffix(sha string) string {
+	if sha == "" {
+		return ""
+	}
+	return " (" + shortSHA(sha) + ")"
+}
+
+// shortSHA abbreviates a commit SHA to 7 characters, as git does
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// DynamicRef is a reference whose action or version is an expression,
+// which defeats pinning: any version can run without the workflow changing
+type DynamicRef struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Name       string `json:"action"`
+	Ref        string `json:"ref"`
+	Line       int    `json:"line,omitempty"`
+	// Files lists every file with this finding, as in OutdatedAction
+	Files []string `json:"files,omitempty"`
+}
+
+// UncheckedAction is a reference that couldn't be evaluated, because its
+// metadata isn't cached in offline mode or the run's API request budget
+// was used up
+type UncheckedAction struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Name       string `json:"action"`
+	Version    string `json:"version"`
+	Reason     string `json:"reason"`
+	// Files lists every file with this finding, as in OutdatedAction
+	Files []string `json:"files,omitempty"`
 }
 
 // GitHubTag represents a tag from the GitHub API
 type GitHubTag struct {
-	Name string `json:"name"`
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// GitHubRelease represents a release from the GitHub API
+type GitHubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Body        string    `json:"body"`
 }
 
 // GitHubCompare represents the compare API response
 type GitHubCompare struct {
-	AheadBy  int    `json:"ahead_by"`
-	BehindBy int    `json:"behind_by"`
-	Status   string `json:"status"`
+	AheadBy      int    `json:"ahead_by"`
+	BehindBy     int    `json:"behind_by"`
+	Status       string `json:"status"`
+	TotalCommits int    `json:"total_commits"`
+}
+
+// GitHubCommit represents a commit from the API
+type GitHubCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// GitHubContent represents a file or directory entry from the contents API
+type GitHubContent struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Type     string `json:"type"` // "file" or "dir"
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// WorkflowFile is a workflow fetched from a repository
+type WorkflowFile struct {
+	Path    string
+	Content []byte
 }
 
 // GitHubRepo represents repository info from the API
 type GitHubRepo struct {
-	DefaultBranch string `json:"default_branch"`
+	FullName      string      `json:"full_name"`
+	DefaultBranch string      `json:"default_branch"`
+	Archived      bool        `json:"archived"`
+	Owner         GitHubOwner `json:"owner"`
+	Fork          bool        `json:"fork"`
+	Parent        *GitHubRepo `json:"parent,omitempty"` // The repository a fork was forked from
+}
+
+// GitHubOwner is the account that owns a repository
+type GitHubOwner struct {
+	Login string `json:"login"`
+	Type  string `json:"type"` // OwnerUser or OwnerOrganization
 }
 
 // GitHubRef represents a git reference from the API
@@ -61,16 +247,6 @@ type GitHubRef struct {
 	} `json:"object"`
 }
 
-// ErrRepoNotAccessible is returned when a repository cannot be accessed
-type ErrRepoNotAccessible struct {
-	Repo   string
-	Status int
-}
-
-func (e *ErrRepoNotAccessible) Error() string {
-	return fmt.Sprintf("repository %s not accessible (status %d)", e.Repo, e.Status)
-}
-
 func FindProjectRoot(startDir string) (string, error) {
 	currentDir, err := filepath.Abs(startDir)
 	if err != nil {
@@ -81,8 +257,15 @@ func FindProjectRoot(startDir string) (string, error) {
 		if _, err := os.Stat(filepath.Join(currentDir, ".git")); err == nil {
 			return currentDir, nil
 		}
-		if _, err := os.Stat(filepath.Join(currentDir, ".github")); err == nil {
-			return currentDir, nil
+		for _, dir := range WorkflowDirs {
+			if _, err := os.Stat(filepath.Join(currentDir, filepath.Dir(dir))); err == nil {
+				return currentDir, nil
+			}
+		}
+		for _, file := range PipelineFiles {
+			if _, err := os.Stat(filepath.Join(currentDir, file)); err == nil {
+				return currentDir, nil
+			}
 		}
 		currentDir = filepath.Dir(currentDir)
 	}
@@ -90,17 +273,213 @@ func FindProjectRoot(startDir string) (string, error) {
 	return "", fmt.Errorf("could not find project root")
 }
 
-func FindActionReferences(startDir string) ([]ActionReference, error) {
+// StdinName is the file name reported for a workflow read from stdin
+const StdinName = "<stdin>"
+
+// stdin is read for the path "-"; tests replace it
+var stdin io.Reader = os.Stdin
+
+// FileReferences parses the given workflow files, such as the changed files
+// a pre-commit hook is passed, and the pipelines in PipelineFiles.
+// Paths are relative to the working directory or absolute; references are
+// reported relative to projectRoot, like FindActionReferences. The path "-"
+// reads a workflow from stdin, reported as StdinName. Files that can't be
+// parsed are left out and reported in an ErrUnparsed returned with the
+// references of the rest.
+func FileReferences(projectRoot string, paths []string) ([]ActionReference, error) {
+	refs := []ActionReference{}
+	var skipped unparsed
+	for _, path := range paths {
+		if path == "-" {
+			content, err := io.ReadAll(stdin)
+			if err != nil {
+				return nil, err
+			}
+			fileRefs, err := ParseWorkflow(StdinName, content)
+			if err := skipped.skip(err); err != nil {
+				return nil, err
+			}
+			refs = append(refs, fileRefs...)
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		relPath := path
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(projectRoot, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				relPath = rel
+			}
+		}
+		fileRefs, err := parseFile(relPath, content)
+		if err := skipped.skip(err); err != nil {
+			return nil, err
+		}
+		refs = append(refs, fileRefs...)
+	}
+	return refs, skipped.err()
+}
+
+// PipelineFiles are the CI configurations, relative to a project root, that
+// are read alongside workflows: a GitLab pipeline, a CircleCI config, a
+// Bitbucket pipeline and an Azure pipeline
+var PipelineFiles = []string{GitLabCIFile, CircleCIFile, BitbucketPipelinesFile, AzurePipelinesFile}
+
+// parseFile parses the pipelines in PipelineFiles with their own parsers
+// and anything else as a workflow
+func parseFile(file string, content []byte) ([]ActionReference, error) {
+	switch slashed := "/" + filepath.ToSlash(file); {
+	case strings.HasSuffix(slashed, "/"+GitLabCIFile):
+		return ParseGitLabCI(file, content)
+	case strings.HasSuffix(slashed, "/"+CircleCIFile):
+		return ParseCircleCI(file, content)
+	case strings.HasSuffix(slashed, "/"+BitbucketPipelinesFile):
+		return ParseBitbucketPipelines(file, content)
+	case strings.HasSuffix(slashed, "/"+AzurePipelinesFile):
+		return ParseAzurePipelines(file, content)
+	}
+	return ParseWorkflow(file, content)
+}
+
+// FindActionReferences returns the actions used by the workflows in the
+// .github/workflows directory of the project containing startDir, in its
+// .forgejo/workflows and .gitea/workflows directories, and in extraDirs,
+// such as templates rendered into .github/workflows, along with the GitLab
+// CI/CD components, CircleCI orbs, Bitbucket Pipes and Azure Pipelines tasks
+// and templates its PipelineFiles use. Relative extraDirs are resolved
+// against the project root. Files that can't be parsed are left out and
+// reported in an ErrUnparsed returned with the references of the rest.
+func FindActionReferences(startDir string, extraDirs ...string) ([]ActionReference, error) {
+	projectRoot, err := FindProjectRoot(startDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, dir := range WorkflowDirs {
+		if _, err := os.Stat(filepath.Join(projectRoot, dir)); err == nil {
+			dirs = append(dirs, filepath.Join(projectRoot, dir))
+		}
+	}
+	var pipelines []string
+	for _, file := range PipelineFiles {
+		if _, err := os.Stat(filepath.Join(projectRoot, file)); err == nil {
+			pipelines = append(pipelines, filepath.Join(projectRoot, file))
+		}
+	}
+	if len(dirs) == 0 && len(pipelines) == 0 {
+		// Reading it reports that there are no workflows
+		dirs = []string{filepath.Join(projectRoot, ".github", "workflows")}
+	}
+
+	var skipped unparsed
+	refs, err := referencesIn(projectRoot, dirs, extraDirs, &skipped)
+	if err != nil {
+		return nil, err
+	}
+	if refs, err = appendPipelines(refs, projectRoot, pipelines, &skipped); err != nil {
+		return nil, err
+	}
+	return refs, skipped.err()
+}
+
+// appendPipelines adds what the pipelines at paths use
+func appendPipelines(refs []ActionReference, projectRoot string, paths []string, skipped *unparsed) ([]ActionReference, error) {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		relPath, err := filepath.Rel(projectRoot, path)
+		if err != nil {
+			relPath = filepath.Base(path)
+		}
+		pipelineRefs, err := parseFile(relPath, content)
+		if err := skipped.skip(err); err != nil {
+			return nil, err
+		}
+		refs = append(refs, pipelineRefs...)
+	}
+	return refs, nil
+}
+
+// FindAllActionReferences is FindActionReferences for monorepos: it walks
+// the whole project and reads every workflows directory in it (under
+// .github, .forgejo or .gitea) and every pipeline in PipelineFiles, such as
+// those of vendored subprojects, reporting files relative to the project
+// root. .git and node_modules directories are skipped.
+func FindAllActionReferences(startDir string, extraDirs ...string) ([]ActionReference, error) {
 	projectRoot, err := FindProjectRoot(startDir)
 	if err != nil {
 		return nil, err
 	}
 
-	workflowDir := filepath.Join(projectRoot, ".github", "workflows")
+	var dirs, pipelines []string
+	err = filepath.WalkDir(projectRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			for _, file := range PipelineFiles {
+				if strings.HasSuffix(filepath.ToSlash(path), "/"+file) {
+					pipelines = append(pipelines, path)
+				}
+			}
+			return nil
+		}
+		if d.Name() == ".git" || d.Name() == "node_modules" {
+			return filepath.SkipDir
+		}
+		if parent := filepath.Base(filepath.Dir(path)); d.Name() == "workflows" && (parent == ".github" || parent == ".forgejo" || parent == ".gitea") {
+			dirs = append(dirs, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var skipped unparsed
+	refs, err := referencesIn(projectRoot, dirs, extraDirs, &skipped)
+	if err != nil {
+		return nil, err
+	}
+	if refs, err = appendPipelines(refs, projectRoot, pipelines, &skipped); err != nil {
+		return nil, err
+	}
+	return refs, skipped.err()
+}
+
+// referencesIn reads the workflows in dirs and extraDirs, which are
+// resolved against projectRoot if relative
+func referencesIn(projectRoot string, dirs, extraDirs []string, skipped *unparsed) ([]ActionReference, error) {
+	for _, dir := range extraDirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(projectRoot, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+
 	actionRefs := []ActionReference{}
 	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		refs, err := walkWorkflows(projectRoot, dir, seen, skipped)
+		if err != nil {
+			return nil, err
+		}
+		actionRefs = append(actionRefs, refs...)
+	}
+	return actionRefs, nil
+}
 
-	err = filepath.Walk(workflowDir, func(path string, info os.FileInfo, err error) error {
+// walkWorkflows parses the YAML files under dir, skipping those already
+// seen, e.g. in a directory that's inside another one, and collecting
+// those that don't parse in skipped
+func walkWorkflows(projectRoot, dir string, seen map[string]bool, skipped *unparsed) ([]ActionReference, error) {
+	actionRefs := []ActionReference{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -112,35 +491,27 @@ func FindActionReferences(startDir string) ([]ActionReference, error) {
 		if !strings.HasSuffix(path, ".yml") && !strings.HasSuffix(path, ".yaml") {
 			return nil
 		}
+		if seen[path] {
+			return nil
+		}
+		seen[path] = true
 
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
 
-		var workflow map[string]interface{}
-		if err := yaml.Unmarshal(content, &workflow); err != nil {
-			return err
-		}
-
 		// Get relative path from project root
 		relPath, err := filepath.Rel(projectRoot, path)
 		if err != nil {
 			relPath = filepath.Base(path)
 		}
 
-		refs := extractActionUses(workflow)
-		for _, ref := range refs {
-			key := ref.Name + "@" + ref.Version + "@" + relPath
-			if !seen[key] {
-				seen[key] = true
-				actionRefs = append(actionRefs, ActionReference{
-					Name:    ref.Name,
-					Version: ref.Version,
-					File:    relPath,
-				})
-			}
+		refs, err := ParseWorkflow(relPath, content)
+		if err := skipped.skip(err); err != nil {
+			return err
 		}
+		actionRefs = append(actionRefs, refs...)
 
 		return nil
 	})
@@ -148,6 +519,151 @@ func FindActionReferences(startDir string) ([]ActionReference, error) {
 	return actionRefs, err
 }
 
+// Exclude drops the references in workflow files matching any of patterns.
+// Patterns are path.Match globs against File, with forward slashes, like
+// ".github/workflows/experimental-*.yml"; a pattern without a slash matches
+// the file's base name.
+func Exclude(refs []ActionReference, patterns []string) ([]ActionReference, error) {
+	if len(patterns) == 0 {
+		return refs, nil
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	kept := []ActionReference{}
+	for _, ref := range refs {
+		if !excluded(filepath.ToSlash(ref.File), patterns) {
+			kept = append(kept, ref)
+		}
+	}
+	return kept, nil
+}
+
+func excluded(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		name := file
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(file)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Only keeps the references to the actions in names, which match an
+// action's full name or its repository and may use * for anything, like
+// "actions/checkout" or "my-org/*". It also returns the names that match no
+// reference, which are likely typos. No names keeps every reference.
+func Only(refs []ActionReference, names []string) ([]ActionReference, []string) {
+	if len(names) == 0 {
+		return refs, nil
+	}
+	kept := []ActionReference{}
+	matched := make(map[string]bool)
+	for _, ref := range refs {
+		keep := false
+		for _, name := range names {
+			if nameMatches(name, ref.Name) {
+				matched[name], keep = true, true
+			}
+		}
+		if keep {
+			kept = append(kept, ref)
+		}
+	}
+	var unmatched []string
+	for _, name := range names {
+		if !matched[name] {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return kept, unmatched
+}
+
+// Skip drops the references to the actions in names, matched as Only
+// matches them
+func Skip(refs []ActionReference, names []string) []ActionReference {
+	if len(names) == 0 {
+		return refs
+	}
+	kept := []ActionReference{}
+	for _, ref := range refs {
+		skipped := false
+		for _, name := range names {
+			skipped = skipped || nameMatches(name, ref.Name)
+		}
+		if !skipped {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+// ParseWorkflow returns the actions a workflow file uses, once per name
+// and version. file is reported as the references' File. Every document
+// of a file with several, separated by ---, is read.
+func ParseWorkflow(file string, content []byte) ([]ActionReference, error) {
+	var uses []ActionReference
+	lines := make(map[string]int)
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		var workflow map[string]interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &ErrParse{Source: file, Err: err}
+		}
+		if err := doc.Decode(&workflow); err != nil {
+			return nil, &ErrParse{Source: file, Err: err}
+		}
+		usesLines(&doc, lines)
+		uses = append(uses, extractActionUses(workflow)...)
+	}
+
+	refs := []ActionReference{}
+	index := make(map[string]int)
+	for _, ref := range uses {
+		key := ref.Name + "@" + ref.Version
+		if i, ok := index[key]; ok {
+			refs[i].Uses++
+			continue
+		}
+		index[key] = len(refs)
+		refs = append(refs, ActionReference{
+			Name:    ref.Name,
+			Version: ref.Version,
+			File:    file,
+			Line:    lines[key],
+			Uses:    1,
+		})
+	}
+	return refs, nil
+}
+
+// usesLines records the first line each "uses" value appears on
+func usesLines(node *yaml.Node, lines map[string]int) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "uses" && value.Kind == yaml.ScalarNode {
+				if _, ok := lines[value.Value]; !ok {
+					lines[value.Value] = value.Line
+				}
+			}
+		}
+	}
+	for _, child := range node.Content {
+		usesLines(child, lines)
+	}
+}
+
 // extractActionUses recursively searches for "uses" fields in the workflow
 func extractActionUses(obj interface{}) []ActionReference {
 	refs := []ActionReference{}
@@ -161,6 +677,9 @@ func extractActionUses(obj interface{}) []ActionReference {
 					if strings.HasPrefix(uses, "./") {
 						continue
 					}
+					// Forgejo and Gitea take actions by URL; github.com
+					// ones are the same as the short form
+					uses = strings.TrimPrefix(uses, "https://github.com/")
 					parts := strings.SplitN(uses, "@", 2)
 					if len(parts) == 2 {
 						refs = append(refs, ActionReference{
@@ -182,146 +701,30 @@ func extractActionUses(obj interface{}) []ActionReference {
 	return refs
 }
 
-// CheckOptions configures the behavior of CheckActionVersions
-type CheckOptions struct {
-	IgnoreSHA   bool
-	IgnoreMinor bool
-	OnProgress  func(action string) // Called when checking each action
-}
-
-// CheckResult contains the results of checking action versions
-type CheckResult struct {
-	Outdated  []OutdatedAction
-	SHAPinned []SHAPinnedAction
-	Warnings  []string
-}
-
-// tagCache stores fetched tags per repo
-type tagCache struct {
-	tags map[string][]GitHubTag
-}
-
-func newTagCache() *tagCache {
-	return &tagCache{tags: make(map[string][]GitHubTag)}
-}
-
-func (tc *tagCache) getTags(repo string) ([]GitHubTag, error) {
-	if tags, ok := tc.tags[repo]; ok {
-		return tags, nil
-	}
-
-	tags, err := fetchTags(repo)
-	if err != nil {
-		return nil, err
-	}
-
-	tc.tags[repo] = tags
-	return tags, nil
-}
-
-func CheckActionVersions(actions []ActionReference, opts CheckOptions) (bool, CheckResult, error) {
-	result := CheckResult{}
-	cache := newTagCache()
-	skippedRepos := make(map[string]bool)
-
-	for _, action := range actions {
-		repo := repoFromAction(action.Name)
-
-		// Skip if we already know this repo is inaccessible
-		if skippedRepos[repo] {
-			continue
-		}
-
-		// Report progress
-		if opts.OnProgress != nil {
-			opts.OnProgress(action.Name)
-		}
-
-		// Check if this is a SHA-pinned action
-		if isSHA(action.Version) {
-			if opts.IgnoreSHA {
-				continue
-			}
-
-			// Check how far behind the SHA is
-			shaInfo, err := checkSHAStatus(repo, action.Version)
-			if err != nil {
-				var notAccessible *ErrRepoNotAccessible
-				if errors.As(err, &notAccessible) {
-					result.Warnings = append(result.Warnings,
-						fmt.Sprintf("skipping %s: repository not accessible", action.Name))
-					skippedRepos[repo] = true
-					continue
-				}
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("skipping %s: %v", action.Name, err))
-				continue
-			}
-
-			if shaInfo.CommitsBehind > 0 {
-				result.SHAPinned = append(result.SHAPinned, SHAPinnedAction{
-					File:          action.File,
-					Name:          action.Name,
-					CurrentSHA:    action.Version,
-					LatestSHA:     shaInfo.LatestSHA,
-					CommitsBehind: shaInfo.CommitsBehind,
-					DefaultBranch: shaInfo.DefaultBranch,
-				})
-			}
-			continue
-		}
-
-		tags, err := cache.getTags(repo)
-		if err != nil {
-			var notAccessible *ErrRepoNotAccessible
-			if errors.As(err, &notAccessible) {
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("skipping %s: repository not accessible", action.Name))
-				skippedRepos[repo] = true
-				continue
-			}
-			return false, result, fmt.Errorf("failed to check %s: %w", action.Name, err)
-		}
-
-		latestVersion := findLatestVersion(tags, action.Version, opts.IgnoreMinor)
-		if latestVersion == "" {
-			continue // No comparable version found
-		}
-
-		if !versionsEqual(action.Version, latestVersion) {
-			result.Outdated = append(result.Outdated, OutdatedAction{
-				Name:           action.Name,
-				CurrentVersion: action.Version,
-				LatestVersion:  latestVersion,
-				File:           action.File,
-			})
-		}
-	}
-
-	allUpToDate := len(result.Outdated) == 0 && len(result.SHAPinned) == 0
-	return allUpToDate, result, nil
-}
-
 // semver represents a parsed semantic version
 type semver struct {
-	Major    int
-	Minor    int
-	Patch    int
-	Raw      string
-	HasMinor bool // true if minor version was explicitly specified
-	HasPatch bool // true if patch version was explicitly specified
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // e.g. "rc.1" for v5.0.0-rc.1
+	Raw        string
+	HasMinor   bool // true if minor version was explicitly specified
+	HasPatch   bool // true if patch version was explicitly specified
 }
 
+// Prerelease and build suffixes need a full major.minor.patch, as in the
+// semver spec, so date tags like 2024-05-01 aren't mistaken for prereleases
+var semverRe = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?)?$`)
+
 // parseSemver parses a version string into a semver struct
-// Supports: v1, v1.2, v1.2.3
+// Supports: v1, v1.2, v1.2.3, v1.2.3-rc.1 and build metadata (v1.2.3+build)
 func parseSemver(version string) *semver {
-	re := regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?$`)
-	matches := re.FindStringSubmatch(version)
+	matches := semverRe.FindStringSubmatch(version)
 	if matches == nil {
 		return nil
 	}
 
-	sv := &semver{Raw: version}
+	sv := &semver{Raw: version, Prerelease: matches[4]}
 
 	sv.Major, _ = strconv.Atoi(matches[1])
 	if matches[2] != "" {
@@ -356,7 +759,42 @@ func (s *semver) compare(other *semver) int {
 		}
 		return 1
 	}
-	return 0
+	return comparePrerelease(s.Prerelease, other.Prerelease)
+}
+
+// comparePrerelease orders prerelease strings by semver precedence: a
+// release sorts after any of its prereleases, and dot-separated identifiers
+// compare numerically when both are numbers
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range min(len(as), len(bs)) {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmp.Compare(an, bn)
+			}
+		case aErr == nil:
+			return -1 // Numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
 }
 
 // findLatestVersion finds the latest version tag
@@ -424,8 +862,8 @@ func findLatestVersion(tags []GitHubTag, currentVersion string, ignoreMinor bool
 			// User only specified major.minor (e.g., v6.1), skip patch updates
 			continue
 		}
-		if candidate.Major == currentSV.Major && candidate.Minor == currentSV.Minor && candidate.Patch > currentSV.Patch {
-			// Same major.minor, newer patch
+		if candidate.Major == currentSV.Major && candidate.Minor == currentSV.Minor && candidate.compare(currentSV) > 0 {
+			// Same major.minor, newer patch (or the release of a prerelease)
 			return candidate.Raw
 		}
 	}
@@ -433,6 +871,44 @@ func findLatestVersion(tags []GitHubTag, currentVersion string, ignoreMinor bool
 	return ""
 }
 
+// hasTag reports whether tags contains a tag named version. Tags are
+// fetched a limited number of pages deep, so a list that ends on a full page
+// may be truncated and is never treated as conclusive.
+func hasTag(tags []GitHubTag, version string) bool {
+	if len(tags) > 0 && len(tags)%perPage == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if tag.Name == version {
+			return true
+		}
+	}
+	return false
+}
+
+// publishedTags returns the tags of releases that aren't drafts and, unless
+// prereleases is set, aren't marked as prereleases
+func publishedTags(releases []GitHubRelease, prereleases bool) []GitHubTag {
+	var tags []GitHubTag
+	for _, release := range releases {
+		if !release.Draft && (prereleases || !release.Prerelease) {
+			tags = append(tags, GitHubTag{Name: release.TagName})
+		}
+	}
+	return tags
+}
+
+// withoutPrereleases drops tags with a semver prerelease suffix
+func withoutPrereleases(tags []GitHubTag) []GitHubTag {
+	var releases []GitHubTag
+	for _, tag := range tags {
+		if sv := parseSemver(tag.Name); sv == nil || sv.Prerelease == "" {
+			releases = append(releases, tag)
+		}
+	}
+	return releases
+}
+
 // versionsEqual checks if two version strings represent the same version
 func versionsEqual(v1, v2 string) bool {
 	sv1 := parseSemver(v1)
@@ -443,6 +919,24 @@ func versionsEqual(v1, v2 string) bool {
 	return sv1.compare(sv2) == 0
 }
 
+// PinsCommits reports whether most of the GitHub actions in refs are
+// pinned to commit SHAs rather than tags or branches, as in projects that
+// pin by policy
+func PinsCommits(refs []ActionReference) bool {
+	pinned, total := 0, 0
+	for _, ref := range refs {
+		// Orbs, pipes, tasks and actions on forges have a scheme or prefix
+		if strings.Contains(ref.Name, ":") || IsGitLabComponent(ref.Name) {
+			continue
+		}
+		total++
+		if isSHA(ref.Version) {
+			pinned++
+		}
+	}
+	return total > 0 && pinned*2 > total
+}
+
 // isSHA returns true if the version string looks like a git SHA
 func isSHA(version string) bool {
 	// SHA commits are 40 hex characters (full) or 7+ hex characters (short)
@@ -464,15 +958,15 @@ type shaStatus struct {
 	DefaultBranch string
 }
 
-func checkSHAStatus(repo, sha string) (*shaStatus, error) {
+func checkSHAStatus(ctx context.Context, client GitHubClient, repo, sha string) (*shaStatus, error) {
 	// First, get the default branch
-	defaultBranch, err := getDefaultBranch(repo)
+	defaultBranch, err := client.DefaultBranch(ctx, repo)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the latest SHA on the default branch
-	latestSHA, err := getBranchHead(repo, defaultBranch)
+	latestSHA, err := client.BranchHead(ctx, repo, defaultBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -480,167 +974,40 @@ func checkSHAStatus(repo, sha string) (*shaStatus, error) {
 	// If already at latest, no need to compare
 	if strings.HasPrefix(latestSHA, sha) || strings.HasPrefix(sha, latestSHA) {
 		return &shaStatus{
-			LatestSHA: latestSHA, 
-			CommitsBehind: 0, 
+			LatestSHA:     latestSHA,
+			CommitsBehind: 0,
 			DefaultBranch: defaultBranch,
 		}, nil
 	}
 
 	// Compare the commits
-	behindBy, err := compareCommits(repo, sha, defaultBranch)
+	behindBy, err := client.CompareCommits(ctx, repo, sha, defaultBranch)
 	if err != nil {
 		return nil, err
 	}
 
 	return &shaStatus{
-		LatestSHA: latestSHA, 
-		CommitsBehind: behindBy, 
+		LatestSHA:     latestSHA,
+		CommitsBehind: behindBy,
 		DefaultBranch: defaultBranch,
 	}, nil
 }
 
-func getDefaultBranch(repo string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s", repo)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "token "+token)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
-		return "", &ErrRepoNotAccessible{Repo: repo, Status: resp.StatusCode}
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var repoInfo GitHubRepo
-	if err := json.NewDecoder(resp.Body).Decode(&repoInfo); err != nil {
-		return "", err
-	}
-
-	return repoInfo.DefaultBranch, nil
-}
-
-func getBranchHead(repo, branch string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/git/ref/heads/%s", repo, branch)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "token "+token)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var ref GitHubRef
-	if err := json.NewDecoder(resp.Body).Decode(&ref); err != nil {
-		return "", err
-	}
-
-	return ref.Object.SHA, nil
-}
-
-func compareCommits(repo, baseSHA, head string) (int, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/compare/%s...%s", repo, baseSHA, head)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return 0, err
-	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "token "+token)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var compare GitHubCompare
-	if err := json.NewDecoder(resp.Body).Decode(&compare); err != nil {
-		return 0, err
-	}
-
-	return compare.AheadBy, nil
-}
-
 // repoFromAction extracts the owner/repo from an action name
 // e.g., "actions/cache/restore" -> "actions/cache"
 func repoFromAction(name string) string {
+	if host, repo, ok := forgeRepo(name); ok {
+		return host + "/" + repo
+	}
+	if host, project, ok := gitlabComponent(name); ok {
+		return host + "/" + project
+	}
+	if IsOrb(name) || IsPipe(name) || IsDockerImage(name) || IsAzureTask(name) {
+		return name
+	}
 	parts := strings.Split(name, "/")
 	if len(parts) >= 2 {
 		return parts[0] + "/" + parts[1]
 	}
 	return name
 }
-
-// fetchTags fetches all tags from GitHub for a repository
-func fetchTags(repo string) ([]GitHubTag, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/tags?per_page=100", repo)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "token "+token)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
-		return nil, &ErrRepoNotAccessible{Repo: repo, Status: resp.StatusCode}
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var tags []GitHubTag
-	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
-		return nil, err
-	}
-
-	return tags, nil
-}
\ No newline at end of file
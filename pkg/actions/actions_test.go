@@ -1,8 +1,11 @@
 package actions
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +19,11 @@ func TestParseSemver(t *testing.T) {
 		{"v1.2.3", &semver{Major: 1, Minor: 2, Patch: 3, Raw: "v1.2.3", HasMinor: true, HasPatch: true}},
 		{"1.2.3", &semver{Major: 1, Minor: 2, Patch: 3, Raw: "1.2.3", HasMinor: true, HasPatch: true}},
 		{"v10.20.30", &semver{Major: 10, Minor: 20, Patch: 30, Raw: "v10.20.30", HasMinor: true, HasPatch: true}},
+		{"v5.0.0-rc.1", &semver{Major: 5, Minor: 0, Patch: 0, Prerelease: "rc.1", Raw: "v5.0.0-rc.1", HasMinor: true, HasPatch: true}},
+		{"v1.2.3+build.5", &semver{Major: 1, Minor: 2, Patch: 3, Raw: "v1.2.3+build.5", HasMinor: true, HasPatch: true}},
+		{"v1.2.3-", nil},
+		{"v5-beta", nil},
+		{"2024-05-01", nil},
 		{"invalid", nil},
 		{"v1.2.3.4", nil},
 		{"vABC", nil},
@@ -38,6 +46,7 @@ func TestParseSemver(t *testing.T) {
 			if result.Major != tt.expected.Major ||
 				result.Minor != tt.expected.Minor ||
 				result.Patch != tt.expected.Patch ||
+				result.Prerelease != tt.expected.Prerelease ||
 				result.Raw != tt.expected.Raw ||
 				result.HasMinor != tt.expected.HasMinor ||
 				result.HasPatch != tt.expected.HasPatch {
@@ -62,6 +71,14 @@ func TestSemverCompare(t *testing.T) {
 		{"v1.0.2", "v1.0.1", 1},
 		{"v1", "v1.0.0", 0},
 		{"v1.2", "v1.2.0", 0},
+		{"v5.0.0-rc.1", "v5.0.0", -1},
+		{"v5.0.0", "v5.0.0-rc.1", 1},
+		{"v5.0.0-rc.1", "v5.0.0-rc.2", -1},
+		{"v5.0.0-rc.2", "v5.0.0-rc.10", -1},
+		{"v5.0.0-alpha", "v5.0.0-alpha.1", -1},
+		{"v5.0.0-alpha.1", "v5.0.0-beta", -1},
+		{"v5.0.0-1", "v5.0.0-alpha", -1},
+		{"v1.2.3+a", "v1.2.3+b", 0},
 	}
 
 	for _, tt := range tests {
@@ -199,6 +216,22 @@ func TestFindLatestVersion(t *testing.T) {
 	}
 }
 
+func TestFindLatestVersionPrereleases(t *testing.T) {
+	tags := []GitHubTag{{Name: "v5.0.0-rc.1"}, {Name: "v5.0.0-rc.2"}, {Name: "v4.1.0"}}
+
+	if result := findLatestVersion(tags, "v5.0.0-rc.1", false); result != "v5.0.0-rc.2" {
+		t.Errorf("expected the newer release candidate, got %q", result)
+	}
+	if result := findLatestVersion(withoutPrereleases(tags), "v4.0.0", false); result != "v4.1.0" {
+		t.Errorf("expected prereleases to be filtered out, got %q", result)
+	}
+
+	tags = append(tags, GitHubTag{Name: "v5.0.0"})
+	if result := findLatestVersion(tags, "v5.0.0-rc.2", false); result != "v5.0.0" {
+		t.Errorf("expected the final release, got %q", result)
+	}
+}
+
 func TestExtractActionUses(t *testing.T) {
 	workflow := map[string]interface{}{
 		"jobs": map[string]interface{}{
@@ -246,6 +279,313 @@ func TestExtractActionUses(t *testing.T) {
 	}
 }
 
+func TestParseWorkflowLines(t *testing.T) {
+	content := []byte(`on: push
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - run: echo hello
+      - uses: actions/setup-go@v5
+  test:
+    steps:
+      - uses: actions/checkout@v4
+`)
+	refs, err := ParseWorkflow("ci.yml", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make(map[string]int)
+	for _, ref := range refs {
+		lines[ref.Name+"@"+ref.Version] = ref.Line
+	}
+	if len(refs) != 2 || lines["actions/checkout@v4"] != 5 || lines["actions/setup-go@v5"] != 7 {
+		t.Errorf("unexpected refs: %+v", refs)
+	}
+}
+
+func TestParseWorkflowMultipleDocuments(t *testing.T) {
+	content := []byte(`# generated
+on: push
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+---
+---
+on: release
+jobs:
+  publish:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/upload-artifact@v4
+`)
+	refs, err := ParseWorkflow("ci.yml", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml", Line: 6, Uses: 2},
+		{Name: "actions/upload-artifact", Version: "v4", File: "ci.yml", Line: 14, Uses: 1},
+	}
+	if !slices.Equal(refs, want) {
+		t.Errorf("expected %+v, got %+v", want, refs)
+	}
+
+	if _, err := ParseWorkflow("ci.yml", []byte("on: push\n---\njobs: [\n")); !errors.Is(err, &ErrParse{}) {
+		t.Errorf("expected a parse error in the second document, got %v", err)
+	}
+	if refs, err := ParseWorkflow("empty.yml", nil); err != nil || len(refs) != 0 {
+		t.Errorf("expected no references in an empty file, got %+v, %v", refs, err)
+	}
+}
+
+func TestFileReferences(t *testing.T) {
+	root := t.TempDir()
+	workflows := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(workflows, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(workflows, "ci.yml")
+	if err := os.WriteFile(path, []byte("steps:\n  - uses: actions/checkout@v4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := FileReferences(root, []string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ActionReference{Name: "actions/checkout", Version: "v4", File: filepath.Join(".github", "workflows", "ci.yml"), Line: 2, Uses: 1}
+	if len(refs) != 1 || refs[0] != want {
+		t.Errorf("expected %+v, got %+v", want, refs)
+	}
+
+	if _, err := FileReferences(root, []string{filepath.Join(workflows, "missing.yml")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+
+	stdin = strings.NewReader("steps:\n  - uses: actions/setup-go@v5\n")
+	t.Cleanup(func() { stdin = os.Stdin })
+	refs, err = FileReferences(root, []string{"-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = ActionReference{Name: "actions/setup-go", Version: "v5", File: StdinName, Line: 2, Uses: 1}
+	if len(refs) != 1 || refs[0] != want {
+		t.Errorf("expected %+v from stdin, got %+v", want, refs)
+	}
+}
+
+func TestFindActionReferencesExtraDirs(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml":          "steps:\n  - uses: actions/checkout@v4\n",
+		".github/workflows/shared/lint.yml": "steps:\n  - uses: actions/setup-go@v5\n",
+		"templates/workflows/release.yaml":  "steps:\n  - uses: actions/upload-artifact@v4\n",
+		"templates/workflows/README.md":     "not a workflow",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A directory inside .github/workflows isn't read twice
+	refs, err := FindActionReferences(root, "templates/workflows", filepath.Join(root, ".github", "workflows", "shared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ref := range refs {
+		got = append(got, ref.File+" "+ref.Name)
+	}
+	want := []string{
+		filepath.Join(".github", "workflows", "ci.yml") + " actions/checkout",
+		filepath.Join(".github", "workflows", "shared", "lint.yml") + " actions/setup-go",
+		filepath.Join("templates", "workflows", "release.yaml") + " actions/upload-artifact",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindActionReferences() = %q, want %q", got, want)
+	}
+
+	if _, err := FindActionReferences(root, "missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestFindActionReferencesUnparsed(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".github/workflows/a.yml":     "steps:\n  - uses: actions/checkout@v4\n",
+		".github/workflows/bad.yml":   "steps: [\n",
+		".github/workflows/c.yml":     "steps:\n  - uses: actions/setup-go@v5\n",
+		".github/workflows/worse.yml": "jobs:\n\tbuild: {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs, err := FindActionReferences(root)
+	var unparsed *ErrUnparsed
+	if !errors.As(err, &unparsed) || !errors.Is(err, &ErrParse{}) {
+		t.Fatalf("expected ErrUnparsed, got %v", err)
+	}
+	var sources []string
+	for _, e := range unparsed.Errs {
+		sources = append(sources, filepath.Base(e.Source))
+	}
+	if !slices.Equal(sources, []string{"bad.yml", "worse.yml"}) {
+		t.Errorf("expected bad.yml and worse.yml to fail, got %v", sources)
+	}
+	if len(refs) != 2 || refs[0].Name != "actions/checkout" || refs[1].Name != "actions/setup-go" {
+		t.Errorf("expected the other files' references, got %+v", refs)
+	}
+}
+
+func TestFindAllActionReferences(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml":                       "steps:\n  - uses: actions/checkout@v4\n",
+		"vendor/lib/.github/workflows/test.yml":          "steps:\n  - uses: actions/setup-go@v5\n",
+		"node_modules/pkg/.github/workflows/publish.yml": "steps:\n  - uses: actions/setup-node@v4\n",
+		"templates/.github/workflows/nested/release.yml": "steps:\n  - uses: actions/upload-artifact@v4\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs, err := FindAllActionReferences(filepath.Join(root, "vendor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ref := range refs {
+		got = append(got, ref.File)
+	}
+	want := []string{
+		filepath.Join(".github", "workflows", "ci.yml"),
+		filepath.Join("templates", ".github", "workflows", "nested", "release.yml"),
+		filepath.Join("vendor", "lib", ".github", "workflows", "test.yml"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindAllActionReferences() read %q, want %q", got, want)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "actions/checkout", File: filepath.Join(".github", "workflows", "ci.yml")},
+		{Name: "actions/checkout", File: filepath.Join(".github", "workflows", "experimental-arm.yml")},
+		{Name: "actions/checkout", File: filepath.Join("vendor", "lib", ".github", "workflows", "generated.yaml")},
+	}
+
+	kept, err := Exclude(refs, []string{".github/workflows/experimental-*.yml", "generated.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0] != refs[0] {
+		t.Errorf("Exclude() = %+v, want only ci.yml", kept)
+	}
+
+	if _, err := Exclude(refs, []string{"[unclosed"}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestOnly(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4"},
+		{Name: "actions/setup-go", Version: "v5"},
+		{Name: "github/codeql-action/init", Version: "v3"},
+		{Name: "my-org/deploy", Version: "v1"},
+	}
+
+	kept, unmatched := Only(refs, []string{"Actions/Checkout", "github/codeql-action", "my-org/*", "actions/chekout"})
+	if want := []ActionReference{refs[0], refs[2], refs[3]}; !slices.Equal(kept, want) {
+		t.Errorf("Only() = %+v, want %+v", kept, want)
+	}
+	if !slices.Equal(unmatched, []string{"actions/chekout"}) {
+		t.Errorf("expected actions/chekout to match nothing, got %v", unmatched)
+	}
+
+	if kept, _ := Only(refs, nil); len(kept) != len(refs) {
+		t.Errorf("Only() without names kept %d of %d references", len(kept), len(refs))
+	}
+}
+
+func TestSkip(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4"},
+		{Name: "my-org/flaky-action", Version: "v1"},
+		{Name: "other-org/tool/setup", Version: "v2"},
+	}
+
+	kept := Skip(refs, []string{"My-Org/flaky-action", "other-org/*"})
+	if want := []ActionReference{refs[0]}; !slices.Equal(kept, want) {
+		t.Errorf("Skip() = %+v, want %+v", kept, want)
+	}
+	if kept := Skip(refs, nil); len(kept) != len(refs) {
+		t.Errorf("Skip() without names kept %d of %d references", len(kept), len(refs))
+	}
+}
+
+func TestPinsCommits(t *testing.T) {
+	sha := "8e5e7e5ab8b370d6c329ec480221332ada57f0ab"
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: sha},
+		{Name: "actions/setup-go", Version: sha},
+		{Name: "actions/cache", Version: "v4"},
+		{Name: "orb:circleci/node", Version: "5.2.0"},
+		{Name: "task:AzureCLI", Version: "2"},
+	}
+	if !PinsCommits(refs) {
+		t.Error("PinsCommits() = false with two of three actions pinned")
+	}
+	if PinsCommits(refs[2:]) {
+		t.Error("PinsCommits() = true without pinned actions")
+	}
+	if PinsCommits(nil) {
+		t.Error("PinsCommits(nil) = true")
+	}
+}
+
+func TestSHAPinnedActionDescribe(t *testing.T) {
+	pin := SHAPinnedAction{Name: "actions/checkout", CurrentSHA: "8e5e7e5ab8b370d6c329ec480221332ada57f0ab",
+		LatestSHA: "a1b2c3d4e5", CommitsBehind: 57, DefaultBranch: "main",
+		CurrentTag: "v4.1.0", LatestTag: "v4.2.2", LatestTagSHA: "11bd71901bbe5b1630ceea73d27597364c9af683"}
+	for _, tt := range []struct {
+		edit func(*SHAPinnedAction)
+		want string
+	}{
+		{func(*SHAPinnedAction) {}, "actions/checkout@8e5e7e5 (v4.1.0) is 57 commits behind main (a1b2c3d); v4.2.2 is out (11bd719)"},
+		{func(p *SHAPinnedAction) { p.DefaultBranch = "" }, "actions/checkout@8e5e7e5 (v4.1.0) can be updated to v4.2.2 (11bd719)"},
+		{func(p *SHAPinnedAction) { p.CurrentTag, p.LatestTag, p.CommitsBehind = "", "", 1 }, "actions/checkout@8e5e7e5 is 1 commit behind main (a1b2c3d)"},
+	} {
+		p := pin
+		tt.edit(&p)
+		if got := p.Describe(); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
 func TestFindProjectRoot(t *testing.T) {
 	// Create a temp directory structure
 	tmpDir, err := os.MkdirTemp("", "aver-test")
@@ -297,29 +637,22 @@ func TestFindProjectRoot(t *testing.T) {
 	}
 }
 
-func TestTagCache(t *testing.T) {
-	cache := newTagCache()
-
-	// Manually populate cache
-	cache.tags["owner/repo"] = []GitHubTag{
-		{Name: "v1.0.0"},
-		{Name: "v2.0.0"},
-	}
-
-	// Should return cached value
-	tags, err := cache.getTags("owner/repo")
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
-	}
-	if len(tags) != 2 {
-		t.Errorf("expected 2 tags, got %d", len(tags))
-	}
-}
-
 func TestErrRepoNotAccessible(t *testing.T) {
 	err := &ErrRepoNotAccessible{Repo: "owner/repo", Status: 404}
 	expected := "repository owner/repo not accessible (status 404)"
 	if err.Error() != expected {
 		t.Errorf("expected %q, got %q", expected, err.Error())
 	}
+	if hint := err.Hint(); hint != "" {
+		t.Errorf("expected no hint, got %q", hint)
+	}
+
+	err.MissingScope = "repo"
+	if hint := err.Hint(); hint != "if it's private, give the token the repo scope" {
+		t.Errorf("unexpected hint %q", hint)
+	}
+	err.SSOURL = "https://github.com/orgs/owner/sso"
+	if hint := err.Hint(); hint != "authorize the token for the organization's SAML single sign-on at https://github.com/orgs/owner/sso" {
+		t.Errorf("unexpected hint %q", hint)
+	}
 }
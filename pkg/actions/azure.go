@@ -0,0 +1,157 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AzurePipelinesFile is the pipeline configuration at the root of an Azure
+// Pipelines project
+const AzurePipelinesFile = "azure-pipelines.yml"
+
+// TaskPrefix starts the names of built-in Azure Pipelines tasks
+// ("task:AzureCLI")
+const TaskPrefix = "task:"
+
+// azureTasksRepo is where the built-in tasks live, one directory per major
+// version (Tasks/AzureCLIV2)
+const azureTasksRepo = "microsoft/azure-pipelines-tasks"
+
+// IsAzureTask reports whether name is a built-in Azure Pipelines task
+func IsAzureTask(name string) bool {
+	return strings.HasPrefix(name, TaskPrefix)
+}
+
+// AzureTaskURL returns the source of a task at a major version, or the
+// directory of every task if version is empty
+func AzureTaskURL(name, version string) string {
+	url := "https://github.com/" + azureTasksRepo + "/tree/master/Tasks"
+	if version == "" {
+		return url
+	}
+	return url + "/" + strings.TrimPrefix(name, TaskPrefix) + "V" + version
+}
+
+// ParseAzurePipelines returns what an Azure pipeline depends on, once per
+// name and version:
+//
+//	resources:
+//	  repositories:
+//	    - repository: templates
+//	      type: github
+//	      name: contoso/pipeline-templates
+//	      ref: refs/tags/v1.2.0
+//	steps:
+//	  - task: AzureCLI@2
+//
+// Template repositories on GitHub are named owner/repo like actions, at the
+// tag, branch or commit of their ref; those without a ref follow the default
+// branch and are left out, as are repositories in Azure Repos or Bitbucket.
+// Built-in tasks are named with TaskPrefix at their major version. Tasks
+// from Marketplace extensions (publisher.extension.task) have no public
+// version history and are left out. file is reported as the references'
+// File.
+func ParseAzurePipelines(file string, content []byte) ([]ActionReference, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, &ErrParse{Source: file, Err: err}
+	}
+
+	refs := []ActionReference{}
+	seen := make(map[string]bool)
+	add := func(name, version string, line int) {
+		if !seen[name+"@"+version] {
+			seen[name+"@"+version] = true
+			refs = append(refs, ActionReference{Name: name, Version: version, File: file, Line: line})
+		}
+	}
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			fields := make(map[string]*yaml.Node)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				fields[node.Content[i].Value] = node.Content[i+1]
+			}
+			if task := fields["task"]; task != nil && task.Kind == yaml.ScalarNode {
+				name, version, ok := strings.Cut(task.Value, "@")
+				if ok && !strings.Contains(name, ".") {
+					add(TaskPrefix+name, version, task.Line)
+				}
+			}
+			if repo, ref := fields["repository"], fields["ref"]; repo != nil && ref != nil && fields["name"] != nil {
+				kind := fields["type"]
+				if kind != nil && (kind.Value == "github" || kind.Value == "githubenterprise") {
+					version := strings.TrimPrefix(strings.TrimPrefix(ref.Value, "refs/tags/"), "refs/heads/")
+					add(fields["name"].Value, version, ref.Line)
+				}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(&doc)
+	return refs, nil
+}
+
+// azureTaskDir matches the directory of a major version of a task
+var azureTaskDir = regexp.MustCompile(`^(.+)V(\d+)$`)
+
+// azureTaskClient answers the GitHubClient calls for built-in tasks from
+// the directories of microsoft/azure-pipelines-tasks on GitHub: each major
+// version of a task is a tag. There are no releases or commits.
+type azureTaskClient struct {
+	*HTTPClient
+}
+
+func (c *azureTaskClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	var entries []GitHubContent
+	status, _, err := c.get(ctx, fmt.Sprintf("/repos/%s/contents/Tasks", azureTasksRepo), &entries)
+	if err != nil {
+		return nil, notAccessible(azureTasksRepo, status, err)
+	}
+	name := strings.TrimPrefix(repo, TaskPrefix)
+	var tags []GitHubTag
+	for _, entry := range entries {
+		if m := azureTaskDir.FindStringSubmatch(entry.Name); m != nil && entry.Type == "dir" && strings.EqualFold(m[1], name) {
+			var tag GitHubTag
+			tag.Name = m[2]
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return nil, &ErrRepoNotAccessible{Repo: repo, Status: http.StatusNotFound}
+	}
+	return tags, nil
+}
+
+func (c *azureTaskClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	return nil, nil
+}
+
+func (c *azureTaskClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *azureTaskClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *azureTaskClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return 0, errNoCommits
+}
+
+func (c *azureTaskClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+}
+
+func (c *azureTaskClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return nil, fmt.Errorf("can't read the workflows of %s: it's a task", repo)
+}
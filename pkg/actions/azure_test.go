@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAzurePipelines(t *testing.T) {
+	refs, err := ParseAzurePipelines(AzurePipelinesFile, []byte(`resources:
+  repositories:
+    - repository: templates
+      type: github
+      name: contoso/pipeline-templates
+      ref: refs/tags/v1.2.0
+      endpoint: github
+    - repository: tools
+      type: git
+      name: Contoso/Tools
+      ref: refs/tags/v3
+    - repository: latest
+      type: github
+      name: contoso/latest
+stages:
+  - stage: build
+    jobs:
+      - job: build
+        steps:
+          - task: AzureCLI@2
+            inputs: {scriptType: bash}
+          - task: contoso.tools.deploy@1
+          - task: AzureCLI@2
+          - template: steps.yml@templates
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ActionReference{
+		{Name: "contoso/pipeline-templates", Version: "v1.2.0", File: AzurePipelinesFile, Line: 6},
+		{Name: "task:AzureCLI", Version: "2", File: AzurePipelinesFile, Line: 20},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("ParseAzurePipelines() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ParseAzurePipelines()[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
+
+func TestCheckerAzureTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/microsoft/azure-pipelines-tasks/contents/Tasks" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"name": "AzureCLIV1", "type": "dir"},
+			{"name": "AzureCLIV2", "type": "dir"},
+			{"name": "AzureCLIV3", "type": "dir"},
+			{"name": "CmdLineV2", "type": "dir"},
+			{"name": "README.md", "type": "file"}
+		]`))
+	}))
+	defer server.Close()
+
+	hc := NewHTTPClient("")
+	hc.BaseURL = server.URL
+	client := &routedClient{fallback: &fakeClient{}, tasks: &azureTaskClient{HTTPClient: hc}}
+
+	refs := []ActionReference{
+		{Name: "task:AzureCLI", Version: "2"},
+		{Name: "task:CmdLine", Version: "2"},
+		{Name: "task:NoSuchTask", Version: "1"},
+	}
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Outdated) != 1 || result.Outdated[0].Name != "task:AzureCLI" || result.Outdated[0].LatestVersion != "3" {
+		t.Errorf("Outdated = %+v, want AzureCLI 2 -> 3", result.Outdated)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Warnings = %q, want one for the unknown task", result.Warnings)
+	}
+	if got := AzureTaskURL("task:AzureCLI", "3"); got != "https://github.com/microsoft/azure-pipelines-tasks/tree/master/Tasks/AzureCLIV3" {
+		t.Errorf("AzureTaskURL() = %q", got)
+	}
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Baseline is a saved JSON report (`aver --json`) of findings that are
+// already known, so that only new ones fail a run
+type Baseline struct {
+	Outdated  []OutdatedAction    `json:"outdated"`
+	SHAPinned []SHAPinnedAction   `json:"sha_pinned"`
+	Commands  []DeprecatedCommand `json:"deprecated_commands"`
+	Inputs    []InputFinding      `json:"inputs"`
+}
+
+// LoadBaseline reads a baseline saved from `aver --json`
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, &ErrParse{Source: path, Err: err}
+	}
+	return &b, nil
+}
+
+// Filter removes findings that are already in the baseline from result and
+// returns how many it removed. A finding is known if the same file (in the
+// same repository, for scans) pins the same action at the same version,
+// whatever the latest version is now. Baselines saved with --dedupe know
+// every file of a merged finding. A deprecated workflow command is known
+// if the file already used it, on any line, and a problem with a step
+// input if a step of the file already passed the input to the action, at
+// any version.
+func (b *Baseline) Filter(result CheckResult) (CheckResult, int) {
+	known := make(map[string]bool)
+	for _, a := range b.Outdated {
+		for _, file := range filesOf(a.File, a.Files) {
+			known[findingKey(a.Repository, file, a.Name, a.CurrentVersion)] = true
+		}
+	}
+	for _, a := range b.SHAPinned {
+		for _, file := range filesOf(a.File, a.Files) {
+			known[findingKey(a.Repository, file, a.Name, a.CurrentSHA)] = true
+		}
+	}
+
+	removed := 0
+	var outdated []OutdatedAction
+	for _, a := range result.Outdated {
+		if known[findingKey(a.Repository, a.File, a.Name, a.CurrentVersion)] {
+			removed++
+			continue
+		}
+		outdated = append(outdated, a)
+	}
+	var shaPinned []SHAPinnedAction
+	for _, a := range result.SHAPinned {
+		if known[findingKey(a.Repository, a.File, a.Name, a.CurrentSHA)] {
+			removed++
+			continue
+		}
+		shaPinned = append(shaPinned, a)
+	}
+	for _, d := range b.Commands {
+		known[findingKey(d.Repository, d.File, "::"+d.Command, "")] = true
+	}
+	var commands []DeprecatedCommand
+	for _, d := range result.Commands {
+		if known[findingKey(d.Repository, d.File, "::"+d.Command, "")] {
+			removed++
+			continue
+		}
+		commands = append(commands, d)
+	}
+	for _, f := range b.Inputs {
+		known[findingKey(f.Repository, f.File, f.Name, "with."+f.Input)] = true
+	}
+	var inputs []InputFinding
+	for _, f := range result.Inputs {
+		if known[findingKey(f.Repository, f.File, f.Name, "with."+f.Input)] {
+			removed++
+			continue
+		}
+		inputs = append(inputs, f)
+	}
+	result.Outdated, result.SHAPinned, result.Commands, result.Inputs = outdated, shaPinned, commands, inputs
+	return result, removed
+}
+
+// findingKey identifies a pin of an action in a workflow file
+func findingKey(repository, file, name, version string) string {
+	return fmt.Sprintf("%s %s %s@%s", repository, file, name, version)
+}
@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaselineFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	report := `{
+  "outdated": [{"file": "ci.yml", "action": "actions/checkout", "current": "v3", "latest": "v4"}],
+  "sha_pinned": [{"file": "ci.yml", "action": "actions/cache", "current_sha": "abc1234"}],
+  "deprecated_commands": [{"file": "ci.yml", "line": 12, "command": "set-output", "use_instead": "$GITHUB_OUTPUT"}],
+  "inputs": [{"file": "ci.yml", "line": 9, "action": "actions/checkout", "version": "v3", "input": "fetch_depth", "problem": "unknown"}]
+}`
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, removed := baseline.Filter(CheckResult{
+		Outdated: []OutdatedAction{
+			// Known, even though a newer version has come out since
+			{File: "ci.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v5"},
+			// The same pin in another file is new
+			{File: "release.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v5"},
+		},
+		SHAPinned: []SHAPinnedAction{
+			{File: "ci.yml", Name: "actions/cache", CurrentSHA: "abc1234"},
+			{File: "ci.yml", Name: "actions/cache", CurrentSHA: "def5678"},
+		},
+		Commands: []DeprecatedCommand{
+			// Known on another line
+			{File: "ci.yml", Line: 14, Command: "set-output", UseInstead: "$GITHUB_OUTPUT"},
+			{File: "ci.yml", Line: 20, Command: "save-state", UseInstead: "$GITHUB_STATE"},
+		},
+		Inputs: []InputFinding{
+			// Known at another version and line
+			{File: "ci.yml", Line: 11, Name: "actions/checkout", Version: "v4", Input: "fetch_depth", Problem: InputUnknown},
+			{File: "ci.yml", Line: 11, Name: "actions/checkout", Version: "v4", Input: "tokn", Problem: InputUnknown},
+			{File: "release.yml", Line: 5, Name: "actions/checkout", Version: "v4", Input: "fetch_depth", Problem: InputUnknown},
+		},
+		Warnings: []string{"kept"},
+	})
+	if removed != 4 {
+		t.Errorf("expected 4 known findings removed, got %d", removed)
+	}
+	if len(result.Inputs) != 2 || result.Inputs[0].Input != "tokn" || result.Inputs[1].File != "release.yml" {
+		t.Errorf("unexpected input problems: %+v", result.Inputs)
+	}
+	if len(result.Commands) != 1 || result.Commands[0].Command != "save-state" {
+		t.Errorf("unexpected deprecated commands: %+v", result.Commands)
+	}
+	if len(result.Outdated) != 1 || result.Outdated[0].File != "release.yml" {
+		t.Errorf("unexpected outdated actions: %+v", result.Outdated)
+	}
+	if len(result.SHAPinned) != 1 || result.SHAPinned[0].CurrentSHA != "def5678" {
+		t.Errorf("unexpected SHA-pinned actions: %+v", result.SHAPinned)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected warnings to be kept, got %v", result.Warnings)
+	}
+}
+
+func TestBaselineFilterDeduped(t *testing.T) {
+	baseline := &Baseline{Outdated: []OutdatedAction{{
+		File: "ci.yml", Files: []string{"ci.yml", "release.yml"},
+		Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4",
+	}}}
+	result, removed := baseline.Filter(CheckResult{Outdated: []OutdatedAction{
+		{File: "ci.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"},
+		{File: "release.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"},
+		{File: "lint.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"},
+	}})
+	if removed != 2 || len(result.Outdated) != 1 || result.Outdated[0].File != "lint.yml" {
+		t.Errorf("expected only lint.yml to be new, got %d removed and %+v", removed, result.Outdated)
+	}
+}
+
+func TestLoadBaselineInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBaseline(path); !errors.Is(err, &ErrParse{}) {
+		t.Errorf("expected ErrParse, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"context"
+	"strings"
+)
+
+// Estimate is how many API requests checking some references takes on the
+// main API host, not counting responses the cache will answer
+type Estimate struct {
+	Repositories int // Repositories whose tags are listed
+	SHAPins      int // Distinct commits compared with their default branch
+	// Requests is the fewest requests the check makes: the tags of each
+	// repository (and its releases with WithReleases), and the default
+	// branch, its head and a comparison for SHA pins. Comparing SHA pins by
+	// tag lists their repositories' tags instead, or as well with
+	// SHACompareBoth.
+	Requests int
+	// MaxRequests also counts what outdated actions cost, for releases,
+	// publish dates and commits behind, as if every tag pin were outdated
+	MaxRequests int
+}
+
+// Estimate works out how many API requests checking refs takes. Only the
+// main API host is counted: routes and other ecosystems have rate limits
+// of their own. Lists are counted as one page.
+func (c *Checker) Estimate(refs []ActionReference) Estimate {
+	var e Estimate
+	hc, ok := c.mainClient().(*HTTPClient)
+	if !ok {
+		return e
+	}
+
+	tagged := make(map[string]map[string]bool) // versions, by repository
+	pinned := make(map[string]map[string]bool) // SHAs, by repository
+	for _, ref := range refs {
+		repo := repoFromAction(ref.Name)
+		if ref.Dynamic() || c.clientFor(repo) != hc || ignoredEntirely(c.ignoreRules, ref.Name) {
+			continue
+		}
+		pins := tagged
+		if isSHA(ref.Version) {
+			if c.ignoreSHA {
+				continue
+			}
+			pins = pinned
+		}
+		if pins[repo] == nil {
+			pins[repo] = make(map[string]bool)
+		}
+		pins[repo][ref.Version] = true
+	}
+
+	// Comparing by tag lists the tags of SHA pins' repositories, and
+	// checks their versions like tag pins
+	byTag := c.shaCompare == SHACompareTag || c.shaCompare == SHACompareBoth
+	if byTag {
+		for repo, shas := range pinned {
+			if tagged[repo] == nil {
+				tagged[repo] = make(map[string]bool)
+			}
+			for sha := range shas {
+				tagged[repo][sha] = true
+			}
+		}
+	}
+
+	extra := 0
+	for repo, versions := range tagged {
+		e.Repositories++
+		if !hc.fresh(hc.tagsPath(repo), nil) {
+			e.Requests++
+		}
+		if !hc.fresh(hc.releasesPath(repo), nil) {
+			if c.releases {
+				e.Requests++
+			} else {
+				extra++
+			}
+		}
+		// A comparison, and the dates of both versions' commits when
+		// there are no releases to date them
+		extra += 3 * len(versions)
+	}
+
+	for repo, shas := range pinned {
+		e.SHAPins += len(shas)
+		// Pins compared by tag only reach the default branch if no version
+		// tag points at them
+		branch := &e.Requests
+		if c.shaCompare == SHACompareTag {
+			branch = &extra
+		}
+		var info GitHubRepo
+		if !hc.fresh(repoPath(repo), &info) {
+			*branch += 2 + len(shas)
+			continue
+		}
+		var head GitHubRef
+		if !hc.fresh(hc.branchPath(repo, info.DefaultBranch), &head) {
+			*branch += 1 + len(shas)
+			continue
+		}
+		latest := head.Object.SHA
+		for sha := range shas {
+			// Pins at the head aren't compared
+			if latest != "" && (strings.HasPrefix(latest, sha) || strings.HasPrefix(sha, latest)) {
+				continue
+			}
+			if !hc.fresh(comparePath(repo, sha, info.DefaultBranch), nil) {
+				*branch++
+			}
+		}
+	}
+
+	e.MaxRequests = e.Requests + extra
+	return e
+}
+
+// Preflight estimates the requests checking refs takes and compares them
+// with the rate limit left on the main API host, which costs no request of
+// its own. It returns ErrOverBudget if even the fewest would run out before
+// the limit resets, rather than failing halfway through the check. Checks
+// the cache can answer entirely don't ask the API, and return a zero
+// RateLimit; so do offline ones.
+func (c *Checker) Preflight(ctx context.Context, refs []ActionReference) (Estimate, RateLimit, error) {
+	e := c.Estimate(refs)
+	if c.offline || e.MaxRequests == 0 {
+		return e, RateLimit{}, nil
+	}
+	info, err := c.TokenInfo(ctx)
+	if err != nil {
+		return e, RateLimit{}, err
+	}
+	rate := info.Rate
+	if e.Requests > rate.Remaining && c.clock().Before(rate.Reset) {
+		return e, rate, &ErrOverBudget{Needed: e.Requests, Remaining: rate.Remaining, Reset: rate.Reset}
+	}
+	return e, rate, nil
+}
@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPreflight(t *testing.T) {
+	head := strings.Repeat("a", 40)
+	old := strings.Repeat("b", 40)
+	var remaining atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining.Load(), 10))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/tags"):
+			_, _ = w.Write([]byte(`[{"name": "v4"}, {"name": "v3"}]`))
+		case strings.HasSuffix(r.URL.Path, "/git/ref/heads/main"):
+			fmt.Fprintf(w, `{"object": {"sha": %q}}`, head)
+		case strings.Contains(r.URL.Path, "/compare/"):
+			_, _ = w.Write([]byte(`{"ahead_by": 2}`))
+		case r.URL.Path == "/repos/actions/setup-go":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/checkout", Version: "v3", File: "release.yml"},
+		{Name: "actions/checkout", Version: "v4", File: "release.yml"},
+		{Name: "actions/cache/restore", Version: "v4", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: head, File: "ci.yml"},
+		{Name: "actions/setup-go", Version: old, File: "ci.yml"},
+	}
+	cacheDir := t.TempDir()
+	checker := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir))
+
+	// Tags for two repositories, and the default branch, its head and two
+	// comparisons for the SHA pins; outdated actions could add releases for
+	// both repositories and three requests for each of three versions
+	want := Estimate{Repositories: 2, SHAPins: 2, Requests: 6, MaxRequests: 17}
+	if got := checker.Estimate(refs); got != want {
+		t.Errorf("Estimate() = %+v, want %+v", got, want)
+	}
+
+	// Comparing SHA pins by tag lists setup-go's tags and checks both pins
+	// like tag pins, and only reaches its default branch for untagged ones
+	byTag := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithSHACompare(SHACompareTag))
+	if got, want := byTag.Estimate(refs), (Estimate{Repositories: 3, SHAPins: 2, Requests: 3, MaxRequests: 25}); got != want {
+		t.Errorf("Estimate() by tag = %+v, want %+v", got, want)
+	}
+	both := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithSHACompare(SHACompareBoth))
+	if got, want := both.Estimate(refs), (Estimate{Repositories: 3, SHAPins: 2, Requests: 7, MaxRequests: 25}); got != want {
+		t.Errorf("Estimate() by both = %+v, want %+v", got, want)
+	}
+
+	remaining.Store(5)
+	_, rate, err := checker.Preflight(context.Background(), refs)
+	var over *ErrOverBudget
+	if !errors.As(err, &over) || over.Needed != 6 || over.Remaining != 5 || rate.Limit != 60 {
+		t.Errorf("expected ErrOverBudget for 6 requests with 5 left, got %v (rate %+v)", err, rate)
+	}
+
+	remaining.Store(50)
+	if _, _, err := checker.Preflight(context.Background(), refs); err != nil {
+		t.Errorf("unexpected error with enough requests left: %v", err)
+	}
+	if _, err := checker.Check(context.Background(), refs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Everything the check needs is cached now, and the pin at the head
+	// isn't compared
+	remaining.Store(0)
+	checker = NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir))
+	if got := checker.Estimate(refs); got.Requests != 0 || got.SHAPins != 2 {
+		t.Errorf("expected no requests with a warm cache, got %+v", got)
+	}
+	if _, _, err := checker.Preflight(context.Background(), refs); err != nil {
+		t.Errorf("unexpected error with a warm cache: %v", err)
+	}
+
+	checker = NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithIgnoreSHA(true), WithReleases(true))
+	if got := checker.Estimate(refs); got.SHAPins != 0 || got.Requests != 1 {
+		t.Errorf("expected only the uncached releases of actions/cache, got %+v", got)
+	}
+}
@@ -0,0 +1,937 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"aver/pkg/auth"
+	"aver/pkg/cache"
+)
+
+// DefaultConcurrency is the number of actions checked in parallel
+const DefaultConcurrency = 4
+
+// Checker checks action references against the latest available versions.
+// Create one with NewChecker.
+type Checker struct {
+	client         GitHubClient
+	token          string
+	tokens         auth.TokenSource
+	baseURL        string
+	httpClient     *http.Client
+	routes         []Route
+	cacheDir       string
+	cacheTTL       time.Duration
+	concurrency    int
+	ignoreSHA      bool
+	shaCompare     string
+	ignoreMinor    bool
+	offline        bool
+	refresh        bool
+	releases       bool
+	prereleases    bool
+	prereleasesFor []string
+	tagRules       map[string]TagRule
+	ignoreRules    []IgnoreRule
+	minReleaseAge  time.Duration
+	notes          bool
+	knownTags      map[string]string
+	now            func() time.Time
+	onProgress     func(Event)
+	logger         *slog.Logger
+	usage          *Usage // API requests and cache hits of the default clients
+}
+
+// Option configures a Checker
+type Option func(*Checker)
+
+// WithClient sets the GitHubClient used for API calls. It takes precedence
+// over WithToken, WithBaseURL, WithHTTPClient, WithRoutes and WithCacheDir,
+// which only configure the default HTTP client.
+func WithClient(client GitHubClient) Option {
+	return func(c *Checker) { c.client = client }
+}
+
+// WithToken sets the GitHub token. Defaults to $GITHUB_TOKEN, then $GH_TOKEN.
+func WithToken(token string) Option {
+	return func(c *Checker) { c.token = token }
+}
+
+// WithTokenSource sets a source of tokens that is consulted before every
+// request, such as an auth.AppTokenSource. It takes precedence over
+// WithToken.
+func WithTokenSource(tokens auth.TokenSource) Option {
+	return func(c *Checker) { c.tokens = tokens }
+}
+
+// WithBaseURL sets the root of the GitHub API. Defaults to DefaultBaseURL.
+func WithBaseURL(url string) Option {
+	return func(c *Checker) { c.baseURL = url }
+}
+
+// WithHTTPClient sets the http.Client used for API requests, e.g. one with
+// a transport from NewTransport. Defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Checker) { c.httpClient = client }
+}
+
+// WithRoutes sends API calls for matching actions to other hosts. Actions
+// that match no route use the host set by WithBaseURL.
+func WithRoutes(routes ...Route) Option {
+	return func(c *Checker) { c.routes = append(c.routes, routes...) }
+}
+
+// WithCacheDir enables the on-disk response cache in dir
+func WithCacheDir(dir string) Option {
+	return func(c *Checker) { c.cacheDir = dir }
+}
+
+// WithCacheTTL sets how long cached responses stay fresh. Defaults to
+// cache.DefaultTTL.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Checker) { c.cacheTTL = ttl }
+}
+
+// WithConcurrency sets how many actions are checked in parallel. Values
+// below 1 are treated as 1.
+func WithConcurrency(n int) Option {
+	return func(c *Checker) { c.concurrency = max(n, 1) }
+}
+
+// WithIgnoreSHA skips SHA-pinned actions
+func WithIgnoreSHA(ignore bool) Option {
+	return func(c *Checker) { c.ignoreSHA = ignore }
+}
+
+// WithSHACompare sets what SHA pins are compared with: SHACompareBranch
+// (the default), SHACompareTag or SHACompareBoth
+func WithSHACompare(mode string) Option {
+	return func(c *Checker) { c.shaCompare = mode }
+}
+
+// WithIgnoreMinor only reports major version differences
+func WithIgnoreMinor(ignore bool) Option {
+	return func(c *Checker) { c.ignoreMinor = ignore }
+}
+
+// WithReleases resolves the latest version from a repository's published
+// releases, ignoring drafts, prereleases and tags without a release.
+// Repositories that don't publish releases fall back to their tags.
+func WithReleases(releases bool) Option {
+	return func(c *Checker) { c.releases = releases }
+}
+
+// WithPrereleases considers prerelease versions such as v5.0.0-rc.1 when
+// looking for the latest version of any action. By default they're skipped
+// unless the current version is itself a prerelease.
+func WithPrereleases(include bool) Option {
+	return func(c *Checker) { c.prereleases = include }
+}
+
+// WithPrereleasesFor considers prerelease versions only for actions under
+// the given owners ("actions") or repositories ("actions/checkout")
+func WithPrereleasesFor(prefixes ...string) Option {
+	return func(c *Checker) { c.prereleasesFor = append(c.prereleasesFor, prefixes...) }
+}
+
+// WithMinReleaseAge skips versions published less than age ago, so that a
+// release has time to be vetted (or pulled) before it's recommended. A
+// version's age comes from its release, or from its commit if it has none.
+func WithMinReleaseAge(age time.Duration) Option {
+	return func(c *Checker) { c.minReleaseAge = age }
+}
+
+// WithReleaseNotes attaches the releases between the current and latest
+// versions to each outdated action
+func WithReleaseNotes(notes bool) Option {
+	return func(c *Checker) { c.notes = notes }
+}
+
+// WithKnownTags reports pinned tags that no longer point at the commit they
+// did on an earlier run. known maps "owner/repo@tag" to a commit SHA, as in
+// CheckResult.Resolved.
+func WithKnownTags(known map[string]string) Option {
+	return func(c *Checker) { c.knownTags = known }
+}
+
+// WithTagRules narrows the tags considered for actions, keyed by owner
+// ("actions"), repository ("actions/checkout") or the full name of an action
+// in a subdirectory ("owner/monorepo/component"). The most specific key wins.
+func WithTagRules(rules map[string]TagRule) Option {
+	return func(c *Checker) { c.tagRules = rules }
+}
+
+// WithIgnoreRules passes over the versions that rules ignore when looking
+// for the latest, and skips the actions they ignore entirely. The rules add
+// to those of earlier WithIgnoreRules options.
+func WithIgnoreRules(rules []IgnoreRule) Option {
+	return func(c *Checker) { c.ignoreRules = append(c.ignoreRules, rules...) }
+}
+
+// WithOffline answers every API call from the cache, however old the
+// entries are, and never touches the network. References whose data isn't
+// cached are reported in CheckResult.Unchecked. Has no effect with
+// WithClient.
+func WithOffline(offline bool) Option {
+	return func(c *Checker) { c.offline = offline }
+}
+
+// WithRefresh ignores cached responses, fetching everything fresh, while
+// still storing the new responses in the cache
+func WithRefresh(refresh bool) Option {
+	return func(c *Checker) { c.refresh = refresh }
+}
+
+// WithProgress sets a callback that receives progress events as actions are
+// checked. It may be called from multiple goroutines at once.
+func WithProgress(fn func(Event)) Option {
+	return func(c *Checker) { c.onProgress = fn }
+}
+
+// WithUsage counts API requests and cache hits in u, so several Checkers
+// can share one count. Defaults to a count of the Checker's own.
+func WithUsage(u *Usage) Option {
+	return func(c *Checker) { c.usage = u }
+}
+
+// WithLogger sets the logger used for diagnostics. Why actions were skipped,
+// failed checks, moved tags and cache hits and misses are logged at Info;
+// API requests, response statuses, rate limit headers and how versions were
+// chosen at Debug. Defaults to discarding all output.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Checker) { c.logger = logger }
+}
+
+// NewChecker returns a Checker configured by opts
+func NewChecker(opts ...Option) *Checker {
+	token, _ := auth.FromEnv()
+	c := &Checker{
+		token:       token,
+		concurrency: DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.logger == nil {
+		c.logger = slog.New(slog.DiscardHandler)
+	}
+	if c.usage == nil {
+		c.usage = &Usage{}
+	}
+
+	if c.client == nil {
+		var responses *cache.Cache
+		if c.cacheDir != "" {
+			responses = cache.New(c.cacheDir, c.cacheTTL)
+		}
+		hc := c.newHTTPClient(c.baseURL, c.token, responses)
+		hc.Tokens = c.tokens
+		hc.Capped = true
+
+		// Tokens are only sent to the hosts they're for, so actions on
+		// other forges are looked up anonymously
+		routed := &routedClient{
+			routes:   c.routes,
+			clients:  make(map[string]GitHubClient, len(c.routes)),
+			fallback: hc,
+			forges: &hostClients{create: func(host string) GitHubClient {
+				hc := c.newHTTPClient(host+"/api/v1", "", responses)
+				hc.Gitea = true
+				return &forgeClient{HTTPClient: hc, prefix: host + "/"}
+			}},
+			gitlab: &hostClients{create: func(host string) GitHubClient {
+				return &gitlabClient{HTTPClient: c.newHTTPClient("https://"+host+"/api/v4", "", responses), host: host}
+			}},
+			orbs:      &orbClient{HTTPClient: c.newHTTPClient(CircleCIURL, "", responses)},
+			pipes:     &bitbucketClient{HTTPClient: c.newHTTPClient(BitbucketURL, "", responses)},
+			dockerHub: &dockerHubClient{HTTPClient: c.newHTTPClient(DockerHubURL, "", responses)},
+			tasks:     &azureTaskClient{HTTPClient: hc},
+		}
+		if hc.BaseURL != DefaultBaseURL {
+			// Built-in tasks are on github.com, whatever the main host is
+			routed.tasks = &azureTaskClient{HTTPClient: c.newHTTPClient(DefaultBaseURL, "", responses)}
+		}
+		for _, route := range c.routes {
+			routed.clients[route.Prefix] = c.newHTTPClient(route.BaseURL, route.Token, responses)
+		}
+		c.client = routed
+	}
+	return c
+}
+
+func (c *Checker) newHTTPClient(baseURL, token string, responses *cache.Cache) *HTTPClient {
+	hc := NewHTTPClient(token)
+	hc.Client = c.httpClient
+	hc.Logger = c.logger
+	hc.Cache = responses
+	hc.Offline = c.offline
+	hc.Refresh = c.refresh
+	hc.Usage = c.usage
+	if baseURL != "" {
+		hc.BaseURL = baseURL
+	}
+	return hc
+}
+
+// Requests returns how many API requests the Checker has sent, not
+// counting cached responses. Clients set with WithClient aren't counted,
+// and a Usage shared with WithUsage counts every Checker's.
+func (c *Checker) Requests() int64 {
+	return c.usage.Requests()
+}
+
+// CheckResult contains the results of checking action versions
+type CheckResult struct {
+	Outdated   []OutdatedAction
+	SHAPinned  []SHAPinnedAction
+	Unchecked  []UncheckedAction   // Not in the offline cache, or over the request budget
+	Moved      []MovedTag          // Only with WithKnownTags
+	Dynamic    []DynamicRef        // Expressions, which aren't looked up
+	Inputs     []InputFinding      // Only from CheckInputs
+	Commands   []DeprecatedCommand // Only from FindDeprecatedCommands
+	Typosquats []Typosquat         // Only from FindTyposquats
+	Forks      []SuspiciousFork    // Only from SuspiciousForks
+	Drift      []VersionDrift      // Only from FindDrift
+	Warnings   []string
+	// Owners maps each action's repository to the type of account that
+	// owns it, only from Owners
+	Owners map[string]string
+	// Resolved maps "owner/repo@tag" to the commit each pinned tag points at
+	Resolved map[string]string
+	// Stats summarizes the check
+	Stats Stats
+}
+
+// Commit returns the commit ref was pinned to during the check: its
+// version if that's a SHA, else the commit its tag pointed at, or "" if
+// that isn't known
+func (r CheckResult) Commit(ref ActionReference) string {
+	if ref.SHAPinned() {
+		return ref.Version
+	}
+	return r.Resolved[repoFromAction(ref.Name)+"@"+ref.Version]
+}
+
+// UpToDate reports whether no outdated or behind actions, problems with
+// their inputs or deprecated workflow commands were found
+func (r CheckResult) UpToDate() bool {
+	return len(r.Outdated) == 0 && len(r.SHAPinned) == 0 && len(r.Inputs) == 0 && len(r.Commands) == 0
+}
+
+// memo remembers the result of one call per key for the duration of a run.
+// Concurrent calls for the same key share a single request. Failures aren't
+// remembered, so a later call retries.
+type memo[V any] struct {
+	mu       sync.Mutex
+	values   map[string]V
+	inflight map[string]*call[V]
+}
+
+// call is a request in progress on behalf of every caller of its key
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func (m *memo[V]) do(key string, fn func() (V, error)) (V, error) {
+	m.mu.Lock()
+	if v, ok := m.values[key]; ok {
+		m.mu.Unlock()
+		return v, nil
+	}
+	if c, ok := m.inflight[key]; ok {
+		m.mu.Unlock()
+		<-c.done
+		return c.value, c.err
+	}
+	if m.values == nil {
+		m.values = make(map[string]V)
+	}
+	if m.inflight == nil {
+		m.inflight = make(map[string]*call[V])
+	}
+	c := &call[V]{done: make(chan struct{})}
+	m.inflight[key] = c
+	m.mu.Unlock()
+
+	c.value, c.err = fn()
+
+	m.mu.Lock()
+	delete(m.inflight, key)
+	if c.err == nil {
+		m.values[key] = c.value
+	}
+	m.mu.Unlock()
+	close(c.done)
+	return c.value, c.err
+}
+
+// tagCache stores fetched tags per repo
+type tagCache struct {
+	client GitHubClient
+	memo[[]GitHubTag]
+}
+
+func newTagCache(client GitHubClient) *tagCache {
+	tc := &tagCache{client: client}
+	tc.values = make(map[string][]GitHubTag)
+	return tc
+}
+
+func (tc *tagCache) getTags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	return tc.do(repo, func() ([]GitHubTag, error) {
+		return tc.client.Tags(ctx, repo)
+	})
+}
+
+// branchCache remembers default branches and branch heads for a run, so
+// several SHA pins in one repo cost a single lookup of each
+type branchCache struct {
+	GitHubClient
+	branches memo[string] // by repo
+	heads    memo[string] // by repo@branch
+}
+
+func (bc *branchCache) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return bc.branches.do(repo, func() (string, error) {
+		return bc.GitHubClient.DefaultBranch(ctx, repo)
+	})
+}
+
+func (bc *branchCache) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return bc.heads.do(repo+"@"+branch, func() (string, error) {
+		return bc.GitHubClient.BranchHead(ctx, repo, branch)
+	})
+}
+
+// run holds the state shared by the workers of a single Check call
+type run struct {
+	tags     *tagCache
+	releases memo[[]GitHubRelease] // by repo
+	branches *branchCache
+	shas     memo[*shaStatus] // by repo@sha
+	dates    memo[time.Time]  // by repo@tag
+	compares memo[int]        // by repo@base...head
+
+	mu           sync.Mutex
+	skippedRepos map[string]bool
+}
+
+func (r *run) skipRepo(repo string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skippedRepos[repo] = true
+}
+
+func (r *run) isSkipped(repo string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skippedRepos[repo]
+}
+
+// Finding is the outcome of checking a single reference. At most one of
+// Outdated, SHAPinned, Unchecked and Warning is set; if none are, the
+// reference is up to date.
+type Finding struct {
+	Index     int              // Position of Ref in the slice passed to the Checker
+	Ref       ActionReference  // The reference that was checked
+	Outdated  *OutdatedAction  // A newer version is available
+	SHAPinned *SHAPinnedAction // The pinned SHA is behind the default branch
+	Unchecked *UncheckedAction // Offline and the data needed isn't cached
+	Dynamic   *DynamicRef      // The reference is an expression
+	Warning   string           // The reference could not be checked
+
+	// Set alongside the above for tag pins
+	ResolvedSHA string    // The commit the pinned tag points at
+	Moved       *MovedTag // The tag pointed elsewhere on an earlier run
+
+	pin          string // pinSHA, pinTag or pinBranch, if known
+	reason       string // why the reference was skipped, for Skipped events
+	inaccessible bool   // the action's repository could not be accessed
+	err          error
+}
+
+// skipped returns a Finding for a reference that could not be checked
+func skipped(action ActionReference, reason string) Finding {
+	return Finding{
+		Warning: fmt.Sprintf("skipping %s: %s", action.Name, reason),
+		reason:  reason,
+	}
+}
+
+// Stream checks every reference and yields each Finding as soon as it is
+// resolved, so findings arrive in completion order rather than input order.
+// Iteration stops after the first error, which is yielded with an empty
+// Finding. Breaking out of the loop cancels any outstanding checks.
+func (c *Checker) Stream(ctx context.Context, refs []ActionReference) iter.Seq2[Finding, error] {
+	return func(yield func(Finding, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(Finding{}, err)
+			return
+		}
+
+		parent := ctx
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		r := &run{
+			tags:         newTagCache(c.client),
+			branches:     &branchCache{GitHubClient: c.client},
+			skippedRepos: make(map[string]bool),
+		}
+
+		jobs := make(chan int)
+		go func() {
+			defer close(jobs)
+			for i := range refs {
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		findings := make(chan Finding)
+		var wg sync.WaitGroup
+		for range c.concurrency {
+			wg.Go(func() {
+				for i := range jobs {
+					f := c.checkRef(ctx, r, refs[i])
+					f.Index = i
+					f.Ref = refs[i]
+					select {
+					case findings <- f:
+					case <-ctx.Done():
+						return
+					}
+				}
+			})
+		}
+		go func() {
+			wg.Wait()
+			close(findings)
+		}()
+
+		yielded := 0
+		for f := range findings {
+			if f.err != nil {
+				yield(Finding{}, f.err)
+				return
+			}
+			if !yield(f, nil) {
+				return
+			}
+			yielded++
+		}
+
+		// Workers stop early only if the caller's context was cancelled
+		if yielded < len(refs) {
+			if err := parent.Err(); err != nil {
+				yield(Finding{}, err)
+			}
+		}
+	}
+}
+
+// Check resolves the latest version of every reference. Results are
+// reported in the order of refs regardless of concurrency.
+func (c *Checker) Check(ctx context.Context, refs []ActionReference) (CheckResult, error) {
+	start, requests := time.Now(), c.Requests()
+	findings := make([]Finding, 0, len(refs))
+	for f, err := range c.Stream(ctx, refs) {
+		if err != nil {
+			return CheckResult{}, err
+		}
+		findings = append(findings, f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Index < findings[j].Index
+	})
+
+	result := CheckResult{}
+	inaccessible := make(map[string]bool)
+	for _, f := range findings {
+		// Only the first reference to an inaccessible repo is reported
+		repo := repoFromAction(f.Ref.Name)
+		if inaccessible[repo] {
+			continue
+		}
+		if f.inaccessible {
+			inaccessible[repo] = true
+		}
+
+		if f.Warning != "" {
+			result.Warnings = append(result.Warnings, f.Warning)
+		}
+		if f.Outdated != nil {
+			result.Outdated = append(result.Outdated, *f.Outdated)
+		}
+		if f.SHAPinned != nil {
+			result.SHAPinned = append(result.SHAPinned, *f.SHAPinned)
+		}
+		if f.Unchecked != nil {
+			result.Unchecked = append(result.Unchecked, *f.Unchecked)
+		}
+		if f.Moved != nil {
+			result.Moved = append(result.Moved, *f.Moved)
+		}
+		if f.Dynamic != nil {
+			result.Dynamic = append(result.Dynamic, *f.Dynamic)
+		}
+		if f.ResolvedSHA != "" {
+			if result.Resolved == nil {
+				result.Resolved = make(map[string]string)
+			}
+			result.Resolved[repo+"@"+f.Ref.Version] = f.ResolvedSHA
+		}
+	}
+
+	result.Stats = newStats(refs, findings, result.Outdated)
+	result.Stats.APIRequests = c.Requests() - requests
+	result.Stats.SetDuration(time.Since(start))
+	return result, nil
+}
+
+// emit delivers a progress event to the registered callback, if any
+func (c *Checker) emit(e Event) {
+	if c.onProgress != nil {
+		c.onProgress(e)
+	}
+}
+
+// checkRef checks a single reference, emitting progress events as it goes
+func (c *Checker) checkRef(ctx context.Context, r *run, action ActionReference) Finding {
+	// There's nothing to look up until the workflow runs
+	if action.Dynamic() {
+		c.logger.Info("skipped", "action", action.Name, "version", action.Version, "reason", "dynamic ref")
+		c.emit(Skipped{Ref: action, Reason: "dynamic ref"})
+		return Finding{
+			Dynamic: &DynamicRef{
+				Repository: action.Repository,
+				File:       action.File,
+				Name:       action.Name,
+				Ref:        action.Version,
+				Line:       action.Line,
+			},
+			pin:    pinDynamic,
+			reason: "dynamic ref",
+		}
+	}
+
+	// Only Docker Hub's tags are looked up
+	if registry := dockerRegistry(action.Name); IsDockerImage(action.Name) && registry != "" {
+		reason := "image on " + registry + ", not Docker Hub"
+		c.logger.Info("skipped", "action", action.Name, "version", action.Version, "reason", reason)
+		c.emit(Skipped{Ref: action, Reason: reason})
+		return skipped(action, reason)
+	}
+
+	repo := repoFromAction(action.Name)
+
+	// Skip if we already know this repo is inaccessible
+	if r.isSkipped(repo) {
+		c.logger.Info("skipped", "action", action.Name, "version", action.Version, "reason", "repository not accessible")
+		c.emit(Skipped{Ref: action, Reason: "repository not accessible"})
+		f := skipped(action, "repository not accessible")
+		f.inaccessible = true
+		return f
+	}
+
+	if c.ignoreSHA && isSHA(action.Version) {
+		c.logger.Info("skipped", "action", action.Name, "version", action.Version, "reason", "SHA-pinned actions are ignored")
+		c.emit(Skipped{Ref: action, Reason: "SHA-pinned actions are ignored"})
+		return Finding{pin: pinSHA}
+	}
+	if ignoredEntirely(c.ignoreRules, action.Name) {
+		c.logger.Info("skipped", "action", action.Name, "version", action.Version, "reason", "ignored by an ignore rule")
+		c.emit(Skipped{Ref: action, Reason: "ignored by an ignore rule"})
+		return Finding{}
+	}
+
+	c.emit(Started{Ref: action, Repo: repo})
+	f := c.resolveRef(ctx, r, action, repo)
+	if f.err == nil && f.Warning == "" {
+		f.pin = pinKind(ctx, r, action, repo)
+	}
+	if f.err == nil && f.Warning == "" && f.Unchecked == nil && !isSHA(action.Version) {
+		c.trackTag(ctx, r, action, repo, &f)
+	}
+
+	switch {
+	case f.err != nil:
+		c.logger.Info("check failed", "action", action.Name, "version", action.Version, "error", f.err)
+	case f.Warning != "" || f.Unchecked != nil:
+		c.logger.Info("skipped", "action", action.Name, "version", action.Version, "reason", f.reason)
+		c.emit(Skipped{Ref: action, Reason: f.reason})
+	case f.Outdated != nil:
+		c.emit(Resolved{Ref: action, Outcome: OutcomeOutdated})
+	case f.SHAPinned != nil:
+		c.emit(Resolved{Ref: action, Outcome: OutcomeBehind})
+	default:
+		c.emit(Resolved{Ref: action, Outcome: OutcomeUpToDate})
+	}
+	return f
+}
+
+// trackTag records the commit a tag pin points at, and whether it has moved
+// since an earlier run. The tags were already fetched, so this is free.
+func (c *Checker) trackTag(ctx context.Context, r *run, action ActionReference, repo string, f *Finding) {
+	tags, err := r.tags.getTags(ctx, repo)
+	if err != nil {
+		return
+	}
+	for _, tag := range tags {
+		if tag.Name != action.Version || tag.Commit.SHA == "" {
+			continue
+		}
+		f.ResolvedSHA = tag.Commit.SHA
+		if old := c.knownTags[repo+"@"+tag.Name]; old != "" && old != tag.Commit.SHA {
+			c.logger.Info("tag moved", "action", action.Name, "tag", tag.Name, "old", old, "new", tag.Commit.SHA)
+			f.Moved = &MovedTag{
+				Repository: action.Repository,
+				File:       action.File,
+				Name:       action.Name,
+				Tag:        tag.Name,
+				OldSHA:     old,
+				NewSHA:     tag.Commit.SHA,
+			}
+		}
+		return
+	}
+}
+
+// failed converts an API error into a Finding. Inaccessible repositories
+// become warnings and are skipped for the rest of the run; other errors are
+// fatal unless warnOnly is set.
+func (c *Checker) failed(r *run, action ActionReference, repo string, err error, warnOnly bool) Finding {
+	var rateLimited *ErrRateLimited
+	if errors.As(err, &rateLimited) {
+		c.emit(RateLimited{Reset: rateLimited.Reset})
+	}
+
+	// Actions the run can't afford to look up are reported, not failed
+	reason := ""
+	switch {
+	case errors.Is(err, &ErrNotCached{}):
+		reason = "not in cache"
+	case errors.Is(err, &ErrBudgetExhausted{}):
+		reason = "API request budget used up"
+	}
+	if reason != "" {
+		return Finding{
+			Unchecked: &UncheckedAction{
+				Repository: action.Repository,
+				File:       action.File,
+				Name:       action.Name,
+				Version:    action.Version,
+				Reason:     reason,
+			},
+			reason: reason,
+		}
+	}
+
+	var notAccessible *ErrRepoNotAccessible
+	if errors.As(err, &notAccessible) {
+		r.skipRepo(repo)
+		reason := "repository not accessible"
+		if notAccessible.Message != "" {
+			reason += ": " + notAccessible.Message
+		}
+		if hint := notAccessible.Hint(); hint != "" {
+			reason += "; " + hint
+		}
+		f := skipped(action, reason)
+		f.inaccessible = true
+		return f
+	}
+	if warnOnly {
+		return skipped(action, err.Error())
+	}
+	return Finding{err: fmt.Errorf("failed to check %s: %w", action.Name, err)}
+}
+
+// publishedAt returns when tag was published: the date of its release if
+// there is one, or else of its commit
+func (c *Checker) publishedAt(ctx context.Context, r *run, repo, tag string) (time.Time, error) {
+	return r.dates.do(repo+"@"+tag, func() (time.Time, error) {
+		releases, err := r.releases.do(repo, func() ([]GitHubRelease, error) {
+			return c.client.Releases(ctx, repo)
+		})
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, release := range releases {
+			if release.TagName == tag && !release.PublishedAt.IsZero() {
+				return release.PublishedAt, nil
+			}
+		}
+		date, err := c.client.CommitDate(ctx, repo, tag)
+		if errors.Is(err, &ErrRefNotFound{}) {
+			return time.Time{}, nil // Unknown, but not worth asking again
+		}
+		return date, err
+	})
+}
+
+func (c *Checker) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// includePrereleases reports whether prerelease versions are candidates for
+// the latest version of the named action, pinned to current
+func (c *Checker) includePrereleases(name, current string) bool {
+	if c.prereleases || matchesAny(c.prereleasesFor, name) {
+		return true
+	}
+	// Someone pinned to a prerelease is following them already
+	sv := parseSemver(current)
+	return sv != nil && sv.Prerelease != ""
+}
+
+// resolveRef does the work of checking a single reference
+func (c *Checker) resolveRef(ctx context.Context, r *run, action ActionReference, repo string) Finding {
+	if isSHA(action.Version) {
+		return c.resolveSHA(ctx, r, action, repo)
+	}
+	return c.resolveTag(ctx, r, action, repo)
+}
+
+// resolveTag checks a reference to a tag or branch against the latest
+// version
+func (c *Checker) resolveTag(ctx context.Context, r *run, action ActionReference, repo string) Finding {
+	tags, err := r.tags.getTags(ctx, repo)
+	if err != nil {
+		return c.failed(r, action, repo, err, false)
+	}
+
+	// A semver-looking ref that isn't a tag can't be compared meaningfully,
+	// except where versions like 1.2 resolve to the latest matching release
+	// (see resolvesPartialVersions)
+	sv := parseSemver(action.Version)
+	partial := sv != nil && !sv.HasPatch && resolvesPartialVersions(action.Name)
+	if sv != nil && !partial && !hasTag(tags, action.Version) {
+		return skipped(action, (&ErrTagNotFound{Repo: repo, Tag: action.Version}).Error())
+	}
+
+	// Tag rules apply to the current version too, so that a pin like
+	// component-a/v1.2.0 is compared as v1.2.0
+	current := action.Version
+	rule, hasRule := matchTagRule(c.tagRules, action.Name)
+	if hasRule {
+		current = strings.TrimPrefix(current, rule.StripPrefix)
+	}
+
+	prereleases := c.includePrereleases(action.Name, current)
+	candidates := tags
+	if c.releases {
+		releases, err := r.releases.do(repo, func() ([]GitHubRelease, error) {
+			return c.client.Releases(ctx, repo)
+		})
+		if err != nil {
+			return c.failed(r, action, repo, err, false)
+		}
+		if published := publishedTags(releases, prereleases); len(published) > 0 {
+			candidates = published
+		}
+	}
+	if hasRule {
+		candidates = rule.apply(candidates)
+	}
+	candidates = withoutIgnored(c.ignoreRules, action.Name, current, candidates)
+	if !prereleases {
+		candidates = withoutPrereleases(candidates)
+	}
+
+	scheme := detectScheme(current)
+	if scheme == nil {
+		c.logger.Debug("unrecognized version scheme", "action", action.Name, "version", action.Version)
+		return Finding{}
+	}
+	latestVersion := scheme.latest(candidates, current, c.ignoreMinor)
+	// Versions that are too new are passed over for the next newest
+	for latestVersion != "" && c.minReleaseAge > 0 {
+		published, err := c.publishedAt(ctx, r, repo, rule.StripPrefix+latestVersion)
+		if err != nil {
+			return c.failed(r, action, repo, err, false)
+		}
+		if age := c.clock().Sub(published); age >= c.minReleaseAge {
+			break
+		}
+		c.logger.Debug("version too new", "action", action.Name, "version", latestVersion, "published", published)
+		candidates = slices.DeleteFunc(slices.Clone(candidates), func(t GitHubTag) bool { return t.Name == latestVersion })
+		latestVersion = scheme.latest(candidates, current, c.ignoreMinor)
+	}
+	if latestVersion == "" {
+		c.logger.Debug("no newer version", "action", action.Name, "version", action.Version, "scheme", scheme.name(), "candidates", len(candidates))
+		return Finding{} // No comparable version found
+	}
+
+	if versionsEqual(current, latestVersion) {
+		return Finding{}
+	}
+	outdated := &OutdatedAction{
+		Name:           action.Name,
+		CurrentVersion: action.Version,
+		LatestVersion:  rule.StripPrefix + latestVersion,
+		LatestSHA:      tagCommit(tags, rule.StripPrefix+latestVersion),
+		Repository:     action.Repository,
+		File:           action.File,
+		Line:           action.Line,
+	}
+	c.addPublishDates(ctx, r, repo, outdated)
+	c.addCommitsBehind(ctx, r, repo, outdated)
+	releases, err := r.releases.do(repo, func() ([]GitHubRelease, error) {
+		return c.client.Releases(ctx, repo)
+	})
+	if err != nil {
+		c.logger.Debug("no releases", "action", action.Name, "error", err)
+	}
+	between := releasesBetween(releases, scheme, rule, current, latestVersion, prereleases)
+	outdated.ReleasesBehind = len(between)
+	if c.notes {
+		outdated.Notes = between
+	}
+	return Finding{Outdated: outdated}
+}
+
+// addCommitsBehind fills in how far the latest version's tag is ahead of
+// the current one's. Like publish dates, it's left unset on failure.
+func (c *Checker) addCommitsBehind(ctx context.Context, r *run, repo string, a *OutdatedAction) {
+	behind, err := r.compares.do(repo+"@"+a.CurrentVersion+"..."+a.LatestVersion, func() (int, error) {
+		return c.client.CompareCommits(ctx, repo, a.CurrentVersion, a.LatestVersion)
+	})
+	if err != nil {
+		c.logger.Debug("no commit count", "action", a.Name, "error", err)
+		return
+	}
+	a.CommitsBehind = behind
+}
+
+// addPublishDates fills in when both versions of an outdated action were
+// published. Dates are a nicety, so failures only leave them unset.
+func (c *Checker) addPublishDates(ctx context.Context, r *run, repo string, a *OutdatedAction) {
+	var err error
+	if a.CurrentPublished, err = c.publishedAt(ctx, r, repo, a.CurrentVersion); err != nil {
+		c.logger.Debug("no publish date", "action", a.Name, "version", a.CurrentVersion, "error", err)
+	}
+	if a.LatestPublished, err = c.publishedAt(ctx, r, repo, a.LatestVersion); err != nil {
+		c.logger.Debug("no publish date", "action", a.Name, "version", a.LatestVersion, "error", err)
+	}
+	if !a.CurrentPublished.IsZero() && a.LatestPublished.After(a.CurrentPublished) {
+		a.DaysBehind = int(a.LatestPublished.Sub(a.CurrentPublished) / (24 * time.Hour))
+	}
+}
@@ -0,0 +1,715 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient is an in-memory GitHubClient
+type fakeClient struct {
+	tags      map[string][]GitHubTag
+	releases  map[string][]GitHubRelease
+	branches  map[string]string    // repo -> default branch
+	heads     map[string]string    // repo -> head SHA of the default branch
+	behind    map[string]int       // base SHA -> commits behind the default branch
+	dates     map[string]time.Time // repo@ref -> commit date
+	workflows map[string][]WorkflowFile
+	files     map[string]string // repo/path@ref -> content
+	owners    map[string]string // owner -> account type
+	forks     map[string]string // repo -> the repo it was forked from, or "" if unknown
+	delay     time.Duration     // how long each Tags call takes
+	calls     atomic.Int64
+}
+
+func (f *fakeClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	f.calls.Add(1)
+	time.Sleep(f.delay)
+	tags, ok := f.tags[repo]
+	if !ok {
+		return nil, &ErrRepoNotAccessible{Repo: repo, Status: 404}
+	}
+	return tags, nil
+}
+
+func (f *fakeClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	f.calls.Add(1)
+	return f.releases[repo], nil
+}
+
+func (f *fakeClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	f.calls.Add(1)
+	branch, ok := f.branches[repo]
+	if !ok {
+		return "", &ErrRepoNotAccessible{Repo: repo, Status: 404}
+	}
+	return branch, nil
+}
+
+func (f *fakeClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	f.calls.Add(1)
+	return f.heads[repo], nil
+}
+
+func (f *fakeClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	f.calls.Add(1)
+	return f.behind[base], nil
+}
+
+func (f *fakeClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	f.calls.Add(1)
+	date, ok := f.dates[repo+"@"+ref]
+	if !ok {
+		return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+	}
+	return date, nil
+}
+
+func (f *fakeClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	f.calls.Add(1)
+	files, ok := f.workflows[repo]
+	if !ok {
+		return nil, &ErrRepoNotAccessible{Repo: repo, Status: 404}
+	}
+	return files, nil
+}
+
+func (f *fakeClient) File(ctx context.Context, repo, path, ref string) ([]byte, error) {
+	f.calls.Add(1)
+	if _, ok := f.tags[repo]; !ok {
+		return nil, &ErrRepoNotAccessible{Repo: repo, Status: 404}
+	}
+	content, ok := f.files[repo+"/"+path+"@"+ref]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(content), nil
+}
+
+func (f *fakeClient) Repository(ctx context.Context, repo string) (GitHubRepo, error) {
+	f.calls.Add(1)
+	if _, ok := f.tags[repo]; !ok {
+		return GitHubRepo{}, &ErrRepoNotAccessible{Repo: repo, Status: 404}
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	info := GitHubRepo{FullName: repo, Owner: GitHubOwner{Login: owner, Type: f.owners[owner]}}
+	if parent, ok := f.forks[repo]; ok {
+		info.Fork = true
+		if parent != "" {
+			info.Parent = &GitHubRepo{FullName: parent}
+		}
+	}
+	return info, nil
+}
+
+func TestChecker(t *testing.T) {
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {{Name: "v4"}, {Name: "v5"}, {Name: "v5.0.1"}},
+			"actions/cache":    {{Name: "v4"}},
+		},
+		branches: map[string]string{"actions/setup-go": "main"},
+		heads:    map[string]string{"actions/setup-go": "ffffffffffffffffffffffffffffffffffffffff"},
+		behind:   map[string]int{"abcdef1234567": 3},
+	}
+
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/cache/restore", Version: "v4", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "abcdef1234567", File: "ci.yml"},
+		{Name: "private/action", Version: "v1", File: "ci.yml"},
+		{Name: "private/action", Version: "v2", File: "release.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client), WithConcurrency(1)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.UpToDate() {
+		t.Error("expected outdated actions")
+	}
+
+	if len(result.Outdated) != 1 || result.Outdated[0].Name != "actions/checkout" || result.Outdated[0].LatestVersion != "v5.0.1" {
+		t.Errorf("unexpected outdated actions: %+v", result.Outdated)
+	}
+	if len(result.SHAPinned) != 1 || result.SHAPinned[0].CommitsBehind != 3 || result.SHAPinned[0].DefaultBranch != "main" {
+		t.Errorf("unexpected SHA-pinned actions: %+v", result.SHAPinned)
+	}
+	// An inaccessible repo is warned about once and then skipped
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", result.Warnings)
+	}
+}
+
+func TestTagCache(t *testing.T) {
+	client := &fakeClient{}
+	cache := newTagCache(client)
+
+	// Manually populate cache
+	cache.values["owner/repo"] = []GitHubTag{
+		{Name: "v1.0.0"},
+		{Name: "v2.0.0"},
+	}
+
+	// Should return cached value
+	tags, err := cache.getTags(context.Background(), "owner/repo")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Errorf("expected 2 tags, got %d", len(tags))
+	}
+	if n := client.calls.Load(); n != 0 {
+		t.Errorf("expected no API calls, got %d", n)
+	}
+}
+
+func TestCheckerConcurrentOrder(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{}}
+	var refs []ActionReference
+	for i := range 20 {
+		repo := fmt.Sprintf("owner/repo%d", i)
+		client.tags[repo] = []GitHubTag{{Name: "v1"}, {Name: "v2"}}
+		refs = append(refs, ActionReference{Name: repo, Version: "v1", File: "ci.yml"})
+	}
+
+	result, err := NewChecker(WithClient(client), WithConcurrency(8)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != len(refs) {
+		t.Fatalf("expected %d outdated actions, got %d", len(refs), len(result.Outdated))
+	}
+	for i, a := range result.Outdated {
+		if a.Name != refs[i].Name {
+			t.Errorf("result %d: expected %s, got %s", i, refs[i].Name, a.Name)
+		}
+	}
+}
+
+func TestCheckerStream(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {{Name: "v4"}, {Name: "v5"}},
+		"actions/cache":    {{Name: "v4"}},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/cache", Version: "v4", File: "ci.yml"},
+		{Name: "missing/action", Version: "v1", File: "ci.yml"},
+	}
+
+	seen := make(map[int]Finding)
+	for f, err := range NewChecker(WithClient(client)).Stream(context.Background(), refs) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[f.Index] = f
+	}
+
+	if len(seen) != len(refs) {
+		t.Fatalf("expected %d findings, got %d", len(refs), len(seen))
+	}
+	if seen[0].Outdated == nil || seen[0].Outdated.LatestVersion != "v5" {
+		t.Errorf("expected actions/checkout to be outdated, got %+v", seen[0])
+	}
+	if seen[1].Outdated != nil || seen[1].SHAPinned != nil || seen[1].Warning != "" {
+		t.Errorf("expected actions/cache to be up to date, got %+v", seen[1])
+	}
+	if seen[2].Warning == "" {
+		t.Errorf("expected a warning for missing/action, got %+v", seen[2])
+	}
+}
+
+func TestCheckerStreamBreak(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{}}
+	var refs []ActionReference
+	for i := range 50 {
+		repo := fmt.Sprintf("owner/repo%d", i)
+		client.tags[repo] = []GitHubTag{{Name: "v1"}}
+		refs = append(refs, ActionReference{Name: repo, Version: "v1"})
+	}
+
+	n := 0
+	for _, err := range NewChecker(WithClient(client)).Stream(context.Background(), refs) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected to stop after 3 findings, got %d", n)
+	}
+}
+
+func TestCheckerCancelled(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{"owner/repo": {{Name: "v1"}}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewChecker(WithClient(client)).Check(ctx, []ActionReference{{Name: "owner/repo", Version: "v1"}})
+	if err == nil {
+		t.Error("expected an error from a cancelled context")
+	}
+}
+
+func TestCheckerEvents(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {{Name: "v4"}, {Name: "v5"}},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4"},
+		{Name: "actions/setup-go", Version: "abcdef1234567"},
+		{Name: "missing/action", Version: "v1"},
+	}
+
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+	checker := NewChecker(WithClient(client), WithConcurrency(1), WithIgnoreSHA(true), WithProgress(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}))
+	if _, err := checker.Check(context.Background(), refs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Event{
+		Started{Ref: refs[0], Repo: "actions/checkout"},
+		Resolved{Ref: refs[0], Outcome: OutcomeOutdated},
+		Skipped{Ref: refs[1], Reason: "SHA-pinned actions are ignored"},
+		Started{Ref: refs[2], Repo: "missing/action"},
+		Skipped{Ref: refs[2], Reason: "repository not accessible"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("event %d: expected %+v, got %+v", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestCheckerTagNotFound(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {{Name: "v4"}, {Name: "v5"}},
+	}}
+	refs := []ActionReference{{Name: "actions/checkout", Version: "v9"}}
+
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "skipping actions/checkout: tag v9 not found in actions/checkout" {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+}
+
+func TestCheckerDynamicRefs(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {{Name: "v4"}},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/setup-node", Version: "${{ inputs.version }}", File: "ci.yml", Line: 9},
+		{Name: "${{ matrix.action }}", Version: "v1", File: "ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls := client.calls.Load(); calls != 1 {
+		t.Errorf("expected only actions/checkout to be looked up, got %d calls", calls)
+	}
+	if len(result.Warnings) != 0 || !result.UpToDate() {
+		t.Errorf("expected no warnings or findings, got %+v", result)
+	}
+	if len(result.Dynamic) != 2 || result.Dynamic[0].Ref != "${{ inputs.version }}" || result.Dynamic[0].Line != 9 ||
+		result.Dynamic[1].Name != "${{ matrix.action }}" {
+		t.Errorf("unexpected dynamic refs: %+v", result.Dynamic)
+	}
+	if result.Stats.Dynamic != 2 || result.Stats.TagPinned != 1 {
+		t.Errorf("unexpected stats: %+v", result.Stats)
+	}
+}
+
+func TestCheckerOffline(t *testing.T) {
+	var offline atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if offline.Load() {
+			t.Errorf("unexpected request in offline mode: %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`[{"name": "v4"}, {"name": "v5"}]`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	refs := []ActionReference{{Name: "actions/checkout", Version: "v4", File: "ci.yml"}}
+	if _, err := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir)).Check(context.Background(), refs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Entries are used in offline mode however stale they are
+	offline.Store(true)
+	checker := NewChecker(
+		WithBaseURL(server.URL),
+		WithCacheDir(cacheDir),
+		WithCacheTTL(time.Nanosecond),
+		WithOffline(true),
+	)
+	result, err := checker.Check(context.Background(), append(refs,
+		ActionReference{Name: "actions/cache", Version: "v3", File: "ci.yml"},
+		ActionReference{Name: "actions/setup-go", Version: "abcdef1234567", File: "ci.yml"},
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Outdated) != 1 || result.Outdated[0].LatestVersion != "v5" {
+		t.Errorf("expected actions/checkout to be outdated from the cache, got %+v", result.Outdated)
+	}
+	if len(result.Unchecked) != 2 || result.Unchecked[0].Name != "actions/cache" || result.Unchecked[1].Name != "actions/setup-go" {
+		t.Errorf("expected uncached actions to be unchecked, got %+v", result.Unchecked)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+}
+
+func TestCheckerCoalescesConcurrentFetches(t *testing.T) {
+	client := &fakeClient{
+		tags:     map[string][]GitHubTag{"actions/checkout": {{Name: "v4"}, {Name: "v5"}}},
+		branches: map[string]string{"actions/setup-go": "main"},
+		heads:    map[string]string{"actions/setup-go": "ffffffffffffffffffffffffffffffffffffffff"},
+		behind:   map[string]int{"abcdef1234567": 3},
+		delay:    20 * time.Millisecond,
+	}
+
+	var refs []ActionReference
+	for i := range 8 {
+		file := fmt.Sprintf("ci%d.yml", i)
+		refs = append(refs,
+			ActionReference{Name: "actions/checkout", Version: "v4", File: file},
+			ActionReference{Name: "actions/setup-go", Version: "abcdef1234567", File: file})
+	}
+
+	result, err := NewChecker(WithClient(client), WithConcurrency(16)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 8 || len(result.SHAPinned) != 8 {
+		t.Errorf("expected every reference reported, got %d outdated and %d behind", len(result.Outdated), len(result.SHAPinned))
+	}
+
+	// Tags, releases, two commit dates and a compare for the tag pin, plus
+	// default branch, head and compare for the SHA pin
+	if n := client.calls.Load(); n != 8 {
+		t.Errorf("expected 8 API calls, got %d", n)
+	}
+}
+
+func TestCheckerMemoizesBranchLookups(t *testing.T) {
+	client := &fakeClient{
+		branches: map[string]string{"actions/setup-go": "main"},
+		heads:    map[string]string{"actions/setup-go": "ffffffffffffffffffffffffffffffffffffffff"},
+		behind:   map[string]int{"abcdef1234567": 3, "1234567abcdef": 5},
+	}
+	refs := []ActionReference{
+		{Name: "actions/setup-go", Version: "abcdef1234567", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "1234567abcdef", File: "release.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client), WithConcurrency(1)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.SHAPinned) != 2 {
+		t.Fatalf("expected 2 SHA-pinned actions, got %+v", result.SHAPinned)
+	}
+
+	// One default branch and one head lookup, plus a compare per pin
+	if n := client.calls.Load(); n != 4 {
+		t.Errorf("expected 4 API calls, got %d", n)
+	}
+}
+
+func TestCheckerReleases(t *testing.T) {
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {{Name: "v4.0.0"}, {Name: "v4.1.0"}, {Name: "v5.0.0"}, {Name: "v6.0.0"}},
+			"actions/cache":    {{Name: "v3.0.0"}, {Name: "v4.0.0"}},
+		},
+		releases: map[string][]GitHubRelease{
+			"actions/checkout": {
+				{TagName: "v6.0.0", Draft: true},
+				{TagName: "v5.0.0", Prerelease: true},
+				{TagName: "v4.1.0"},
+				{TagName: "v4.0.0"},
+			},
+		},
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4.0.0", File: "ci.yml"},
+		{Name: "actions/cache", Version: "v3.0.0", File: "ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client), WithReleases(true)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 2 {
+		t.Fatalf("expected 2 outdated actions, got %+v", result.Outdated)
+	}
+	if got := result.Outdated[0].LatestVersion; got != "v4.1.0" {
+		t.Errorf("expected the latest published release v4.1.0, got %s", got)
+	}
+	// Without releases, the tags are used
+	if got := result.Outdated[1].LatestVersion; got != "v4.0.0" {
+		t.Errorf("expected the latest tag v4.0.0, got %s", got)
+	}
+}
+
+func TestCheckerPrereleases(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {{Name: "v4.0.0"}, {Name: "v4.1.0"}, {Name: "v5.0.0-rc.1"}},
+		"docker/build":     {{Name: "v1.0.0"}, {Name: "v2.0.0-beta.1"}},
+		"owner/nightly":    {{Name: "v1.0.0-rc.1"}, {Name: "v1.0.0-rc.2"}},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4.0.0", File: "ci.yml"},
+		{Name: "docker/build", Version: "v1.0.0", File: "ci.yml"},
+		{Name: "owner/nightly", Version: "v1.0.0-rc.1", File: "ci.yml"},
+	}
+
+	latest := func(opts ...Option) []string {
+		result, err := NewChecker(append(opts, WithClient(client))...).Check(context.Background(), refs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var versions []string
+		for _, a := range result.Outdated {
+			versions = append(versions, a.LatestVersion)
+		}
+		return versions
+	}
+
+	// Pinning a prerelease opts that action in
+	if got := fmt.Sprint(latest()); got != "[v4.1.0 v1.0.0-rc.2]" {
+		t.Errorf("default: got %s", got)
+	}
+	if got := fmt.Sprint(latest(WithPrereleasesFor("docker"))); got != "[v4.1.0 v2.0.0-beta.1 v1.0.0-rc.2]" {
+		t.Errorf("for docker: got %s", got)
+	}
+	if got := fmt.Sprint(latest(WithPrereleases(true))); got != "[v5.0.0-rc.1 v2.0.0-beta.1 v1.0.0-rc.2]" {
+		t.Errorf("all: got %s", got)
+	}
+}
+
+func TestCheckerVersionSchemes(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"owner/nightly": {{Name: "nightly-2025-01-02"}, {Name: "nightly-2025-03-01"}, {Name: "v1"}},
+		"owner/build":   {{Name: "r99"}, {Name: "r100"}},
+		"owner/branch":  {{Name: "v1"}},
+	}}
+	refs := []ActionReference{
+		{Name: "owner/nightly", Version: "nightly-2025-01-02", File: "ci.yml"},
+		{Name: "owner/build", Version: "r99", File: "ci.yml"},
+		{Name: "owner/branch", Version: "main", File: "ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 2 ||
+		result.Outdated[0].LatestVersion != "nightly-2025-03-01" ||
+		result.Outdated[1].LatestVersion != "r100" {
+		t.Errorf("unexpected outdated actions: %+v", result.Outdated)
+	}
+}
+
+func TestCheckerTagRules(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"owner/mono": {
+			{Name: "component-a/v1.0.0"}, {Name: "component-a/v1.2.0"},
+			{Name: "component-b/v3.0.0"}, {Name: "v9.0.0"},
+		},
+		"owner/tools": {{Name: "v1.0.0"}, {Name: "v1.1.0"}, {Name: "v2.0.0-custom"}, {Name: "v20240101"}},
+	}}
+	refs := []ActionReference{
+		{Name: "owner/mono/component-a", Version: "component-a/v1.0.0", File: "ci.yml"},
+		{Name: "owner/tools", Version: "v1.0.0", File: "ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client), WithTagRules(map[string]TagRule{
+		"owner":                  {Pattern: regexp.MustCompile(`^v\d+\.\d+\.\d+$`)},
+		"owner/mono/component-a": {StripPrefix: "component-a/"},
+	})).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 2 ||
+		result.Outdated[0].LatestVersion != "component-a/v1.2.0" ||
+		result.Outdated[1].LatestVersion != "v1.1.0" {
+		t.Errorf("unexpected outdated actions: %+v", result.Outdated)
+	}
+}
+
+func TestCheckerMinReleaseAge(t *testing.T) {
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {{Name: "v4.0.0"}, {Name: "v4.1.0"}, {Name: "v4.2.0"}},
+			"owner/fresh":      {{Name: "v1.0.0"}, {Name: "v1.1.0"}},
+		},
+		releases: map[string][]GitHubRelease{
+			"actions/checkout": {{TagName: "v4.2.0", PublishedAt: daysAgo(2)}},
+		},
+		dates: map[string]time.Time{
+			"actions/checkout@v4.1.0": daysAgo(30),
+			"owner/fresh@v1.1.0":      daysAgo(1),
+		},
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4.0.0", File: "ci.yml"},
+		{Name: "owner/fresh", Version: "v1.0.0", File: "ci.yml"},
+	}
+
+	checker := NewChecker(WithClient(client), WithMinReleaseAge(7*24*time.Hour))
+	checker.now = func() time.Time { return now }
+	result, err := checker.Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// v4.2.0's release and v1.1.0's commit are too recent
+	if len(result.Outdated) != 1 || result.Outdated[0].LatestVersion != "v4.1.0" {
+		t.Errorf("unexpected outdated actions: %+v", result.Outdated)
+	}
+}
+
+func TestCheckerLatestSHA(t *testing.T) {
+	tag := func(name, sha string) GitHubTag {
+		g := GitHubTag{Name: name}
+		g.Commit.SHA = sha
+		return g
+	}
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {tag("v4", "aaa"), tag("v5", "bbb"), tag("v5.0.1", "bbb")},
+		"owner/mono":       {tag("component-a/v1.0.0", "ccc"), tag("component-a/v1.2.0", "ddd")},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "owner/mono", Version: "component-a/v1.0.0", File: "ci.yml"},
+	}
+	result, err := NewChecker(WithClient(client), WithTagRules(map[string]TagRule{
+		"owner/mono": {StripPrefix: "component-a/"},
+	})).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 2 || result.Outdated[0].LatestSHA != "bbb" || result.Outdated[1].LatestSHA != "ddd" {
+		t.Errorf("expected the latest versions' commits, got %+v", result.Outdated)
+	}
+}
+
+func TestCheckerHowFarBehind(t *testing.T) {
+	released := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{"actions/checkout": {{Name: "v4.0.0"}, {Name: "v4.1.0"}}},
+		releases: map[string][]GitHubRelease{
+			"actions/checkout": {{TagName: "v4.1.0", PublishedAt: released}},
+		},
+		dates:  map[string]time.Time{"actions/checkout@v4.0.0": released.AddDate(0, 0, -45)},
+		behind: map[string]int{"v4.0.0": 17},
+	}
+	refs := []ActionReference{{Name: "actions/checkout", Version: "v4.0.0", File: "ci.yml"}}
+
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 1 {
+		t.Fatalf("expected one outdated action, got %+v", result.Outdated)
+	}
+	a := result.Outdated[0]
+	if !a.LatestPublished.Equal(released) || a.CurrentPublished.IsZero() || a.DaysBehind != 45 {
+		t.Errorf("unexpected publish dates: %+v", a)
+	}
+	if a.CommitsBehind != 17 {
+		t.Errorf("expected 17 commits behind, got %d", a.CommitsBehind)
+	}
+}
+
+func TestCheckerReleaseNotes(t *testing.T) {
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{"actions/checkout": {{Name: "v4.0.0"}, {Name: "v4.1.0"}, {Name: "v4.2.0"}}},
+		releases: map[string][]GitHubRelease{"actions/checkout": {
+			{TagName: "v4.2.0", Body: "Add sparse checkout"},
+			{TagName: "v4.1.0", Body: "Fix submodules"},
+			{TagName: "v4.0.0", Body: "Node 20"},
+		}},
+	}
+	refs := []ActionReference{{Name: "actions/checkout", Version: "v4.0.0", File: "ci.yml"}}
+
+	for _, notes := range []bool{false, true} {
+		result, err := NewChecker(WithClient(client), WithReleaseNotes(notes)).Check(context.Background(), refs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := result.Outdated[0].Notes
+		if !notes && got != nil {
+			t.Errorf("expected no notes unless requested, got %+v", got)
+		}
+		if notes && (len(got) != 2 || got[0].TagName != "v4.2.0" || got[1].Body != "Fix submodules") {
+			t.Errorf("unexpected notes: %+v", got)
+		}
+		if n := result.Outdated[0].ReleasesBehind; n != 2 {
+			t.Errorf("expected 2 releases behind, got %d", n)
+		}
+	}
+}
+
+func TestCheckerKnownTags(t *testing.T) {
+	tag := func(name, sha string) GitHubTag {
+		g := GitHubTag{Name: name}
+		g.Commit.SHA = sha
+		return g
+	}
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {tag("v4", "new4"), tag("v5", "sha5")},
+		"actions/cache":    {tag("v4", "same")},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/cache", Version: "v4", File: "ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client), WithKnownTags(map[string]string{
+		"actions/checkout@v4": "old4",
+		"actions/cache@v4":    "same",
+	})).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Moved) != 1 || result.Moved[0].Name != "actions/checkout" ||
+		result.Moved[0].OldSHA != "old4" || result.Moved[0].NewSHA != "new4" {
+		t.Errorf("unexpected moved tags: %+v", result.Moved)
+	}
+	if result.Resolved["actions/checkout@v4"] != "new4" || result.Resolved["actions/cache@v4"] != "same" {
+		t.Errorf("unexpected resolved tags: %v", result.Resolved)
+	}
+}
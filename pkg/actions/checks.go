@@ -0,0 +1,174 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxAnnotations is how many annotations the Checks API accepts per request
+const maxAnnotations = 50
+
+// CheckRun is a GitHub check run, shown on commits and pull requests and
+// usable as a required status check
+type CheckRun struct {
+	ID         int64          `json:"id,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	HeadSHA    string         `json:"head_sha,omitempty"`
+	Status     string         `json:"status,omitempty"`     // "completed" once finished
+	Conclusion string         `json:"conclusion,omitempty"` // "success", "failure" or "neutral"
+	HTMLURL    string         `json:"html_url,omitempty"`
+	Output     CheckRunOutput `json:"output"`
+}
+
+// CheckRunOutput is the report shown on a check run's page
+type CheckRunOutput struct {
+	Title       string               `json:"title"`
+	Summary     string               `json:"summary"` // Markdown
+	Annotations []CheckRunAnnotation `json:"annotations,omitempty"`
+}
+
+// CheckRunAnnotation marks a line of a file in the pull request diff
+type CheckRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"` // "notice", "warning" or "failure"
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+// CommitStatus is the simpler alternative to a check run: a state and a
+// one-line description on a commit
+type CommitStatus struct {
+	State       string `json:"state"` // "success", "failure", "error" or "pending"
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+// StatusReporter reports results on commits. The HTTPClient implements it.
+type StatusReporter interface {
+	CreateCheckRun(ctx context.Context, repo string, run CheckRun) (CheckRun, error)
+	UpdateCheckRun(ctx context.Context, repo string, run CheckRun) (CheckRun, error)
+	CreateStatus(ctx context.Context, repo, sha string, status CommitStatus) error
+}
+
+// NewCheckRun builds a completed check run named "aver" for a result, with
+// an annotation on the line of each finding. summary is the Markdown report.
+func NewCheckRun(sha string, result CheckResult, summary string) CheckRun {
+	run := CheckRun{
+		Name:       "aver",
+		HeadSHA:    sha,
+		Status:     "completed",
+		Conclusion: "success",
+		Output:     CheckRunOutput{Title: "All actions are up to date", Summary: summary},
+	}
+	if !result.UpToDate() {
+		run.Conclusion = "failure"
+		run.Output.Title = fmt.Sprintf("Outdated: %d, SHA pins behind: %d", len(result.Outdated), len(result.SHAPinned))
+		if len(result.Inputs) > 0 {
+			run.Output.Title += fmt.Sprintf(", input problems: %d", len(result.Inputs))
+		}
+		if len(result.Commands) > 0 {
+			run.Output.Title += fmt.Sprintf(", deprecated commands: %d", len(result.Commands))
+		}
+	}
+
+	for _, o := range result.Outdated {
+		run.Output.Annotations = append(run.Output.Annotations, CheckRunAnnotation{
+			Path:            o.File,
+			StartLine:       max(o.Line, 1),
+			EndLine:         max(o.Line, 1),
+			AnnotationLevel: "warning",
+			Title:           "Outdated action",
+			Message:         fmt.Sprintf("%s@%s can be updated to %s", o.Name, o.CurrentVersion, o.LatestVersion),
+		})
+	}
+	for _, s := range result.SHAPinned {
+		run.Output.Annotations = append(run.Output.Annotations, CheckRunAnnotation{
+			Path:            s.File,
+			StartLine:       max(s.Line, 1),
+			EndLine:         max(s.Line, 1),
+			AnnotationLevel: "warning",
+			Title:           "SHA-pinned action behind default branch",
+			Message:         s.Describe(),
+		})
+	}
+	for _, f := range result.Inputs {
+		run.Output.Annotations = append(run.Output.Annotations, CheckRunAnnotation{
+			Path:            f.File,
+			StartLine:       max(f.Line, 1),
+			EndLine:         max(f.Line, 1),
+			AnnotationLevel: "warning",
+			Title:           "Problem with a step input",
+			Message:         f.Message,
+		})
+	}
+	for _, d := range result.Commands {
+		run.Output.Annotations = append(run.Output.Annotations, CheckRunAnnotation{
+			Path:            d.File,
+			StartLine:       max(d.Line, 1),
+			EndLine:         max(d.Line, 1),
+			AnnotationLevel: "warning",
+			Title:           "Deprecated workflow command",
+			Message:         d.Message(),
+		})
+	}
+	return run
+}
+
+// CreateCheckRun creates a check run
+func (c *HTTPClient) CreateCheckRun(ctx context.Context, repo string, run CheckRun) (CheckRun, error) {
+	var created CheckRun
+	status, _, err := c.send(ctx, "POST", fmt.Sprintf("/repos/%s/check-runs", repo), run, &created)
+	return created, notAccessible(repo, status, err)
+}
+
+// UpdateCheckRun updates the check run with run.ID. Annotations are added
+// to those already on the run.
+func (c *HTTPClient) UpdateCheckRun(ctx context.Context, repo string, run CheckRun) (CheckRun, error) {
+	var updated CheckRun
+	status, _, err := c.send(ctx, "PATCH", fmt.Sprintf("/repos/%s/check-runs/%d", repo, run.ID), run, &updated)
+	return updated, notAccessible(repo, status, err)
+}
+
+// CreateStatus sets a commit status
+func (c *HTTPClient) CreateStatus(ctx context.Context, repo, sha string, commitStatus CommitStatus) error {
+	var created CommitStatus
+	status, _, err := c.send(ctx, "POST", fmt.Sprintf("/repos/%s/statuses/%s", repo, sha), commitStatus, &created)
+	return notAccessible(repo, status, err)
+}
+
+// ReportCheckRun creates a check run on a commit. The API takes at most 50
+// annotations per request, so the rest are added by updating the run.
+func (c *Checker) ReportCheckRun(ctx context.Context, repo string, run CheckRun) (CheckRun, error) {
+	reporter, ok := c.clientFor(repo).(StatusReporter)
+	if !ok {
+		return CheckRun{}, errors.New("this GitHub client can't report check runs")
+	}
+
+	annotations := run.Output.Annotations
+	run.Output.Annotations = annotations[:min(maxAnnotations, len(annotations))]
+	created, err := reporter.CreateCheckRun(ctx, repo, run)
+	if err != nil {
+		return CheckRun{}, err
+	}
+	for i := maxAnnotations; i < len(annotations); i += maxAnnotations {
+		update := CheckRun{ID: created.ID, Output: run.Output}
+		update.Output.Annotations = annotations[i:min(i+maxAnnotations, len(annotations))]
+		if _, err := reporter.UpdateCheckRun(ctx, repo, update); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// ReportStatus sets a commit status on sha
+func (c *Checker) ReportStatus(ctx context.Context, repo, sha string, status CommitStatus) error {
+	reporter, ok := c.clientFor(repo).(StatusReporter)
+	if !ok {
+		return errors.New("this GitHub client can't report commit statuses")
+	}
+	return reporter.CreateStatus(ctx, repo, sha, status)
+}
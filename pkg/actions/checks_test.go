@@ -0,0 +1,78 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCheckRun(t *testing.T) {
+	run := NewCheckRun("abc123", CheckResult{}, "## aver")
+	if run.Conclusion != "success" || run.Status != "completed" || run.HeadSHA != "abc123" || len(run.Output.Annotations) != 0 {
+		t.Errorf("unexpected run for a clean result: %+v", run)
+	}
+
+	run = NewCheckRun("abc123", CheckResult{
+		Outdated:  []OutdatedAction{{File: ".github/workflows/ci.yml", Line: 12, Name: "actions/checkout", CurrentVersion: "v4", LatestVersion: "v5"}},
+		SHAPinned: []SHAPinnedAction{{File: "release.yml", Name: "owner/tool", CommitsBehind: 3, DefaultBranch: "main"}},
+	}, "## aver")
+	if run.Conclusion != "failure" || run.Output.Title != "Outdated: 1, SHA pins behind: 1" {
+		t.Errorf("unexpected run: %+v", run)
+	}
+	want := CheckRunAnnotation{
+		Path: ".github/workflows/ci.yml", StartLine: 12, EndLine: 12, AnnotationLevel: "warning",
+		Title: "Outdated action", Message: "actions/checkout@v4 can be updated to v5",
+	}
+	if len(run.Output.Annotations) != 2 || run.Output.Annotations[0] != want || run.Output.Annotations[1].StartLine != 1 {
+		t.Errorf("unexpected annotations: %+v", run.Output.Annotations)
+	}
+}
+
+func TestReportCheckRun(t *testing.T) {
+	var requests []string
+	var annotations []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var run CheckRun
+		if err := json.NewDecoder(r.Body).Decode(&run); err != nil {
+			t.Error(err)
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		annotations = append(annotations, len(run.Output.Annotations))
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repos/owner/app/check-runs":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 42, "html_url": "https://github.com/owner/app/runs/42"}`))
+		case r.Method == "PATCH" && r.URL.Path == "/repos/owner/app/check-runs/42":
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		case r.Method == "POST" && r.URL.Path == "/repos/owner/app/statuses/abc123":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"state": "failure"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	var result CheckResult
+	for i := range 120 {
+		result.Outdated = append(result.Outdated, OutdatedAction{File: "ci.yml", Line: i + 1, Name: fmt.Sprintf("owner/action-%d", i)})
+	}
+	run, err := checker.ReportCheckRun(ctx, "owner/app", NewCheckRun("abc123", result, "summary"))
+	if err != nil || run.ID != 42 || run.HTMLURL == "" {
+		t.Fatalf("unexpected run %+v, %v", run, err)
+	}
+	wantRequests := []string{"POST /repos/owner/app/check-runs", "PATCH /repos/owner/app/check-runs/42", "PATCH /repos/owner/app/check-runs/42"}
+	if fmt.Sprint(requests) != fmt.Sprint(wantRequests) || fmt.Sprint(annotations) != "[50 50 20]" {
+		t.Errorf("unexpected requests %v with annotations %v", requests, annotations)
+	}
+
+	if err := checker.ReportStatus(ctx, "owner/app", "abc123", CommitStatus{State: "failure", Context: "aver"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
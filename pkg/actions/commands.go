@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// deprecatedCommands maps the workflow commands that GitHub replaced with
+// environment files to the file that replaces each. set-env and add-path
+// are disabled; set-output and save-state print a warning and are due to
+// stop working.
+var deprecatedCommands = map[string]string{
+	"set-output": "$GITHUB_OUTPUT",
+	"save-state": "$GITHUB_STATE",
+	"set-env":    "$GITHUB_ENV",
+	"add-path":   "$GITHUB_PATH",
+}
+
+var deprecatedCommand = regexp.MustCompile(`::(set-output|save-state|set-env|add-path)\b`)
+
+// DeprecatedCommand is a use of a deprecated workflow command, like
+// echo "::set-output name=tag::v1", in a run: block
+type DeprecatedCommand struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Command    string `json:"command"`     // e.g. "set-output"
+	UseInstead string `json:"use_instead"` // The environment file, e.g. "$GITHUB_OUTPUT"
+}
+
+// Message describes the finding in a sentence
+func (d DeprecatedCommand) Message() string {
+	return fmt.Sprintf("the %s command is deprecated; write to %s instead", d.Command, d.UseInstead)
+}
+
+// ParseDeprecatedCommands returns the deprecated workflow commands in the
+// run: blocks of a workflow file, once per line. file is reported as their
+// File.
+func ParseDeprecatedCommands(file string, content []byte) ([]DeprecatedCommand, error) {
+	var found []DeprecatedCommand
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &ErrParse{Source: file, Err: err}
+		}
+		found = appendCommands(found, file, &doc)
+	}
+	return found, nil
+}
+
+// appendCommands appends the deprecated commands in the run: values of
+// node and its children to found. The inputs of a step are its action's
+// business, even one called run.
+func appendCommands(found []DeprecatedCommand, file string, node *yaml.Node) []DeprecatedCommand {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			found = appendCommands(found, file, child)
+		}
+		return found
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch {
+		case key.Value == "with":
+			// A step's inputs go to its action
+		case key.Value == "run" && value.Kind == yaml.ScalarNode:
+			for n, line := range strings.Split(value.Value, "\n") {
+				seen := make(map[string]bool)
+				for _, match := range deprecatedCommand.FindAllStringSubmatch(line, -1) {
+					if command := match[1]; !seen[command] {
+						seen[command] = true
+						found = append(found, DeprecatedCommand{
+							File:       file,
+							Line:       scriptLine(value, n),
+							Command:    command,
+							UseInstead: deprecatedCommands[command],
+						})
+					}
+				}
+			}
+		default:
+			found = appendCommands(found, file, value)
+		}
+	}
+	return found
+}
+
+// scriptLine returns the line of the file that line n of a script is on.
+// A block scalar (run: |) starts on the line after its key; lines of a
+// folded one (run: >) may be joined, so it's a best guess there.
+func scriptLine(value *yaml.Node, n int) int {
+	if value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return value.Line + 1 + n
+	}
+	return value.Line + n
+}
+
+// FindDeprecatedCommands returns the deprecated workflow commands in the
+// local workflow files that refs were found in, under projectRoot, as
+// FindSteps reads them
+func FindDeprecatedCommands(projectRoot string, refs []ActionReference) ([]DeprecatedCommand, error) {
+	var found []DeprecatedCommand
+	err := readWorkflows(projectRoot, refs, func(file string, content []byte) {
+		if commands, err := ParseDeprecatedCommands(file, content); err == nil {
+			found = append(found, commands...)
+		}
+	})
+	return found, err
+}
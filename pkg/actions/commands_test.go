@@ -0,0 +1,54 @@
+package actions
+
+import "testing"
+
+func TestParseDeprecatedCommands(t *testing.T) {
+	workflow := `jobs:
+  build:
+    steps:
+      - id: version
+        run: |
+          echo "building"
+          echo "::set-output name=tag::v1" && echo "::set-output name=sha::abc"
+          echo "tag=v1" >> "$GITHUB_OUTPUT"
+          echo "::save-state name=pid::$PID"
+      - run: echo "::set-env name=FOO::bar"
+      - run: echo "::add-path::/opt/bin"
+      - uses: actions/checkout@v4
+        with:
+          run: echo "::set-output name=not::a-script"
+      - run: echo "::set-outputs are not a command"
+---
+steps:
+  - run: >
+      echo ok &&
+      echo "::save-state name=a::b"
+`
+	got, err := ParseDeprecatedCommands("ci.yml", []byte(workflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DeprecatedCommand{
+		{File: "ci.yml", Line: 7, Command: "set-output", UseInstead: "$GITHUB_OUTPUT"},
+		{File: "ci.yml", Line: 9, Command: "save-state", UseInstead: "$GITHUB_STATE"},
+		{File: "ci.yml", Line: 10, Command: "set-env", UseInstead: "$GITHUB_ENV"},
+		{File: "ci.yml", Line: 11, Command: "add-path", UseInstead: "$GITHUB_PATH"},
+		// The folded script is one line, reported where it starts
+		{File: "ci.yml", Line: 19, Command: "save-state", UseInstead: "$GITHUB_STATE"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d commands, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("command %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+	if msg := got[0].Message(); msg != "the set-output command is deprecated; write to $GITHUB_OUTPUT instead" {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	if _, err := ParseDeprecatedCommands("bad.yml", []byte("run: [")); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
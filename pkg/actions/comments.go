@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IssueComment is a comment on an issue or pull request
+type IssueComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Commenter posts and edits comments on issues and pull requests. The
+// HTTPClient implements it.
+type Commenter interface {
+	// Comments returns the comments on an issue or pull request, oldest first
+	Comments(ctx context.Context, repo string, number int) ([]IssueComment, error)
+	CreateComment(ctx context.Context, repo string, number int, body string) (IssueComment, error)
+	EditComment(ctx context.Context, repo string, id int64, body string) (IssueComment, error)
+}
+
+// Comments fetches every comment on an issue or pull request, bypassing
+// the cache so a comment posted by the last run is always seen
+func (c *HTTPClient) Comments(ctx context.Context, repo string, number int) ([]IssueComment, error) {
+	var all []IssueComment
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=%d", repo, number, perPage)
+	for path != "" {
+		var page []IssueComment
+		status, link, err := c.send(ctx, "GET", path, nil, &page)
+		if err != nil {
+			return nil, notAccessible(repo, status, err)
+		}
+		all = append(all, page...)
+
+		path = ""
+		if next := nextPage(link); next != "" {
+			path, _ = strings.CutPrefix(next, c.baseURL())
+			if path == next {
+				path = ""
+			}
+		}
+	}
+	return all, nil
+}
+
+// CreateComment posts a comment on an issue or pull request
+func (c *HTTPClient) CreateComment(ctx context.Context, repo string, number int, body string) (IssueComment, error) {
+	var comment IssueComment
+	status, _, err := c.send(ctx, "POST", fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number),
+		map[string]string{"body": body}, &comment)
+	return comment, notAccessible(repo, status, err)
+}
+
+// EditComment replaces the body of a comment
+func (c *HTTPClient) EditComment(ctx context.Context, repo string, id int64, body string) (IssueComment, error) {
+	var comment IssueComment
+	status, _, err := c.send(ctx, "PATCH", fmt.Sprintf("/repos/%s/issues/comments/%d", repo, id),
+		map[string]string{"body": body}, &comment)
+	return comment, notAccessible(repo, status, err)
+}
+
+// UpsertComment keeps a single comment up to date on a pull request: it
+// edits the comment containing marker, e.g. an HTML comment, or posts body
+// as a new one. With onlyUpdate, nothing is posted unless there's a comment
+// to edit, so clean runs don't add noise. The returned comment is zero if
+// nothing was posted.
+func (c *Checker) UpsertComment(ctx context.Context, repo string, number int, marker, body string, onlyUpdate bool) (IssueComment, error) {
+	commenter, ok := c.clientFor(repo).(Commenter)
+	if !ok {
+		return IssueComment{}, errors.New("this GitHub client can't post comments")
+	}
+
+	comments, err := commenter.Comments(ctx, repo, number)
+	if err != nil {
+		return IssueComment{}, err
+	}
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			if comment.Body == body {
+				return comment, nil
+			}
+			return commenter.EditComment(ctx, repo, comment.ID, body)
+		}
+	}
+	if onlyUpdate {
+		return IssueComment{}, nil
+	}
+	return commenter.CreateComment(ctx, repo, number, body)
+}
@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// commentServer is an issue with comments, served over the GitHub API
+type commentServer struct {
+	mu       sync.Mutex
+	comments []IssueComment
+	nextID   int64
+}
+
+func (s *commentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var in struct{ Body string }
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&in)
+	}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/repos/owner/app/issues/7/comments":
+		// Two pages, to check that every comment is seen
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/owner/app/issues/7/comments?page=2>; rel="next"`, r.Host))
+			_ = json.NewEncoder(w).Encode(s.comments[:min(1, len(s.comments))])
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s.comments[min(1, len(s.comments)):])
+	case r.Method == "POST" && r.URL.Path == "/repos/owner/app/issues/7/comments":
+		s.nextID++
+		s.comments = append(s.comments, IssueComment{ID: s.nextID, Body: in.Body})
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(s.comments[len(s.comments)-1])
+	case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/repos/owner/app/issues/comments/"):
+		for i := range s.comments {
+			if r.URL.Path == fmt.Sprintf("/repos/owner/app/issues/comments/%d", s.comments[i].ID) {
+				s.comments[i].Body = in.Body
+				_ = json.NewEncoder(w).Encode(s.comments[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestUpsertComment(t *testing.T) {
+	issue := &commentServer{comments: []IssueComment{{ID: 100, Body: "LGTM"}}, nextID: 100}
+	server := httptest.NewServer(issue)
+	defer server.Close()
+
+	checker := NewChecker(WithBaseURL(server.URL), WithToken("secret"))
+	ctx := context.Background()
+	const marker = "<!-- aver -->"
+
+	// A clean run doesn't start a thread
+	comment, err := checker.UpsertComment(ctx, "owner/app", 7, marker, marker+"\nAll up to date", true)
+	if err != nil || comment.ID != 0 || len(issue.comments) != 1 {
+		t.Fatalf("expected nothing posted, got %+v, %v", comment, err)
+	}
+
+	comment, err = checker.UpsertComment(ctx, "owner/app", 7, marker, marker+"\n1 outdated", false)
+	if err != nil || comment.ID != 101 {
+		t.Fatalf("expected a new comment, got %+v, %v", comment, err)
+	}
+
+	// Later runs edit the same comment, even when it's on a later page
+	comment, err = checker.UpsertComment(ctx, "owner/app", 7, marker, marker+"\nAll up to date", true)
+	if err != nil || comment.ID != 101 {
+		t.Fatalf("expected the comment to be edited, got %+v, %v", comment, err)
+	}
+	if len(issue.comments) != 2 || issue.comments[1].Body != marker+"\nAll up to date" {
+		t.Errorf("unexpected comments: %+v", issue.comments)
+	}
+
+	if _, err := checker.UpsertComment(ctx, "owner/missing", 7, marker, "x", false); !errors.Is(err, &ErrRepoNotAccessible{}) {
+		t.Errorf("expected ErrRepoNotAccessible, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package actions
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// VersionDrift is an action pinned to different versions in different
+// places of one repository, e.g. actions/checkout@v3 in one workflow and
+// @v4 in another. Neither needs to be outdated: the workflows just don't
+// run the same code.
+type VersionDrift struct {
+	Repository string         `json:"repository,omitempty"`
+	Name       string         `json:"action"`
+	Versions   []DriftVersion `json:"versions"`
+	// Unified is the version to use everywhere, the newest of Versions, or
+	// "" if they can't be compared, as with commit SHAs and branches
+	Unified string `json:"unify_to,omitempty"`
+}
+
+// DriftVersion is one of the versions of a VersionDrift and the files that
+// pin it
+type DriftVersion struct {
+	Version string   `json:"version"`
+	Files   []string `json:"files"`
+}
+
+// FindDrift returns the actions that refs pin to more than one version,
+// per repository, ordered by repository and name. Dynamic refs are left
+// out. Nothing is looked up.
+func FindDrift(refs []ActionReference) []VersionDrift {
+	type key struct{ repository, name string }
+	files := make(map[key]map[string][]string)
+	for _, ref := range refs {
+		if ref.Dynamic() {
+			continue
+		}
+		k := key{ref.Repository, ref.Name}
+		if files[k] == nil {
+			files[k] = make(map[string][]string)
+		}
+		if !slices.Contains(files[k][ref.Version], ref.File) {
+			files[k][ref.Version] = append(files[k][ref.Version], ref.File)
+		}
+	}
+
+	var drift []VersionDrift
+	for k, byVersion := range files {
+		if len(byVersion) < 2 {
+			continue
+		}
+		d := VersionDrift{Repository: k.repository, Name: k.name}
+		for version, files := range byVersion {
+			d.Versions = append(d.Versions, DriftVersion{Version: version, Files: files})
+		}
+		slices.SortFunc(d.Versions, func(a, b DriftVersion) int {
+			return compareVersions(a.Version, b.Version)
+		})
+		d.Unified = unifiedVersion(d.Versions)
+		drift = append(drift, d)
+	}
+	slices.SortFunc(drift, func(a, b VersionDrift) int {
+		return cmp.Or(strings.Compare(a.Repository, b.Repository), strings.Compare(a.Name, b.Name))
+	})
+	return drift
+}
+
+// unifiedVersion returns the newest of versions, sorted oldest first, if
+// they're all semantic versions. A partial version is newer than the full
+// ones it covers, since it moves to the newest of them: v4 over v4.1.0.
+func unifiedVersion(versions []DriftVersion) string {
+	var newest *semver
+	for _, v := range versions {
+		sv := parseSemver(v.Version)
+		if sv == nil {
+			return ""
+		}
+		if newest == nil || (sv.compare(newest) > 0 && !covers(newest, sv)) || covers(sv, newest) {
+			newest = sv
+		}
+	}
+	return newest.Raw
+}
+
+// covers reports whether the partial version s includes the version other,
+// as v4 includes v4.1.0 and v4.1 includes v4.1.2
+func covers(s, other *semver) bool {
+	switch {
+	case !s.HasMinor:
+		return other.HasMinor && s.Major == other.Major
+	case !s.HasPatch:
+		return other.HasPatch && s.Major == other.Major && s.Minor == other.Minor
+	}
+	return false
+}
+
+// compareVersions orders semantic versions by precedence and anything else,
+// like commit SHAs and branches, after them by name
+func compareVersions(a, b string) int {
+	sa, sb := parseSemver(a), parseSemver(b)
+	switch {
+	case sa != nil && sb != nil:
+		return cmp.Or(sa.compare(sb), strings.Compare(a, b))
+	case sa != nil:
+		return -1
+	case sb != nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDrift(t *testing.T) {
+	sha := "1234567890abcdef1234567890abcdef12345678"
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/checkout", Version: "v3", File: "release.yml"},
+		{Name: "actions/checkout", Version: "v4", File: "lint.yml"},
+		{Name: "actions/setup-go", Version: "v5", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "v5", File: "release.yml"},
+		{Name: "actions/cache", Version: sha, File: "ci.yml"},
+		{Name: "actions/cache", Version: "v4", File: "release.yml"},
+		{Name: "owner/tool", Version: "${{ inputs.version }}", File: "ci.yml"},
+		{Name: "owner/tool", Version: "v1", File: "ci.yml"},
+		// Other repositories drift on their own
+		{Name: "actions/checkout", Version: "v2", File: "ci.yml", Repository: "owner/other"},
+	}
+	got := FindDrift(refs)
+	want := []VersionDrift{
+		{Name: "actions/cache", Versions: []DriftVersion{
+			{Version: "v4", Files: []string{"release.yml"}},
+			{Version: sha, Files: []string{"ci.yml"}},
+		}},
+		{Name: "actions/checkout", Unified: "v4", Versions: []DriftVersion{
+			{Version: "v3", Files: []string{"release.yml"}},
+			{Version: "v4", Files: []string{"ci.yml", "lint.yml"}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%+v\ngot\n%+v", want, got)
+	}
+}
+
+func TestUnifiedVersion(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     string
+	}{
+		{[]string{"v3", "v4"}, "v4"},
+		{[]string{"v9", "v10"}, "v10"},
+		{[]string{"v4.0.1", "v4.2.0"}, "v4.2.0"},
+		// A partial version moves to the newest release it covers
+		{[]string{"v4", "v4.1.0"}, "v4"},
+		{[]string{"v4.1", "v4.1.2"}, "v4.1"},
+		{[]string{"v4.1", "v4.2.0"}, "v4.2.0"},
+		{[]string{"v3.9.9", "v4", "v4.1.0"}, "v4"},
+		{[]string{"v4", "main"}, ""},
+	}
+	for _, tt := range tests {
+		var versions []DriftVersion
+		for _, v := range tt.versions {
+			versions = append(versions, DriftVersion{Version: v})
+		}
+		if got := unifiedVersion(versions); got != tt.want {
+			t.Errorf("unifiedVersion(%v) = %q, want %q", tt.versions, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The error types below support both errors.As, to inspect the details, and
+// errors.Is with a zero value of the type, to test the cause:
+//
+//	if errors.Is(err, &actions.ErrRateLimited{}) { ... }
+
+// ErrRepoNotAccessible is returned when a repository cannot be accessed:
+// it doesn't exist, is private, or the token may not read it
+type ErrRepoNotAccessible struct {
+	Repo    string
+	Status  int
+	Message string // Why, in the API's words, if it said, e.g. that SAML SSO blocks the token
+	// SSOURL is where to authorize the token for the owner's SAML single
+	// sign-on, when that's what keeps it out
+	SSOURL string
+	// MissingScope is a scope a classic token lacks that private
+	// repositories need, e.g. "repo". A 404 doesn't say whether the
+	// repository is private, so it may not be the cause.
+	MissingScope string
+}
+
+func (e *ErrRepoNotAccessible) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("repository %s not accessible (status %d: %s)", e.Repo, e.Status, e.Message)
+	}
+	return fmt.Sprintf("repository %s not accessible (status %d)", e.Repo, e.Status)
+}
+
+func (e *ErrRepoNotAccessible) Is(target error) bool {
+	_, ok := target.(*ErrRepoNotAccessible)
+	return ok
+}
+
+// Hint says how to give the token access, or "" if the response didn't
+// show what's missing
+func (e *ErrRepoNotAccessible) Hint() string {
+	switch {
+	case e.SSOURL != "":
+		return "authorize the token for the organization's SAML single sign-on at " + e.SSOURL
+	case e.MissingScope != "":
+		return fmt.Sprintf("if it's private, give the token the %s scope", e.MissingScope)
+	}
+	return ""
+}
+
+// ErrRateLimited is returned when the GitHub API rate limit is exhausted,
+// or requests came too quickly for its secondary rate limit
+type ErrRateLimited struct {
+	Reset     time.Time // When the rate limit resets, or requests may resume; zero if unknown
+	Secondary bool      // The secondary rate limit, which a token doesn't raise
+}
+
+func (e *ErrRateLimited) Error() string {
+	limit := "rate limit"
+	if e.Secondary {
+		limit = "secondary rate limit"
+	}
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("GitHub API %s exceeded", limit)
+	}
+	return fmt.Sprintf("GitHub API %s exceeded (resets at %s)", limit, e.Reset.Format(time.Kitchen))
+}
+
+func (e *ErrRateLimited) Is(target error) bool {
+	_, ok := target.(*ErrRateLimited)
+	return ok
+}
+
+// ErrOverBudget is returned when checking would need more API requests
+// than the rate limit has left
+type ErrOverBudget struct {
+	Needed    int       // The fewest requests the check makes
+	Remaining int       // Requests left before the rate limit resets
+	Reset     time.Time // When the rate limit resets
+}
+
+func (e *ErrOverBudget) Error() string {
+	return fmt.Sprintf("checking needs at least %d API requests but only %d are left (resets at %s)",
+		e.Needed, e.Remaining, e.Reset.Format(time.Kitchen))
+}
+
+func (e *ErrOverBudget) Is(target error) bool {
+	_, ok := target.(*ErrOverBudget)
+	return ok
+}
+
+// ErrBudgetExhausted is returned instead of an API request once a run has
+// sent the most it may (see Usage.Max)
+type ErrBudgetExhausted struct {
+	Max int64
+}
+
+func (e *ErrBudgetExhausted) Error() string {
+	return fmt.Sprintf("API request budget of %d used up", e.Max)
+}
+
+func (e *ErrBudgetExhausted) Is(target error) bool {
+	_, ok := target.(*ErrBudgetExhausted)
+	return ok
+}
+
+// ErrTagNotFound is returned when an action is pinned to a tag that doesn't
+// exist in its repository
+type ErrTagNotFound struct {
+	Repo string
+	Tag  string
+}
+
+func (e *ErrTagNotFound) Error() string {
+	return fmt.Sprintf("tag %s not found in %s", e.Tag, e.Repo)
+}
+
+func (e *ErrTagNotFound) Is(target error) bool {
+	_, ok := target.(*ErrTagNotFound)
+	return ok
+}
+
+// ErrRefNotFound is returned when a branch or commit doesn't exist in a
+// repository
+type ErrRefNotFound struct {
+	Repo string
+	Ref  string
+}
+
+func (e *ErrRefNotFound) Error() string {
+	return fmt.Sprintf("ref %s not found in %s", e.Ref, e.Repo)
+}
+
+func (e *ErrRefNotFound) Is(target error) bool {
+	_, ok := target.(*ErrRefNotFound)
+	return ok
+}
+
+// ErrNetwork is returned when the GitHub API could not be reached
+type ErrNetwork struct {
+	Err error
+}
+
+func (e *ErrNetwork) Error() string {
+	return fmt.Sprintf("network error: %v", e.Err)
+}
+
+func (e *ErrNetwork) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrNetwork) Is(target error) bool {
+	_, ok := target.(*ErrNetwork)
+	return ok
+}
+
+// ErrParse is returned when a workflow file or an API response can't be
+// parsed
+type ErrParse struct {
+	Source string // The file path or URL that failed to parse
+	Err    error
+}
+
+func (e *ErrParse) Error() string {
+	return fmt.Sprintf("failed to parse %s: %v", e.Source, e.Err)
+}
+
+func (e *ErrParse) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrParse) Is(target error) bool {
+	_, ok := target.(*ErrParse)
+	return ok
+}
+
+// ErrUnparsed lists the workflow files that couldn't be parsed. Discovery
+// returns it alongside the references in every other file, so callers can
+// warn about the files and carry on, or fail.
+type ErrUnparsed struct {
+	Errs []*ErrParse
+}
+
+func (e *ErrUnparsed) Error() string {
+	if len(e.Errs) == 1 {
+		return e.Errs[0].Error()
+	}
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d files failed to parse: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap makes errors.Is(err, &ErrParse{}) hold for ErrUnparsed too
+func (e *ErrUnparsed) Unwrap() []error {
+	errs := make([]error, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (e *ErrUnparsed) Is(target error) bool {
+	_, ok := target.(*ErrUnparsed)
+	return ok
+}
+
+// unparsed collects parse errors during discovery
+type unparsed []*ErrParse
+
+// skip records err if it's a parse error and returns nil, so the caller
+// moves on to the next file, or returns any other error
+func (u *unparsed) skip(err error) error {
+	var parseErr *ErrParse
+	if errors.As(err, &parseErr) {
+		*u = append(*u, parseErr)
+		return nil
+	}
+	return err
+}
+
+// err is an ErrUnparsed of the errors collected, or nil if there are none
+func (u unparsed) err() error {
+	if len(u) == 0 {
+		return nil
+	}
+	return &ErrUnparsed{Errs: u}
+}
+
+// ErrNotCached means a response was needed in offline mode but isn't in the
+// cache
+type ErrNotCached struct {
+	URL string
+}
+
+func (e *ErrNotCached) Error() string {
+	return fmt.Sprintf("%s is not cached and aver is offline", e.URL)
+}
+
+func (e *ErrNotCached) Is(target error) bool {
+	_, ok := target.(*ErrNotCached)
+	return ok
+}
+
+// ErrUnauthorized is returned when the API rejects the token, e.g. because
+// it has expired or been revoked
+type ErrUnauthorized struct {
+	Host string
+}
+
+func (e *ErrUnauthorized) Error() string {
+	return fmt.Sprintf("%s rejected the token (status 401)", e.Host)
+}
+
+func (e *ErrUnauthorized) Is(target error) bool {
+	_, ok := target.(*ErrUnauthorized)
+	return ok
+}
@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorsIs(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{"not accessible", &ErrRepoNotAccessible{Repo: "a/b", Status: 404}, &ErrRepoNotAccessible{}},
+		{"rate limited", &ErrRateLimited{Reset: time.Now()}, &ErrRateLimited{}},
+		{"tag not found", &ErrTagNotFound{Repo: "a/b", Tag: "v9"}, &ErrTagNotFound{}},
+		{"ref not found", &ErrRefNotFound{Repo: "a/b", Ref: "main"}, &ErrRefNotFound{}},
+		{"network", &ErrNetwork{Err: errors.New("connection refused")}, &ErrNetwork{}},
+		{"parse", &ErrParse{Source: "ci.yml", Err: errors.New("bad indent")}, &ErrParse{}},
+		{"not cached", &ErrNotCached{URL: "https://api.github.com/repos/a/b"}, &ErrNotCached{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("failed to check a/b: %w", tt.err)
+			if !errors.Is(wrapped, tt.target) {
+				t.Errorf("expected errors.Is to match %T", tt.target)
+			}
+			for _, other := range tests {
+				if other.name != tt.name && errors.Is(wrapped, other.target) {
+					t.Errorf("%T unexpectedly matched %T", tt.err, other.target)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorsUnwrap(t *testing.T) {
+	cause := errors.New("connection reset")
+	if !errors.Is(&ErrNetwork{Err: cause}, cause) {
+		t.Error("expected ErrNetwork to unwrap to its cause")
+	}
+	if !errors.Is(&ErrParse{Source: "ci.yml", Err: cause}, cause) {
+		t.Error("expected ErrParse to unwrap to its cause")
+	}
+}
@@ -0,0 +1,59 @@
+package actions
+
+import "time"
+
+// Event is a progress notification emitted while a Checker runs. It is one
+// of Started, Resolved, Skipped or RateLimited. Events are delivered to the
+// callback set with WithProgress, possibly from several goroutines at once.
+type Event interface {
+	event()
+}
+
+// Started is emitted when a reference begins checking
+type Started struct {
+	Ref  ActionReference
+	Repo string // The repository the reference resolves to
+}
+
+// Outcome describes the result of a successful check
+type Outcome int
+
+const (
+	OutcomeUpToDate Outcome = iota // No newer version is available
+	OutcomeOutdated                // A newer version is available
+	OutcomeBehind                  // A SHA pin is behind its default branch
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeOutdated:
+		return "outdated"
+	case OutcomeBehind:
+		return "behind"
+	default:
+		return "up to date"
+	}
+}
+
+// Resolved is emitted when a reference has been checked
+type Resolved struct {
+	Ref     ActionReference
+	Outcome Outcome
+}
+
+// Skipped is emitted when a reference could not be or was not checked
+type Skipped struct {
+	Ref    ActionReference
+	Reason string
+}
+
+// RateLimited is emitted when the GitHub API refuses a request because the
+// rate limit is exhausted
+type RateLimited struct {
+	Reset time.Time // When the rate limit resets; zero if unknown
+}
+
+func (Started) event()     {}
+func (Resolved) event()    {}
+func (Skipped) event()     {}
+func (RateLimited) event() {}
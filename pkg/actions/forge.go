@@ -0,0 +1,134 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkflowDirs are the directories, relative to a project root, that hold
+// workflows: GitHub's, and those of Forgejo and Gitea, which run the same
+// syntax
+var WorkflowDirs = []string{".github/workflows", ".forgejo/workflows", ".gitea/workflows"}
+
+// forgeRepo splits an action named by URL, as Forgejo and Gitea workflows
+// allow (https://code.forgejo.org/actions/checkout), into the URL of its
+// host ("https://code.forgejo.org") and its owner/repo
+func forgeRepo(name string) (host, repo string, ok bool) {
+	scheme, rest, ok := strings.Cut(name, "://")
+	if !ok || (scheme != "https" && scheme != "http") {
+		return "", "", false
+	}
+	host, path, _ := strings.Cut(rest, "/")
+	parts := strings.Split(path, "/")
+	if host == "" || len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return scheme + "://" + host, parts[0] + "/" + parts[1], true
+}
+
+// ForgeRepoURL returns the web URL of the repository of an action named by
+// URL, such as https://code.forgejo.org/actions/checkout, or of the project
+// of a GitLab component, and false for actions named the GitHub way
+func ForgeRepoURL(name string) (string, bool) {
+	if host, project, ok := gitlabComponent(name); ok {
+		return "https://" + host + "/" + project, true
+	}
+	host, repo, ok := forgeRepo(name)
+	if !ok {
+		return "", false
+	}
+	return host + "/" + repo, true
+}
+
+// QualifyActions names the actions that Forgejo and Gitea workflows refer
+// to by owner/repo after defaultActionsURL, the instance's
+// DEFAULT_ACTIONS_URL (e.g. https://data.forgejo.org), so they're looked up
+// where the runner fetches them. References in .github workflows are left
+// alone, as are all references if defaultActionsURL is empty or github.com.
+func QualifyActions(refs []ActionReference, defaultActionsURL string) []ActionReference {
+	defaultActionsURL = strings.TrimSuffix(defaultActionsURL, "/")
+	if defaultActionsURL == "" || defaultActionsURL == "https://github.com" {
+		return refs
+	}
+	qualified := make([]ActionReference, len(refs))
+	for i, ref := range refs {
+		if forgeWorkflow(ref.File) && !strings.Contains(ref.Name, "://") {
+			ref.Name = defaultActionsURL + "/" + ref.Name
+		}
+		qualified[i] = ref
+	}
+	return qualified
+}
+
+// forgeWorkflow reports whether file is in a Forgejo or Gitea workflows
+// directory
+func forgeWorkflow(file string) bool {
+	file = "/" + strings.ReplaceAll(file, "\\", "/")
+	return strings.Contains(file, "/.forgejo/workflows/") || strings.Contains(file, "/.gitea/workflows/")
+}
+
+// hostClients creates a client for each API host on first use
+type hostClients struct {
+	mu      sync.Mutex
+	clients map[string]GitHubClient
+	create  func(host string) GitHubClient
+}
+
+func (h *hostClients) client(host string) GitHubClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if client, ok := h.clients[host]; ok {
+		return client
+	}
+	if h.clients == nil {
+		h.clients = make(map[string]GitHubClient)
+	}
+	client := h.create(host)
+	h.clients[host] = client
+	return client
+}
+
+// forgeClient takes repositories named by URL, as the Checker has them for
+// actions named by URL, and asks its host's API about the owner/repo
+type forgeClient struct {
+	*HTTPClient
+	prefix string // e.g. "https://code.forgejo.org/"
+}
+
+func (c *forgeClient) repo(name string) string {
+	return strings.TrimPrefix(name, c.prefix)
+}
+
+func (c *forgeClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	return c.HTTPClient.Tags(ctx, c.repo(repo))
+}
+
+func (c *forgeClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	return c.HTTPClient.Releases(ctx, c.repo(repo))
+}
+
+func (c *forgeClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return c.HTTPClient.DefaultBranch(ctx, c.repo(repo))
+}
+
+func (c *forgeClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return c.HTTPClient.BranchHead(ctx, c.repo(repo), branch)
+}
+
+func (c *forgeClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return c.HTTPClient.CompareCommits(ctx, c.repo(repo), base, head)
+}
+
+func (c *forgeClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return c.HTTPClient.CommitDate(ctx, c.repo(repo), ref)
+}
+
+func (c *forgeClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return c.HTTPClient.Workflows(ctx, c.repo(repo), ref)
+}
+
+func (c *forgeClient) File(ctx context.Context, repo, path, ref string) ([]byte, error) {
+	return c.HTTPClient.File(ctx, c.repo(repo), path, ref)
+}
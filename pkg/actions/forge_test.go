@@ -0,0 +1,137 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRepoFromForgeAction(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"https://code.forgejo.org/actions/checkout", "https://code.forgejo.org/actions/checkout"},
+		{"https://codeberg.org/owner/repo/sub/action", "https://codeberg.org/owner/repo"},
+		{"actions/cache/restore", "actions/cache"},
+	}
+	for _, tt := range tests {
+		if got := repoFromAction(tt.name); got != tt.want {
+			t.Errorf("repoFromAction(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, ok := ForgeRepoURL("docker://alpine/git"); ok {
+		t.Error("docker:// references aren't forge actions")
+	}
+}
+
+func TestParseWorkflowActionURLs(t *testing.T) {
+	refs, err := ParseWorkflow("ci.yml", []byte(`steps:
+  - uses: https://github.com/actions/checkout@v4
+  - uses: https://code.forgejo.org/actions/setup-go@v5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 || refs[0].Name != "actions/checkout" || refs[1].Name != "https://code.forgejo.org/actions/setup-go" {
+		t.Errorf("ParseWorkflow() = %+v", refs)
+	}
+}
+
+func TestQualifyActions(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "actions/checkout", File: ".github/workflows/ci.yml"},
+		{Name: "actions/checkout", File: ".forgejo/workflows/ci.yml"},
+		{Name: "actions/checkout", File: "sub/.gitea/workflows/ci.yml"},
+		{Name: "https://codeberg.org/owner/action", File: ".forgejo/workflows/ci.yml"},
+	}
+	got := QualifyActions(refs, "https://data.forgejo.org/")
+	want := []string{
+		"actions/checkout",
+		"https://data.forgejo.org/actions/checkout",
+		"https://data.forgejo.org/actions/checkout",
+		"https://codeberg.org/owner/action",
+	}
+	for i := range want {
+		if got[i].Name != want[i] {
+			t.Errorf("QualifyActions()[%d] = %q, want %q", i, got[i].Name, want[i])
+		}
+	}
+	if refs[1].Name != "actions/checkout" {
+		t.Error("QualifyActions() modified its argument")
+	}
+	if got := QualifyActions(refs, "https://github.com"); got[1].Name != "actions/checkout" {
+		t.Errorf("github.com qualified %q", got[1].Name)
+	}
+}
+
+func TestFindForgejoWorkflows(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".forgejo", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("steps:\n  - uses: actions/checkout@v4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// There's no .github directory, so .forgejo marks the project root
+	refs, err := FindActionReferences(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].File != filepath.Join(".forgejo", "workflows", "ci.yml") {
+		t.Errorf("FindActionReferences() = %+v", refs)
+	}
+}
+
+func TestCheckerForgeActions(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RequestURI())
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "" {
+			t.Error("a token was sent to another forge")
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/actions/checkout/tags":
+			_, _ = w.Write([]byte(`[{"name": "v4"}, {"name": "v3"}]`))
+		case "/api/v1/repos/actions/cache":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		case "/api/v1/repos/actions/cache/branches/main":
+			_, _ = w.Write([]byte(`{"name": "main", "commit": {"id": "` + strings.Repeat("b", 40) + `"}}`))
+		case "/api/v1/repos/actions/cache/compare/" + strings.Repeat("a", 40) + "...main":
+			_, _ = w.Write([]byte(`{"total_commits": 3}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	refs := []ActionReference{
+		{Name: server.URL + "/actions/checkout", Version: "v3"},
+		{Name: server.URL + "/actions/cache", Version: strings.Repeat("a", 40)},
+	}
+	result, err := NewChecker(WithToken("secret")).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Outdated) != 1 || result.Outdated[0].LatestVersion != "v4" {
+		t.Errorf("Outdated = %+v, want v3 -> v4; requests: %v", result.Outdated, requests)
+	}
+	if len(result.SHAPinned) != 1 || result.SHAPinned[0].CommitsBehind != 3 {
+		t.Errorf("SHAPinned = %+v, want 3 commits behind; requests: %v", result.SHAPinned, requests)
+	}
+	for _, uri := range requests {
+		if strings.Contains(uri, "/tags") && !strings.Contains(uri, "limit=50") {
+			t.Errorf("tags were requested without Gitea paging: %s", uri)
+		}
+	}
+}
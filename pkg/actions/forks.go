@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SuspiciousFork is an action in a fork whose name resembles a popular
+// action under another owner, which is worth a review
+type SuspiciousFork struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Name       string `json:"action"`
+	Version    string `json:"version"`
+	Resembles  string `json:"resembles"`        // The popular action, e.g. "actions/checkout"
+	Parent     string `json:"parent,omitempty"` // The repository it was forked from, if known
+}
+
+// Message describes the finding in a sentence
+func (f SuspiciousFork) Message() string {
+	repo := repoFromAction(f.Name)
+	switch f.Parent {
+	case f.Resembles:
+		return fmt.Sprintf("%s is a fork of %s; review what it changes, or use %s", repo, f.Resembles, f.Resembles)
+	case "":
+		return fmt.Sprintf("%s resembles %s and is a fork; make sure it's the action you meant", repo, f.Resembles)
+	}
+	return fmt.Sprintf("%s resembles %s and is a fork of %s; make sure it's the action you meant", repo, f.Resembles, f.Parent)
+}
+
+// ForkResult is the suspicious forks among a set of actions
+type ForkResult struct {
+	Forks    []SuspiciousFork
+	Warnings []string
+}
+
+// SuspiciousForks looks for actions whose repository's name resembles a
+// popular action's under another owner, and reports those that are forks
+// at their first reference. Only those repositories are looked up, so most
+// projects cost no requests. Refs are passed over if the client can't fetch
+// repositories.
+func (c *Checker) SuspiciousForks(ctx context.Context, refs []ActionReference) (ForkResult, error) {
+	repos, ok := c.client.(RepositoryClient)
+	if !ok {
+		return ForkResult{}, nil
+	}
+	var names []string
+	first := make(map[string]ActionReference)
+	resembles := make(map[string]string)
+	for _, ref := range refs {
+		if ref.Dynamic() || !githubAction(ref.Name) {
+			continue
+		}
+		name := repoFromAction(ref.Name)
+		if _, ok := first[name]; ok {
+			continue
+		}
+		first[name] = ref
+		if popular := resemblesPopular(name); popular != "" {
+			resembles[name] = popular
+			names = append(names, name)
+		}
+	}
+
+	infos := make([]GitHubRepo, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			infos[i], errs[i] = repos.Repository(ctx, name)
+		})
+	}
+	wg.Wait()
+
+	var result ForkResult
+	for i, name := range names {
+		err := errs[i]
+		switch {
+		case err == nil:
+		case errors.Is(err, &ErrRepoNotAccessible{}), errors.Is(err, &ErrNotCached{}):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("not checking whether %s is a fork: %v", name, err))
+			continue
+		default:
+			return ForkResult{}, err
+		}
+		if !infos[i].Fork {
+			continue
+		}
+		ref := first[name]
+		fork := SuspiciousFork{
+			Repository: ref.Repository,
+			File:       ref.File,
+			Line:       ref.Line,
+			Name:       ref.Name,
+			Version:    ref.Version,
+			Resembles:  resembles[name],
+		}
+		if infos[i].Parent != nil {
+			fork.Parent = infos[i].Parent.FullName
+		}
+		result.Forks = append(result.Forks, fork)
+	}
+	return result, nil
+}
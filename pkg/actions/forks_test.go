@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckerSuspiciousForks(t *testing.T) {
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout":    {{Name: "v4"}},
+			"evil/checkout":       {{Name: "v4"}},
+			"someone/setup-nodes": {{Name: "v4"}},
+			"acme/cache":          {{Name: "v1"}},
+			"other/build-tool":    {{Name: "v1"}},
+			"actlons/setup-go":    {{Name: "v5"}},
+		},
+		forks: map[string]string{
+			"evil/checkout":       "actions/checkout",
+			"someone/setup-nodes": "other/node-setup",
+			"actlons/setup-go":    "",
+			"other/build-tool":    "docker/build-push-action",
+		},
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "evil/checkout", Version: "v4", File: "ci.yml", Line: 9},
+		{Name: "evil/checkout", Version: "v4", File: "release.yml", Line: 3},
+		{Name: "someone/setup-nodes", Version: "v4", File: "ci.yml", Line: 11},
+		// Resembles actions/cache, but isn't a fork
+		{Name: "acme/cache", Version: "v1", File: "ci.yml"},
+		// A fork, but of a name nothing like it
+		{Name: "other/build-tool", Version: "v1", File: "ci.yml"},
+		{Name: "actlons/setup-go", Version: "v5", File: "ci.yml", Line: 14},
+		{Name: "gone/checkout", Version: "v4", File: "ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client)).SuspiciousForks(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct {
+		name, resembles, message string
+		line                     int
+	}{
+		{"evil/checkout", "actions/checkout", "evil/checkout is a fork of actions/checkout; review what it changes, or use actions/checkout", 9},
+		{"someone/setup-nodes", "actions/setup-node", "someone/setup-nodes resembles actions/setup-node and is a fork of other/node-setup; make sure it's the action you meant", 11},
+		{"actlons/setup-go", "actions/setup-go", "actlons/setup-go resembles actions/setup-go and is a fork; make sure it's the action you meant", 14},
+	}
+	if len(result.Forks) != len(want) {
+		t.Fatalf("expected %d forks, got %+v", len(want), result.Forks)
+	}
+	for i, w := range want {
+		f := result.Forks[i]
+		if f.Name != w.name || f.Resembles != w.resembles || f.Line != w.line || f.Message() != w.message {
+			t.Errorf("fork %d: expected %s resembling %s on line %d, got %+v: %s", i, w.name, w.resembles, w.line, f, f.Message())
+		}
+	}
+	// The inaccessible lookalike is warned about
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", result.Warnings)
+	}
+	// Only the lookalikes are looked up
+	if calls := client.calls.Load(); calls != 5 {
+		t.Errorf("expected 5 lookups, got %d", calls)
+	}
+}
@@ -0,0 +1,776 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aver/pkg/auth"
+	"aver/pkg/cache"
+)
+
+// DefaultBaseURL is the root of the public GitHub REST API
+const DefaultBaseURL = "https://api.github.com"
+
+// DefaultMaxPages caps how many pages of tags or releases are fetched per
+// repository
+const DefaultMaxPages = 10
+
+// perPage is the page size for list endpoints, the most GitHub allows
+const perPage = 100
+
+// giteaPageSize is the most Gitea and Forgejo serve per page by default
+const giteaPageSize = 50
+
+// giteaBranch is a branch from the Gitea API
+type giteaBranch struct {
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// NormalizeBaseURL turns a GitHub API location as a user might write it into
+// an API root. A GitHub Enterprise Server hostname such as
+// "ghes.example.com" becomes "https://ghes.example.com/api/v3", and
+// "github.com" the public API.
+func NormalizeBaseURL(raw string) (string, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid API URL %q: %w", raw, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid API URL %q: missing host", raw)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if u.Path == "" && (u.Host == "github.com" || u.Host == "www.github.com") {
+		return DefaultBaseURL, nil
+	}
+	if u.Path == "" && u.Host != "api.github.com" {
+		u.Path = "/api/v3"
+	}
+	return u.String(), nil
+}
+
+// WebURL returns the web root corresponding to an API root, e.g.
+// "https://ghes.example.com" for "https://ghes.example.com/api/v3"
+func WebURL(baseURL string) string {
+	if baseURL == "" || baseURL == DefaultBaseURL {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/api/v3")
+}
+
+// NewTransport returns an HTTP transport for talking to GitHub through
+// corporate networks. Like http.DefaultTransport it honors HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY. If caFile is set, the PEM certificates in it are
+// trusted in addition to the system roots, e.g. for a TLS-intercepting
+// proxy. If insecure is set, certificates aren't verified at all.
+func NewTransport(caFile string, insecure bool) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificates: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// GitHubClient is the set of GitHub API calls aver needs to check actions.
+// Implementations must be safe for concurrent use.
+type GitHubClient interface {
+	// Tags returns the tags of a repository
+	Tags(ctx context.Context, repo string) ([]GitHubTag, error)
+	// Releases returns the releases of a repository, newest first
+	Releases(ctx context.Context, repo string) ([]GitHubRelease, error)
+	// DefaultBranch returns the name of a repository's default branch
+	DefaultBranch(ctx context.Context, repo string) (string, error)
+	// BranchHead returns the SHA at the tip of a branch
+	BranchHead(ctx context.Context, repo, branch string) (string, error)
+	// CompareCommits returns how many commits head is ahead of base
+	CompareCommits(ctx context.Context, repo, base, head string) (int, error)
+	// CommitDate returns when the commit a ref points to was committed
+	CommitDate(ctx context.Context, repo, ref string) (time.Time, error)
+	// Workflows returns the workflow files of a repository at ref, or on
+	// its default branch if ref is empty
+	Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error)
+}
+
+// HTTPClient is a GitHubClient backed by the GitHub REST API
+type HTTPClient struct {
+	BaseURL string           // API root, defaults to DefaultBaseURL
+	Token   string           // Optional token; requests are unauthenticated if empty
+	Tokens  auth.TokenSource // Optional; takes precedence over Token, e.g. for GitHub Apps
+	Client  *http.Client     // Defaults to http.DefaultClient
+	Cache   *cache.Cache     // Optional on-disk cache of successful responses
+	Logger  *slog.Logger     // Optional log of requests (Debug) and cache lookups (Info)
+	Offline bool             // Answer only from Cache, however old; misses return ErrNotCached
+	Refresh bool             // Ignore cached responses but still store fresh ones
+	Usage   *Usage           // Optional count of requests and cache hits, e.g. shared by several clients
+	Capped  bool             // Usage.Max applies to this client's requests
+
+	// MaxPages caps how many pages of tags or releases are fetched per
+	// repository. Defaults to DefaultMaxPages.
+	MaxPages int
+
+	// Gitea speaks the Gitea/Forgejo API (BaseURL ending in /api/v1), which
+	// mostly mirrors GitHub's but differs for branches, comparisons and
+	// page sizes
+	Gitea bool
+
+	mu     sync.Mutex
+	rate   RateLimit // As of the last response with rate limit headers
+	scopes *string   // X-OAuth-Scopes of the last response that had it
+}
+
+// RateLimit is the request budget of an API host
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// NewHTTPClient returns a client for the public GitHub API. If token is
+// empty, requests are unauthenticated and limited to 60 per hour.
+func NewHTTPClient(token string) *HTTPClient {
+	return &HTTPClient{BaseURL: DefaultBaseURL, Token: token}
+}
+
+func (c *HTTPClient) cacheHit(key string) {
+	if c.Usage != nil {
+		c.Usage.record(key, true)
+	}
+}
+
+func (c *HTTPClient) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return c.Logger
+}
+
+// get fetches path from the API and decodes the JSON response into v. It
+// returns the status code, which is valid whenever the request completed,
+// and the Link header used for pagination.
+func (c *HTTPClient) get(ctx context.Context, path string, v any) (int, string, error) {
+	reqURL := c.baseURL() + path
+	return c.cached(ctx, reqURL, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	}, v)
+}
+
+// query POSTs a JSON body that only reads data, such as a GraphQL query,
+// and decodes the response into v. Responses are cached like get's, keyed
+// by the URL and body.
+func (c *HTTPClient) query(ctx context.Context, path string, in, v any) (int, error) {
+	reqURL := c.baseURL() + path
+	data, err := json.Marshal(in)
+	if err != nil {
+		return 0, err
+	}
+	status, _, err := c.cached(ctx, reqURL+" "+string(data), func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", reqURL, bytes.NewReader(data))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, err
+	}, v)
+	return status, err
+}
+
+// cached answers a request from the cache under key if it can, and
+// otherwise makes it with newRequest and caches a successful response
+func (c *HTTPClient) cached(ctx context.Context, key string, newRequest func() (*http.Request, error), v any) (int, string, error) {
+	logger := c.logger()
+
+	if c.Offline {
+		if c.Cache != nil {
+			if e, ok := c.Cache.Lookup(key); ok {
+				logger.Info("cache hit", "url", key, "offline", true, "age", time.Since(e.FetchedAt).Round(time.Second))
+				c.cacheHit(key)
+				return c.decodeCached(e, v)
+			}
+		}
+		logger.Info("cache miss", "url", key, "offline", true)
+		return 0, "", &ErrNotCached{URL: key}
+	}
+
+	if c.Cache != nil && !c.Refresh {
+		if e, ok := c.Cache.Get(key); ok {
+			logger.Info("cache hit", "url", key)
+			c.cacheHit(key)
+			return c.decodeCached(e, v)
+		}
+		logger.Info("cache miss", "url", key)
+	}
+
+	if c.Capped && c.Usage != nil && !c.Usage.spend() {
+		logger.Info("request budget exhausted", "url", key, "max", c.Usage.Max)
+		return 0, "", &ErrBudgetExhausted{Max: c.Usage.Max}
+	}
+	req, err := newRequest()
+	if err != nil {
+		return 0, "", err
+	}
+
+	status, body, link, err := c.do(ctx, req, http.StatusOK)
+	if err != nil {
+		return status, "", err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return status, "", &ErrParse{Source: req.URL.String(), Err: err}
+	}
+
+	if c.Cache != nil {
+		// A cache write failure only costs us a future API call
+		_ = c.Cache.Put(cache.Entry{Key: key, Body: body, Link: link})
+	}
+	return status, link, nil
+}
+
+// fresh reports whether get would answer path from the cache, without
+// asking the API, and decodes the cached response into v if v isn't nil
+func (c *HTTPClient) fresh(path string, v any) bool {
+	if c.Cache == nil || c.Refresh {
+		return false
+	}
+	e, ok := c.Cache.Get(c.baseURL() + path)
+	if !ok {
+		return false
+	}
+	if v != nil {
+		if _, _, err := c.decodeCached(e, v); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// send makes an uncached request with an optional JSON body, for calls
+// that change something or must see the latest state, and decodes the
+// response into v
+func (c *HTTPClient) send(ctx context.Context, method, path string, in, v any) (int, string, error) {
+	if c.Offline {
+		return 0, "", fmt.Errorf("can't %s %s in offline mode", method, path)
+	}
+	reqURL := c.baseURL() + path
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, "", err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return 0, "", err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	status, body, link, err := c.do(ctx, req, http.StatusOK, http.StatusCreated)
+	if err != nil {
+		return status, "", err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return status, "", &ErrParse{Source: reqURL, Err: err}
+	}
+	return status, link, nil
+}
+
+// do authorizes and sends req, returning the body of responses with one of
+// the accepted statuses and an error for any other
+func (c *HTTPClient) do(ctx context.Context, req *http.Request, accepted ...int) (int, []byte, string, error) {
+	logger := c.logger()
+	reqURL := req.URL.String()
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	token := c.Token
+	if c.Tokens != nil {
+		var err error
+		if token, err = c.Tokens.Token(ctx); err != nil {
+			return 0, nil, "", err
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	logger.Debug("api request", "method", req.Method, "url", reqURL)
+	if c.Usage != nil {
+		c.Usage.record(reqURL, false)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Debug("api request failed", "url", reqURL, "error", err)
+		if ctx.Err() != nil {
+			return 0, nil, "", ctx.Err()
+		}
+		return 0, nil, "", &ErrNetwork{Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("api response",
+		"url", reqURL,
+		"status", resp.StatusCode,
+		"ratelimit_limit", resp.Header.Get("X-RateLimit-Limit"),
+		"ratelimit_remaining", resp.Header.Get("X-RateLimit-Remaining"),
+		"ratelimit_reset", resp.Header.Get("X-RateLimit-Reset"))
+
+	c.recordRateLimit(resp)
+	body, err := io.ReadAll(resp.Body)
+	if !slices.Contains(accepted, resp.StatusCode) {
+		// The body of an error says why, which tells a rate limit from a
+		// repository the token can't read
+		message := errorMessage(body)
+		if err := rateLimitError(resp, message); err != nil {
+			return resp.StatusCode, nil, "", err
+		}
+		return resp.StatusCode, nil, "", &statusError{
+			Status:       resp.StatusCode,
+			Message:      message,
+			SSOURL:       ssoURL(resp.Header),
+			MissingScope: missingScope(resp.Header),
+		}
+	}
+	if err != nil {
+		return resp.StatusCode, nil, "", &ErrNetwork{Err: err}
+	}
+	return resp.StatusCode, body, resp.Header.Get("Link"), nil
+}
+
+// statusError is a response with a status the caller didn't expect
+type statusError struct {
+	Status       int
+	Message      string // The message in the body, if any
+	SSOURL       string // From X-GitHub-SSO, as in ErrRepoNotAccessible
+	MissingScope string // As in ErrRepoNotAccessible
+}
+
+func (e *statusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("GitHub API returned status %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("GitHub API returned status %d", e.Status)
+}
+
+// ssoURL returns the authorization URL of an X-GitHub-SSO header like
+// "required; url=https://github.com/orgs/acme/sso?authorization_request=...",
+// which GitHub sends when SAML single sign-on hasn't authorized the token
+func ssoURL(header http.Header) string {
+	status, params, _ := strings.Cut(header.Get("X-GitHub-SSO"), ";")
+	if strings.TrimSpace(status) != "required" {
+		return ""
+	}
+	for param := range strings.SplitSeq(params, ";") {
+		if url, ok := strings.CutPrefix(strings.TrimSpace(param), "url="); ok {
+			return url
+		}
+	}
+	return ""
+}
+
+// missingScope returns "repo" if the token is a classic one, which lists
+// its scopes in X-OAuth-Scopes, without the repo scope private
+// repositories need. Other tokens don't send the header.
+func missingScope(header http.Header) string {
+	scopes, ok := header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !ok {
+		return ""
+	}
+	for _, list := range scopes {
+		for scope := range strings.SplitSeq(list, ",") {
+			if strings.TrimSpace(scope) == "repo" {
+				return ""
+			}
+		}
+	}
+	return "repo"
+}
+
+// errorMessage returns the message of an API error body, like
+// {"message": "Not Found"}, or "" if it has none
+func errorMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	return strings.TrimSpace(e.Message)
+}
+
+func (c *HTTPClient) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return c.BaseURL
+}
+
+// pageSize is the query parameter for the largest page the API serves
+func (c *HTTPClient) pageSize() string {
+	if c.Gitea {
+		return fmt.Sprintf("limit=%d", giteaPageSize)
+	}
+	return fmt.Sprintf("per_page=%d", perPage)
+}
+
+func (c *HTTPClient) maxPages() int {
+	if c.MaxPages == 0 {
+		return DefaultMaxPages
+	}
+	return c.MaxPages
+}
+
+func (c *HTTPClient) decodeCached(e cache.Entry, v any) (int, string, error) {
+	if err := json.Unmarshal(e.Body, v); err != nil {
+		return http.StatusOK, "", &ErrParse{Source: e.Key, Err: err}
+	}
+	return http.StatusOK, e.Link, nil
+}
+
+// getPages fetches the pages of a list endpoint by following Link headers,
+// stopping after maxPages pages unless maxPages is 0
+func getPages[T any](ctx context.Context, c *HTTPClient, path string, maxPages int) ([]T, int, error) {
+	var all []T
+	for page := 0; path != "" && (maxPages == 0 || page < maxPages); page++ {
+		var items []T
+		status, link, err := c.get(ctx, path, &items)
+		if err != nil {
+			return nil, status, err
+		}
+		all = append(all, items...)
+
+		path = ""
+		if next := nextPage(link); next != "" {
+			// Only follow links back to the same API
+			path, _ = strings.CutPrefix(next, c.baseURL())
+			if path == next {
+				path = ""
+			}
+		}
+	}
+	return all, http.StatusOK, nil
+}
+
+// nextPage returns the rel="next" URL from a Link header, if any
+func nextPage(link string) string {
+	for part := range strings.SplitSeq(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok {
+			continue
+		}
+		for param := range strings.SplitSeq(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// RateLimit returns the request budget reported by the last API response.
+// It's false until a response carried rate limit headers, e.g. while
+// everything comes from the cache or if the host doesn't limit requests.
+func (c *HTTPClient) RateLimit() (RateLimit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rate, c.rate.Limit > 0
+}
+
+func (c *HTTPClient) recordRateLimit(resp *http.Response) {
+	if scopes, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		joined := strings.Join(scopes, ",")
+		c.mu.Lock()
+		c.scopes = &joined
+		c.mu.Unlock()
+	}
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rate = RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+}
+
+// rateLimitError returns ErrRateLimited if resp was refused because the rate
+// limit is exhausted or, with a Retry-After header or a message saying so,
+// because of the secondary rate limit. message is the error body's. Any
+// other 403 means the token may not read the repository.
+func rateLimitError(resp *http.Response, message string) error {
+	exhausted := resp.Header.Get("X-RateLimit-Remaining") == "0"
+	retryAfter := resp.Header.Get("Retry-After")
+	mentioned := strings.Contains(strings.ToLower(message), "rate limit")
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && (exhausted || retryAfter != "" || mentioned):
+	default:
+		return nil
+	}
+
+	secondary := retryAfter != "" || strings.Contains(strings.ToLower(message), "secondary rate limit")
+	err := &ErrRateLimited{Secondary: !exhausted && secondary}
+	if seconds, perr := strconv.Atoi(retryAfter); perr == nil {
+		err.Reset = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else if reset, perr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); perr == nil {
+		err.Reset = time.Unix(reset, 0)
+	}
+	return err
+}
+
+// notAccessible converts 404 and 403 responses into ErrRepoNotAccessible,
+// keeping the API's explanation of a 403
+func notAccessible(repo string, status int, err error) error {
+	var rateLimited *ErrRateLimited
+	if errors.As(err, &rateLimited) {
+		return err
+	}
+	if status == http.StatusNotFound || status == http.StatusForbidden {
+		e := &ErrRepoNotAccessible{Repo: repo, Status: status}
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			e.SSOURL, e.MissingScope = statusErr.SSOURL, statusErr.MissingScope
+			if status == http.StatusForbidden {
+				e.Message = statusErr.Message
+			}
+		}
+		return e
+	}
+	return err
+}
+
+// The paths of the calls the Checker makes, which Estimate looks up in the
+// cache too
+
+func (c *HTTPClient) tagsPath(repo string) string {
+	return fmt.Sprintf("/repos/%s/tags?%s", repo, c.pageSize())
+}
+
+func (c *HTTPClient) releasesPath(repo string) string {
+	return fmt.Sprintf("/repos/%s/releases?%s", repo, c.pageSize())
+}
+
+func repoPath(repo string) string {
+	return "/repos/" + repo
+}
+
+func (c *HTTPClient) branchPath(repo, branch string) string {
+	if c.Gitea {
+		return fmt.Sprintf("/repos/%s/branches/%s", repo, url.PathEscape(branch))
+	}
+	return fmt.Sprintf("/repos/%s/git/ref/heads/%s", repo, branch)
+}
+
+func comparePath(repo, base, head string) string {
+	return fmt.Sprintf("/repos/%s/compare/%s...%s", repo, base, head)
+}
+
+// Tags fetches the tags of a repository, up to MaxPages pages
+func (c *HTTPClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	tags, status, err := getPages[GitHubTag](ctx, c, c.tagsPath(repo), c.maxPages())
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	return tags, nil
+}
+
+// Releases fetches the releases of a repository, up to MaxPages pages
+func (c *HTTPClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	releases, status, err := getPages[GitHubRelease](ctx, c, c.releasesPath(repo), c.maxPages())
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	return releases, nil
+}
+
+// DefaultBranch fetches the default branch of a repository
+func (c *HTTPClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	repoInfo, err := c.Repository(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	return repoInfo.DefaultBranch, nil
+}
+
+// Repository fetches a repository's information, including its owner
+func (c *HTTPClient) Repository(ctx context.Context, repo string) (GitHubRepo, error) {
+	var repoInfo GitHubRepo
+	status, _, err := c.get(ctx, repoPath(repo), &repoInfo)
+	if err != nil {
+		return GitHubRepo{}, notAccessible(repo, status, err)
+	}
+	return repoInfo, nil
+}
+
+// BranchHead fetches the SHA at the tip of a branch
+func (c *HTTPClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	if c.Gitea {
+		var b giteaBranch
+		status, _, err := c.get(ctx, c.branchPath(repo, branch), &b)
+		if status == http.StatusNotFound {
+			return "", &ErrRefNotFound{Repo: repo, Ref: branch}
+		}
+		if err != nil {
+			return "", err
+		}
+		return b.Commit.ID, nil
+	}
+
+	var ref GitHubRef
+	status, _, err := c.get(ctx, c.branchPath(repo, branch), &ref)
+	if status == http.StatusNotFound {
+		return "", &ErrRefNotFound{Repo: repo, Ref: branch}
+	}
+	if err != nil {
+		return "", err
+	}
+	return ref.Object.SHA, nil
+}
+
+// CompareCommits returns how many commits head is ahead of base
+func (c *HTTPClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	var compare GitHubCompare
+	status, _, err := c.get(ctx, comparePath(repo, base, head), &compare)
+	if status == http.StatusNotFound {
+		return 0, &ErrRefNotFound{Repo: repo, Ref: base}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if c.Gitea {
+		// Gitea has no ahead_by; the commits compared are those in head
+		// that aren't in base
+		return compare.TotalCommits, nil
+	}
+	return compare.AheadBy, nil
+}
+
+// CommitDate returns when the commit a ref points to was committed
+func (c *HTTPClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	path := fmt.Sprintf("/repos/%s/commits/%s", repo, url.PathEscape(ref))
+	if c.Gitea {
+		path = fmt.Sprintf("/repos/%s/git/commits/%s", repo, url.PathEscape(ref))
+	}
+	var commit GitHubCommit
+	status, _, err := c.get(ctx, path, &commit)
+	if status == http.StatusNotFound || status == http.StatusUnprocessableEntity {
+		return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.Commit.Committer.Date, nil
+}
+
+// Workflows fetches the workflow files of a repository at ref, or on its
+// default branch if ref is empty
+func (c *HTTPClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	query := ""
+	if ref != "" {
+		query = "?ref=" + url.QueryEscape(ref)
+	}
+	var entries []GitHubContent
+	status, _, err := c.get(ctx, fmt.Sprintf("/repos/%s/contents/.github/workflows%s", repo, query), &entries)
+	if status == http.StatusNotFound {
+		// The repository, the ref or the workflows directory is missing
+		if _, err := c.DefaultBranch(ctx, repo); err != nil {
+			return nil, err
+		}
+		if ref != "" {
+			if _, err := c.CommitDate(ctx, repo, ref); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+
+	var files []WorkflowFile
+	for _, entry := range entries {
+		if entry.Type != "file" || (!strings.HasSuffix(entry.Name, ".yml") && !strings.HasSuffix(entry.Name, ".yaml")) {
+			continue
+		}
+		var file GitHubContent
+		if _, _, err := c.get(ctx, fmt.Sprintf("/repos/%s/contents/%s%s", repo, entry.Path, query), &file); err != nil {
+			return nil, err
+		}
+		content, err := decodeContent(entry.Path, file)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, WorkflowFile{Path: entry.Path, Content: content})
+	}
+	return files, nil
+}
+
+// File returns the content of the file at path in repo at ref, or on the
+// default branch if ref is empty. It returns nil if there's no such file.
+func (c *HTTPClient) File(ctx context.Context, repo, path, ref string) ([]byte, error) {
+	query := ""
+	if ref != "" {
+		query = "?ref=" + url.QueryEscape(ref)
+	}
+	var file GitHubContent
+	status, _, err := c.get(ctx, fmt.Sprintf("/repos/%s/contents/%s%s", repo, path, query), &file)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	return decodeContent(path, file)
+}
+
+// decodeContent returns the content of a file from the contents API
+func decodeContent(path string, file GitHubContent) ([]byte, error) {
+	if file.Encoding != "base64" {
+		return nil, &ErrParse{Source: path, Err: fmt.Errorf("unexpected encoding %q", file.Encoding)}
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return nil, &ErrParse{Source: path, Err: err}
+	}
+	return content, nil
+}
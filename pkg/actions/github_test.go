@@ -0,0 +1,507 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"aver/pkg/cache"
+)
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		switch r.URL.Path {
+		case "/repos/actions/checkout/tags":
+			_, _ = w.Write([]byte(`[{"name": "v5"}, {"name": "v4"}]`))
+		case "/repos/actions/checkout/releases":
+			_, _ = w.Write([]byte(`[{"tag_name": "v5.0.0", "prerelease": true, "published_at": "2025-08-11T12:00:00Z"}]`))
+		case "/repos/actions/checkout":
+			_, _ = w.Write([]byte(`{"default_branch": "main", "owner": {"login": "actions", "type": "Organization"}}`))
+		case "/repos/actions/checkout/git/ref/heads/main":
+			_, _ = w.Write([]byte(`{"object": {"sha": "abc123"}}`))
+		case "/repos/actions/checkout/compare/def456...main":
+			_, _ = w.Write([]byte(`{"ahead_by": 4, "behind_by": 0}`))
+		case "/repos/actions/checkout/commits/v5":
+			_, _ = w.Write([]byte(`{"sha": "abc123", "commit": {"committer": {"date": "2025-08-11T12:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	tags, err := client.Tags(ctx, "actions/checkout")
+	if err != nil || len(tags) != 2 || tags[0].Name != "v5" {
+		t.Errorf("Tags: got %v, %v", tags, err)
+	}
+
+	releases, err := client.Releases(ctx, "actions/checkout")
+	if err != nil || len(releases) != 1 || releases[0].TagName != "v5.0.0" || !releases[0].Prerelease || releases[0].PublishedAt.IsZero() {
+		t.Errorf("Releases: got %+v, %v", releases, err)
+	}
+
+	branch, err := client.DefaultBranch(ctx, "actions/checkout")
+	if err != nil || branch != "main" {
+		t.Errorf("DefaultBranch: got %q, %v", branch, err)
+	}
+
+	repo, err := client.Repository(ctx, "actions/checkout")
+	if err != nil || repo.Owner.Type != OwnerOrganization {
+		t.Errorf("Repository: got %+v, %v", repo, err)
+	}
+
+	sha, err := client.BranchHead(ctx, "actions/checkout", "main")
+	if err != nil || sha != "abc123" {
+		t.Errorf("BranchHead: got %q, %v", sha, err)
+	}
+
+	behind, err := client.CompareCommits(ctx, "actions/checkout", "def456", "main")
+	if err != nil || behind != 4 {
+		t.Errorf("CompareCommits: got %d, %v", behind, err)
+	}
+
+	date, err := client.CommitDate(ctx, "actions/checkout", "v5")
+	if err != nil || !date.Equal(time.Date(2025, 8, 11, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("CommitDate: got %v, %v", date, err)
+	}
+
+	_, err = client.BranchHead(ctx, "actions/checkout", "gone")
+	if !errors.Is(err, &ErrRefNotFound{}) {
+		t.Errorf("expected ErrRefNotFound, got %v", err)
+	}
+
+	_, err = client.Tags(ctx, "missing/repo")
+	var notAccessible *ErrRepoNotAccessible
+	if !errors.As(err, &notAccessible) || notAccessible.Status != 404 {
+		t.Errorf("expected ErrRepoNotAccessible, got %v", err)
+	}
+}
+
+func TestHTTPClientRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+
+	_, err := client.Tags(context.Background(), "actions/checkout")
+	var rateLimited *ErrRateLimited
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if rateLimited.Reset.Unix() != 1700000000 {
+		t.Errorf("unexpected reset time %v", rateLimited.Reset)
+	}
+}
+
+func TestHTTPClientForbidden(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    map[string]string
+		body      string
+		limited   bool
+		secondary bool
+		message   string
+	}{
+		{name: "secondary with Retry-After", header: map[string]string{"Retry-After": "60", "X-RateLimit-Remaining": "4000"},
+			body: `{"message": "You have exceeded a secondary rate limit."}`, limited: true, secondary: true},
+		{name: "secondary by message", body: `{"message": "You have exceeded a secondary rate limit and have been temporarily blocked"}`,
+			limited: true, secondary: true},
+		{name: "primary by message", body: `{"message": "API rate limit exceeded for 203.0.113.1."}`, limited: true},
+		{name: "SAML", header: map[string]string{"X-RateLimit-Remaining": "4999"},
+			body:    `{"message": "Resource protected by organization SAML enforcement."}`,
+			message: "Resource protected by organization SAML enforcement."},
+		{name: "no body", body: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient("")
+			client.BaseURL = server.URL
+
+			_, err := client.Tags(context.Background(), "acme/private")
+			var rateLimited *ErrRateLimited
+			var notAccessible *ErrRepoNotAccessible
+			switch {
+			case tt.limited:
+				if !errors.As(err, &rateLimited) || rateLimited.Secondary != tt.secondary {
+					t.Fatalf("expected ErrRateLimited with Secondary %v, got %v", tt.secondary, err)
+				}
+			case !errors.As(err, &notAccessible):
+				t.Fatalf("expected ErrRepoNotAccessible, got %v", err)
+			case notAccessible.Message != tt.message:
+				t.Errorf("expected message %q, got %q", tt.message, notAccessible.Message)
+			}
+		})
+	}
+}
+
+func TestHTTPClientPrivateRepo(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		header       map[string]string
+		ssoURL       string
+		missingScope string
+	}{
+		{name: "SAML SSO", status: http.StatusForbidden,
+			header: map[string]string{"X-GitHub-SSO": "required; url=https://github.com/orgs/acme/sso?authorization_request=abc", "X-OAuth-Scopes": "repo"},
+			ssoURL: "https://github.com/orgs/acme/sso?authorization_request=abc"},
+		{name: "classic token without repo", status: http.StatusNotFound,
+			header: map[string]string{"X-OAuth-Scopes": "public_repo, read:org"}, missingScope: "repo"},
+		{name: "classic token with repo", status: http.StatusNotFound,
+			header: map[string]string{"X-OAuth-Scopes": "read:org, repo"}},
+		{name: "classic token without scopes", status: http.StatusNotFound,
+			header: map[string]string{"X-OAuth-Scopes": ""}, missingScope: "repo"},
+		{name: "fine-grained token", status: http.StatusNotFound},
+		{name: "partial SSO results", status: http.StatusNotFound,
+			header: map[string]string{"X-GitHub-SSO": "partial-results; organizations=21955855"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient("")
+			client.BaseURL = server.URL
+
+			_, err := client.Tags(context.Background(), "acme/private")
+			var notAccessible *ErrRepoNotAccessible
+			if !errors.As(err, &notAccessible) {
+				t.Fatalf("expected ErrRepoNotAccessible, got %v", err)
+			}
+			if notAccessible.SSOURL != tt.ssoURL || notAccessible.MissingScope != tt.missingScope {
+				t.Errorf("expected SSO URL %q and missing scope %q, got %+v", tt.ssoURL, tt.missingScope, notAccessible)
+			}
+		})
+	}
+}
+
+func TestHTTPClientNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+	server.Close()
+
+	_, err := client.Tags(context.Background(), "actions/checkout")
+	if !errors.Is(err, &ErrNetwork{}) {
+		t.Errorf("expected ErrNetwork, got %v", err)
+	}
+}
+
+func TestHTTPClientDebugLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		_, _ = w.Write([]byte(`[{"name": "v1"}]`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+	client.Cache = cache.New(t.TempDir(), time.Hour)
+	client.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	for range 2 {
+		if _, err := client.Tags(context.Background(), "actions/checkout"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	log := buf.String()
+	for _, want := range []string{"cache miss", "api request", "status=200", "ratelimit_remaining=59", "cache hit"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected log to contain %q, got:\n%s", want, log)
+		}
+	}
+	if n := strings.Count(log, "api request"); n != 1 {
+		t.Errorf("expected 1 API request, got %d", n)
+	}
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://api.github.com", "https://api.github.com"},
+		{"api.github.com", "https://api.github.com"},
+		{"github.com", "https://api.github.com"},
+		{"https://www.github.com/", "https://api.github.com"},
+		{"ghes.example.com", "https://ghes.example.com/api/v3"},
+		{"https://ghes.example.com/", "https://ghes.example.com/api/v3"},
+		{"https://ghes.example.com/api/v3/", "https://ghes.example.com/api/v3"},
+		{"http://localhost:8080/api", "http://localhost:8080/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := NormalizeBaseURL(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := NormalizeBaseURL("https://"); err == nil {
+		t.Error("expected error for URL without host")
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "https://github.com"},
+		{DefaultBaseURL, "https://github.com"},
+		{"https://ghes.example.com/api/v3", "https://ghes.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := WebURL(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"name": "v1"}]`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tags := func(caFile string, insecure bool) error {
+		transport, err := NewTransport(caFile, insecure)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client := NewHTTPClient("")
+		client.BaseURL = server.URL
+		client.Client = &http.Client{Transport: transport}
+		_, err = client.Tags(context.Background(), "actions/checkout")
+		return err
+	}
+
+	if err := tags("", false); err == nil {
+		t.Error("expected a certificate error without the CA")
+	}
+	if err := tags(caFile, false); err != nil {
+		t.Errorf("unexpected error with the CA: %v", err)
+	}
+	if err := tags("", true); err != nil {
+		t.Errorf("unexpected error with verification off: %v", err)
+	}
+
+	if _, err := NewTransport(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTransport(empty, false); err == nil {
+		t.Error("expected an error for a file without certificates")
+	}
+}
+
+func TestHTTPClientRefresh(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(`[{"name": "v1"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name": "v1"}, {"name": "v2"}]`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+	client.Cache = cache.New(t.TempDir(), time.Hour)
+	if _, err := client.Tags(context.Background(), "actions/checkout"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client.Refresh = true
+	tags, err := client.Tags(context.Background(), "actions/checkout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || requests.Load() != 2 {
+		t.Fatalf("expected a fresh response, got %v after %d requests", tags, requests.Load())
+	}
+
+	// The fresh response replaced the cached one
+	client.Refresh = false
+	if tags, _ = client.Tags(context.Background(), "actions/checkout"); len(tags) != 2 || requests.Load() != 2 {
+		t.Errorf("expected the refreshed entry from the cache, got %v after %d requests", tags, requests.Load())
+	}
+}
+
+func TestHTTPClientPagination(t *testing.T) {
+	var requests atomic.Int64
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=%d>; rel="next", <%s%s?per_page=100&page=3>; rel="last"`,
+				server.URL, r.URL.Path, page+1, server.URL, r.URL.Path))
+		}
+		_, _ = fmt.Fprintf(w, `[{"name": "v%d"}]`, page)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+	client.Cache = cache.New(t.TempDir(), time.Hour)
+
+	tags, err := client.Tags(context.Background(), "actions/runner")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 3 || tags[2].Name != "v3" {
+		t.Errorf("expected tags from all 3 pages, got %v", tags)
+	}
+
+	// Pages are followed from the cache too
+	tags, err = client.Tags(context.Background(), "actions/runner")
+	if err != nil || len(tags) != 3 || requests.Load() != 3 {
+		t.Errorf("expected 3 cached tags and no new requests, got %v, %v after %d requests", tags, err, requests.Load())
+	}
+
+	client.Cache = nil
+	client.MaxPages = 2
+	if tags, _ = client.Tags(context.Background(), "actions/runner"); len(tags) != 2 {
+		t.Errorf("expected MaxPages to stop after 2 pages, got %v", tags)
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected string
+	}{
+		{"", ""},
+		{`<https://api.github.com/repos/a/b/tags?page=2>; rel="next", <https://api.github.com/repos/a/b/tags?page=5>; rel="last"`, "https://api.github.com/repos/a/b/tags?page=2"},
+		{`<https://api.github.com/repos/a/b/tags?page=1>; rel="prev", <https://api.github.com/repos/a/b/tags?page=1>; rel="first"`, ""},
+	}
+
+	for _, tt := range tests {
+		if result := nextPage(tt.link); result != tt.expected {
+			t.Errorf("nextPage(%q): expected %q, got %q", tt.link, tt.expected, result)
+		}
+	}
+}
+
+func TestHTTPClientWorkflows(t *testing.T) {
+	workflow := base64.StdEncoding.EncodeToString([]byte("jobs:\n  test:\n    steps:\n      - uses: actions/checkout@v4\n"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Files are read at the requested ref
+		if ref := r.URL.Query().Get("ref"); ref != "" && ref != "v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/owner/app":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		case "/repos/owner/app/contents/.github/workflows":
+			_, _ = w.Write([]byte(`[
+				{"name": "ci.yml", "path": ".github/workflows/ci.yml", "type": "file"},
+				{"name": "README.md", "path": ".github/workflows/README.md", "type": "file"},
+				{"name": "shared", "path": ".github/workflows/shared", "type": "dir"}
+			]`))
+		case "/repos/owner/app/contents/.github/workflows/ci.yml":
+			// The API wraps base64 content at 60 characters
+			fmt.Fprintf(w, `{"name": "ci.yml", "encoding": "base64", "content": %q}`, workflow[:20]+"\n"+workflow[20:])
+		case "/repos/owner/empty":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	files, err := client.Workflows(ctx, "owner/app", "")
+	if err != nil || len(files) != 1 || files[0].Path != ".github/workflows/ci.yml" {
+		t.Fatalf("unexpected workflows: %+v, %v", files, err)
+	}
+	if files, err := client.Workflows(ctx, "owner/app", "v1"); err != nil || len(files) != 1 {
+		t.Errorf("unexpected workflows at v1: %+v, %v", files, err)
+	}
+	if _, err := client.Workflows(ctx, "owner/app", "nope"); !errors.Is(err, &ErrRefNotFound{}) {
+		t.Errorf("expected ErrRefNotFound for a missing ref, got %v", err)
+	}
+	refs, err := ParseWorkflow(files[0].Path, files[0].Content)
+	if err != nil || len(refs) != 1 || refs[0].Name != "actions/checkout" {
+		t.Errorf("unexpected references: %+v, %v", refs, err)
+	}
+
+	if files, err := client.Workflows(ctx, "owner/empty", ""); err != nil || len(files) != 0 {
+		t.Errorf("expected no workflows, got %+v, %v", files, err)
+	}
+	if _, err := client.Workflows(ctx, "owner/missing", ""); !errors.Is(err, &ErrRepoNotAccessible{}) {
+		t.Errorf("expected ErrRepoNotAccessible, got %v", err)
+	}
+
+	content, err := client.File(ctx, "owner/app", ".github/workflows/ci.yml", "v1")
+	if err != nil || !bytes.Equal(content, files[0].Content) {
+		t.Errorf("unexpected file: %q, %v", content, err)
+	}
+	if content, err := client.File(ctx, "owner/app", "action.yml", ""); err != nil || content != nil {
+		t.Errorf("expected no content for a missing file, got %q, %v", content, err)
+	}
+}
@@ -0,0 +1,255 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitLabCIFile is the pipeline configuration at the root of a GitLab project
+const GitLabCIFile = ".gitlab-ci.yml"
+
+// DefaultGitLabHost serves components named after $CI_SERVER_FQDN when the
+// GitLab instance isn't known
+const DefaultGitLabHost = "gitlab.com"
+
+// gitlabHost returns the host of a GitLab component name
+// ("gitlab.com/components/sast/sast") or of its project
+// ("gitlab.com/components/sast"). GitHub owners can't contain dots, so a
+// first segment that looks like a hostname is a GitLab instance.
+func gitlabHost(name string) (string, bool) {
+	if strings.Contains(name, "://") {
+		return "", false
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) < 3 || !strings.ContainsAny(parts[0], ".:") || slices.Contains(parts, "") {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// gitlabComponent splits a CI/CD component name into its GitLab host and
+// the path of the project it lives in. The component is the last segment;
+// projects may be nested in subgroups.
+func gitlabComponent(name string) (host, project string, ok bool) {
+	host, ok = gitlabHost(name)
+	parts := strings.Split(name, "/")
+	if !ok || len(parts) < 4 {
+		return "", "", false
+	}
+	return host, strings.Join(parts[1:len(parts)-1], "/"), true
+}
+
+// IsGitLabComponent reports whether name is a GitLab CI/CD component, such
+// as gitlab.com/components/sast/sast, rather than an action
+func IsGitLabComponent(name string) bool {
+	_, _, ok := gitlabComponent(name)
+	return ok
+}
+
+// ParseGitLabCI returns the CI/CD components a GitLab pipeline includes,
+// once per name and version:
+//
+//	include:
+//	  - component: gitlab.com/components/sast/sast@2.0.0
+//
+// Components pinned to ~latest always get the newest release and are left
+// out. file is reported as the references' File.
+func ParseGitLabCI(file string, content []byte) ([]ActionReference, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, &ErrParse{Source: file, Err: err}
+	}
+
+	refs := []ActionReference{}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return refs, nil
+	}
+	var includes []*yaml.Node
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "include" {
+			continue
+		}
+		switch value := root.Content[i+1]; value.Kind {
+		case yaml.SequenceNode:
+			includes = append(includes, value.Content...)
+		case yaml.MappingNode:
+			includes = append(includes, value)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, include := range includes {
+		if include.Kind != yaml.MappingNode {
+			continue // A local file, as a plain string
+		}
+		for i := 0; i+1 < len(include.Content); i += 2 {
+			key, value := include.Content[i], include.Content[i+1]
+			if key.Value != "component" || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			name, version, ok := strings.Cut(value.Value, "@")
+			if !ok || version == "~latest" || seen[value.Value] {
+				continue
+			}
+			seen[value.Value] = true
+			refs = append(refs, ActionReference{
+				Name:    name,
+				Version: version,
+				File:    file,
+				Line:    value.Line,
+			})
+		}
+	}
+	return refs, nil
+}
+
+// ExpandGitLabHost names the components that a pipeline includes from its
+// own instance ($CI_SERVER_FQDN/group/project/component) after host, or
+// DefaultGitLabHost if host is empty
+func ExpandGitLabHost(refs []ActionReference, host string) []ActionReference {
+	if host == "" {
+		host = DefaultGitLabHost
+	}
+	expanded := make([]ActionReference, len(refs))
+	for i, ref := range refs {
+		for _, variable := range []string{"$CI_SERVER_FQDN/", "${CI_SERVER_FQDN}/", "$CI_SERVER_HOST/", "${CI_SERVER_HOST}/"} {
+			if rest, ok := strings.CutPrefix(ref.Name, variable); ok {
+				ref.Name = host + "/" + rest
+			}
+		}
+		expanded[i] = ref
+	}
+	return expanded
+}
+
+// gitlabTag is a tag from the GitLab API
+type gitlabTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// gitlabRelease is a release from the GitLab API. Publishing a release is
+// how a component version reaches the CI/CD catalog.
+type gitlabRelease struct {
+	TagName         string    `json:"tag_name"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	ReleasedAt      time.Time `json:"released_at"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+}
+
+// gitlabCommit is a commit from the GitLab API
+type gitlabCommit struct {
+	ID            string    `json:"id"`
+	CommittedDate time.Time `json:"committed_date"`
+}
+
+// gitlabClient answers the GitHubClient calls for component projects from
+// the GitLab REST API (v4). Repositories are named host/project, as
+// repoFromAction returns them for components.
+type gitlabClient struct {
+	*HTTPClient
+	host string // e.g. "gitlab.com"
+}
+
+// project returns the URL-encoded project path the API takes as its ID
+func (c *gitlabClient) project(repo string) string {
+	return url.PathEscape(strings.TrimPrefix(repo, c.host+"/"))
+}
+
+func (c *gitlabClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	tags, status, err := getPages[gitlabTag](ctx, c.HTTPClient, fmt.Sprintf("/projects/%s/repository/tags?per_page=%d", c.project(repo), perPage), c.maxPages())
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	converted := make([]GitHubTag, len(tags))
+	for i, tag := range tags {
+		converted[i].Name = tag.Name
+		converted[i].Commit.SHA = tag.Commit.ID
+	}
+	return converted, nil
+}
+
+func (c *gitlabClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	releases, status, err := getPages[gitlabRelease](ctx, c.HTTPClient, fmt.Sprintf("/projects/%s/releases?per_page=%d", c.project(repo), perPage), c.maxPages())
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	var converted []GitHubRelease
+	for _, release := range releases {
+		if release.UpcomingRelease {
+			continue
+		}
+		converted = append(converted, GitHubRelease{
+			TagName:     release.TagName,
+			Name:        release.Name,
+			PublishedAt: release.ReleasedAt,
+			Body:        release.Description,
+		})
+	}
+	return converted, nil
+}
+
+func (c *gitlabClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	var project GitHubRepo
+	status, _, err := c.get(ctx, "/projects/"+c.project(repo), &project)
+	if err != nil {
+		return "", notAccessible(repo, status, err)
+	}
+	return project.DefaultBranch, nil
+}
+
+func (c *gitlabClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	var b giteaBranch // GitLab's branches have the same shape
+	status, _, err := c.get(ctx, fmt.Sprintf("/projects/%s/repository/branches/%s", c.project(repo), url.PathEscape(branch)), &b)
+	if status == http.StatusNotFound {
+		return "", &ErrRefNotFound{Repo: repo, Ref: branch}
+	}
+	if err != nil {
+		return "", err
+	}
+	return b.Commit.ID, nil
+}
+
+func (c *gitlabClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	var compare struct {
+		Commits []json.RawMessage `json:"commits"`
+	}
+	path := fmt.Sprintf("/projects/%s/repository/compare?from=%s&to=%s", c.project(repo), url.QueryEscape(base), url.QueryEscape(head))
+	status, _, err := c.get(ctx, path, &compare)
+	if status == http.StatusNotFound {
+		return 0, &ErrRefNotFound{Repo: repo, Ref: base}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(compare.Commits), nil
+}
+
+func (c *gitlabClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	var commit gitlabCommit
+	status, _, err := c.get(ctx, fmt.Sprintf("/projects/%s/repository/commits/%s", c.project(repo), url.PathEscape(ref)), &commit)
+	if status == http.StatusNotFound {
+		return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.CommittedDate, nil
+}
+
+// Workflows isn't supported: remote checks read GitHub workflows
+func (c *gitlabClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return nil, fmt.Errorf("can't read the workflows of %s: GitLab projects aren't supported", repo)
+}
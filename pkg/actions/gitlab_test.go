@@ -0,0 +1,185 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseGitLabCI(t *testing.T) {
+	refs, err := ParseGitLabCI(".gitlab-ci.yml", []byte(`include:
+  - local: ci/lint.yml
+  - component: gitlab.com/components/sast/sast@2.0.0
+    inputs:
+      stage: test
+  - component: $CI_SERVER_FQDN/acme/ci/templates/deploy@1.2
+  - component: gitlab.com/components/opentofu/full-pipeline@~latest
+  - component: gitlab.com/components/sast/sast@2.0.0
+  - remote: https://example.com/ci.yml
+stages: [test]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ActionReference{
+		{Name: "gitlab.com/components/sast/sast", Version: "2.0.0", File: ".gitlab-ci.yml", Line: 3},
+		{Name: "$CI_SERVER_FQDN/acme/ci/templates/deploy", Version: "1.2", File: ".gitlab-ci.yml", Line: 6},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("ParseGitLabCI() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ParseGitLabCI()[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+
+	// A single include may be a mapping
+	refs, err = ParseGitLabCI(".gitlab-ci.yml", []byte("include:\n  component: gitlab.com/acme/ci/lint@1.0.0\n"))
+	if err != nil || len(refs) != 1 {
+		t.Errorf("ParseGitLabCI() = %+v, %v", refs, err)
+	}
+}
+
+func TestGitLabComponentNames(t *testing.T) {
+	tests := []struct {
+		name string
+		repo string
+		url  string
+	}{
+		{"gitlab.com/components/sast/sast", "gitlab.com/components/sast", "https://gitlab.com/components/sast"},
+		{"gitlab.example.com/acme/platform/ci/deploy", "gitlab.example.com/acme/platform/ci", "https://gitlab.example.com/acme/platform/ci"},
+	}
+	for _, tt := range tests {
+		if got := repoFromAction(tt.name); got != tt.repo {
+			t.Errorf("repoFromAction(%q) = %q, want %q", tt.name, got, tt.repo)
+		}
+		if got, ok := ForgeRepoURL(tt.name); !ok || got != tt.url {
+			t.Errorf("ForgeRepoURL(%q) = %q, want %q", tt.name, got, tt.url)
+		}
+	}
+	for _, name := range []string{"actions/cache/restore", "gitlab.com/acme/ci", "$CI_SERVER_FQDN/acme/ci/deploy"} {
+		if IsGitLabComponent(name) {
+			t.Errorf("IsGitLabComponent(%q) = true", name)
+		}
+	}
+}
+
+func TestExpandGitLabHost(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "$CI_SERVER_FQDN/acme/ci/deploy"},
+		{Name: "${CI_SERVER_HOST}/acme/ci/deploy"},
+		{Name: "gitlab.com/components/sast/sast"},
+	}
+	got := ExpandGitLabHost(refs, "gitlab.example.com")
+	for i, want := range []string{"gitlab.example.com/acme/ci/deploy", "gitlab.example.com/acme/ci/deploy", "gitlab.com/components/sast/sast"} {
+		if got[i].Name != want {
+			t.Errorf("ExpandGitLabHost()[%d] = %q, want %q", i, got[i].Name, want)
+		}
+	}
+	if got := ExpandGitLabHost(refs, ""); got[0].Name != "gitlab.com/acme/ci/deploy" {
+		t.Errorf("ExpandGitLabHost() without a host = %q", got[0].Name)
+	}
+}
+
+func TestFindGitLabPipeline(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, GitLabCIFile), []byte("include:\n  - component: gitlab.com/components/sast/sast@2.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// There are no workflows at all, only the pipeline
+	refs, err := FindActionReferences(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].File != GitLabCIFile {
+		t.Errorf("FindActionReferences() = %+v", refs)
+	}
+
+	sub := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, GitLabCIFile), []byte("include:\n  - component: gitlab.com/acme/ci/build@1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err = FindAllActionReferences(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 || refs[1].File != filepath.Join("services", "api", GitLabCIFile) {
+		t.Errorf("FindAllActionReferences() = %+v", refs)
+	}
+}
+
+func TestCheckerGitLabComponents(t *testing.T) {
+	old, current := strings.Repeat("a", 40), strings.Repeat("b", 40)
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RequestURI())
+		mu.Unlock()
+		switch r.URL.EscapedPath() {
+		case "/projects/components%2Fsast/repository/tags":
+			_, _ = w.Write([]byte(`[{"name": "2.1.0", "commit": {"id": "` + current + `"}}, {"name": "2.0.0"}, {"name": "1.4.0"}]`))
+		case "/projects/components%2Fsast/releases":
+			_, _ = w.Write([]byte(`[{"tag_name": "2.1.0", "released_at": "2026-03-01T00:00:00Z"}, {"tag_name": "2.0.0", "released_at": "2026-01-01T00:00:00Z"}]`))
+		case "/projects/components%2Fsast/repository/compare":
+			if r.URL.Query().Get("from") == "2.0.0" && r.URL.Query().Get("to") == "2.1.0" {
+				_, _ = w.Write([]byte(`{"commits": [{}, {}]}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		case "/projects/acme%2Fplatform%2Fci":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		case "/projects/acme%2Fplatform%2Fci/repository/branches/main":
+			_, _ = w.Write([]byte(`{"commit": {"id": "` + current + `"}}`))
+		case "/projects/acme%2Fplatform%2Fci/repository/compare":
+			_, _ = w.Write([]byte(`{"commits": [{}, {}, {}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &routedClient{
+		fallback: &fakeClient{},
+		gitlab: &hostClients{create: func(host string) GitHubClient {
+			hc := NewHTTPClient("")
+			hc.BaseURL = server.URL
+			return &gitlabClient{HTTPClient: hc, host: host}
+		}},
+	}
+	refs := []ActionReference{
+		{Name: "gitlab.com/components/sast/sast", Version: "2.0.0"},
+		{Name: "gitlab.com/components/sast/secret-detection", Version: "1"},
+		{Name: "gitlab.example.com/acme/platform/ci/deploy", Version: old},
+	}
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Warnings = %q; requests: %v", result.Warnings, requests)
+	}
+	if len(result.Outdated) != 2 {
+		t.Fatalf("Outdated = %+v, want both sast components; requests: %v", result.Outdated, requests)
+	}
+	if got := result.Outdated[0]; got.LatestVersion != "2.1.0" || got.CommitsBehind != 2 || got.DaysBehind != 59 {
+		t.Errorf("Outdated[0] = %+v, want 2.1.0, 2 commits and 59 days behind", got)
+	}
+	// A major version pin resolves to the latest 1.x, so only 2.x is newer
+	if got := result.Outdated[1]; got.CurrentVersion != "1" || got.LatestVersion != "2.1.0" {
+		t.Errorf("Outdated[1] = %+v, want 1 -> 2.1.0", got)
+	}
+	if len(result.SHAPinned) != 1 || result.SHAPinned[0].CommitsBehind != 3 {
+		t.Errorf("SHAPinned = %+v, want 3 commits behind", result.SHAPinned)
+	}
+}
@@ -0,0 +1,164 @@
+package actions
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IgnoreRule passes over versions of matching actions, like an ignore rule
+// in a Dependabot configuration. A rule without Versions or UpdateTypes
+// ignores the action entirely; otherwise a version is passed over if it's
+// in any of Versions or is any of UpdateTypes away from the current one.
+type IgnoreRule struct {
+	// Name is an action or repository name in which * matches anything,
+	// e.g. "actions/checkout" or "aws-actions/*"
+	Name string
+	// Versions are ranges of comma-separated constraints: "4.x", ">= 5",
+	// "~> 2.1" or ">= 3, < 4"
+	Versions []string
+	// UpdateTypes are "major", "minor" or "patch"
+	UpdateTypes []string
+}
+
+// matches reports whether the rule applies to an action, by its full name
+// or its repository
+func (r IgnoreRule) matches(name string) bool {
+	return nameMatches(r.Name, name)
+}
+
+// nameMatches reports whether pattern, in which * matches anything, matches
+// an action's full name or its repository, ignoring case
+func nameMatches(pattern, name string) bool {
+	re, err := regexp.Compile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name) || re.MatchString(repoFromAction(name))
+}
+
+// all reports whether the rule ignores every version
+func (r IgnoreRule) all() bool {
+	return len(r.Versions) == 0 && len(r.UpdateTypes) == 0
+}
+
+// ignores reports whether the rule passes over version as an update from
+// current. Only semantic versions are matched.
+func (r IgnoreRule) ignores(version, current string) bool {
+	v := parseSemver(version)
+	if v == nil {
+		return false
+	}
+	for _, constraints := range r.Versions {
+		if inRange(v, constraints) {
+			return true
+		}
+	}
+	cur := parseSemver(current)
+	return cur != nil && slices.Contains(r.UpdateTypes, updateType(cur, v))
+}
+
+// ignoredEntirely reports whether any of rules ignores every version of an
+// action
+func ignoredEntirely(rules []IgnoreRule, name string) bool {
+	return slices.ContainsFunc(rules, func(r IgnoreRule) bool { return r.all() && r.matches(name) })
+}
+
+// withoutIgnored drops the tags any of rules passes over as updates of an
+// action from current
+func withoutIgnored(rules []IgnoreRule, name, current string, tags []GitHubTag) []GitHubTag {
+	var matched []IgnoreRule
+	for _, r := range rules {
+		if r.matches(name) {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) == 0 {
+		return tags
+	}
+	return slices.DeleteFunc(slices.Clone(tags), func(t GitHubTag) bool {
+		return slices.ContainsFunc(matched, func(r IgnoreRule) bool { return r.ignores(t.Name, current) })
+	})
+}
+
+// updateType classifies the update from current to v by the most
+// significant part that changes. A floating pin like v4 only sees new
+// majors, and v4.1 new majors and minors.
+func updateType(current, v *semver) string {
+	switch {
+	case v.Major != current.Major:
+		return "major"
+	case v.Minor != current.Minor:
+		return "minor"
+	}
+	return "patch"
+}
+
+// inRange reports whether v satisfies every comma-separated constraint.
+// Constraints that can't be parsed match nothing.
+func inRange(v *semver, constraints string) bool {
+	for _, c := range strings.Split(constraints, ",") {
+		if !satisfies(v, strings.TrimSpace(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfies reports whether v satisfies a single constraint: a comparison
+// (=, !=, <, <=, >, >=), a pessimistic ~> like "~> 2.1" (>= 2.1, < 3) or
+// "~> 2" (>= 2, < 3), or a
+// version with wildcards like "4.x" or "4.*"
+func satisfies(v *semver, constraint string) bool {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", "~>", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(constraint, prefix); ok {
+			op, constraint = prefix, strings.TrimSpace(rest)
+			break
+		}
+	}
+
+	parts := strings.Split(strings.TrimPrefix(constraint, "v"), ".")
+	if i := slices.IndexFunc(parts, func(p string) bool { return p == "x" || p == "X" || p == "*" }); i >= 0 {
+		if op != "" && op != "=" {
+			return false
+		}
+		// A wildcard matches every version sharing the parts before it
+		prefix := parseSemver(strings.Join(parts[:i], "."))
+		if i == 0 {
+			return true
+		}
+		if prefix == nil {
+			return false
+		}
+		return prefix.Major == v.Major && (i < 2 || prefix.Minor == v.Minor)
+	}
+
+	want := parseSemver(constraint)
+	if want == nil {
+		return false
+	}
+	cmp := v.compare(want)
+	switch op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "~>":
+		// The last part given may rise, the ones before it may not
+		if cmp < 0 {
+			return false
+		}
+		if want.HasPatch {
+			return v.Major == want.Major && v.Minor == want.Minor
+		}
+		return v.Major == want.Major
+	}
+	return cmp == 0
+}
@@ -0,0 +1,81 @@
+package actions
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIgnoreRuleIgnores(t *testing.T) {
+	for _, tt := range []struct {
+		rule             IgnoreRule
+		version, current string
+		want             bool
+	}{
+		{IgnoreRule{Versions: []string{"5.x"}}, "v5.1.0", "v4", true},
+		{IgnoreRule{Versions: []string{"5.x"}}, "v6.0.0", "v4", false},
+		{IgnoreRule{Versions: []string{"5.1.*"}}, "v5.1.3", "v4", true},
+		{IgnoreRule{Versions: []string{">= 5"}}, "v6", "v4", true},
+		{IgnoreRule{Versions: []string{">= 5, < 6"}}, "v6.0.0", "v4", false},
+		{IgnoreRule{Versions: []string{"~> 2.1"}}, "v2.9.0", "v2.0.0", true},
+		{IgnoreRule{Versions: []string{"~> 2.1"}}, "v3.0.0", "v2.0.0", false},
+		{IgnoreRule{Versions: []string{"~> 2.1.0"}}, "v2.2.0", "v2.0.0", false},
+		{IgnoreRule{Versions: []string{"4.2.0"}}, "v4.2.0", "v4.1.0", true},
+		{IgnoreRule{Versions: []string{"nonsense"}}, "v4.2.0", "v4.1.0", false},
+		{IgnoreRule{UpdateTypes: []string{"major"}}, "v5", "v4", true},
+		{IgnoreRule{UpdateTypes: []string{"major"}}, "v4.2.0", "v4.1.0", false},
+		{IgnoreRule{UpdateTypes: []string{"minor", "patch"}}, "v4.2.0", "v4.1.0", true},
+		{IgnoreRule{UpdateTypes: []string{"patch"}}, "v4.1.1", "v4.1.0", true},
+		{IgnoreRule{UpdateTypes: []string{"major"}}, "nightly-2", "nightly-1", false},
+	} {
+		if got := tt.rule.ignores(tt.version, tt.current); got != tt.want {
+			t.Errorf("%+v.ignores(%q, %q) = %v, want %v", tt.rule, tt.version, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	for _, tt := range []struct {
+		rule, name string
+		want       bool
+	}{
+		{"actions/checkout", "actions/checkout", true},
+		{"Actions/Checkout", "actions/checkout", true},
+		{"github/codeql-action", "github/codeql-action/init", true},
+		{"aws-actions/*", "aws-actions/configure-aws-credentials", true},
+		{"*", "actions/cache", true},
+		{"actions/check", "actions/checkout", false},
+	} {
+		if got := (IgnoreRule{Name: tt.rule}).matches(tt.name); got != tt.want {
+			t.Errorf("IgnoreRule{%q}.matches(%q) = %v, want %v", tt.rule, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckerIgnoreRules(t *testing.T) {
+	client := &fakeClient{tags: map[string][]GitHubTag{
+		"actions/checkout": {{Name: "v4"}, {Name: "v5"}, {Name: "v6"}},
+		"actions/cache":    {{Name: "v3"}, {Name: "v4"}},
+		"actions/setup-go": {{Name: "v5.0.0"}, {Name: "v5.1.0"}, {Name: "v6.0.0"}},
+	}}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/cache", Version: "v3", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "v5.0.0", File: "ci.yml"},
+	}
+
+	// Rules from several options all apply
+	result, err := NewChecker(WithClient(client), WithIgnoreRules([]IgnoreRule{
+		{Name: "actions/checkout", Versions: []string{"6.x"}},
+		{Name: "actions/cache"},
+	}), WithIgnoreRules([]IgnoreRule{
+		{Name: "actions/setup-*", UpdateTypes: []string{"major"}},
+	})).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Outdated) != 2 ||
+		result.Outdated[0].LatestVersion != "v5" ||
+		result.Outdated[1].LatestVersion != "v5.1.0" {
+		t.Errorf("unexpected outdated actions: %+v", result.Outdated)
+	}
+}
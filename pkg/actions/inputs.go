@@ -0,0 +1,392 @@
+package actions
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionMetadataFiles are the names an action's metadata file may have, in
+// the order the runner looks for them
+var ActionMetadataFiles = []string{"action.yml", "action.yaml"}
+
+// Problems with a step's inputs, for InputFinding.Problem
+const (
+	InputUnknown    = "unknown"    // The action doesn't declare the input
+	InputDeprecated = "deprecated" // The action declares it with a deprecationMessage
+	InputMissing    = "missing"    // The action requires it, and it has no default
+)
+
+// FileClient fetches files from repositories, which checking inputs needs.
+// The HTTPClient implements it.
+type FileClient interface {
+	// File returns the content of a file in a repository at ref, or nil if
+	// there's no such file
+	File(ctx context.Context, repo, path, ref string) ([]byte, error)
+}
+
+// ActionMetadata is the part of an action's metadata file (action.yml) that
+// aver reads
+type ActionMetadata struct {
+	Inputs map[string]ActionInput `yaml:"inputs"`
+}
+
+// ActionInput is an input an action declares
+type ActionInput struct {
+	Description string       `yaml:"description"`
+	Required    metadataBool `yaml:"required"`
+	Default     *string      `yaml:"default"` // nil if there's none
+	// DeprecationMessage, if set, marks the input as deprecated and says
+	// what to use instead
+	DeprecationMessage string `yaml:"deprecationMessage"`
+}
+
+// metadataBool is a boolean in action.yml, where actions write true as
+// often as "true". Anything else is false, as it is to the runner.
+type metadataBool bool
+
+func (b *metadataBool) UnmarshalYAML(node *yaml.Node) error {
+	v, _ := strconv.ParseBool(node.Value)
+	*b = metadataBool(v)
+	return nil
+}
+
+// input returns the declared input named name, which like the runner is
+// matched regardless of case
+func (m *ActionMetadata) input(name string) (ActionInput, bool) {
+	for declared, input := range m.Inputs {
+		if strings.EqualFold(declared, name) {
+			return input, true
+		}
+	}
+	return ActionInput{}, false
+}
+
+// ParseActionMetadata parses an action's metadata file. file is reported in
+// errors.
+func ParseActionMetadata(file string, content []byte) (*ActionMetadata, error) {
+	var meta ActionMetadata
+	if err := yaml.Unmarshal(content, &meta); err != nil {
+		return nil, &ErrParse{Source: file, Err: err}
+	}
+	return &meta, nil
+}
+
+// Step is a workflow step that uses an action, with the inputs it passes
+// in with:
+type Step struct {
+	Ref  ActionReference // Line is the line of the step's uses:
+	With []StepInput
+}
+
+// StepInput is an input a step passes to its action
+type StepInput struct {
+	Name string
+	Line int
+}
+
+// ParseSteps returns the steps of a workflow file that use an action of
+// another repository, in the order they appear. Local actions, Docker
+// images, reusable workflows and dynamic refs are left out. file is
+// reported as the steps' File.
+func ParseSteps(file string, content []byte) ([]Step, error) {
+	var steps []Step
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &ErrParse{Source: file, Err: err}
+		}
+		steps = appendSteps(steps, file, &doc)
+	}
+	return steps, nil
+}
+
+// appendSteps appends the steps in node and its children to steps
+func appendSteps(steps []Step, file string, node *yaml.Node) []Step {
+	if node.Kind == yaml.MappingNode {
+		if step, ok := parseStep(file, node); ok {
+			steps = append(steps, step)
+		}
+	}
+	for _, child := range node.Content {
+		steps = appendSteps(steps, file, child)
+	}
+	return steps
+}
+
+// parseStep reads a mapping with a uses: key as a step
+func parseStep(file string, node *yaml.Node) (Step, bool) {
+	var uses, with *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case "uses":
+			uses = node.Content[i+1]
+		case "with":
+			with = node.Content[i+1]
+		}
+	}
+	if uses == nil || uses.Kind != yaml.ScalarNode {
+		return Step{}, false
+	}
+	name, version, ok := strings.Cut(strings.TrimPrefix(uses.Value, "https://github.com/"), "@")
+	if !ok || strings.HasPrefix(name, "./") || strings.HasPrefix(name, "docker://") || strings.Contains(name, "/.github/workflows/") {
+		return Step{}, false
+	}
+	step := Step{Ref: ActionReference{Name: name, Version: version, File: file, Line: uses.Line}}
+	if step.Ref.Dynamic() {
+		return Step{}, false
+	}
+	if with != nil && with.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(with.Content); i += 2 {
+			step.With = append(step.With, StepInput{Name: with.Content[i].Value, Line: with.Content[i].Line})
+		}
+	}
+	return step, true
+}
+
+// FindSteps returns the steps of the local workflow files that refs were
+// found in, under projectRoot. References from other repositories are
+// passed over, as are files that can't be read or parsed, which were
+// reported when their references were found.
+func FindSteps(projectRoot string, refs []ActionReference) ([]Step, error) {
+	var steps []Step
+	err := readWorkflows(projectRoot, refs, func(file string, content []byte) {
+		if fileSteps, err := ParseSteps(file, content); err == nil {
+			steps = append(steps, fileSteps...)
+		}
+	})
+	return steps, err
+}
+
+// readWorkflows calls fn with each local workflow file that refs were found
+// in, once, skipping references from other repositories and files that
+// don't exist, such as "<stdin>"
+func readWorkflows(projectRoot string, refs []ActionReference, fn func(file string, content []byte)) error {
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.Repository != "" || seen[ref.File] {
+			continue
+		}
+		seen[ref.File] = true
+		content, err := os.ReadFile(filepath.Join(projectRoot, ref.File))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fn(ref.File, content)
+	}
+	return nil
+}
+
+// InputFinding is a problem with an input of a step
+type InputFinding struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Name       string `json:"action"`
+	Version    string `json:"version"`
+	Input      string `json:"input"`
+	Problem    string `json:"problem"` // InputUnknown or InputDeprecated
+	Message    string `json:"message"`
+	// Suggestion is a declared input that an unknown one may be a typo of
+	Suggestion string `json:"suggestion,omitempty"`
+	// Deprecation is the deprecationMessage of a deprecated input
+	Deprecation string `json:"deprecation,omitempty"`
+}
+
+// InputResult is what CheckInputs found
+type InputResult struct {
+	Findings []InputFinding
+	Warnings []string // Actions whose metadata could not be fetched
+}
+
+// CheckInputs fetches the metadata of the action each step uses, at the
+// step's ref, and reports the inputs the step passes that the action
+// doesn't declare, like fetch_depth for fetch-depth, or has deprecated,
+// which are likely to go in its next major version, and the required
+// inputs without a default that the step leaves out. Each action@ref is
+// fetched once. Actions without a metadata file are passed over, as are
+// all steps if the client can't fetch files.
+func (c *Checker) CheckInputs(ctx context.Context, steps []Step) (InputResult, error) {
+	files, ok := c.client.(FileClient)
+	if !ok {
+		return InputResult{}, nil
+	}
+	var keys []string
+	for _, step := range steps {
+		if key := step.Ref.Name + "@" + step.Ref.Version; !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	metadata := make([]*ActionMetadata, len(keys))
+	errs := make([]error, len(keys))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			name, ref, _ := strings.Cut(key, "@")
+			metadata[i], errs[i] = c.actionMetadata(ctx, files, name, ref)
+		})
+	}
+	wg.Wait()
+
+	var result InputResult
+	byKey := make(map[string]*ActionMetadata, len(keys))
+	for i, key := range keys {
+		err := errs[i]
+		switch {
+		case err == nil:
+			byKey[key] = metadata[i]
+		case errors.Is(err, &ErrRepoNotAccessible{}), errors.Is(err, &ErrNotCached{}), errors.Is(err, &ErrParse{}):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("not checking the inputs of %s: %v", key, err))
+		default:
+			return InputResult{}, err
+		}
+	}
+	for _, step := range steps {
+		if meta := byKey[step.Ref.Name+"@"+step.Ref.Version]; meta != nil {
+			result.Findings = append(result.Findings, inputFindings(step, meta)...)
+		}
+	}
+	return result, nil
+}
+
+// actionMetadata fetches and parses the metadata file of an action at ref,
+// which for an action in a subdirectory is in that directory. It returns
+// nil if the action has none.
+func (c *Checker) actionMetadata(ctx context.Context, files FileClient, name, ref string) (*ActionMetadata, error) {
+	repo := repoFromAction(name)
+	dir := strings.Trim(strings.TrimPrefix(name, repo), "/")
+	for _, file := range ActionMetadataFiles {
+		content, err := files.File(ctx, repo, path.Join(dir, file), ref)
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			return ParseActionMetadata(name+"@"+ref+"/"+file, content)
+		}
+	}
+	c.logger.Info("no action metadata", "action", name, "version", ref)
+	return nil, nil
+}
+
+// inputFindings reports the inputs a step passes that its action doesn't
+// declare or has deprecated, then the required ones it doesn't pass
+func inputFindings(step Step, meta *ActionMetadata) []InputFinding {
+	var findings []InputFinding
+	for _, input := range step.With {
+		if declared, ok := meta.input(input.Name); ok {
+			if declared.DeprecationMessage != "" {
+				f := newInputFinding(step, input, InputDeprecated)
+				f.Deprecation = strings.Join(strings.Fields(declared.DeprecationMessage), " ")
+				f.Message = fmt.Sprintf("input %q of %s@%s is deprecated: %s", input.Name, f.Name, f.Version, f.Deprecation)
+				findings = append(findings, f)
+			}
+			continue
+		}
+		f := newInputFinding(step, input, InputUnknown)
+		f.Message = fmt.Sprintf("%s@%s has no input %q", f.Name, f.Version, input.Name)
+		if f.Suggestion = closestInput(input.Name, meta); f.Suggestion != "" {
+			f.Message += fmt.Sprintf("; did you mean %q?", f.Suggestion)
+		}
+		findings = append(findings, f)
+	}
+
+	// An input with a default is never missing, even if it's required
+	var missing []string
+	for name, declared := range meta.Inputs {
+		if !declared.Required || declared.Default != nil {
+			continue
+		}
+		if !slices.ContainsFunc(step.With, func(input StepInput) bool { return strings.EqualFold(input.Name, name) }) {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+	for _, name := range missing {
+		// Reported on the step's uses: line
+		f := newInputFinding(step, StepInput{Name: name}, InputMissing)
+		f.Message = fmt.Sprintf("%s@%s requires input %q, which has no default", f.Name, f.Version, name)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func newInputFinding(step Step, input StepInput, problem string) InputFinding {
+	return InputFinding{
+		Repository: step.Ref.Repository,
+		File:       step.Ref.File,
+		Line:       cmp.Or(input.Line, step.Ref.Line),
+		Name:       step.Ref.Name,
+		Version:    step.Ref.Version,
+		Input:      input.Name,
+		Problem:    problem,
+	}
+}
+
+// closestInput returns the declared input name is most likely a typo of:
+// one spelled the same but for case and dashes or underscores, or else the
+// nearest within two edits. It returns "" if there's none.
+func closestInput(name string, meta *ActionMetadata) string {
+	normalize := func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(s), "_", "-")
+	}
+	best, bestDistance := "", 3
+	for declared := range meta.Inputs {
+		if normalize(declared) == normalize(name) {
+			return declared
+		}
+		if d := editDistance(normalize(declared), normalize(name)); d < bestDistance || (d == bestDistance && declared < best) {
+			best, bestDistance = declared, d
+		}
+	}
+	// A two-letter difference makes any short name look like a typo
+	if bestDistance >= len(name)/2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,195 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const inputsWorkflow = `jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch_depth: 0
+          Token: ${{ secrets.TOKEN }}
+      - uses: ./local-action
+        with:
+          anything: 1
+      - uses: actions/cache/restore@v4
+        with:
+          path: ~/.cache
+          keys: x
+      - uses: owner/tool@${{ inputs.version }}
+      - run: echo hi
+  reuse:
+    uses: owner/repo/.github/workflows/build.yml@v1
+    with:
+      target: prod
+---
+steps:
+  - uses: docker://alpine:3
+  - uses: https://github.com/actions/setup-go@v5
+`
+
+func TestParseSteps(t *testing.T) {
+	steps, err := ParseSteps("ci.yml", []byte(inputsWorkflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %+v", steps)
+	}
+	want := []struct {
+		name, version string
+		line          int
+		with          []StepInput
+	}{
+		{"actions/checkout", "v4", 4, []StepInput{{"fetch_depth", 6}, {"Token", 7}}},
+		{"actions/cache/restore", "v4", 11, []StepInput{{"path", 13}, {"keys", 14}}},
+		{"actions/setup-go", "v5", 24, nil},
+	}
+	for i, w := range want {
+		ref := steps[i].Ref
+		if ref.Name != w.name || ref.Version != w.version || ref.Line != w.line || ref.File != "ci.yml" {
+			t.Errorf("step %d: expected %s@%s on line %d, got %+v", i, w.name, w.version, w.line, ref)
+		}
+		if len(steps[i].With) != len(w.with) {
+			t.Errorf("step %d: expected inputs %v, got %v", i, w.with, steps[i].With)
+			continue
+		}
+		for j, input := range w.with {
+			if steps[i].With[j] != input {
+				t.Errorf("step %d: expected input %v, got %v", i, input, steps[i].With[j])
+			}
+		}
+	}
+
+	if _, err := ParseSteps("bad.yml", []byte("steps: [")); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
+
+func TestFindSteps(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(inputsWorkflow), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: ".github/workflows/ci.yml"},
+		{Name: "actions/setup-go", Version: "v5", File: ".github/workflows/ci.yml"},
+		{Name: "actions/checkout", Version: "v4", File: ".github/workflows/ci.yml", Repository: "owner/app"},
+		{Name: "actions/checkout", Version: "v4", File: "<stdin>"},
+	}
+	steps, err := FindSteps(root, refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 3 || steps[0].Ref.File != ".github/workflows/ci.yml" {
+		t.Errorf("expected the 3 steps of ci.yml once, got %+v", steps)
+	}
+}
+
+func TestCheckerCheckInputs(t *testing.T) {
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {{Name: "v4"}},
+			"actions/cache":    {{Name: "v4"}},
+			"owner/plain":      {{Name: "v1"}},
+			"owner/broken":     {{Name: "v1"}},
+			"owner/deploy":     {{Name: "v1"}},
+		},
+		files: map[string]string{
+			"actions/checkout/action.yml@v4":       "inputs:\n  fetch-depth:\n    default: 1\n  token:\n  ref:\n  persist:\n    deprecationMessage: >\n      Use persist-credentials\n      instead.\n",
+			"actions/cache/restore/action.yaml@v4": "inputs:\n  path:\n  key:\n  restore-keys:\n",
+			"owner/broken/action.yml@v1":           "inputs: [",
+			"owner/deploy/action.yml@v1": `inputs:
+  environment:
+    required: true
+  app:
+    required: "true"
+  region:
+    required: true
+    default: us-east-1
+  force:
+    required: true
+    default: false
+  dry-run:
+    required: false
+`,
+		},
+	}
+	steps := []Step{
+		{Ref: ActionReference{Name: "actions/checkout", Version: "v4", File: "ci.yml", Line: 4},
+			With: []StepInput{{"fetch_depth", 6}, {"TOKEN", 7}, {"submodules", 8}, {"persist", 9}}},
+		{Ref: ActionReference{Name: "actions/checkout", Version: "v4", File: "release.yml", Line: 3},
+			With: []StepInput{{"rf", 5}}},
+		{Ref: ActionReference{Name: "actions/cache/restore", Version: "v4", File: "ci.yml", Line: 10},
+			With: []StepInput{{"path", 12}, {"keys", 13}}},
+		{Ref: ActionReference{Name: "owner/plain", Version: "v1", File: "ci.yml", Line: 15},
+			With: []StepInput{{"anything", 16}}},
+		{Ref: ActionReference{Name: "owner/broken", Version: "v1", File: "ci.yml", Line: 18},
+			With: []StepInput{{"anything", 19}}},
+		{Ref: ActionReference{Name: "private/action", Version: "v1", File: "ci.yml", Line: 20},
+			With: []StepInput{{"anything", 21}}},
+		{Ref: ActionReference{Name: "owner/deploy", Version: "v1", File: "deploy.yml", Line: 7}},
+		{Ref: ActionReference{Name: "owner/deploy", Version: "v1", File: "deploy.yml", Line: 12},
+			With: []StepInput{{"Environment", 14}, {"app", 15}}},
+	}
+
+	result, err := NewChecker(WithClient(client)).CheckInputs(context.Background(), steps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []InputFinding{
+		{File: "ci.yml", Line: 6, Name: "actions/checkout", Version: "v4", Input: "fetch_depth", Problem: InputUnknown,
+			Message: `actions/checkout@v4 has no input "fetch_depth"; did you mean "fetch-depth"?`, Suggestion: "fetch-depth"},
+		{File: "ci.yml", Line: 8, Name: "actions/checkout", Version: "v4", Input: "submodules", Problem: InputUnknown,
+			Message: `actions/checkout@v4 has no input "submodules"`},
+		{File: "ci.yml", Line: 9, Name: "actions/checkout", Version: "v4", Input: "persist", Problem: InputDeprecated,
+			Message:     `input "persist" of actions/checkout@v4 is deprecated: Use persist-credentials instead.`,
+			Deprecation: "Use persist-credentials instead."},
+		{File: "release.yml", Line: 5, Name: "actions/checkout", Version: "v4", Input: "rf", Problem: InputUnknown,
+			Message: `actions/checkout@v4 has no input "rf"`},
+		{File: "ci.yml", Line: 13, Name: "actions/cache/restore", Version: "v4", Input: "keys", Problem: InputUnknown,
+			Message: `actions/cache/restore@v4 has no input "keys"; did you mean "key"?`, Suggestion: "key"},
+		{File: "deploy.yml", Line: 7, Name: "owner/deploy", Version: "v1", Input: "app", Problem: InputMissing,
+			Message: `owner/deploy@v1 requires input "app", which has no default`},
+		{File: "deploy.yml", Line: 7, Name: "owner/deploy", Version: "v1", Input: "environment", Problem: InputMissing,
+			Message: `owner/deploy@v1 requires input "environment", which has no default`},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), result.Findings)
+	}
+	for i, w := range want {
+		if result.Findings[i] != w {
+			t.Errorf("finding %d: expected %+v, got %+v", i, w, result.Findings[i])
+		}
+	}
+	// The broken metadata and the inaccessible repository are warned about
+	if len(result.Warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %v", result.Warnings)
+	}
+}
+
+func TestClosestInput(t *testing.T) {
+	meta := &ActionMetadata{Inputs: map[string]ActionInput{"fetch-depth": {}, "token": {}, "go-version": {}, "go-version-file": {}}}
+	for _, tt := range []struct{ name, want string }{
+		{"fetch_depth", "fetch-depth"},
+		{"FETCH-DEPTH", "fetch-depth"},
+		{"tokn", "token"},
+		{"go-verison", "go-version"},
+		{"go-version-files", "go-version-file"},
+		{"ref", ""},
+		{"debug", ""},
+	} {
+		if got := closestInput(tt.name, meta); got != tt.want {
+			t.Errorf("closestInput(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
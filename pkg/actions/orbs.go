@@ -0,0 +1,184 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CircleCIFile is the pipeline configuration of a CircleCI project,
+// relative to its root
+const CircleCIFile = ".circleci/config.yml"
+
+// CircleCIURL is the root of the CircleCI API, which serves the orb registry
+const CircleCIURL = "https://circleci.com"
+
+// OrbPrefix starts the names of CircleCI orbs ("orb:circleci/node"), which
+// would otherwise look like actions
+const OrbPrefix = "orb:"
+
+// IsOrb reports whether name is a CircleCI orb rather than an action
+func IsOrb(name string) bool {
+	return strings.HasPrefix(name, OrbPrefix)
+}
+
+// OrbURL returns the orb registry page of an orb
+func OrbURL(name string) string {
+	return CircleCIURL + "/developer/orbs/orb/" + strings.TrimPrefix(name, OrbPrefix)
+}
+
+// ParseCircleCI returns the orbs a CircleCI config imports, once per name
+// and version:
+//
+//	orbs:
+//	  node: circleci/node@5.2.0
+//
+// Orbs are named with OrbPrefix. Inline orb definitions, and orbs pinned to
+// volatile or to dev: versions, which aren't released, are left out. file
+// is reported as the references' File.
+func ParseCircleCI(file string, content []byte) ([]ActionReference, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, &ErrParse{Source: file, Err: err}
+	}
+
+	refs := []ActionReference{}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return refs, nil
+	}
+	root := doc.Content[0]
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		orbs := root.Content[i+1]
+		if root.Content[i].Value != "orbs" || orbs.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(orbs.Content); j += 2 {
+			value := orbs.Content[j+1]
+			if value.Kind != yaml.ScalarNode {
+				continue // An inline orb
+			}
+			name, version, ok := strings.Cut(value.Value, "@")
+			if !ok || version == "volatile" || strings.HasPrefix(version, "dev:") || seen[value.Value] {
+				continue
+			}
+			seen[value.Value] = true
+			refs = append(refs, ActionReference{
+				Name:    OrbPrefix + name,
+				Version: version,
+				File:    file,
+				Line:    value.Line,
+			})
+		}
+	}
+	return refs, nil
+}
+
+// resolvesPartialVersions reports whether a pin like 1.2 gets the latest
+// matching release rather than naming a tag, as with GitLab components,
+// CircleCI orbs and pipes
+func resolvesPartialVersions(name string) bool {
+	return IsGitLabComponent(name) || IsOrb(name) || IsPipe(name) || IsDockerImage(name)
+}
+
+// orbVersionsQuery asks the orb registry for an orb's releases, newest first
+const orbVersionsQuery = `query($name: String!, $count: Int!) {
+  orb(name: $name) { versions(count: $count) { version createdAt } }
+}`
+
+// orbVersions is the registry's answer to orbVersionsQuery
+type orbVersions struct {
+	Data struct {
+		Orb *struct {
+			Versions []struct {
+				Version   string    `json:"version"`
+				CreatedAt time.Time `json:"createdAt"`
+			} `json:"versions"`
+		} `json:"orb"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// errNoCommits is returned for the calls that need git history by clients
+// of registries that don't have it
+var errNoCommits = errors.New("no commit history is available")
+
+// orbClient answers the GitHubClient calls for orbs from the CircleCI orb
+// registry's GraphQL API. Each published version is both a tag and a
+// release; there are no branches or commits.
+type orbClient struct {
+	*HTTPClient
+}
+
+// versions fetches the published versions of an orb
+func (c *orbClient) versions(ctx context.Context, repo string) (orbVersions, error) {
+	name := strings.TrimPrefix(repo, OrbPrefix)
+	var resp orbVersions
+	status, err := c.query(ctx, "/graphql-unstable", map[string]any{
+		"query":     orbVersionsQuery,
+		"variables": map[string]any{"name": name, "count": perPage * c.maxPages()},
+	}, &resp)
+	if err != nil {
+		return orbVersions{}, notAccessible(repo, status, err)
+	}
+	if len(resp.Errors) > 0 {
+		return orbVersions{}, fmt.Errorf("orb registry: %s", resp.Errors[0].Message)
+	}
+	if resp.Data.Orb == nil {
+		return orbVersions{}, &ErrRepoNotAccessible{Repo: repo, Status: http.StatusNotFound}
+	}
+	return resp, nil
+}
+
+func (c *orbClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	resp, err := c.versions(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]GitHubTag, len(resp.Data.Orb.Versions))
+	for i, v := range resp.Data.Orb.Versions {
+		tags[i].Name = v.Version
+	}
+	return tags, nil
+}
+
+func (c *orbClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	resp, err := c.versions(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]GitHubRelease, len(resp.Data.Orb.Versions))
+	for i, v := range resp.Data.Orb.Versions {
+		releases[i] = GitHubRelease{TagName: v.Version, Name: v.Version, PublishedAt: v.CreatedAt}
+	}
+	return releases, nil
+}
+
+func (c *orbClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *orbClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *orbClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return 0, errNoCommits
+}
+
+// CommitDate reports every ref as not found, so publish dates come from
+// Releases alone
+func (c *orbClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+}
+
+func (c *orbClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return nil, fmt.Errorf("can't read the workflows of %s: it's an orb", repo)
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCircleCI(t *testing.T) {
+	refs, err := ParseCircleCI(CircleCIFile, []byte(`version: 2.1
+orbs:
+  node: circleci/node@5.2.0
+  aws-cli: circleci/aws-cli@4
+  slack: circleci/slack@volatile
+  beta: acme/beta@dev:alpha
+  inline:
+    jobs:
+      hello:
+        docker: [{image: cimg/base:stable}]
+jobs:
+  build:
+    steps: [checkout]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ActionReference{
+		{Name: "orb:circleci/node", Version: "5.2.0", File: CircleCIFile, Line: 3},
+		{Name: "orb:circleci/aws-cli", Version: "4", File: CircleCIFile, Line: 4},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("ParseCircleCI() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ParseCircleCI()[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+	if repo := repoFromAction("orb:circleci/node"); repo != "orb:circleci/node" {
+		t.Errorf("repoFromAction() = %q", repo)
+	}
+}
+
+func TestFindCircleCIConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".circleci"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, CircleCIFile), []byte("orbs:\n  node: circleci/node@5.2.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := FindActionReferences(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].File != filepath.FromSlash(CircleCIFile) {
+		t.Errorf("FindActionReferences() = %+v", refs)
+	}
+
+	refs, err = FileReferences(root, []string{filepath.Join(root, CircleCIFile)})
+	if err != nil || len(refs) != 1 || refs[0].Name != "orb:circleci/node" {
+		t.Errorf("FileReferences() = %+v, %v", refs, err)
+	}
+}
+
+func TestCheckerOrbs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Name string `json:"name"`
+			} `json:"variables"`
+		}
+		if r.Method != "POST" || r.URL.Path != "/graphql-unstable" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch req.Variables.Name {
+		case "circleci/node":
+			_, _ = w.Write([]byte(`{"data": {"orb": {"versions": [
+				{"version": "5.3.0", "createdAt": "2026-03-01T00:00:00Z"},
+				{"version": "5.2.0", "createdAt": "2026-01-30T00:00:00Z"},
+				{"version": "5.1.0", "createdAt": "2026-01-01T00:00:00Z"},
+				{"version": "4.9.0", "createdAt": "2025-06-01T00:00:00Z"}
+			]}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data": {"orb": null}}`))
+		}
+	}))
+	defer server.Close()
+
+	hc := NewHTTPClient("")
+	hc.BaseURL = server.URL
+	client := &routedClient{fallback: &fakeClient{}, orbs: &orbClient{HTTPClient: hc}}
+
+	refs := []ActionReference{
+		{Name: "orb:circleci/node", Version: "5.1.0"},
+		{Name: "orb:circleci/node", Version: "4"},
+		{Name: "orb:acme/missing", Version: "1.0.0"},
+	}
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Outdated) != 2 {
+		t.Fatalf("Outdated = %+v, want both node pins", result.Outdated)
+	}
+	if got := result.Outdated[0]; got.LatestVersion != "5.3.0" || got.DaysBehind != 59 || got.ReleasesBehind != 2 {
+		t.Errorf("Outdated[0] = %+v, want 5.3.0, 59 days and 2 releases behind", got)
+	}
+	if got := result.Outdated[1]; got.LatestVersion != "5.3.0" {
+		t.Errorf("Outdated[1] = %+v, want 4 -> 5.3.0", got)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Warnings = %q, want one for the missing orb", result.Warnings)
+	}
+
+	if _, err := client.orbs.Tags(context.Background(), "orb:acme/missing"); !errors.Is(err, &ErrRepoNotAccessible{}) {
+		t.Errorf("Tags() of a missing orb = %v, want ErrRepoNotAccessible", err)
+	}
+}
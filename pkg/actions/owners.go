@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The kinds of account that own repositories, for GitHubOwner.Type
+const (
+	OwnerUser         = "User"
+	OwnerOrganization = "Organization"
+)
+
+// RepositoryClient fetches repositories' information, which looking up
+// their owners needs. The HTTPClient implements it.
+type RepositoryClient interface {
+	Repository(ctx context.Context, repo string) (GitHubRepo, error)
+}
+
+// OwnerResult is what Owners found out about who owns a set of actions
+type OwnerResult struct {
+	// Types maps the repository of each action, e.g. "actions/checkout",
+	// to the type of account that owns it, OwnerUser or OwnerOrganization
+	Types map[string]string
+	// Personal is the first reference to each repository a personal
+	// account owns. One person's account has none of an organization's
+	// controls, like required two-factor authentication and several
+	// owners, so these are a higher supply chain risk.
+	Personal []ActionReference
+	Warnings []string
+}
+
+// Owners looks up the account that owns the repository of each GitHub
+// action in refs, once per repository. Dynamic refs and the actions of
+// other ecosystems are passed over, as are all refs if the client can't
+// fetch repositories.
+func (c *Checker) Owners(ctx context.Context, refs []ActionReference) (OwnerResult, error) {
+	repos, ok := c.client.(RepositoryClient)
+	if !ok {
+		return OwnerResult{}, nil
+	}
+	var names []string
+	first := make(map[string]ActionReference)
+	for _, ref := range refs {
+		if ref.Dynamic() || !githubAction(ref.Name) {
+			continue
+		}
+		name := repoFromAction(ref.Name)
+		if _, ok := first[name]; !ok {
+			first[name] = ref
+			names = append(names, name)
+		}
+	}
+
+	owners := make([]GitHubOwner, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			var repo GitHubRepo
+			repo, errs[i] = repos.Repository(ctx, name)
+			owners[i] = repo.Owner
+		})
+	}
+	wg.Wait()
+
+	result := OwnerResult{Types: make(map[string]string)}
+	for i, name := range names {
+		err := errs[i]
+		switch {
+		case err == nil:
+		case errors.Is(err, &ErrRepoNotAccessible{}), errors.Is(err, &ErrNotCached{}):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("not checking the owner of %s: %v", name, err))
+			continue
+		default:
+			return OwnerResult{}, err
+		}
+		if owners[i].Type == "" {
+			continue
+		}
+		result.Types[name] = owners[i].Type
+		if owners[i].Type == OwnerUser {
+			result.Personal = append(result.Personal, first[name])
+		}
+	}
+	return result, nil
+}
+
+// githubAction reports whether name is an action in a GitHub repository,
+// rather than an image, orb, pipe, task, component or action on another
+// forge
+func githubAction(name string) bool {
+	if _, _, ok := forgeRepo(name); ok {
+		return false
+	}
+	if IsOrb(name) || IsPipe(name) || IsDockerImage(name) || IsAzureTask(name) {
+		return false
+	}
+	if _, ok := gitlabHost(name); ok {
+		return false
+	}
+	return strings.Contains(name, "/")
+}
@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckerOwners(t *testing.T) {
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {{Name: "v4"}},
+			"actions/cache":    {{Name: "v4"}},
+			"someone/tool":     {{Name: "v1"}},
+		},
+		owners: map[string]string{"actions": OwnerOrganization, "someone": OwnerUser},
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/cache/restore", Version: "v4", File: "ci.yml"},
+		{Name: "someone/tool", Version: "v1", File: "ci.yml", Line: 12},
+		{Name: "someone/tool", Version: "v1", File: "release.yml"},
+		{Name: "private/action", Version: "v1", File: "ci.yml"},
+		{Name: "someone/${{ inputs.tool }}", Version: "v1", File: "ci.yml"},
+		{Name: "docker://alpine", Version: "3", File: "ci.yml"},
+		{Name: "orb:circleci/node", Version: "5.2.0", File: ".circleci/config.yml"},
+		{Name: "https://code.forgejo.org/actions/checkout", Version: "v4", File: ".forgejo/workflows/ci.yml"},
+		{Name: "gitlab.com/components/sast/sast", Version: "2.0.0", File: ".gitlab-ci.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client)).Owners(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"actions/checkout": OwnerOrganization, "actions/cache": OwnerOrganization, "someone/tool": OwnerUser}
+	if len(result.Types) != len(want) {
+		t.Errorf("expected owners %v, got %v", want, result.Types)
+	}
+	for repo, typ := range want {
+		if result.Types[repo] != typ {
+			t.Errorf("expected %s to be owned by a %s, got %q", repo, typ, result.Types[repo])
+		}
+	}
+	// Each personal repository is reported at its first reference
+	if len(result.Personal) != 1 || result.Personal[0].File != "ci.yml" || result.Personal[0].Line != 12 {
+		t.Errorf("expected someone/tool in ci.yml, got %+v", result.Personal)
+	}
+	// Only the inaccessible repository is warned about; the others aren't
+	// looked up
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", result.Warnings)
+	}
+	if calls := client.calls.Load(); calls != 4 {
+		t.Errorf("expected 4 lookups, got %d", calls)
+	}
+}
@@ -0,0 +1,308 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BitbucketPipelinesFile is the pipeline configuration at the root of a
+// Bitbucket repository
+const BitbucketPipelinesFile = "bitbucket-pipelines.yml"
+
+// BitbucketURL is the root of the Bitbucket Cloud API
+const BitbucketURL = "https://api.bitbucket.org/2.0"
+
+// DockerHubURL is the root of the Docker Hub API
+const DockerHubURL = "https://hub.docker.com/v2"
+
+// PipePrefix starts the names of Bitbucket Pipes hosted in Bitbucket
+// repositories ("pipe:atlassian/aws-s3-deploy"), which would otherwise look
+// like actions
+const PipePrefix = "pipe:"
+
+// DockerPrefix starts the names of pipes that are Docker Hub images
+// ("docker://acme/deploy-pipe"), as they're written in pipelines
+const DockerPrefix = "docker://"
+
+// IsPipe reports whether name is a pipe in a Bitbucket repository
+func IsPipe(name string) bool {
+	return strings.HasPrefix(name, PipePrefix)
+}
+
+// IsDockerImage reports whether name is a Docker Hub image
+func IsDockerImage(name string) bool {
+	return strings.HasPrefix(name, DockerPrefix)
+}
+
+// dockerRegistry returns the registry host of an image ("ghcr.io" for
+// docker://ghcr.io/acme/pipe), or "" for Docker Hub. As with docker pull, a
+// first component with a dot or port, or localhost, is a host.
+func dockerRegistry(name string) string {
+	image := strings.TrimPrefix(name, DockerPrefix)
+	first, _, ok := strings.Cut(image, "/")
+	if !ok || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return ""
+	}
+	if first == "docker.io" || first == "index.docker.io" {
+		return ""
+	}
+	return first
+}
+
+// dockerHubRepository returns the Docker Hub repository of an image:
+// "acme/deploy-pipe", or "library/alpine" for an official image
+func dockerHubRepository(name string) string {
+	image := strings.TrimPrefix(name, DockerPrefix)
+	for _, host := range []string{"docker.io/", "index.docker.io/"} {
+		image = strings.TrimPrefix(image, host)
+	}
+	if !strings.Contains(image, "/") {
+		image = "library/" + image // An official image
+	}
+	return image
+}
+
+// PipeURL returns the web page of a pipe's repository or image
+func PipeURL(name string) string {
+	if IsDockerImage(name) {
+		if image, ok := strings.CutPrefix(dockerHubRepository(name), "library/"); ok {
+			return "https://hub.docker.com/_/" + image
+		}
+		return "https://hub.docker.com/r/" + dockerHubRepository(name)
+	}
+	return "https://bitbucket.org/" + strings.TrimPrefix(name, PipePrefix)
+}
+
+// ParseBitbucketPipelines returns the pipes a Bitbucket pipeline runs,
+// once per name and version:
+//
+//	script:
+//	  - pipe: atlassian/aws-s3-deploy:1.1.0
+//	  - pipe: docker://acme/deploy-pipe:2.0.0
+//
+// Pipes in Bitbucket repositories are named with PipePrefix. Images pinned
+// to a digest, without a tag, or on registries other than Docker Hub are
+// left out. file is reported as the references' File.
+func ParseBitbucketPipelines(file string, content []byte) ([]ActionReference, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, &ErrParse{Source: file, Err: err}
+	}
+
+	refs := []ActionReference{}
+	seen := make(map[string]bool)
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Value != "pipe" || value.Kind != yaml.ScalarNode || seen[value.Value] {
+					continue
+				}
+				if name, version, ok := parsePipe(value.Value); ok {
+					seen[value.Value] = true
+					refs = append(refs, ActionReference{Name: name, Version: version, File: file, Line: value.Line})
+				}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(&doc)
+	return refs, nil
+}
+
+// parsePipe splits a pipe reference into the name aver knows it by and its
+// version
+func parsePipe(pipe string) (name, version string, ok bool) {
+	if strings.Contains(pipe, "@") {
+		return "", "", false // Pinned to a digest
+	}
+	image, isDocker := strings.CutPrefix(pipe, DockerPrefix)
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return "", "", false
+	}
+	image, version = image[:i], image[i+1:]
+	if !isDocker {
+		return PipePrefix + image, version, strings.Count(image, "/") == 1
+	}
+	// Only Docker Hub is supported
+	if dockerRegistry(image) != "" {
+		return "", "", false
+	}
+	return DockerPrefix + image, version, true
+}
+
+// bodyPage is a page of a list from an API that links to the following
+// page in the response body: Bitbucket puts items in values and Docker Hub
+// in results
+type bodyPage[T any] struct {
+	Next    string `json:"next"`
+	Values  []T    `json:"values"`
+	Results []T    `json:"results"`
+}
+
+// getBodyPages is getPages for APIs that link to the next page in the
+// body rather than a Link header
+func getBodyPages[T any](ctx context.Context, c *HTTPClient, path string, maxPages int) ([]T, int, error) {
+	var all []T
+	for page := 0; path != "" && (maxPages == 0 || page < maxPages); page++ {
+		var items bodyPage[T]
+		status, _, err := c.get(ctx, path, &items)
+		if err != nil {
+			return nil, status, err
+		}
+		all = append(all, items.Values...)
+		all = append(all, items.Results...)
+
+		// Only follow links back to the same API
+		path = ""
+		if next, ok := strings.CutPrefix(items.Next, c.baseURL()); ok {
+			path = next
+		}
+	}
+	return all, http.StatusOK, nil
+}
+
+// bitbucketTag is a tag from the Bitbucket API
+type bitbucketTag struct {
+	Name   string `json:"name"`
+	Target struct {
+		Hash string    `json:"hash"`
+		Date time.Time `json:"date"`
+	} `json:"target"`
+}
+
+// bitbucketClient answers the GitHubClient calls for pipes from the tags of
+// their Bitbucket repositories. Each tag is also a release, dated by its
+// commit; commit history isn't looked up.
+type bitbucketClient struct {
+	*HTTPClient
+}
+
+func (c *bitbucketClient) tags(ctx context.Context, repo string) ([]bitbucketTag, error) {
+	path := fmt.Sprintf("/repositories/%s/refs/tags?pagelen=%d&sort=-target.date", strings.TrimPrefix(repo, PipePrefix), perPage)
+	tags, status, err := getBodyPages[bitbucketTag](ctx, c.HTTPClient, path, c.maxPages())
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	return tags, nil
+}
+
+func (c *bitbucketClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	tags, err := c.tags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]GitHubTag, len(tags))
+	for i, tag := range tags {
+		converted[i].Name = tag.Name
+		converted[i].Commit.SHA = tag.Target.Hash
+	}
+	return converted, nil
+}
+
+func (c *bitbucketClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	tags, err := c.tags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]GitHubRelease, len(tags))
+	for i, tag := range tags {
+		releases[i] = GitHubRelease{TagName: tag.Name, Name: tag.Name, PublishedAt: tag.Target.Date}
+	}
+	return releases, nil
+}
+
+func (c *bitbucketClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *bitbucketClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *bitbucketClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return 0, errNoCommits
+}
+
+func (c *bitbucketClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+}
+
+func (c *bitbucketClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return nil, fmt.Errorf("can't read the workflows of %s: it's a pipe", repo)
+}
+
+// dockerTag is a tag from the Docker Hub API
+type dockerTag struct {
+	Name          string    `json:"name"`
+	TagLastPushed time.Time `json:"tag_last_pushed"`
+}
+
+// dockerHubClient answers the GitHubClient calls for pipes that are Docker
+// Hub images from the image's tags, dated by when they were last pushed.
+// Digests aren't commits, so tags aren't tracked.
+type dockerHubClient struct {
+	*HTTPClient
+}
+
+func (c *dockerHubClient) tags(ctx context.Context, repo string) ([]dockerTag, error) {
+	path := fmt.Sprintf("/repositories/%s/tags?page_size=%d", dockerHubRepository(repo), perPage)
+	tags, status, err := getBodyPages[dockerTag](ctx, c.HTTPClient, path, c.maxPages())
+	if err != nil {
+		return nil, notAccessible(repo, status, err)
+	}
+	return tags, nil
+}
+
+func (c *dockerHubClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	tags, err := c.tags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]GitHubTag, len(tags))
+	for i, tag := range tags {
+		converted[i].Name = tag.Name
+	}
+	return converted, nil
+}
+
+func (c *dockerHubClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	tags, err := c.tags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]GitHubRelease, len(tags))
+	for i, tag := range tags {
+		releases[i] = GitHubRelease{TagName: tag.Name, Name: tag.Name, PublishedAt: tag.TagLastPushed}
+	}
+	return releases, nil
+}
+
+func (c *dockerHubClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *dockerHubClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return "", errNoCommits
+}
+
+func (c *dockerHubClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return 0, errNoCommits
+}
+
+func (c *dockerHubClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return time.Time{}, &ErrRefNotFound{Repo: repo, Ref: ref}
+}
+
+func (c *dockerHubClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return nil, fmt.Errorf("can't read the workflows of %s: it's an image", repo)
+}
@@ -0,0 +1,116 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestParseBitbucketPipelines(t *testing.T) {
+	refs, err := ParseBitbucketPipelines(BitbucketPipelinesFile, []byte(`pipelines:
+  default:
+    - step:
+        script:
+          - pipe: atlassian/aws-s3-deploy:1.1.0
+            variables:
+              S3_BUCKET: my-bucket
+          - pipe: docker://acme/deploy-pipe:2.0.0
+          - pipe: docker://alpine:3
+          - pipe: docker://ghcr.io/acme/pipe:1.0.0
+          - pipe: docker://localhost/pipe:1.0.0
+          - pipe: docker://acme/pinned@sha256:0123
+          - pipe: atlassian/untagged
+  branches:
+    main:
+      - step:
+          script:
+            - pipe: atlassian/aws-s3-deploy:1.1.0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ActionReference{
+		{Name: "pipe:atlassian/aws-s3-deploy", Version: "1.1.0", File: BitbucketPipelinesFile, Line: 5},
+		{Name: "docker://acme/deploy-pipe", Version: "2.0.0", File: BitbucketPipelinesFile, Line: 8},
+		{Name: "docker://alpine", Version: "3", File: BitbucketPipelinesFile, Line: 9},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("ParseBitbucketPipelines() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ParseBitbucketPipelines()[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+
+	for name, url := range map[string]string{
+		"pipe:atlassian/aws-s3-deploy": "https://bitbucket.org/atlassian/aws-s3-deploy",
+		"docker://acme/deploy-pipe":    "https://hub.docker.com/r/acme/deploy-pipe",
+		"docker://alpine":              "https://hub.docker.com/_/alpine",
+		"docker://docker.io/alpine":    "https://hub.docker.com/_/alpine",
+	} {
+		if got := PipeURL(name); got != url {
+			t.Errorf("PipeURL(%q) = %q, want %q", name, got, url)
+		}
+	}
+}
+
+func TestCheckerPipes(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/atlassian/aws-s3-deploy/refs/tags":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"values": [{"name": "1.6.0", "target": {"hash": "abc", "date": "2026-03-01T00:00:00Z"}}],
+					"next": "%s/repositories/atlassian/aws-s3-deploy/refs/tags?page=2"}`, server.URL)
+				return
+			}
+			_, _ = w.Write([]byte(`{"values": [{"name": "1.1.0", "target": {"hash": "def", "date": "2026-01-01T00:00:00Z"}}]}`))
+		case "/repositories/library/alpine/tags":
+			_, _ = w.Write([]byte(`{"results": [{"name": "latest"}, {"name": "3.21"}, {"name": "3"}, {"name": "4.0"}], "next": null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hc := NewHTTPClient("")
+	hc.BaseURL = server.URL
+	client := &routedClient{
+		fallback:  &fakeClient{},
+		pipes:     &bitbucketClient{HTTPClient: hc},
+		dockerHub: &dockerHubClient{HTTPClient: hc},
+	}
+	refs := []ActionReference{
+		{Name: "pipe:atlassian/aws-s3-deploy", Version: "1.1.0"},
+		{Name: "docker://alpine", Version: "3"},
+		{Name: "docker://acme/missing", Version: "1.0.0"},
+		{Name: "docker://docker.io/library/alpine", Version: "3.21"},
+		{Name: "docker://ghcr.io/acme/pipe", Version: "1.0.0"},
+	}
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Outdated) != 3 {
+		t.Fatalf("Outdated = %+v, want the pipe and alpine twice", result.Outdated)
+	}
+	if got := result.Outdated[0]; got.LatestVersion != "1.6.0" || got.DaysBehind != 59 {
+		t.Errorf("Outdated[0] = %+v, want 1.6.0, 59 days behind", got)
+	}
+	if got := result.Outdated[1]; got.LatestVersion != "4.0" {
+		t.Errorf("Outdated[1] = %+v, want 3 -> 4.0", got)
+	}
+	if got := result.Outdated[2]; got.LatestVersion != "4.0" {
+		t.Errorf("Outdated[2] = %+v, want docker.io's alpine looked up on Docker Hub", got)
+	}
+	if len(result.Warnings) != 2 || !slices.Contains(result.Warnings, "skipping docker://ghcr.io/acme/pipe: image on ghcr.io, not Docker Hub") {
+		t.Errorf("Warnings = %q, want the missing image and the ghcr.io one skipped", result.Warnings)
+	}
+	if result.Resolved["pipe:atlassian/aws-s3-deploy@1.1.0"] != "def" {
+		t.Errorf("Resolved = %v, want the pipe's tag commit", result.Resolved)
+	}
+}
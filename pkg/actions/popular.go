@@ -0,0 +1,137 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// popularActions are the repositories of the most used actions, whose
+// names attackers imitate: with a typo (actions/chekout, action/checkout),
+// or with a fork that changes a little of the code, e.g. to send secrets
+// away. Keep it sorted.
+var popularActions = []string{
+	"actions/cache",
+	"actions/checkout",
+	"actions/configure-pages",
+	"actions/create-github-app-token",
+	"actions/dependency-review-action",
+	"actions/deploy-pages",
+	"actions/download-artifact",
+	"actions/github-script",
+	"actions/labeler",
+	"actions/setup-dotnet",
+	"actions/setup-go",
+	"actions/setup-java",
+	"actions/setup-node",
+	"actions/setup-python",
+	"actions/stale",
+	"actions/upload-artifact",
+	"actions/upload-pages-artifact",
+	"aws-actions/amazon-ecr-login",
+	"aws-actions/configure-aws-credentials",
+	"azure/login",
+	"codecov/codecov-action",
+	"docker/build-push-action",
+	"docker/login-action",
+	"docker/metadata-action",
+	"docker/setup-buildx-action",
+	"docker/setup-qemu-action",
+	"dorny/paths-filter",
+	"dtolnay/rust-toolchain",
+	"github/codeql-action",
+	"golangci/golangci-lint-action",
+	"google-github-actions/auth",
+	"google-github-actions/setup-gcloud",
+	"goreleaser/goreleaser-action",
+	"hashicorp/setup-terraform",
+	"peaceiris/actions-gh-pages",
+	"peter-evans/create-pull-request",
+	"pnpm/action-setup",
+	"pypa/gh-action-pypi-publish",
+	"ruby/setup-ruby",
+	"softprops/action-gh-release",
+	"subosito/flutter-action",
+	"swatinem/rust-cache",
+	"tj-actions/changed-files",
+}
+
+// Typosquat is a reference to an action whose repository's name is a typo
+// or two away from a popular action's, e.g. actions/chekout
+type Typosquat struct {
+	Repository string `json:"repository,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Name       string `json:"action"`
+	Version    string `json:"version"`
+	Resembles  string `json:"resembles"` // The popular action, e.g. "actions/checkout"
+}
+
+// Message describes the finding in a sentence
+func (t Typosquat) Message() string {
+	return fmt.Sprintf("%s is named like the popular action %s; make sure it's the action you meant", repoFromAction(t.Name), t.Resembles)
+}
+
+// FindTyposquats returns the first reference to each action whose
+// repository's owner/name is one or two edits away from a popular action's
+// without being it. Nothing is looked up.
+func FindTyposquats(refs []ActionReference) []Typosquat {
+	var found []Typosquat
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.Dynamic() || !githubAction(ref.Name) {
+			continue
+		}
+		repo := repoFromAction(ref.Name)
+		if seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		if popular := misspelledPopular(repo); popular != "" {
+			found = append(found, Typosquat{
+				Repository: ref.Repository,
+				File:       ref.File,
+				Line:       ref.Line,
+				Name:       ref.Name,
+				Version:    ref.Version,
+				Resembles:  popular,
+			})
+		}
+	}
+	return found
+}
+
+// misspelledPopular returns the popular action repo, an owner/name, is a
+// typo of, or "" if it's none or is popular itself. Names are compared
+// case-insensitively, as GitHub does.
+func misspelledPopular(repo string) string {
+	repo = strings.ToLower(repo)
+	best, bestDistance := "", 3
+	for _, popular := range popularActions {
+		if repo == popular {
+			return ""
+		}
+		if d := editDistance(repo, popular); d < bestDistance {
+			best, bestDistance = popular, d
+		}
+	}
+	return best
+}
+
+// resemblesPopular returns the popular action that repo, an owner/name,
+// imitates: one with the same name, or a name or owner/name a typo or two
+// away, under another owner. It returns "" if there's none.
+func resemblesPopular(repo string) string {
+	repo = strings.ToLower(repo)
+	owner, name, _ := strings.Cut(repo, "/")
+	for _, popular := range popularActions {
+		popularOwner, popularName, _ := strings.Cut(popular, "/")
+		if owner == popularOwner {
+			continue
+		}
+		if name == popularName || (len(popularName) > 4 && editDistance(name, popularName) == 1) ||
+			editDistance(repo, popular) <= 2 {
+			return popular
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package actions
+
+import "testing"
+
+func TestFindTyposquats(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/chekout", Version: "v4", File: "ci.yml", Line: 7},
+		{Name: "actions/chekout", Version: "v4", File: "release.yml", Line: 3},
+		{Name: "action/checkout", Version: "v4", File: "ci.yml", Line: 9},
+		{Name: "Docker/Login-Actoin", Version: "v3", File: "ci.yml", Line: 11},
+		{Name: "actions/cache/restore", Version: "v4", File: "ci.yml"},
+		{Name: "owner/tool", Version: "v1", File: "ci.yml"},
+		{Name: "actions/${{ inputs.checkout }}", Version: "v4", File: "ci.yml"},
+		{Name: "docker://actions/checkout", Version: "v4", File: "ci.yml"},
+	}
+	want := []Typosquat{
+		{File: "ci.yml", Line: 7, Name: "actions/chekout", Version: "v4", Resembles: "actions/checkout"},
+		{File: "ci.yml", Line: 9, Name: "action/checkout", Version: "v4", Resembles: "actions/checkout"},
+		{File: "ci.yml", Line: 11, Name: "Docker/Login-Actoin", Version: "v3", Resembles: "docker/login-action"},
+	}
+	got := FindTyposquats(refs)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d typosquats, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("typosquat %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+	if msg := got[0].Message(); msg != "actions/chekout is named like the popular action actions/checkout; make sure it's the action you meant" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestPopularActionsApart(t *testing.T) {
+	// A popular action mustn't look like a typo of another
+	for i, a := range popularActions {
+		for _, b := range popularActions[i+1:] {
+			if d := editDistance(a, b); d < 3 {
+				t.Errorf("%s and %s are only %d edits apart", a, b, d)
+			}
+		}
+	}
+}
+
+func TestResemblesPopular(t *testing.T) {
+	for _, tt := range []struct{ repo, want string }{
+		{"evil/checkout", "actions/checkout"},
+		{"Evil/Checkout", "actions/checkout"},
+		{"actions/checkout", ""},
+		{"someone/chekout", "actions/checkout"},
+		{"actlons/setup-go", "actions/setup-go"},
+		{"docker/login-action", ""},
+		{"dockr/login-action", "docker/login-action"},
+		{"someone/setup-rust", ""},
+		{"someone/cach", "actions/cache"},
+		{"someone/cat", ""},
+		{"owner/tool", ""},
+	} {
+		if got := resemblesPopular(tt.repo); got != tt.want {
+			t.Errorf("resemblesPopular(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package actions
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Ways of grouping findings, for CheckResult.GroupBy
+const (
+	GroupByFile   = "file"
+	GroupByAction = "action"
+	GroupByOwner  = "owner"
+)
+
+// Groupings are the keys CheckResult.GroupBy accepts
+var Groupings = []string{GroupByFile, GroupByAction, GroupByOwner}
+
+// Orders of findings, for CheckResult.Sort
+const (
+	SortBySeverity = "severity"
+	SortByCommits  = "commits"
+	SortByAge      = "age"
+)
+
+// SortOrders are the orders CheckResult.Sort accepts
+var SortOrders = []string{SortBySeverity, SortByCommits, SortByAge}
+
+// Group is the part of a CheckResult sharing a key, e.g. an action or owner
+type Group struct {
+	Key    string
+	Result CheckResult
+}
+
+// GroupBy splits the findings by file, action or owner, in the order each
+// key first appears. Warnings and Resolved aren't split.
+func (r CheckResult) GroupBy(by string) []Group {
+	return r.split(func(_, file, name string) string {
+		switch by {
+		case GroupByAction:
+			return name
+		case GroupByOwner:
+			return ownerOf(name)
+		}
+		return file
+	})
+}
+
+// split groups the findings by key, which is given each finding's
+// repository, file and action name
+func (r CheckResult) split(key func(repository, file, name string) string) []Group {
+	var groups []Group
+	index := make(map[string]int)
+	group := func(k string) *CheckResult {
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, Group{Key: k})
+		}
+		return &groups[i].Result
+	}
+	for _, a := range r.Outdated {
+		g := group(key(a.Repository, a.File, a.Name))
+		g.Outdated = append(g.Outdated, a)
+	}
+	for _, a := range r.SHAPinned {
+		g := group(key(a.Repository, a.File, a.Name))
+		g.SHAPinned = append(g.SHAPinned, a)
+	}
+	for _, a := range r.Unchecked {
+		g := group(key(a.Repository, a.File, a.Name))
+		g.Unchecked = append(g.Unchecked, a)
+	}
+	for _, m := range r.Moved {
+		g := group(key(m.Repository, m.File, m.Name))
+		g.Moved = append(g.Moved, m)
+	}
+	for _, d := range r.Dynamic {
+		g := group(key(d.Repository, d.File, d.Name))
+		g.Dynamic = append(g.Dynamic, d)
+	}
+	for _, f := range r.Inputs {
+		g := group(key(f.Repository, f.File, f.Name))
+		g.Inputs = append(g.Inputs, f)
+	}
+	for _, d := range r.Commands {
+		// Grouped by action, commands are under their own name
+		g := group(key(d.Repository, d.File, d.Command))
+		g.Commands = append(g.Commands, d)
+	}
+	return groups
+}
+
+// ownerOf returns who publishes an action: the owner of its repository
+// (with the host for GitLab components and actions named by URL), or the
+// namespace of an orb, pipe or image. Official Docker images are
+// docker://library.
+func ownerOf(name string) string {
+	repo := repoFromAction(name)
+	prefix := ""
+	for _, p := range []string{OrbPrefix, PipePrefix, DockerPrefix, TaskPrefix} {
+		if rest, ok := strings.CutPrefix(repo, p); ok {
+			prefix, repo = p, rest
+			break
+		}
+	}
+	if i := strings.LastIndex(repo, "/"); i >= 0 {
+		return prefix + repo[:i]
+	}
+	if prefix == DockerPrefix {
+		return prefix + "library"
+	}
+	return prefix + repo
+}
+
+// ByOwner keeps the findings about actions published by any of owners, as
+// ownerOf names them ("actions", "my-org"), ignoring case. Deprecated
+// workflow commands belong to no action, so they're dropped. Warnings,
+// Stats and the rest of the result still describe everything checked.
+func (r CheckResult) ByOwner(owners []string) CheckResult {
+	if len(owners) == 0 {
+		return r
+	}
+	owned := func(name string) bool {
+		return slices.ContainsFunc(owners, func(o string) bool { return strings.EqualFold(o, ownerOf(name)) })
+	}
+	r.Outdated = keep(r.Outdated, func(a OutdatedAction) bool { return owned(a.Name) })
+	r.SHAPinned = keep(r.SHAPinned, func(a SHAPinnedAction) bool { return owned(a.Name) })
+	r.Unchecked = keep(r.Unchecked, func(a UncheckedAction) bool { return owned(a.Name) })
+	r.Moved = keep(r.Moved, func(m MovedTag) bool { return owned(m.Name) })
+	r.Dynamic = keep(r.Dynamic, func(d DynamicRef) bool { return owned(d.Name) })
+	r.Inputs = keep(r.Inputs, func(f InputFinding) bool { return owned(f.Name) })
+	r.Typosquats = keep(r.Typosquats, func(t Typosquat) bool { return owned(t.Name) })
+	r.Forks = keep(r.Forks, func(f SuspiciousFork) bool { return owned(f.Name) })
+	r.Drift = keep(r.Drift, func(d VersionDrift) bool { return owned(d.Name) })
+	r.Commands = nil
+	return r
+}
+
+// keep returns the items f is true for, without changing items
+func keep[T any](items []T, f func(T) bool) []T {
+	var kept []T
+	for _, item := range items {
+		if f(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// Sort orders the outdated and SHA-pinned findings, most pressing first:
+// by severity (major updates before minor ones, then patches), by how many
+// commits behind they are, or by how many days older than the latest
+// version they are. Ties keep their order, as do findings Sort can't rank,
+// such as SHA pins by age.
+func (r CheckResult) Sort(by string) CheckResult {
+	r.Outdated = slices.Clone(r.Outdated)
+	r.SHAPinned = slices.Clone(r.SHAPinned)
+	// The only measure of a SHA pin is how far behind it is
+	byCommits := func(a, b SHAPinnedAction) int { return cmp.Compare(b.CommitsBehind, a.CommitsBehind) }
+	switch by {
+	case SortBySeverity:
+		slices.SortStableFunc(r.Outdated, func(a, b OutdatedAction) int {
+			return cmp.Compare(severityRank(a), severityRank(b))
+		})
+		slices.SortStableFunc(r.SHAPinned, byCommits)
+	case SortByCommits:
+		slices.SortStableFunc(r.Outdated, func(a, b OutdatedAction) int {
+			return cmp.Compare(b.CommitsBehind, a.CommitsBehind)
+		})
+		slices.SortStableFunc(r.SHAPinned, byCommits)
+	case SortByAge:
+		slices.SortStableFunc(r.Outdated, func(a, b OutdatedAction) int {
+			return cmp.Compare(b.DaysBehind, a.DaysBehind)
+		})
+	}
+	return r
+}
+
+// severity classifies an outdated action by the most significant part of
+// the version that changes: "major", "minor" or "patch", or "other" for
+// versions that aren't semantic
+func severity(a OutdatedAction) string {
+	return cmp.Or(UpdateType(a.CurrentVersion, a.LatestVersion), "other")
+}
+
+// UpdateType classifies the update from one version to another by the most
+// significant part that changes: "major", "minor" or "patch", or "" if
+// either isn't a semantic version
+func UpdateType(from, to string) string {
+	current, latest := parseSemver(from), parseSemver(to)
+	if current == nil || latest == nil {
+		return ""
+	}
+	return updateType(current, latest)
+}
+
+// severityRank orders severities, most severe first
+func severityRank(a OutdatedAction) int {
+	return slices.Index([]string{"major", "minor", "patch", "other"}, severity(a))
+}
+
+// Dedupe merges identical findings in different files into the first of
+// them, listing all their files in Files: outdated actions with the same
+// current and latest versions, SHA pins of the same commit, and unchecked
+// and dynamic references to the same version. Findings in different
+// repositories of a scan aren't merged. Findings in a single file keep
+// Files empty.
+func (r CheckResult) Dedupe() CheckResult {
+	r.Outdated = dedupe(r.Outdated,
+		func(a OutdatedAction) string {
+			return a.Repository + " " + a.Name + "@" + a.CurrentVersion + "..." + a.LatestVersion
+		},
+		func(a *OutdatedAction) (string, *[]string) { return a.File, &a.Files })
+	r.SHAPinned = dedupe(r.SHAPinned,
+		func(a SHAPinnedAction) string { return a.Repository + " " + a.Name + "@" + a.CurrentSHA },
+		func(a *SHAPinnedAction) (string, *[]string) { return a.File, &a.Files })
+	r.Unchecked = dedupe(r.Unchecked,
+		func(a UncheckedAction) string { return a.Repository + " " + a.Name + "@" + a.Version },
+		func(a *UncheckedAction) (string, *[]string) { return a.File, &a.Files })
+	r.Dynamic = dedupe(r.Dynamic,
+		func(d DynamicRef) string { return d.Repository + " " + d.Name + "@" + d.Ref },
+		func(d *DynamicRef) (string, *[]string) { return d.File, &d.Files })
+	return r
+}
+
+// dedupe merges the items with the same key, collecting their distinct
+// files in the Files of the first
+func dedupe[T any](items []T, key func(T) string, files func(*T) (string, *[]string)) []T {
+	var merged []T
+	index := make(map[string]int)
+	for _, item := range items {
+		file, _ := files(&item)
+		k := key(item)
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(merged)
+			merged = append(merged, item)
+			continue
+		}
+		first, all := files(&merged[i])
+		if len(*all) == 0 {
+			*all = []string{first}
+		}
+		if !slices.Contains(*all, file) {
+			*all = append(*all, file)
+		}
+	}
+	// Several references in one file are still one file
+	for i := range merged {
+		if _, all := files(&merged[i]); len(*all) == 1 {
+			*all = nil
+		}
+	}
+	return merged
+}
+
+// filesOf returns the files of a finding that may have been merged
+func filesOf(file string, files []string) []string {
+	if len(files) > 0 {
+		return files
+	}
+	return []string{file}
+}
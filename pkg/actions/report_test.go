@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	result := CheckResult{
+		Outdated: []OutdatedAction{
+			{File: "ci.yml", Name: "actions/checkout"},
+			{File: "release.yml", Name: "actions/setup-go"},
+			{File: "release.yml", Name: "actions/checkout"},
+		},
+		SHAPinned: []SHAPinnedAction{{File: "ci.yml", Name: "docker/login-action"}},
+	}
+
+	var keys []string
+	for _, g := range result.GroupBy(GroupByAction) {
+		keys = append(keys, g.Key)
+	}
+	if !slices.Equal(keys, []string{"actions/checkout", "actions/setup-go", "docker/login-action"}) {
+		t.Errorf("unexpected action groups: %v", keys)
+	}
+
+	groups := result.GroupBy(GroupByOwner)
+	if len(groups) != 2 || groups[0].Key != "actions" || len(groups[0].Result.Outdated) != 3 ||
+		groups[1].Key != "docker" || len(groups[1].Result.SHAPinned) != 1 {
+		t.Errorf("unexpected owner groups: %+v", groups)
+	}
+
+	groups = result.GroupBy(GroupByFile)
+	if len(groups) != 2 || groups[0].Key != "ci.yml" || len(groups[0].Result.Outdated) != 1 || len(groups[0].Result.SHAPinned) != 1 {
+		t.Errorf("unexpected file groups: %+v", groups)
+	}
+}
+
+func TestOwnerOf(t *testing.T) {
+	for name, want := range map[string]string{
+		"actions/checkout":                      "actions",
+		"github/codeql-action/init":             "github",
+		"https://codeberg.org/forgejo/checkout": "https://codeberg.org/forgejo",
+		"gitlab.com/components/opentofu/full":   "gitlab.com/components",
+		"orb:circleci/node":                     "orb:circleci",
+		"pipe:atlassian/aws-s3-deploy":          "pipe:atlassian",
+		"docker://alpine":                       "docker://library",
+		"docker://ghcr.io/owner/image":          "docker://ghcr.io/owner",
+		"task:DotNetCoreCLI":                    "task:DotNetCoreCLI",
+	} {
+		if got := ownerOf(name); got != want {
+			t.Errorf("ownerOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestByOwner(t *testing.T) {
+	result := CheckResult{
+		Outdated: []OutdatedAction{
+			{File: "ci.yml", Name: "actions/checkout"},
+			{File: "ci.yml", Name: "my-org/deploy"},
+			{File: "ci.yml", Name: "docker/login-action"},
+		},
+		SHAPinned: []SHAPinnedAction{{File: "ci.yml", Name: "github/codeql-action/init"}},
+		Drift:     []VersionDrift{{Name: "actions/setup-go"}, {Name: "other/tool"}},
+		Commands:  []DeprecatedCommand{{File: "ci.yml", Command: "set-output"}},
+		Warnings:  []string{"skipping docker/login-action"},
+	}
+
+	owned := result.ByOwner([]string{"Actions", "my-org"})
+	var names []string
+	for _, a := range owned.Outdated {
+		names = append(names, a.Name)
+	}
+	if !slices.Equal(names, []string{"actions/checkout", "my-org/deploy"}) {
+		t.Errorf("expected the outdated actions of actions and my-org, got %v", names)
+	}
+	if len(owned.SHAPinned) != 0 || len(owned.Drift) != 1 || owned.Drift[0].Name != "actions/setup-go" {
+		t.Errorf("unexpected SHA pins %+v or drift %+v", owned.SHAPinned, owned.Drift)
+	}
+	if len(owned.Commands) != 0 || len(owned.Warnings) != 1 {
+		t.Errorf("expected commands dropped and warnings kept, got %+v and %v", owned.Commands, owned.Warnings)
+	}
+	if len(result.Outdated) != 3 {
+		t.Error("ByOwner changed the result it was called on")
+	}
+
+	if all := result.ByOwner(nil); len(all.Outdated) != 3 || len(all.Commands) != 1 {
+		t.Errorf("ByOwner(nil) dropped findings: %+v", all)
+	}
+}
+
+func TestSort(t *testing.T) {
+	result := CheckResult{
+		Outdated: []OutdatedAction{
+			{Name: "a", CurrentVersion: "v1.0.0", LatestVersion: "v1.0.1", CommitsBehind: 3, DaysBehind: 400},
+			{Name: "b", CurrentVersion: "nightly-1", LatestVersion: "nightly-2", CommitsBehind: 1, DaysBehind: 10},
+			{Name: "c", CurrentVersion: "v1", LatestVersion: "v2", CommitsBehind: 50, DaysBehind: 30},
+			{Name: "d", CurrentVersion: "v1.1", LatestVersion: "v1.2", DaysBehind: 90},
+		},
+		SHAPinned: []SHAPinnedAction{{Name: "e", CommitsBehind: 2}, {Name: "f", CommitsBehind: 20}},
+	}
+	names := func(r CheckResult) []string {
+		var names []string
+		for _, a := range r.Outdated {
+			names = append(names, a.Name)
+		}
+		for _, a := range r.SHAPinned {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+
+	for by, want := range map[string][]string{
+		SortBySeverity: {"c", "d", "a", "b", "f", "e"},
+		SortByCommits:  {"c", "a", "b", "d", "f", "e"},
+		SortByAge:      {"a", "d", "c", "b", "e", "f"},
+		"":             {"a", "b", "c", "d", "e", "f"},
+	} {
+		if got := names(result.Sort(by)); !slices.Equal(got, want) {
+			t.Errorf("Sort(%q) = %v, want %v", by, got, want)
+		}
+	}
+	if result.Outdated[0].Name != "a" {
+		t.Error("Sort changed the original result")
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	result := CheckResult{
+		Outdated: []OutdatedAction{
+			{File: "a.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4", Line: 5},
+			{File: "b.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"},
+			{File: "b.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"},
+			{File: "c.yml", Name: "actions/checkout", CurrentVersion: "v2", LatestVersion: "v4"},
+			{Repository: "owner/other", File: "a.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"},
+		},
+		SHAPinned: []SHAPinnedAction{
+			{File: "a.yml", Name: "actions/cache", CurrentSHA: "abc1234"},
+			{File: "a.yml", Name: "actions/cache", CurrentSHA: "abc1234"},
+		},
+		Unchecked: []UncheckedAction{
+			{File: "a.yml", Name: "actions/setup-go", Version: "v5"},
+			{File: "b.yml", Name: "actions/setup-go", Version: "v5"},
+		},
+	}
+
+	deduped := result.Dedupe()
+	if len(deduped.Outdated) != 3 {
+		t.Fatalf("expected 3 outdated findings, got %+v", deduped.Outdated)
+	}
+	first := deduped.Outdated[0]
+	if first.File != "a.yml" || first.Line != 5 || !slices.Equal(first.Files, []string{"a.yml", "b.yml"}) {
+		t.Errorf("unexpected merged finding: %+v", first)
+	}
+	if deduped.Outdated[1].Files != nil || deduped.Outdated[2].Repository != "owner/other" {
+		t.Errorf("expected other versions and repositories to stay apart: %+v", deduped.Outdated[1:])
+	}
+	// Two references in one file are one file
+	if len(deduped.SHAPinned) != 1 || deduped.SHAPinned[0].Files != nil {
+		t.Errorf("unexpected SHA pins: %+v", deduped.SHAPinned)
+	}
+	if len(deduped.Unchecked) != 1 || len(deduped.Unchecked[0].Files) != 2 {
+		t.Errorf("unexpected unchecked actions: %+v", deduped.Unchecked)
+	}
+	if result.Outdated[0].Files != nil {
+		t.Error("Dedupe changed the original result")
+	}
+}
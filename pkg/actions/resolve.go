@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ResolveResult is the commit every mutable reference points at
+type ResolveResult struct {
+	// Refs maps "owner/repo@ref" to a commit SHA. SHA pins are left out,
+	// since they can't move, as are dynamic refs, which can't be resolved.
+	Refs     map[string]string
+	Warnings []string // References that could not be resolved
+}
+
+// Resolve looks up the commit each tag or branch in refs points at. Each
+// repo@ref is resolved once, however many workflows use it.
+func (c *Checker) Resolve(ctx context.Context, refs []ActionReference) (ResolveResult, error) {
+	var keys []string
+	for _, ref := range refs {
+		if ref.Dynamic() {
+			continue
+		}
+		if key := repoFromAction(ref.Name) + "@" + ref.Version; !isSHA(ref.Version) && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	r := &run{
+		tags:     newTagCache(c.client),
+		branches: &branchCache{GitHubClient: c.client},
+	}
+	shas := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			repo, ref, _ := strings.Cut(key, "@")
+			shas[i], errs[i] = c.resolveCommit(ctx, r, repo, ref)
+		})
+	}
+	wg.Wait()
+
+	result := ResolveResult{Refs: make(map[string]string)}
+	for i, key := range keys {
+		err := errs[i]
+		switch {
+		case err == nil:
+			result.Refs[key] = shas[i]
+		case errors.Is(err, &ErrRepoNotAccessible{}), errors.Is(err, &ErrRefNotFound{}), errors.Is(err, &ErrNotCached{}):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipping %s: %v", key, err))
+		default:
+			return ResolveResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// resolveCommit returns the commit a tag or, failing that, a branch points at
+func (c *Checker) resolveCommit(ctx context.Context, r *run, repo, ref string) (string, error) {
+	tags, err := r.tags.getTags(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag.Name == ref && tag.Commit.SHA != "" {
+			return tag.Commit.SHA, nil
+		}
+	}
+	return r.branches.BranchHead(ctx, repo, ref)
+}
+
+// RepoReferences returns the actions used by the workflows of a repository
+// at ref, or on its default branch if ref is empty, fetched through the
+// API. Workflows that can't be parsed are reported in an ErrUnparsed, as
+// with FindActionReferences.
+func (c *Checker) RepoReferences(ctx context.Context, repo, ref string) ([]ActionReference, error) {
+	files, err := c.client.Workflows(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	refs := []ActionReference{}
+	var skipped unparsed
+	for _, file := range files {
+		fileRefs, err := ParseWorkflow(file.Path, file.Content)
+		if err := skipped.skip(err); err != nil {
+			return nil, err
+		}
+		refs = append(refs, fileRefs...)
+	}
+	return refs, skipped.err()
+}
@@ -0,0 +1,75 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckerResolve(t *testing.T) {
+	tag := func(name, sha string) GitHubTag {
+		g := GitHubTag{Name: name}
+		g.Commit.SHA = sha
+		return g
+	}
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {tag("v4", "sha4")},
+			"actions/cache":    {tag("v4", "cache4")},
+			"owner/tool":       {},
+		},
+		heads: map[string]string{"owner/tool": "mainsha"},
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "a.yml"},
+		{Name: "actions/checkout", Version: "v4", File: "b.yml"},
+		{Name: "actions/cache/restore", Version: "v4", File: "a.yml"},
+		{Name: "owner/tool", Version: "main", File: "a.yml"},
+		{Name: "actions/setup-go", Version: "abcdef1234567890abcdef1234567890abcdef12", File: "a.yml"},
+		{Name: "missing/repo", Version: "v1", File: "a.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client)).Resolve(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"actions/checkout@v4": "sha4",
+		"actions/cache@v4":    "cache4",
+		"owner/tool@main":     "mainsha",
+	}
+	if len(result.Refs) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, result.Refs)
+	}
+	for key, sha := range expected {
+		if result.Refs[key] != sha {
+			t.Errorf("%s: expected %s, got %s", key, sha, result.Refs[key])
+		}
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected a warning for the missing repo, got %v", result.Warnings)
+	}
+}
+
+func TestCheckerRepoReferences(t *testing.T) {
+	client := &fakeClient{workflows: map[string][]WorkflowFile{
+		"owner/app": {
+			{Path: ".github/workflows/ci.yml", Content: []byte("steps:\n  - uses: actions/checkout@v4\n  - uses: actions/checkout@v4\n")},
+			{Path: ".github/workflows/release.yml", Content: []byte("steps:\n  - uses: actions/setup-go@v5\n")},
+		},
+		"owner/broken": {{Path: ".github/workflows/bad.yml", Content: []byte("steps: [")}},
+	}}
+	checker := NewChecker(WithClient(client))
+
+	refs, err := checker.RepoReferences(context.Background(), "owner/app", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(refs) != 2 || refs[0].File != ".github/workflows/ci.yml" || refs[1].Name != "actions/setup-go" {
+		t.Errorf("unexpected references: %+v", refs)
+	}
+
+	if _, err := checker.RepoReferences(context.Background(), "owner/broken", ""); !errors.Is(err, &ErrParse{}) {
+		t.Errorf("expected ErrParse, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Route sends API calls for the actions under Prefix to a different GitHub
+// host, e.g. github.com actions used from a GitHub Enterprise Server
+// workflow.
+type Route struct {
+	Prefix  string // An owner ("actions") or repository ("actions/checkout")
+	BaseURL string // API root for matching actions
+	Token   string // Optional token for BaseURL
+}
+
+// ValidatePrefix checks that prefix names an owner or an owner/repo
+func ValidatePrefix(prefix string) error {
+	parts := strings.Split(prefix, "/")
+	if len(parts) > 2 || slices.Contains(parts, "") {
+		return fmt.Errorf("invalid host prefix %q: expected owner or owner/repo", prefix)
+	}
+	return nil
+}
+
+// MatchRoute returns the route whose prefix best matches an action name.
+// A repository prefix beats an owner prefix.
+func MatchRoute(routes []Route, name string) (Route, bool) {
+	var best Route
+	bestScore := 0
+	for _, route := range routes {
+		if score := matchPrefix(route.Prefix, name); score > bestScore {
+			best, bestScore = route, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// matchPrefix reports how specifically prefix matches an action name: 3 if
+// it's the full name of an action in a subdirectory, 2 if it names the
+// action's repository, 1 if it names its owner, 0 otherwise
+func matchPrefix(prefix, name string) int {
+	repo := repoFromAction(name)
+	owner, _, _ := strings.Cut(repo, "/")
+	switch prefix {
+	case repo:
+		return 2
+	case owner:
+		return 1
+	case name:
+		return 3
+	}
+	return 0
+}
+
+// matchesAny reports whether any of prefixes matches an action name
+func matchesAny(prefixes []string, name string) bool {
+	return slices.ContainsFunc(prefixes, func(prefix string) bool {
+		return matchPrefix(prefix, name) > 0
+	})
+}
+
+// routedClient dispatches each call to the client for the repo's route,
+// or to fallback if no route matches. Actions named by URL go to the Gitea
+// API of their host, GitLab components to their GitLab instance, orbs to
+// the CircleCI orb registry, pipes to Bitbucket or Docker Hub, and Azure
+// Pipelines tasks to the repository of built-in tasks.
+type routedClient struct {
+	routes    []Route
+	clients   map[string]GitHubClient // by route prefix
+	fallback  GitHubClient
+	forges    *hostClients // by URL scheme and host
+	gitlab    *hostClients // by host
+	orbs      GitHubClient
+	pipes     GitHubClient
+	dockerHub GitHubClient
+	tasks     GitHubClient
+}
+
+func (c *routedClient) client(repo string) GitHubClient {
+	if host, _, ok := forgeRepo(repo); ok && c.forges != nil {
+		return c.forges.client(host)
+	}
+	if host, ok := gitlabHost(repo); ok && c.gitlab != nil {
+		return c.gitlab.client(host)
+	}
+	switch {
+	case IsOrb(repo) && c.orbs != nil:
+		return c.orbs
+	case IsPipe(repo) && c.pipes != nil:
+		return c.pipes
+	case IsDockerImage(repo) && c.dockerHub != nil:
+		return c.dockerHub
+	case IsAzureTask(repo) && c.tasks != nil:
+		return c.tasks
+	}
+	if route, ok := MatchRoute(c.routes, repo); ok {
+		return c.clients[route.Prefix]
+	}
+	return c.fallback
+}
+
+// mainClient returns the client of the main API host, which serves every
+// repository no route or other ecosystem claims
+func (c *Checker) mainClient() GitHubClient {
+	if routed, ok := c.client.(*routedClient); ok {
+		return routed.fallback
+	}
+	return c.client
+}
+
+// clientFor returns the client that serves repo, looking through routes
+func (c *Checker) clientFor(repo string) GitHubClient {
+	if routed, ok := c.client.(*routedClient); ok {
+		return routed.client(repo)
+	}
+	return c.client
+}
+
+func (c *routedClient) Tags(ctx context.Context, repo string) ([]GitHubTag, error) {
+	return c.client(repo).Tags(ctx, repo)
+}
+
+func (c *routedClient) Releases(ctx context.Context, repo string) ([]GitHubRelease, error) {
+	return c.client(repo).Releases(ctx, repo)
+}
+
+func (c *routedClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return c.client(repo).DefaultBranch(ctx, repo)
+}
+
+func (c *routedClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return c.client(repo).BranchHead(ctx, repo, branch)
+}
+
+func (c *routedClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return c.client(repo).CompareCommits(ctx, repo, base, head)
+}
+
+func (c *routedClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return c.client(repo).CommitDate(ctx, repo, ref)
+}
+
+func (c *routedClient) Workflows(ctx context.Context, repo, ref string) ([]WorkflowFile, error) {
+	return c.client(repo).Workflows(ctx, repo, ref)
+}
+
+// Repository fetches repositories from GitHub hosts only. Gitea's API
+// doesn't say what kind of account owns a repository, and other ecosystems
+// have no repositories, so their information is empty.
+func (c *routedClient) Repository(ctx context.Context, repo string) (GitHubRepo, error) {
+	if client, ok := c.client(repo).(*HTTPClient); ok && !client.Gitea {
+		return client.Repository(ctx, repo)
+	}
+	return GitHubRepo{}, nil
+}
+
+// File fetches files from GitHub and Forgejo or Gitea hosts only; other
+// ecosystems have no files to read, so there are none
+func (c *routedClient) File(ctx context.Context, repo, path, ref string) ([]byte, error) {
+	switch client := c.client(repo).(type) {
+	case *HTTPClient:
+		return client.File(ctx, repo, path, ref)
+	case *forgeClient:
+		return client.File(ctx, repo, path, ref)
+	}
+	return nil, nil
+}
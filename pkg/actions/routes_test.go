@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchRoute(t *testing.T) {
+	routes := []Route{
+		{Prefix: "actions", BaseURL: "https://api.github.com"},
+		{Prefix: "myorg/tool", BaseURL: "https://ghes2.example.com/api/v3"},
+		{Prefix: "myorg", BaseURL: "https://ghes.example.com/api/v3"},
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"actions/checkout", "https://api.github.com"},
+		{"actions/cache/restore", "https://api.github.com"},
+		{"myorg/tool", "https://ghes2.example.com/api/v3"},
+		{"myorg/tool/sub", "https://ghes2.example.com/api/v3"},
+		{"myorg/other", "https://ghes.example.com/api/v3"},
+		{"actionsx/checkout", ""},
+		{"other/repo", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, ok := MatchRoute(routes, tt.name)
+			if ok != (tt.expected != "") || route.BaseURL != tt.expected {
+				t.Errorf("expected %q, got %q (matched %v)", tt.expected, route.BaseURL, ok)
+			}
+		})
+	}
+}
+
+func TestValidatePrefix(t *testing.T) {
+	for _, prefix := range []string{"actions", "actions/checkout"} {
+		if err := ValidatePrefix(prefix); err != nil {
+			t.Errorf("unexpected error for %q: %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"", "/", "actions/", "a/b/c"} {
+		if err := ValidatePrefix(prefix); err == nil {
+			t.Errorf("expected error for %q", prefix)
+		}
+	}
+}
+
+func TestCheckerRoutes(t *testing.T) {
+	tagServer := func(tags, token string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "token "+token {
+				t.Errorf("expected token %q at %s, got %q", token, r.URL, got)
+			}
+			_, _ = w.Write([]byte(tags))
+		}))
+	}
+	ghes := tagServer(`[{"name": "v1"}, {"name": "v2"}]`, "ghes-token")
+	defer ghes.Close()
+	dotcom := tagServer(`[{"name": "v4"}, {"name": "v5"}]`, "dotcom-token")
+	defer dotcom.Close()
+
+	checker := NewChecker(
+		WithBaseURL(ghes.URL),
+		WithToken("ghes-token"),
+		WithRoutes(Route{Prefix: "actions", BaseURL: dotcom.URL, Token: "dotcom-token"}),
+	)
+	result, err := checker.Check(context.Background(), []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "myorg/deploy", Version: "v1", File: "ci.yml"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Outdated) != 2 {
+		t.Fatalf("expected 2 outdated actions, got %+v", result.Outdated)
+	}
+	if result.Outdated[0].LatestVersion != "v5" || result.Outdated[1].LatestVersion != "v2" {
+		t.Errorf("expected each action resolved against its own host, got %+v", result.Outdated)
+	}
+}
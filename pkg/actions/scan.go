@@ -0,0 +1,214 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OrgLister lists the repositories of an organization. The HTTPClient
+// implements it.
+type OrgLister interface {
+	OrgRepos(ctx context.Context, org string) ([]GitHubRepo, error)
+}
+
+// OrgRepos fetches every repository of an organization that the token can
+// see, following every page
+func (c *HTTPClient) OrgRepos(ctx context.Context, org string) ([]GitHubRepo, error) {
+	repos, status, err := getPages[GitHubRepo](ctx, c, fmt.Sprintf("/orgs/%s/repos?type=all&per_page=%d", org, perPage), 0)
+	if err != nil {
+		return nil, notAccessible(org, status, err)
+	}
+	return repos, nil
+}
+
+// OrgRepos returns the names of an organization's repositories that aren't
+// archived, sorted
+func (c *Checker) OrgRepos(ctx context.Context, org string) ([]string, error) {
+	lister, ok := c.clientFor(org).(OrgLister)
+	if !ok {
+		return nil, fmt.Errorf("can't list the repositories of %s with this client", org)
+	}
+	repos, err := lister.OrgRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, repo := range repos {
+		if !repo.Archived {
+			names = append(names, repo.FullName)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// LoadRepoList reads a list of repositories to scan. The file has one
+// owner/name per line, ignoring blank lines and # comments, or is a JSON
+// array of names or of objects with a "full_name" (as the API returns) or
+// "nameWithOwner" (as `gh repo list --json nameWithOwner` prints) field.
+// Duplicates are dropped.
+func LoadRepoList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, &ErrParse{Source: path, Err: err}
+		}
+		for _, entry := range entries {
+			var name string
+			if err := json.Unmarshal(entry, &name); err != nil {
+				var repo struct {
+					FullName      string `json:"full_name"`
+					NameWithOwner string `json:"nameWithOwner"`
+				}
+				if err := json.Unmarshal(entry, &repo); err != nil {
+					return nil, &ErrParse{Source: path, Err: err}
+				}
+				name = cmp.Or(repo.FullName, repo.NameWithOwner)
+			}
+			names = append(names, name)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			if line = strings.TrimSpace(line); line != "" {
+				names = append(names, line)
+			}
+		}
+	}
+
+	var repos []string
+	for _, name := range names {
+		if owner, repo, ok := strings.Cut(name, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return nil, &ErrParse{Source: path, Err: fmt.Errorf("%q isn't an owner/name repository", name)}
+		}
+		if !slices.Contains(repos, name) {
+			repos = append(repos, name)
+		}
+	}
+	return repos, nil
+}
+
+// RepoScan is the workflows read from several repositories, to be checked
+// together so each action is only looked up once
+type RepoScan struct {
+	Refs     []ActionReference // With Repository set, in the order of the repositories
+	Skipped  []string          // Repositories left unread to save the rate limit
+	Warnings []string
+}
+
+// rateLimiter is implemented by clients that know their request budget
+type rateLimiter interface {
+	RateLimit() (RateLimit, bool)
+}
+
+// ScanRepos reads the workflows on the default branch of each repository,
+// several at a time. Inaccessible repositories and unparseable workflows
+// become warnings. Once less than a quarter of the rate limit is left, the
+// remaining repositories are skipped so there's budget to check the actions
+// that were found.
+func (c *Checker) ScanRepos(ctx context.Context, repos []string) (RepoScan, error) {
+	type fetched struct {
+		refs    []ActionReference
+		warning string
+		skipped bool
+		err     error
+	}
+	results := make([]fetched, len(repos))
+
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := ctx.Err(); err != nil {
+				results[i].err = err
+				return
+			}
+			if c.budgetLow(repo) {
+				results[i].skipped = true
+				return
+			}
+			refs, err := c.RepoReferences(ctx, repo, "")
+			switch {
+			case errors.Is(err, &ErrRateLimited{}):
+				results[i].skipped = true
+			case errors.Is(err, &ErrUnparsed{}):
+				// The other workflows are still checked
+				results[i].warning = fmt.Sprintf("skipping workflows of %s: %v", repo, err)
+			case errors.Is(err, &ErrRepoNotAccessible{}), errors.Is(err, &ErrParse{}):
+				results[i].warning = fmt.Sprintf("skipping %s: %v", repo, err)
+			case err != nil:
+				results[i].err = err
+			}
+			for j := range refs {
+				refs[j].Repository = repo
+			}
+			results[i].refs = refs
+		})
+	}
+	wg.Wait()
+
+	scan := RepoScan{Refs: []ActionReference{}}
+	for i, r := range results {
+		if r.err != nil {
+			return RepoScan{}, r.err
+		}
+		if r.skipped {
+			scan.Skipped = append(scan.Skipped, repos[i])
+		}
+		if r.warning != "" {
+			scan.Warnings = append(scan.Warnings, r.warning)
+		}
+		scan.Refs = append(scan.Refs, r.refs...)
+	}
+	if len(scan.Skipped) > 0 {
+		scan.Warnings = append(scan.Warnings, fmt.Sprintf(
+			"skipped %d of %d repositories to stay within the API rate limit", len(scan.Skipped), len(repos)))
+	}
+	return scan, nil
+}
+
+// budgetLow reports whether less than a quarter of the rate limit of the
+// host serving repo is left before it resets
+func (c *Checker) budgetLow(repo string) bool {
+	limiter, ok := c.clientFor(repo).(rateLimiter)
+	if !ok {
+		return false
+	}
+	rate, ok := limiter.RateLimit()
+	return ok && rate.Remaining < rate.Limit/4 && c.clock().Before(rate.Reset)
+}
+
+// RepositoryResult is the part of a CheckResult about one repository
+type RepositoryResult struct {
+	Repository string
+	Result     CheckResult
+}
+
+// ByRepository splits the findings of a scan by their Repository, in the
+// order each repository first appears. Warnings and Resolved aren't split.
+func (r CheckResult) ByRepository() []RepositoryResult {
+	var groups []RepositoryResult
+	for _, g := range r.split(func(repository, _, _ string) string { return repository }) {
+		groups = append(groups, RepositoryResult{Repository: g.Key, Result: g.Result})
+	}
+	return groups
+}
@@ -0,0 +1,185 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientOrgRepos(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4998")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/acme/repos?page=2>; rel="next"`, server.URL))
+			_, _ = w.Write([]byte(`[{"full_name": "acme/api"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"full_name": "acme/old", "archived": true}]`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("")
+	client.BaseURL = server.URL
+	client.MaxPages = 1 // Doesn't apply to repository lists
+
+	if _, ok := client.RateLimit(); ok {
+		t.Error("RateLimit() is known before any request")
+	}
+	repos, err := client.OrgRepos(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 || repos[0].FullName != "acme/api" || !repos[1].Archived {
+		t.Errorf("OrgRepos() = %+v", repos)
+	}
+	rate, ok := client.RateLimit()
+	want := RateLimit{Limit: 5000, Remaining: 4998, Reset: time.Unix(1700000000, 0)}
+	if !ok || rate != want {
+		t.Errorf("RateLimit() = %+v, %v, want %+v", rate, ok, want)
+	}
+
+	names, err := NewChecker(WithClient(client)).OrgRepos(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"acme/api"}) {
+		t.Errorf("Checker.OrgRepos() = %v, want only the unarchived repository", names)
+	}
+
+	if _, err := client.OrgRepos(context.Background(), "nobody"); !errors.Is(err, &ErrRepoNotAccessible{}) {
+		t.Errorf("OrgRepos() of a missing org = %v, want ErrRepoNotAccessible", err)
+	}
+}
+
+// limitedClient reports a fixed rate limit
+type limitedClient struct {
+	*fakeClient
+	rate RateLimit
+}
+
+func (c *limitedClient) RateLimit() (RateLimit, bool) {
+	return c.rate, true
+}
+
+func TestScanRepos(t *testing.T) {
+	workflow := []byte("jobs:\n  test:\n    steps:\n      - uses: actions/checkout@v4\n")
+	client := &fakeClient{workflows: map[string][]WorkflowFile{
+		"acme/api":    {{Path: ".github/workflows/ci.yml", Content: workflow}},
+		"acme/web":    {{Path: ".github/workflows/ci.yml", Content: workflow}},
+		"acme/broken": {{Path: ".github/workflows/ci.yml", Content: []byte("jobs: [")}},
+	}}
+
+	scan, err := NewChecker(WithClient(client)).ScanRepos(context.Background(),
+		[]string{"acme/api", "acme/private", "acme/broken", "acme/web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var repos []string
+	for _, ref := range scan.Refs {
+		repos = append(repos, ref.Repository)
+	}
+	if !slices.Equal(repos, []string{"acme/api", "acme/web"}) {
+		t.Errorf("refs are from %v, want acme/api and acme/web in order", repos)
+	}
+	if len(scan.Warnings) != 2 || len(scan.Skipped) != 0 {
+		t.Errorf("warnings = %q, skipped = %v; want warnings for acme/private and acme/broken", scan.Warnings, scan.Skipped)
+	}
+}
+
+func TestScanReposBudget(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := &limitedClient{
+		fakeClient: &fakeClient{workflows: map[string][]WorkflowFile{"acme/api": nil}},
+		rate:       RateLimit{Limit: 5000, Remaining: 1000, Reset: now.Add(time.Hour)},
+	}
+	checker := NewChecker(WithClient(client))
+	checker.now = func() time.Time { return now }
+
+	scan, err := checker.ScanRepos(context.Background(), []string{"acme/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(scan.Skipped, []string{"acme/api"}) || len(scan.Warnings) != 1 {
+		t.Errorf("skipped = %v, warnings = %q; want acme/api skipped", scan.Skipped, scan.Warnings)
+	}
+	if client.calls.Load() != 0 {
+		t.Errorf("made %d requests with a low budget", client.calls.Load())
+	}
+
+	// A budget that has been reset isn't low
+	client.rate.Reset = now.Add(-time.Minute)
+	if scan, _ := checker.ScanRepos(context.Background(), []string{"acme/api"}); len(scan.Skipped) != 0 {
+		t.Errorf("skipped %v after the rate limit reset", scan.Skipped)
+	}
+}
+
+func TestByRepository(t *testing.T) {
+	result := CheckResult{
+		Outdated: []OutdatedAction{
+			{Repository: "acme/web", Name: "actions/checkout"},
+			{Repository: "acme/api", Name: "actions/checkout"},
+		},
+		SHAPinned: []SHAPinnedAction{{Repository: "acme/web", Name: "actions/cache"}},
+		Warnings:  []string{"shared"},
+	}
+	groups := result.ByRepository()
+	if len(groups) != 2 || groups[0].Repository != "acme/web" || groups[1].Repository != "acme/api" {
+		t.Fatalf("ByRepository() = %+v", groups)
+	}
+	if len(groups[0].Result.Outdated) != 1 || len(groups[0].Result.SHAPinned) != 1 || len(groups[1].Result.Outdated) != 1 {
+		t.Errorf("ByRepository() = %+v", groups)
+	}
+	if groups[0].Result.Warnings != nil {
+		t.Errorf("warnings were split: %q", groups[0].Result.Warnings)
+	}
+}
+
+func TestLoadRepoList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{"lines", "# platform repos\nacme/api\n\nacme/web  # frontend\nacme/api\n", []string{"acme/api", "acme/web"}, false},
+		{"json names", `["acme/api", "acme/web"]`, []string{"acme/api", "acme/web"}, false},
+		{"gh repo list", `[{"nameWithOwner": "acme/api"}, {"full_name": "acme/web"}]`, []string{"acme/api", "acme/web"}, false},
+		{"not a repository", "acme\n", nil, true},
+		{"bad json", `["acme/api"`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "repos.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadRepoList(path)
+			if tt.wantErr {
+				if !errors.Is(err, &ErrParse{}) {
+					t.Errorf("LoadRepoList() error = %v, want ErrParse", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("LoadRepoList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package actions
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// versionScheme is a style of version tag that aver knows how to order.
+// Schemes are tried in order, and the first one that recognizes the
+// current version decides which tags are comparable to it.
+type versionScheme interface {
+	// name identifies the scheme in debug output
+	name() string
+	// matches reports whether version belongs to the scheme
+	matches(version string) bool
+	// latest returns the newest tag newer than current, or "" if there's
+	// none. ignoreMinor only reports changes to the most significant part.
+	latest(tags []GitHubTag, current string, ignoreMinor bool) string
+	// after reports whether version is newer than anything current pins;
+	// a floating pin like v4 covers every v4.x.y
+	after(version, current string) bool
+}
+
+// versionSchemes lists the supported schemes, most specific first
+var versionSchemes = []versionScheme{
+	semverScheme{},
+	numberedScheme{calver: true},
+	numberedScheme{},
+}
+
+// detectScheme returns the scheme of version, or nil if none recognizes it
+func detectScheme(version string) versionScheme {
+	for _, scheme := range versionSchemes {
+		if scheme.matches(version) {
+			return scheme
+		}
+	}
+	return nil
+}
+
+// semverScheme is v1, v1.2 and v1.2.3 with optional prerelease suffixes
+type semverScheme struct{}
+
+func (semverScheme) name() string { return "semver" }
+
+func (semverScheme) matches(version string) bool { return parseSemver(version) != nil }
+
+func (semverScheme) latest(tags []GitHubTag, current string, ignoreMinor bool) string {
+	return findLatestVersion(tags, current, ignoreMinor)
+}
+
+func (semverScheme) after(version, current string) bool {
+	v, cur := parseSemver(version), parseSemver(current)
+	if v == nil || cur == nil {
+		return false
+	}
+	switch {
+	case !cur.HasMinor:
+		return v.Major > cur.Major
+	case !cur.HasPatch:
+		return v.Major > cur.Major || (v.Major == cur.Major && v.Minor > cur.Minor)
+	}
+	return v.compare(cur) > 0
+}
+
+// numberedScheme is any run of numbers separated by dots, dashes or
+// underscores behind a fixed prefix, such as nightly-2025-01-02, r123 or
+// release-42. With calver set it only matches versions that start with a
+// year, like 2024-05-01.
+type numberedScheme struct {
+	calver bool
+}
+
+func (s numberedScheme) name() string {
+	if s.calver {
+		return "calver"
+	}
+	return "numeric"
+}
+
+func (s numberedScheme) matches(version string) bool {
+	n := parseNumbered(version)
+	if n == nil {
+		return false
+	}
+	return !s.calver || (len(n.parts) >= 2 && n.parts[0] >= 1990 && n.parts[0] < 2200)
+}
+
+func (s numberedScheme) latest(tags []GitHubTag, current string, ignoreMinor bool) string {
+	cur := parseNumbered(current)
+	if cur == nil {
+		return ""
+	}
+
+	var best *numbered
+	for _, tag := range tags {
+		n := parseNumbered(tag.Name)
+		// Only tags with the same prefix and shape are comparable, so that
+		// nightly-2025-01-02 is never compared against v2 or 2025.1
+		if n == nil || n.prefix != cur.prefix || !slices.Equal(n.seps, cur.seps) {
+			continue
+		}
+		if best == nil || n.compare(best) > 0 {
+			best = n
+		}
+	}
+
+	if best == nil || best.compare(cur) <= 0 {
+		return ""
+	}
+	if ignoreMinor && best.parts[0] == cur.parts[0] {
+		return ""
+	}
+	return best.raw
+}
+
+func (numberedScheme) after(version, current string) bool {
+	v, cur := parseNumbered(version), parseNumbered(current)
+	return v != nil && cur != nil && v.prefix == cur.prefix &&
+		slices.Equal(v.seps, cur.seps) && v.compare(cur) > 0
+}
+
+// numbered is a version parsed by numberedScheme
+type numbered struct {
+	raw    string
+	prefix string   // Everything before the first number, e.g. "nightly-"
+	parts  []int    // The numbers, e.g. 2025, 1, 2
+	seps   []string // The separators between them, e.g. "-", "-"
+}
+
+// parseNumbered splits version into a prefix without digits and a run of
+// separated numbers, or returns nil if it isn't of that form
+func parseNumbered(version string) *numbered {
+	i := strings.IndexFunc(version, func(r rune) bool { return r >= '0' && r <= '9' })
+	if i < 0 {
+		return nil
+	}
+	n := parseNumbers(version[i:])
+	if n == nil {
+		return nil
+	}
+	n.raw = version
+	n.prefix = version[:i]
+	return n
+}
+
+// parseNumbers parses s if it consists only of numbers and separators
+func parseNumbers(s string) *numbered {
+	n := &numbered{}
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && isDigit(s[i]) {
+			continue
+		}
+		if i == start {
+			return nil // Empty number: leading, trailing or doubled separator
+		}
+		part, err := strconv.Atoi(s[start:i])
+		if err != nil {
+			return nil
+		}
+		n.parts = append(n.parts, part)
+		if i == len(s) {
+			break
+		}
+		if s[i] != '.' && s[i] != '-' && s[i] != '_' {
+			return nil
+		}
+		n.seps = append(n.seps, s[i:i+1])
+		start = i + 1
+	}
+	return n
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// compare returns -1 if n < other, 0 if equal, 1 if n > other
+func (n *numbered) compare(other *numbered) int {
+	return slices.Compare(n.parts, other.parts)
+}
+
+// TagRule narrows the tags considered for an action, for repositories that
+// tag several components or use tags that aren't versions
+type TagRule struct {
+	// Pattern, if set, must match a tag for it to be considered
+	Pattern *regexp.Regexp
+	// StripPrefix, if set, is required on tags and removed before versions
+	// are compared, e.g. "release/" or "component-a/"
+	StripPrefix string
+}
+
+// apply returns the tags that satisfy the rule, with the prefix stripped
+func (r TagRule) apply(tags []GitHubTag) []GitHubTag {
+	var matched []GitHubTag
+	for _, tag := range tags {
+		if r.Pattern != nil && !r.Pattern.MatchString(tag.Name) {
+			continue
+		}
+		name, ok := strings.CutPrefix(tag.Name, r.StripPrefix)
+		if !ok {
+			continue
+		}
+		matched = append(matched, GitHubTag{Name: name})
+	}
+	return matched
+}
+
+// matchTagRule returns the most specific rule for an action name
+func matchTagRule(rules map[string]TagRule, name string) (TagRule, bool) {
+	var best TagRule
+	bestScore := 0
+	for prefix, rule := range rules {
+		if score := matchPrefix(prefix, name); score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// releasesBetween returns the published releases newer than current and no
+// newer than latest, newest first. Versions are compared after rule strips
+// their prefix.
+func releasesBetween(releases []GitHubRelease, scheme versionScheme, rule TagRule, current, latest string, prereleases bool) []GitHubRelease {
+	var between []GitHubRelease
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && !prereleases) {
+			continue
+		}
+		if rule.Pattern != nil && !rule.Pattern.MatchString(release.TagName) {
+			continue
+		}
+		version, ok := strings.CutPrefix(release.TagName, rule.StripPrefix)
+		if ok && scheme.after(version, current) && !scheme.after(version, latest) {
+			between = append(between, release)
+		}
+	}
+	slices.SortStableFunc(between, func(a, b GitHubRelease) int {
+		switch {
+		case scheme.after(a.TagName[len(rule.StripPrefix):], b.TagName[len(rule.StripPrefix):]):
+			return -1
+		case scheme.after(b.TagName[len(rule.StripPrefix):], a.TagName[len(rule.StripPrefix):]):
+			return 1
+		}
+		return 0
+	})
+	return between
+}
@@ -0,0 +1,118 @@
+package actions
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDetectScheme(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"v4", "semver"},
+		{"v5.0.0-rc.1", "semver"},
+		{"2024.05.1", "semver"}, // Orders the same as calver
+		{"nightly-2025-01-02", "calver"},
+		{"2024-05-01", "calver"},
+		{"release-2024.05.01", "calver"},
+		{"r123", "numeric"},
+		{"build-42", "numeric"},
+		{"release/1.2.3.4", "numeric"},
+		{"main", ""},
+		{"nightly", ""},
+		{"v1..2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			name := ""
+			if scheme := detectScheme(tt.version); scheme != nil {
+				name = scheme.name()
+			}
+			if name != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, name)
+			}
+		})
+	}
+}
+
+func TestNumberedSchemeLatest(t *testing.T) {
+	tags := []GitHubTag{
+		{Name: "nightly-2025-01-02"},
+		{Name: "nightly-2025-02-10"},
+		{Name: "nightly-2024-12-31"},
+		{Name: "stable-2026-01-01"},
+		{Name: "2026.1.1"},
+		{Name: "r99"},
+		{Name: "r123"},
+		{Name: "r1000"},
+		{Name: "v2"},
+	}
+
+	tests := []struct {
+		name        string
+		current     string
+		ignoreMinor bool
+		expected    string
+	}{
+		{"newer nightly", "nightly-2025-01-02", false, "nightly-2025-02-10"},
+		{"latest nightly", "nightly-2025-02-10", false, ""},
+		{"ignore minor within a year", "nightly-2025-01-02", true, ""},
+		{"ignore minor across years", "nightly-2024-12-31", true, "nightly-2025-02-10"},
+		{"numeric compares numbers, not strings", "r123", false, "r1000"},
+		{"no comparable tags", "build-1", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := detectScheme(tt.current)
+			if scheme == nil {
+				t.Fatalf("no scheme for %q", tt.current)
+			}
+			if result := scheme.latest(tags, tt.current, tt.ignoreMinor); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReleasesBetween(t *testing.T) {
+	releases := []GitHubRelease{
+		{TagName: "v5.1.0"},
+		{TagName: "v4.2.0"},
+		{TagName: "v5.0.0"},
+		{TagName: "v5.2.0-rc.1", Prerelease: true},
+		{TagName: "v4.1.0"},
+		{TagName: "v6.0.0", Draft: true},
+	}
+
+	tests := []struct {
+		current  string
+		latest   string
+		expected string
+	}{
+		{"v4.1.0", "v5.1.0", "[v5.1.0 v5.0.0 v4.2.0]"},
+		{"v4", "v5.1.0", "[v5.1.0 v5.0.0]"},
+		{"v4", "v5", "[v5.1.0 v5.0.0]"},
+		{"v5.1.0", "v5.1.0", "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"..."+tt.latest, func(t *testing.T) {
+			var tags []string
+			for _, r := range releasesBetween(releases, semverScheme{}, TagRule{}, tt.current, tt.latest, false) {
+				tags = append(tags, r.TagName)
+			}
+			if got := fmt.Sprint(tags); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	prefixed := []GitHubRelease{{TagName: "component-a/v1.1.0"}, {TagName: "component-b/v9.0.0"}}
+	got := releasesBetween(prefixed, semverScheme{}, TagRule{StripPrefix: "component-a/"}, "v1.0.0", "v1.1.0", false)
+	if len(got) != 1 || got[0].TagName != "component-a/v1.1.0" {
+		t.Errorf("expected only component-a's release, got %+v", got)
+	}
+}
@@ -0,0 +1,160 @@
+package actions
+
+import (
+	"context"
+	"strings"
+)
+
+// What SHA pins are compared with, for WithSHACompare
+const (
+	// SHACompareBranch counts the commits the default branch is ahead
+	SHACompareBranch = "branch"
+	// SHACompareTag finds the release the pinned commit is tagged as and
+	// compares that version with the latest, like a tag pin. Commits that
+	// no version tag points at are compared with the default branch.
+	SHACompareTag = "tag"
+	// SHACompareBoth reports a pin if either comparison finds it behind
+	SHACompareBoth = "both"
+)
+
+// SHACompareModes are the modes WithSHACompare accepts
+var SHACompareModes = []string{SHACompareBranch, SHACompareTag, SHACompareBoth}
+
+// resolveSHA checks a reference pinned to a commit, against its default
+// branch, the latest release or both
+func (c *Checker) resolveSHA(ctx context.Context, r *run, action ActionReference, repo string) Finding {
+	var currentTag string
+	var byTag *SHAPinnedAction
+	if c.shaCompare == SHACompareTag || c.shaCompare == SHACompareBoth {
+		tag, f := c.compareByTag(ctx, r, action, repo)
+		if (tag != "" && c.shaCompare == SHACompareTag) || f.err != nil || f.Warning != "" || f.Unchecked != nil {
+			return f
+		}
+		currentTag, byTag = tag, f.SHAPinned
+	}
+
+	// Check how far behind the SHA is
+	shaInfo, err := r.shas.do(repo+"@"+action.Version, func() (*shaStatus, error) {
+		return checkSHAStatus(ctx, r.branches, repo, action.Version)
+	})
+	if err != nil {
+		return c.failed(r, action, repo, err, true)
+	}
+	if shaInfo.CommitsBehind == 0 && byTag == nil {
+		return Finding{}
+	}
+	pinned := &SHAPinnedAction{
+		Repository:    action.Repository,
+		File:          action.File,
+		Name:          action.Name,
+		CurrentSHA:    action.Version,
+		LatestSHA:     shaInfo.LatestSHA,
+		CommitsBehind: shaInfo.CommitsBehind,
+		DefaultBranch: shaInfo.DefaultBranch,
+		Line:          action.Line,
+		CurrentTag:    currentTag,
+	}
+	if byTag != nil {
+		pinned.LatestTag, pinned.LatestTagSHA = byTag.LatestTag, byTag.LatestTagSHA
+	}
+	return Finding{SHAPinned: pinned}
+}
+
+// compareByTag finds the version tag that points at the commit action is
+// pinned to and checks it like a tag pin. It returns the tag, or "" if
+// there's none, and a Finding with a SHAPinnedAction if a newer version is
+// out. Its LatestSHA is the newer version's commit, and it has no
+// DefaultBranch.
+func (c *Checker) compareByTag(ctx context.Context, r *run, action ActionReference, repo string) (string, Finding) {
+	tags, err := r.tags.getTags(ctx, repo)
+	if err != nil {
+		return "", c.failed(r, action, repo, err, true)
+	}
+	rule, _ := matchTagRule(c.tagRules, action.Name)
+	tag := taggedVersion(tags, action.Version, rule.StripPrefix)
+	if tag == "" {
+		c.logger.Debug("no version tag at commit", "action", action.Name, "sha", action.Version)
+		return "", Finding{}
+	}
+
+	tagged := action
+	tagged.Version = tag
+	f := c.resolveTag(ctx, r, tagged, repo)
+	if f.Unchecked != nil {
+		f.Unchecked.Version = action.Version
+	}
+	if f.Outdated == nil {
+		return tag, f
+	}
+	return tag, Finding{SHAPinned: &SHAPinnedAction{
+		Repository:    action.Repository,
+		File:          action.File,
+		Name:          action.Name,
+		CurrentSHA:    action.Version,
+		LatestSHA:     f.Outdated.LatestSHA,
+		CommitsBehind: f.Outdated.CommitsBehind,
+		Line:          action.Line,
+		CurrentTag:    tag,
+		LatestTag:     f.Outdated.LatestVersion,
+		LatestTagSHA:  f.Outdated.LatestSHA,
+	}}
+}
+
+// taggedVersion returns the newest semantic version among the tags that
+// point at sha, or "" if none does. Of tags for the same version, the most
+// precise wins: v4.2.0 over v4.2. Only tags starting with prefix, a tag
+// rule's StripPrefix, count, and it's stripped before they're compared.
+func taggedVersion(tags []GitHubTag, sha, prefix string) string {
+	var best *semver
+	var name string
+	for _, tag := range tags {
+		if !sameCommit(tag.Commit.SHA, sha) {
+			continue
+		}
+		version, ok := strings.CutPrefix(tag.Name, prefix)
+		if !ok {
+			continue
+		}
+		sv := parseSemver(version)
+		if sv == nil {
+			continue
+		}
+		if best == nil || sv.compare(best) > 0 || (sv.compare(best) == 0 && precision(sv) > precision(best)) {
+			best, name = sv, tag.Name
+		}
+	}
+	return name
+}
+
+// precision counts the parts of a version that are given: 1 for v4, 3 for
+// v4.2.0
+func precision(s *semver) int {
+	switch {
+	case s.HasPatch:
+		return 3
+	case s.HasMinor:
+		return 2
+	}
+	return 1
+}
+
+// tagCommit returns the commit the tag called name points at, or "" if it
+// isn't in tags
+func tagCommit(tags []GitHubTag, name string) string {
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag.Commit.SHA
+		}
+	}
+	return ""
+}
+
+// sameCommit reports whether two SHAs name the same commit, either being
+// abbreviated
+func sameCommit(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
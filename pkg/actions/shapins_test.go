@@ -0,0 +1,122 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCheckerSHACompare(t *testing.T) {
+	tag := func(name, sha string) GitHubTag {
+		g := GitHubTag{Name: name}
+		g.Commit.SHA = sha
+		return g
+	}
+	sha410 := strings.Repeat("a", 40)
+	sha422 := strings.Repeat("b", 40)
+	head := strings.Repeat("c", 40)
+	untagged := strings.Repeat("d", 40)
+	newClient := func() *fakeClient {
+		return &fakeClient{
+			tags: map[string][]GitHubTag{
+				"actions/checkout": {
+					tag("v4", sha422), tag("v4.2.2", sha422), tag("v4.1", sha410), tag("v4.1.0", sha410),
+				},
+			},
+			branches: map[string]string{"actions/checkout": "main"},
+			heads:    map[string]string{"actions/checkout": head},
+			behind:   map[string]int{sha410: 57, sha422: 3, untagged[:7]: 9, "v4.1.0": 12},
+		}
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: sha410, File: "ci.yml"},
+		// The latest release, but behind the default branch
+		{Name: "actions/checkout", Version: sha422, File: "lint.yml"},
+		{Name: "actions/checkout", Version: untagged[:7], File: "release.yml"},
+	}
+	check := func(mode string) map[string]SHAPinnedAction {
+		t.Helper()
+		result, err := NewChecker(WithClient(newClient()), WithSHACompare(mode)).Check(context.Background(), refs)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", mode, err)
+		}
+		if len(result.Warnings) > 0 || len(result.Outdated) > 0 {
+			t.Errorf("%s: unexpected warnings or outdated actions: %+v", mode, result)
+		}
+		byFile := make(map[string]SHAPinnedAction)
+		for _, s := range result.SHAPinned {
+			byFile[s.File] = s
+		}
+		return byFile
+	}
+
+	branch := check(SHACompareBranch)
+	if len(branch) != 3 || branch["ci.yml"].CommitsBehind != 57 || branch["ci.yml"].CurrentTag != "" {
+		t.Errorf("branch: unexpected SHA pins: %+v", branch)
+	}
+
+	byTag := check(SHACompareTag)
+	want := SHAPinnedAction{
+		File:          "ci.yml",
+		Name:          "actions/checkout",
+		CurrentSHA:    sha410,
+		LatestSHA:     sha422,
+		CommitsBehind: 12,
+		CurrentTag:    "v4.1.0",
+		LatestTag:     "v4.2.2",
+		LatestTagSHA:  sha422,
+	}
+	if got := byTag["ci.yml"]; got.File != want.File || got.CurrentTag != want.CurrentTag ||
+		got.LatestTag != want.LatestTag || got.LatestSHA != want.LatestSHA || got.LatestTagSHA != want.LatestTagSHA ||
+		got.CommitsBehind != want.CommitsBehind || got.DefaultBranch != "" {
+		t.Errorf("tag: expected %+v, got %+v", want, got)
+	}
+	if _, ok := byTag["lint.yml"]; ok {
+		t.Errorf("tag: the latest release was reported: %+v", byTag["lint.yml"])
+	}
+	// A commit no version tag points at is compared with the branch
+	if got := byTag["release.yml"]; got.DefaultBranch != "main" || got.CommitsBehind != 9 {
+		t.Errorf("tag: expected the untagged pin to be compared with main, got %+v", got)
+	}
+
+	both := check(SHACompareBoth)
+	if got := both["ci.yml"]; got.CommitsBehind != 57 || got.DefaultBranch != "main" || got.LatestSHA != head ||
+		got.CurrentTag != "v4.1.0" || got.LatestTag != "v4.2.2" || got.LatestTagSHA != sha422 {
+		t.Errorf("both: unexpected pin %+v", got)
+	}
+	if got := both["lint.yml"]; got.CommitsBehind != 3 || got.CurrentTag != "v4.2.2" || got.LatestTag != "" {
+		t.Errorf("both: unexpected pin %+v", got)
+	}
+	if len(both) != 3 {
+		t.Errorf("both: expected 3 SHA pins, got %+v", both)
+	}
+}
+
+func TestTaggedVersion(t *testing.T) {
+	tag := func(name, sha string) GitHubTag {
+		g := GitHubTag{Name: name}
+		g.Commit.SHA = sha
+		return g
+	}
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	tags := []GitHubTag{
+		tag("v4", sha), tag("v4.2", sha), tag("v4.2.0", sha), tag("latest", sha),
+		tag("v5.0.0", "fedcba9876543210fedcba9876543210fedcba98"),
+		tag("component/v1.3.0", sha),
+	}
+	tests := []struct {
+		sha, prefix, want string
+	}{
+		{sha, "", "v4.2.0"},
+		{"0123456", "", "v4.2.0"},
+		{"0123456789ABCDEF0123456789ABCDEF01234567", "", "v4.2.0"},
+		{"fedcba9", "", "v5.0.0"},
+		{"1111111", "", ""},
+		{sha, "component/", "component/v1.3.0"},
+	}
+	for _, tt := range tests {
+		if got := taggedVersion(tags, tt.sha, tt.prefix); got != tt.want {
+			t.Errorf("taggedVersion(%q, %q) = %q, want %q", tt.sha, tt.prefix, got, tt.want)
+		}
+	}
+}
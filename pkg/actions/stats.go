@@ -0,0 +1,184 @@
+package actions
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// How a reference is pinned
+const (
+	pinSHA     = "sha"
+	pinTag     = "tag"
+	pinBranch  = "branch"
+	pinDynamic = "dynamic"
+)
+
+// Stats summarizes a check: what was scanned, how it's pinned, how far
+// behind it is and what the check cost
+type Stats struct {
+	// Workflows is the number of distinct files the references came from
+	Workflows int `json:"workflows"`
+	// Actions is the number of distinct actions referenced
+	Actions int `json:"actions"`
+	// References is the number of references checked
+	References int `json:"references"`
+	// SHAPinned, TagPinned and BranchPinned count references by how they're
+	// pinned. References whose pin couldn't be classified, e.g. because
+	// their repository wasn't accessible, are in none of them.
+	SHAPinned    int `json:"sha_pinned"`
+	TagPinned    int `json:"tag_pinned"`
+	BranchPinned int `json:"branch_pinned"`
+	// Dynamic counts references pinned to an expression, which aren't
+	// checked
+	Dynamic int `json:"dynamic"`
+	// Outdated counts outdated references by update type
+	Outdated Severity `json:"outdated"`
+	// Usage counts where each action is used, most used first
+	Usage []ActionUsage `json:"usage"`
+	// APIRequests is the number of requests sent, not counting cached
+	// responses
+	APIRequests int64 `json:"api_requests"`
+	// Duration is the wall-clock time the check took
+	Duration time.Duration `json:"-"`
+	// DurationSeconds is Duration, for JSON
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// ActionUsage is how widely an action is used, which is how much work
+// upgrading it is and how much breaks if a release goes wrong
+type ActionUsage struct {
+	Name string `json:"action"`
+	// Workflows is the number of distinct files that use the action
+	Workflows int `json:"workflows"`
+	// Steps is the number of references to it, one per uses: line
+	Steps int `json:"steps"`
+	// Versions are the distinct versions it's pinned to, sorted
+	Versions []string `json:"versions"`
+}
+
+// Severity counts outdated references by the most significant part of the
+// version that changes. Versions that aren't semantic, like dates or
+// prefixed tags, are Other.
+type Severity struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+	Other int `json:"other"`
+}
+
+// Total is the number of outdated references
+func (s Severity) Total() int {
+	return s.Major + s.Minor + s.Patch + s.Other
+}
+
+// newStats counts refs, the pins found while checking them and outdated
+func newStats(refs []ActionReference, findings []Finding, outdated []OutdatedAction) Stats {
+	var s Stats
+	files := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, ref := range refs {
+		files[ref.Repository+":"+ref.File] = true
+		names[ref.Name] = true
+	}
+	s.Workflows, s.Actions, s.References = len(files), len(names), len(refs)
+	s.Usage = actionUsage(refs)
+	for _, f := range findings {
+		switch f.pin {
+		case pinSHA:
+			s.SHAPinned++
+		case pinTag:
+			s.TagPinned++
+		case pinBranch:
+			s.BranchPinned++
+		case pinDynamic:
+			s.Dynamic++
+		}
+	}
+	for _, o := range outdated {
+		switch severity(o) {
+		case "major":
+			s.Outdated.Major++
+		case "minor":
+			s.Outdated.Minor++
+		case "patch":
+			s.Outdated.Patch++
+		default:
+			s.Outdated.Other++
+		}
+	}
+	return s
+}
+
+// actionUsage counts the workflows and steps that use each action in refs,
+// ordered by steps, then workflows, then name
+func actionUsage(refs []ActionReference) []ActionUsage {
+	type counts struct {
+		files    map[string]bool
+		versions map[string]bool
+		steps    int
+	}
+	byName := make(map[string]*counts)
+	var names []string
+	for _, ref := range refs {
+		c, ok := byName[ref.Name]
+		if !ok {
+			c = &counts{files: make(map[string]bool), versions: make(map[string]bool)}
+			byName[ref.Name] = c
+			names = append(names, ref.Name)
+		}
+		c.files[ref.Repository+":"+ref.File] = true
+		c.versions[ref.Version] = true
+		c.steps += max(ref.Uses, 1)
+	}
+	usage := make([]ActionUsage, 0, len(names))
+	for _, name := range names {
+		c := byName[name]
+		u := ActionUsage{Name: name, Workflows: len(c.files), Steps: c.steps}
+		for v := range c.versions {
+			u.Versions = append(u.Versions, v)
+		}
+		slices.Sort(u.Versions)
+		usage = append(usage, u)
+	}
+	slices.SortFunc(usage, func(a, b ActionUsage) int {
+		if a.Steps != b.Steps {
+			return b.Steps - a.Steps
+		}
+		if a.Workflows != b.Workflows {
+			return b.Workflows - a.Workflows
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return usage
+}
+
+// SetDuration sets Duration and DurationSeconds
+func (s *Stats) SetDuration(d time.Duration) {
+	s.Duration = d
+	s.DurationSeconds = d.Round(time.Millisecond).Seconds()
+}
+
+// pinKind classifies how action is pinned once it has been checked: a
+// commit, a tag (or a partial version that resolves to one), or a branch.
+// The tags were already fetched, so this is free.
+func pinKind(ctx context.Context, r *run, action ActionReference, repo string) string {
+	if isSHA(action.Version) {
+		return pinSHA
+	}
+	tags, err := r.tags.getTags(ctx, repo)
+	if err != nil {
+		return ""
+	}
+	sv := parseSemver(action.Version)
+	switch {
+	case hasTag(tags, action.Version):
+		return pinTag
+	case sv != nil && !sv.HasPatch && resolvesPartialVersions(action.Name):
+		return pinTag
+	case sv == nil:
+		return pinBranch
+	}
+	return ""
+}
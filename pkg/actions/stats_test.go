@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCheckStats(t *testing.T) {
+	sha := "1234567890abcdef1234567890abcdef12345678"
+	client := &fakeClient{
+		tags: map[string][]GitHubTag{
+			"actions/checkout": {{Name: "v4"}, {Name: "v5"}},
+			"actions/setup-go": {{Name: "v5.0.0"}, {Name: "v5.1.0"}},
+			"actions/cache":    {{Name: "v4"}},
+			"owner/nightly":    {{Name: "nightly-2024-01-02"}, {Name: "nightly-2025-01-02"}},
+		},
+		branches: map[string]string{"actions/cache": "main"},
+		heads:    map[string]string{"actions/cache": sha},
+	}
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/checkout", Version: "v4", File: "release.yml"},
+		{Name: "actions/setup-go", Version: "v5.0.0", File: "ci.yml"},
+		{Name: "actions/cache", Version: sha, File: "ci.yml"},
+		{Name: "actions/cache", Version: "main", File: "release.yml"},
+		{Name: "owner/nightly", Version: "nightly-2024-01-02", File: "release.yml"},
+		{Name: "owner/missing", Version: "v1", File: "release.yml"},
+	}
+
+	result, err := NewChecker(WithClient(client)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := result.Stats
+	if s.Workflows != 2 || s.Actions != 5 || s.References != 7 {
+		t.Errorf("unexpected totals: %+v", s)
+	}
+	// owner/missing isn't accessible, so its pin is unknown
+	if s.SHAPinned != 1 || s.TagPinned != 4 || s.BranchPinned != 1 {
+		t.Errorf("unexpected pins: %+v", s)
+	}
+	if s.Outdated != (Severity{Major: 2, Minor: 1, Other: 1}) {
+		t.Errorf("unexpected outdated: %+v", s.Outdated)
+	}
+	wantUsage := []ActionUsage{
+		{Name: "actions/cache", Workflows: 2, Steps: 2, Versions: []string{sha, "main"}},
+		{Name: "actions/checkout", Workflows: 2, Steps: 2, Versions: []string{"v4"}},
+		{Name: "actions/setup-go", Workflows: 1, Steps: 1, Versions: []string{"v5.0.0"}},
+		{Name: "owner/missing", Workflows: 1, Steps: 1, Versions: []string{"v1"}},
+		{Name: "owner/nightly", Workflows: 1, Steps: 1, Versions: []string{"nightly-2024-01-02"}},
+	}
+	if !reflect.DeepEqual(s.Usage, wantUsage) {
+		t.Errorf("unexpected usage:\n%+v\nwant\n%+v", s.Usage, wantUsage)
+	}
+	if s.Duration <= 0 {
+		t.Errorf("expected a duration, got %v", s.Duration)
+	}
+}
+
+func TestCheckerRequests(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`[{"name": "v4"}, {"name": "v5"}]`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/checkout", Version: "v5", File: "ci.yml"},
+	}
+	result, err := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := requests.Load(); n == 0 || result.Stats.APIRequests != n {
+		t.Errorf("expected %d requests, got %d", n, result.Stats.APIRequests)
+	}
+
+	// Cached responses aren't requests
+	result, err = NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithOffline(true)).Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Stats.APIRequests != 0 {
+		t.Errorf("expected no requests from the cache, got %d", result.Stats.APIRequests)
+	}
+}
+
+func TestActionUsage(t *testing.T) {
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "v5", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "v5", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "v4", File: "release.yml"},
+		// The same file name in another repository is another workflow
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml", Repository: "owner/other"},
+		{Name: "actions/checkout", Version: "v3", File: "lint.yml"},
+	}
+	got := actionUsage(refs)
+	want := []ActionUsage{
+		{Name: "actions/checkout", Workflows: 3, Steps: 3, Versions: []string{"v3", "v4"}},
+		{Name: "actions/setup-go", Workflows: 2, Steps: 3, Versions: []string{"v4", "v5"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := actionUsage(nil); len(got) != 0 {
+		t.Errorf("expected no usage, got %+v", got)
+	}
+}
+
+func TestActionUsageRepeatedSteps(t *testing.T) {
+	refs, err := ParseWorkflow("ci.yml", []byte(`jobs:
+  test:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/checkout@v4
+  lint:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+      - uses: actions/checkout@v4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := actionUsage(refs)
+	want := []ActionUsage{
+		{Name: "actions/checkout", Workflows: 1, Steps: 4, Versions: []string{"v4"}},
+		{Name: "actions/setup-go", Workflows: 1, Steps: 1, Versions: []string{"v5"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
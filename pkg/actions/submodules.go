@@ -0,0 +1,100 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitModulesFile lists a repository's submodules
+const GitModulesFile = ".gitmodules"
+
+// Submodules returns the paths of the git submodules of the project at
+// projectRoot, relative to it, from its .gitmodules and those of the
+// submodules themselves. Submodules that aren't checked out are included;
+// they just have no workflows.
+func Submodules(projectRoot string) ([]string, error) {
+	var paths []string
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		content, err := os.ReadFile(filepath.Join(projectRoot, dir, GitModulesFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, sub := range parseGitModules(content) {
+			sub = path.Join(filepath.ToSlash(dir), sub)
+			// A path can't leave the repository that declares it
+			if sub == ".." || strings.HasPrefix(sub, "../") {
+				continue
+			}
+			paths = append(paths, filepath.FromSlash(sub))
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk("."); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// parseGitModules returns the path of every submodule in a .gitmodules
+// file, which is in git's config format:
+//
+//	[submodule "templates/base"]
+//		path = templates/base
+//		url = https://github.com/owner/base
+func parseGitModules(content []byte) []string {
+	var paths []string
+	inSubmodule := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[':
+			inSubmodule = strings.HasPrefix(line, "[submodule ")
+		case inSubmodule:
+			key, value, ok := strings.Cut(line, "=")
+			if ok && strings.TrimSpace(key) == "path" {
+				value = strings.Trim(strings.TrimSpace(value), `"`)
+				if value != "" {
+					paths = append(paths, value)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// SubmoduleWorkflowDirs returns the workflow directories (any of
+// WorkflowDirs) of the project's submodules that exist, relative to
+// projectRoot, to pass to FindActionReferences as extra directories. Their
+// references are reported under the submodule's path, like
+// templates/base/.github/workflows/ci.yml.
+func SubmoduleWorkflowDirs(projectRoot string) ([]string, error) {
+	subs, err := Submodules(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, sub := range subs {
+		for _, dir := range WorkflowDirs {
+			dir = filepath.Join(sub, filepath.FromSlash(dir))
+			if info, err := os.Stat(filepath.Join(projectRoot, dir)); err == nil && info.IsDir() {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs, nil
+}
@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSubmoduleWorkflowDirs(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":                  "ref: refs/heads/main\n",
+		".github/workflows/ci.yml":   "steps:\n  - uses: actions/checkout@v4\n",
+		".gitmodules":                "[submodule \"base\"]\n\tpath = templates/base\n\turl = https://github.com/owner/base\n[submodule \"empty\"]\n\tpath = vendor/empty\n[core]\n\tpath = not/a/submodule\n[submodule \"escape\"]\n\tpath = ../outside\n",
+		"templates/base/.git":        "gitdir: ../../.git/modules/base\n",
+		"templates/base/.gitmodules": "[submodule \"nested\"]\n  path = \"lib\"\n",
+		"templates/base/.github/workflows/release.yml":     "steps:\n  - uses: actions/upload-artifact@v4\n",
+		"templates/base/lib/.forgejo/workflows/build.yaml": "steps:\n  - uses: actions/setup-go@v5\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "vendor", "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := Submodules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.FromSlash("templates/base"), filepath.FromSlash("templates/base/lib"), filepath.FromSlash("vendor/empty")}
+	if !slices.Equal(subs, want) {
+		t.Errorf("expected submodules %v, got %v", want, subs)
+	}
+
+	dirs, err := SubmoduleWorkflowDirs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := FindActionReferences(root, dirs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ref := range refs {
+		got = append(got, filepath.ToSlash(ref.File)+" "+ref.Name)
+	}
+	wantRefs := []string{
+		".github/workflows/ci.yml actions/checkout",
+		"templates/base/.github/workflows/release.yml actions/upload-artifact",
+		"templates/base/lib/.forgejo/workflows/build.yaml actions/setup-go",
+	}
+	if !slices.Equal(got, wantRefs) {
+		t.Errorf("expected %v, got %v", wantRefs, got)
+	}
+
+	if subs, err := Submodules(t.TempDir()); err != nil || subs != nil {
+		t.Errorf("expected no submodules without .gitmodules, got %v, %v", subs, err)
+	}
+}
@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TokenInfo is what the API reports about the credentials in use
+type TokenInfo struct {
+	// Classic is set for classic personal access tokens and OAuth tokens,
+	// the only ones whose scopes the API reports
+	Classic bool
+	// Scopes are the OAuth scopes of a classic token
+	Scopes []string
+	// Rate is the budget of the core API
+	Rate RateLimit
+}
+
+// TokenInfo asks the API about the credentials in use, which also tells
+// whether it can be reached. The request doesn't count against the rate
+// limit. A token the API rejects returns ErrUnauthorized.
+func (c *HTTPClient) TokenInfo(ctx context.Context) (TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL()+"/rate_limit", nil)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	status, _, _, err := c.do(ctx, req, http.StatusOK)
+	if status == http.StatusUnauthorized {
+		host := c.baseURL()
+		if u, perr := url.Parse(host); perr == nil {
+			host = u.Host
+		}
+		return TokenInfo{}, &ErrUnauthorized{Host: host}
+	}
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := TokenInfo{Classic: c.scopes != nil, Rate: c.rate}
+	if c.scopes != nil {
+		for _, scope := range strings.Split(*c.scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	return info, nil
+}
+
+// TokenInfo asks the main API host about the credentials in use; see
+// HTTPClient.TokenInfo
+func (c *Checker) TokenInfo(ctx context.Context) (TokenInfo, error) {
+	hc, ok := c.mainClient().(*HTTPClient)
+	if !ok {
+		return TokenInfo{}, errors.New("the client can't report on its token")
+	}
+	return hc.TokenInfo(ctx)
+}
@@ -0,0 +1,44 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "token classic":
+			w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		case "token revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := &HTTPClient{BaseURL: server.URL, Token: "classic"}
+	info, err := client.TokenInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.Classic || len(info.Scopes) != 2 || info.Scopes[0] != "repo" || info.Rate.Remaining != 4999 {
+		t.Errorf("unexpected token info: %+v", info)
+	}
+
+	client = &HTTPClient{BaseURL: server.URL, Token: "fine-grained"}
+	if info, err = client.TokenInfo(context.Background()); err != nil || info.Classic || info.Scopes != nil {
+		t.Errorf("expected no scopes for a fine-grained token, got %+v, %v", info, err)
+	}
+
+	client = &HTTPClient{BaseURL: server.URL, Token: "revoked"}
+	if _, err := client.TokenInfo(context.Background()); !errors.Is(err, &ErrUnauthorized{}) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package actions
+
+import (
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Usage counts the API requests clients send and the lookups they answer
+// from the cache, by category: "tags", "releases", "refs" (branches),
+// "repository" (default branches), "compare", "commits", "contents" and
+// "other". One Usage can be shared by several Checkers. It's safe for
+// concurrent use.
+type Usage struct {
+	// Max caps the lookups Capped clients send to the API, if set: the
+	// main API host's, whose rate limit Estimate counts against. Once it's
+	// reached they fail with ErrBudgetExhausted instead; lookups answered
+	// from the cache, and writes like comments, don't count.
+	Max int64
+
+	mu       sync.Mutex
+	requests map[string]int
+	cached   map[string]int
+	spent    int64
+}
+
+// CategoryUsage is the usage of one category of requests
+type CategoryUsage struct {
+	Category string
+	Requests int // Sent to the API
+	Cached   int // Answered from the cache
+}
+
+func (u *Usage) record(rawURL string, cached bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	counts := &u.requests
+	if cached {
+		counts = &u.cached
+	}
+	if *counts == nil {
+		*counts = make(map[string]int)
+	}
+	(*counts)[requestCategory(rawURL)]++
+}
+
+// spend takes a lookup from the budget, reporting false if none is left
+func (u *Usage) spend() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.Max > 0 && u.spent >= u.Max {
+		return false
+	}
+	u.spent++
+	return true
+}
+
+// Requests returns how many requests were sent
+func (u *Usage) Requests() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var n int
+	for _, count := range u.requests {
+		n += count
+	}
+	return int64(n)
+}
+
+// Categories returns the usage of each category that was used, by name
+func (u *Usage) Categories() []CategoryUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	names := make(map[string]bool)
+	for name := range u.requests {
+		names[name] = true
+	}
+	for name := range u.cached {
+		names[name] = true
+	}
+	var categories []CategoryUsage
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		categories = append(categories, CategoryUsage{Category: name, Requests: u.requests[name], Cached: u.cached[name]})
+	}
+	return categories
+}
+
+// repositoryPath matches requests for a repository itself, e.g. for its
+// default branch, on GitHub, Gitea and GitLab
+var repositoryPath = regexp.MustCompile(`^(/api/v\d+)?/(repos/[^/]+/[^/]+|projects/[^/]+)/?$`)
+
+// requestCategory classifies a request by its URL. Cache keys of queries
+// are the URL followed by the body.
+func requestCategory(rawURL string) string {
+	path := rawURL
+	if fields := strings.Fields(rawURL); len(fields) > 0 {
+		if u, err := url.Parse(fields[0]); err == nil {
+			path = u.EscapedPath()
+		}
+	}
+	switch {
+	case strings.Contains(path, "/compare"):
+		return "compare"
+	case strings.Contains(path, "/tags"):
+		return "tags"
+	case strings.Contains(path, "/releases"):
+		return "releases"
+	case strings.Contains(path, "/git/ref"), strings.Contains(path, "/branches"):
+		return "refs"
+	case strings.Contains(path, "/commits"):
+		return "commits"
+	case strings.Contains(path, "/contents"):
+		return "contents"
+	case repositoryPath.MatchString(path):
+		return "repository"
+	}
+	return "other"
+}
+
+// RateLimit returns the request budget of the main API host as of the
+// last response that reported it
+func (c *Checker) RateLimit() (RateLimit, bool) {
+	limiter, ok := c.mainClient().(rateLimiter)
+	if !ok {
+		return RateLimit{}, false
+	}
+	return limiter.RateLimit()
+}
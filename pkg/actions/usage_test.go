@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRequestCategory(t *testing.T) {
+	for rawURL, want := range map[string]string{
+		"https://api.github.com/repos/actions/checkout/tags?per_page=100":           "tags",
+		"https://api.github.com/repos/actions/checkout/releases?per_page=100":       "releases",
+		"https://api.github.com/repos/actions/checkout":                             "repository",
+		"https://api.github.com/repos/actions/checkout/git/ref/heads/main":          "refs",
+		"https://codeberg.org/api/v1/repos/actions/cache/branches/main":             "refs",
+		"https://api.github.com/repos/actions/checkout/compare/v1...v2":             "compare",
+		"https://api.github.com/repos/actions/checkout/commits/v4":                  "commits",
+		"https://api.github.com/repos/owner/app/contents/.github/workflows":         "contents",
+		"https://gitlab.com/api/v4/projects/components%2Fsast":                      "repository",
+		"https://gitlab.com/api/v4/projects/components%2Fsast/repository/tags":      "tags",
+		"https://circleci.com/graphql-unstable {\"query\":\"orb(name: $name) {}\"}": "other",
+	} {
+		if got := requestCategory(rawURL); got != want {
+			t.Errorf("requestCategory(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestCheckerUsage(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4990")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		_, _ = w.Write([]byte(`[{"name": "v4"}]`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	usage := &Usage{}
+	refs := []ActionReference{{Name: "actions/checkout", Version: "v4", File: "ci.yml"}}
+	for range 2 {
+		checker := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithUsage(usage))
+		if _, err := checker.Check(context.Background(), refs); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	categories := usage.Categories()
+	if len(categories) == 0 || categories[len(categories)-1] != (CategoryUsage{Category: "tags", Requests: 1, Cached: 1}) {
+		t.Errorf("expected one request for tags and one cache hit, got %+v", categories)
+	}
+
+	checker := NewChecker(WithBaseURL(server.URL))
+	if _, ok := checker.RateLimit(); ok {
+		t.Error("expected no rate limit before any response")
+	}
+	if _, err := checker.Check(context.Background(), refs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rate, ok := checker.RateLimit()
+	if !ok || rate.Remaining != 4990 || rate.Limit != 5000 || !rate.Reset.Equal(reset) {
+		t.Errorf("unexpected rate limit: %+v", rate)
+	}
+}
+
+func TestUsageMax(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"name": "v4"}]`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	refs := []ActionReference{
+		{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+		{Name: "actions/setup-go", Version: "v4", File: "ci.yml"},
+		{Name: "actions/cache", Version: "v4", File: "ci.yml"},
+		{Name: "octo/tool", Version: "v4", File: "ci.yml"},
+	}
+	// Routed hosts have rate limits of their own, so only the main host's
+	// requests count against Max
+	route := WithRoutes(Route{Prefix: "octo", BaseURL: server.URL})
+	usage := &Usage{Max: 1}
+	checker := NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithUsage(usage), route)
+	result, err := checker.Check(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Requests() != 2 || len(result.Unchecked) != 2 || result.Unchecked[0].Reason != "API request budget used up" {
+		t.Errorf("expected two requests and two unchecked actions, got %d and %+v", usage.Requests(), result.Unchecked)
+	}
+
+	// Cached lookups don't count, so another run gets one more action
+	usage = &Usage{Max: 1}
+	checker = NewChecker(WithBaseURL(server.URL), WithCacheDir(cacheDir), WithUsage(usage), route)
+	if result, err = checker.Check(context.Background(), refs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Requests() != 1 || len(result.Unchecked) != 1 {
+		t.Errorf("expected one request and one unchecked action, got %d and %+v", usage.Requests(), result.Unchecked)
+	}
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies the token used to authenticate each API request
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token
+type StaticToken string
+
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// defaultAPIURL is the root of the public GitHub REST API
+const defaultAPIURL = "https://api.github.com"
+
+// refreshMargin is how long before expiry an installation token is replaced
+const refreshMargin = 5 * time.Minute
+
+// AppTokenSource authenticates as a GitHub App installation. It mints
+// installation tokens on demand and replaces them shortly before they
+// expire, so long-running scans never use a stale token.
+type AppTokenSource struct {
+	AppID          string
+	InstallationID int64 // If zero, the app's only installation is used
+	Key            *rsa.PrivateKey
+	BaseURL        string       // API root, defaults to https://api.github.com
+	Client         *http.Client // Defaults to http.DefaultClient
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	now     func() time.Time
+}
+
+// NewAppTokenSource returns a token source for a GitHub App from its ID and
+// PEM-encoded private key, as downloaded from the app's settings page
+func NewAppTokenSource(appID string, installationID int64, pemKey []byte) (*AppTokenSource, error) {
+	key, err := parsePrivateKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	return &AppTokenSource{AppID: appID, InstallationID: installationID, Key: key}, nil
+}
+
+// AppFromEnv configures a GitHub App token source from $AVER_APP_ID,
+// $AVER_APP_INSTALLATION_ID (optional), and the private key in either
+// $AVER_APP_PRIVATE_KEY or the file named by $AVER_APP_PRIVATE_KEY_FILE. It
+// returns nil if $AVER_APP_ID is unset.
+func AppFromEnv() (*AppTokenSource, error) {
+	appID := os.Getenv("AVER_APP_ID")
+	if appID == "" {
+		return nil, nil
+	}
+
+	var installationID int64
+	if id := os.Getenv("AVER_APP_INSTALLATION_ID"); id != "" {
+		var err error
+		installationID, err = strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AVER_APP_INSTALLATION_ID %q", id)
+		}
+	}
+
+	key := []byte(os.Getenv("AVER_APP_PRIVATE_KEY"))
+	if len(key) == 0 {
+		path := os.Getenv("AVER_APP_PRIVATE_KEY_FILE")
+		if path == "" {
+			return nil, errors.New("AVER_APP_ID is set but neither AVER_APP_PRIVATE_KEY nor AVER_APP_PRIVATE_KEY_FILE is")
+		}
+		var err error
+		key, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewAppTokenSource(appID, installationID, key)
+}
+
+func parsePrivateKey(pemKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Token returns a valid installation token, minting a new one if the
+// current token is missing or about to expire
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.clock().Add(refreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	jwt, err := s.jwt()
+	if err != nil {
+		return "", err
+	}
+
+	if s.InstallationID == 0 {
+		id, err := s.findInstallation(ctx, jwt)
+		if err != nil {
+			return "", err
+		}
+		s.InstallationID = id
+	}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", s.InstallationID)
+	if err := s.do(ctx, "POST", path, jwt, http.StatusCreated, &resp); err != nil {
+		return "", err
+	}
+
+	s.token = resp.Token
+	s.expires = resp.ExpiresAt
+	return s.token, nil
+}
+
+// findInstallation returns the ID of the app's installation when it has
+// exactly one
+func (s *AppTokenSource) findInstallation(ctx context.Context, jwt string) (int64, error) {
+	var installations []struct {
+		ID      int64 `json:"id"`
+		Account struct {
+			Login string `json:"login"`
+		} `json:"account"`
+	}
+	if err := s.do(ctx, "GET", "/app/installations", jwt, http.StatusOK, &installations); err != nil {
+		return 0, err
+	}
+
+	switch len(installations) {
+	case 0:
+		return 0, fmt.Errorf("GitHub App %s has no installations", s.AppID)
+	case 1:
+		return installations[0].ID, nil
+	}
+	return 0, fmt.Errorf("GitHub App %s has %d installations; specify which one to use", s.AppID, len(installations))
+}
+
+// jwt returns a short-lived JSON Web Token identifying the app
+func (s *AppTokenSource) jwt() (string, error) {
+	now := s.clock()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	// Backdate the issue time to allow for clock drift, per GitHub's docs
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": s.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.Key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+func (s *AppTokenSource) do(ctx context.Context, method, path, jwt string, wantStatus int, v any) error {
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = defaultAPIURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("GitHub App authentication failed: %s %s returned status %d", method, path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (s *AppTokenSource) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
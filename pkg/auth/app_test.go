@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var minted atomic.Int32
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			t.Errorf("expected a bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		verifyJWT(t, jwt, &key.PublicKey)
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/app/installations":
+			_, _ = w.Write([]byte(`[{"id": 42, "account": {"login": "my-org"}}]`))
+		case r.Method == "POST" && r.URL.Path == "/app/installations/42/access_tokens":
+			n := minted.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, n, now.Add(time.Hour).Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	source, err := NewAppTokenSource("1234", 0, pemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.BaseURL = server.URL
+	source.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	token, err := source.Token(ctx)
+	if err != nil || token != "ghs_1" {
+		t.Fatalf("expected ghs_1, got %q, %v", token, err)
+	}
+	if source.InstallationID != 42 {
+		t.Errorf("expected installation 42 to be discovered, got %d", source.InstallationID)
+	}
+
+	// Reused while fresh
+	now = now.Add(30 * time.Minute)
+	if token, _ := source.Token(ctx); token != "ghs_1" {
+		t.Errorf("expected cached token, got %q", token)
+	}
+
+	// Refreshed shortly before expiry
+	now = now.Add(27 * time.Minute)
+	if token, _ := source.Token(ctx); token != "ghs_2" {
+		t.Errorf("expected refreshed token, got %q", token)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})); err != nil {
+		t.Errorf("PKCS8 key: %v", err)
+	}
+	if _, err := parsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("expected an error for a non-PEM key")
+	}
+}
+
+func TestAppFromEnv(t *testing.T) {
+	t.Setenv("AVER_APP_ID", "")
+	if source, err := AppFromEnv(); source != nil || err != nil {
+		t.Errorf("expected nil when unset, got %v, %v", source, err)
+	}
+
+	t.Setenv("AVER_APP_ID", "1234")
+	t.Setenv("AVER_APP_PRIVATE_KEY", "")
+	t.Setenv("AVER_APP_PRIVATE_KEY_FILE", "")
+	if _, err := AppFromEnv(); err == nil {
+		t.Error("expected an error when the private key is missing")
+	}
+}
+
+func verifyJWT(t *testing.T, jwt string, key *rsa.PublicKey) {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed JWT %q", jwt)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+		t.Errorf("invalid JWT signature: %v", err)
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if !strings.Contains(string(claims), `"iss":"1234"`) {
+		t.Errorf("unexpected claims %s", claims)
+	}
+}
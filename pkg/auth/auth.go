@@ -0,0 +1,141 @@
+// Package auth locates a GitHub token so users who are already
+// authenticated locally don't have to export one.
+package auth
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Token sources reported by Token
+const (
+	SourceGitHubToken = "GITHUB_TOKEN"
+	SourceGHToken     = "GH_TOKEN"
+	SourceEnterprise  = "GH_ENTERPRISE_TOKEN"
+	SourceCommand     = "token_command"
+	SourceNetrc       = ".netrc"
+	SourceGHConfig    = "gh hosts.yml"
+	SourceGHCLI       = "gh auth token"
+)
+
+// DefaultHost is the host of github.com, as opposed to a GitHub Enterprise
+// Server instance
+const DefaultHost = "github.com"
+
+// FromEnv returns the token from $GITHUB_TOKEN or $GH_TOKEN, in that order
+func FromEnv() (token, source string) {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token, SourceGitHubToken
+	}
+	if token := os.Getenv("GH_TOKEN"); token != "" {
+		return token, SourceGHToken
+	}
+	return "", ""
+}
+
+// Token returns a token for host (DefaultHost if empty) and a description
+// of where it came from. It checks, in order: the environment, command (a
+// configured credential helper, skipped if empty), ~/.netrc, the gh CLI's
+// config file, and finally the gh CLI itself, which covers tokens kept in
+// the system keyring. For GitHub Enterprise Server hosts,
+// $GH_ENTERPRISE_TOKEN and $GITHUB_ENTERPRISE_TOKEN are checked first. If
+// no token is found both values are empty and requests should be made
+// unauthenticated. An error is only returned if command fails.
+func Token(command, host string) (token, source string, err error) {
+	if host == "" {
+		host = DefaultHost
+	}
+	if host != DefaultHost {
+		for _, name := range []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+			if token := os.Getenv(name); token != "" {
+				return token, SourceEnterprise, nil
+			}
+		}
+	}
+	if token, source := FromEnv(); token != "" {
+		return token, source, nil
+	}
+	if command != "" {
+		token, err := fromCommand(command)
+		if err != nil {
+			return "", "", err
+		}
+		return token, SourceCommand, nil
+	}
+	if token := fromNetrc(netrcPath(), "api."+host, host); token != "" {
+		return token, SourceNetrc, nil
+	}
+	if token := fromHostsFile(ghHostsPath(), host); token != "" {
+		return token, SourceGHConfig, nil
+	}
+	if token := fromGHCLI(host); token != "" {
+		return token, SourceGHCLI, nil
+	}
+	return "", "", nil
+}
+
+// ghHostsPath returns the location of the gh CLI's hosts.yml
+func ghHostsPath() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// fromHostsFile reads the oauth_token for host from a gh hosts.yml. Recent
+// versions of gh keep the token in the system keyring instead, in which
+// case this returns an empty string.
+func fromHostsFile(path, host string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return ""
+	}
+	return hosts[host].OAuthToken
+}
+
+// fromGHCLI runs `gh auth token`, returning an empty string if gh isn't
+// installed or isn't logged in
+func fromGHCLI(host string) string {
+	path, err := exec.LookPath("gh")
+	if err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
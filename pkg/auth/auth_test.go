@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTokenPrecedence(t *testing.T) {
+	configDir := t.TempDir()
+	hosts := "github.com:\n    user: octocat\n    oauth_token: gho_fromconfig\n    git_protocol: https\n"
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hosts), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GH_CONFIG_DIR", configDir)
+	t.Setenv("NETRC", filepath.Join(configDir, "missing-netrc"))
+	t.Setenv("PATH", "") // never run a real gh binary
+
+	tests := []struct {
+		name        string
+		githubToken string
+		ghToken     string
+		token       string
+		source      string
+	}{
+		{"GITHUB_TOKEN wins", "ghp_github", "ghp_gh", "ghp_github", SourceGitHubToken},
+		{"GH_TOKEN next", "", "ghp_gh", "ghp_gh", SourceGHToken},
+		{"gh config last", "", "", "gho_fromconfig", SourceGHConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", tt.githubToken)
+			t.Setenv("GH_TOKEN", tt.ghToken)
+
+			token, source, err := Token("", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if token != tt.token || source != tt.source {
+				t.Errorf("expected %q from %q, got %q from %q", tt.token, tt.source, token, source)
+			}
+		})
+	}
+}
+
+func TestTokenNone(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv("PATH", "")
+
+	if token, source, _ := Token("", ""); token != "" || source != "" {
+		t.Errorf("expected no token, got %q from %q", token, source)
+	}
+}
+
+func TestFromHostsFileKeyring(t *testing.T) {
+	// gh 2.40+ stores the token in the keyring and omits oauth_token
+	path := filepath.Join(t.TempDir(), "hosts.yml")
+	hosts := "github.com:\n    users:\n        octocat:\n    user: octocat\n    git_protocol: https\n"
+	if err := os.WriteFile(path, []byte(hosts), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if token := fromHostsFile(path, "github.com"); token != "" {
+		t.Errorf("expected no token, got %q", token)
+	}
+}
+
+func TestTokenNetrc(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netrc")
+	netrc := "# work\nmachine gitlab.com login me password glpat\nmachine github.com\n  login me\n  password ghp_netrc\n"
+	if err := os.WriteFile(path, []byte(netrc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("NETRC", path)
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("PATH", "")
+
+	token, source, err := Token("", "")
+	if err != nil || token != "ghp_netrc" || source != SourceNetrc {
+		t.Errorf("expected ghp_netrc from .netrc, got %q from %q (%v)", token, source, err)
+	}
+}
+
+func TestTokenCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	token, source, err := Token("echo '  ghp_fromcommand  '", "")
+	if err != nil || token != "ghp_fromcommand" || source != SourceCommand {
+		t.Errorf("expected ghp_fromcommand from command, got %q from %q (%v)", token, source, err)
+	}
+
+	if _, _, err := Token("echo oops >&2; exit 1", ""); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("expected the command's stderr in the error, got %v", err)
+	}
+}
+
+func TestParseNetrc(t *testing.T) {
+	passwords := parseNetrc("machine a.com login x password one\ndefault login y password fallback\nmacdef init\nmachine b.com password two\n")
+	if passwords["a.com"] != "one" || passwords["default"] != "fallback" {
+		t.Errorf("unexpected passwords %v", passwords)
+	}
+	if _, ok := passwords["b.com"]; ok {
+		t.Error("expected entries after macdef to be ignored")
+	}
+}
+
+func TestTokenEnterprise(t *testing.T) {
+	configDir := t.TempDir()
+	hosts := "github.com:\n    oauth_token: gho_dotcom\nghes.example.com:\n    oauth_token: gho_ghes\n"
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hosts), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GH_CONFIG_DIR", configDir)
+	t.Setenv("NETRC", filepath.Join(configDir, "netrc"))
+	t.Setenv("PATH", "")
+
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+	if token, _, _ := Token("", "ghes.example.com"); token != "gho_ghes" {
+		t.Errorf("expected the GHES host's gh token, got %q", token)
+	}
+
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghp_enterprise")
+	if token, source, _ := Token("", "ghes.example.com"); token != "ghp_enterprise" || source != SourceEnterprise {
+		t.Errorf("expected GH_ENTERPRISE_TOKEN, got %q from %q", token, source)
+	}
+	if token, _, _ := Token("", ""); token != "gho_dotcom" {
+		t.Errorf("expected enterprise tokens to be ignored for github.com, got %q", token)
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// netrcPath returns the location of the user's netrc file
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// fromNetrc returns the password for the first of hosts that has an entry
+// in the netrc file at path. GitHub tokens are stored as the password,
+// with any login.
+func fromNetrc(path string, hosts ...string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	passwords := parseNetrc(string(data))
+	for _, host := range hosts {
+		if password := passwords[host]; password != "" {
+			return password
+		}
+	}
+	return passwords["default"]
+}
+
+// parseNetrc maps each machine (and "default") to its password
+func parseNetrc(data string) map[string]string {
+	passwords := make(map[string]string)
+
+	// Strip comments; netrc is otherwise a flat stream of tokens
+	var b strings.Builder
+	for line := range strings.Lines(data) {
+		if before, _, found := strings.Cut(line, "#"); found {
+			line = before + "\n"
+		}
+		b.WriteString(line)
+	}
+	tokens := strings.Fields(b.String())
+
+	machine := ""
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			if i+1 < len(tokens) {
+				i++
+				machine = tokens[i]
+			}
+		case "default":
+			machine = "default"
+		case "password":
+			if i+1 < len(tokens) && machine != "" {
+				i++
+				if _, ok := passwords[machine]; !ok {
+					passwords[machine] = tokens[i]
+				}
+			}
+		case "macdef":
+			// Macro definitions run to the end of the file in practice;
+			// nothing after them is a credential we can use
+			return passwords
+		}
+	}
+	return passwords
+}
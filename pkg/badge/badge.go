@@ -0,0 +1,61 @@
+// Package badge renders shields.io-style SVG badges.
+package badge
+
+import (
+	"fmt"
+	"html"
+	"unicode/utf8"
+
+	"aver/pkg/actions"
+)
+
+// Colors used by shields.io
+const (
+	Green  = "#4c1"
+	Yellow = "#dfb317"
+	Orange = "#fe7d37"
+	Red    = "#e05d44"
+	Grey   = "#9f9f9f"
+)
+
+// Result renders a badge like "actions: 3 outdated" for a check result,
+// counting outdated tag pins and SHA pins behind their default branch
+func Result(result actions.CheckResult) []byte {
+	count := len(result.Outdated) + len(result.SHAPinned)
+	switch {
+	case len(result.Unchecked) > 0:
+		return Render("actions", "unknown", Grey)
+	case count == 0:
+		return Render("actions", "up to date", Green)
+	case count < 3:
+		return Render("actions", fmt.Sprintf("%d outdated", count), Yellow)
+	case count < 10:
+		return Render("actions", fmt.Sprintf("%d outdated", count), Orange)
+	}
+	return Render("actions", fmt.Sprintf("%d outdated", count), Red)
+}
+
+// Render returns a flat badge with label on a grey background and message
+// on color
+func Render(label, message, color string) []byte {
+	lw, mw := textWidth(label), textWidth(message)
+	width := lw + mw
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%[2]s: %[3]s">
+<title>%[2]s: %[3]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[4]d" height="20" fill="#555"/><rect x="%[4]d" width="%[5]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[2]s</text><text x="%[7]d" y="14">%[2]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[8]d" y="14">%[3]s</text>
+</g>
+</svg>
+`, width, html.EscapeString(label), html.EscapeString(message), lw, mw, color, lw/2, lw+mw/2)
+}
+
+// textWidth estimates the width of s in 11px Verdana, plus padding. Exact
+// metrics would need the font; an average character width is close enough
+// for short labels.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)*7 + 10
+}
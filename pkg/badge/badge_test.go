@@ -0,0 +1,71 @@
+package badge
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func TestRender(t *testing.T) {
+	svg := string(Render("actions", "3 outdated", Yellow))
+
+	var doc struct {
+		XMLName xml.Name
+		Width   int    `xml:"width,attr"`
+		Title   string `xml:"title"`
+	}
+	if err := xml.Unmarshal([]byte(svg), &doc); err != nil {
+		t.Fatalf("badge is not valid XML: %v\n%s", err, svg)
+	}
+	if doc.XMLName.Local != "svg" || doc.Title != "actions: 3 outdated" {
+		t.Errorf("unexpected badge: %+v", doc)
+	}
+	if doc.Width != textWidth("actions")+textWidth("3 outdated") {
+		t.Errorf("unexpected width %d", doc.Width)
+	}
+	if !strings.Contains(svg, `fill="#dfb317"`) {
+		t.Errorf("expected the message color in the badge:\n%s", svg)
+	}
+}
+
+func TestRenderEscapes(t *testing.T) {
+	var doc struct {
+		Title string `xml:"title"`
+	}
+	if err := xml.Unmarshal(Render("a<b", "c&d", Green), &doc); err != nil {
+		t.Fatalf("badge is not valid XML: %v", err)
+	}
+	if doc.Title != "a<b: c&d" {
+		t.Errorf("unexpected title %q", doc.Title)
+	}
+}
+
+func TestResult(t *testing.T) {
+	outdated := func(n int) []actions.OutdatedAction { return make([]actions.OutdatedAction, n) }
+	tests := []struct {
+		name     string
+		result   actions.CheckResult
+		expected string
+	}{
+		{"up to date", actions.CheckResult{}, "actions: up to date"},
+		{"one", actions.CheckResult{Outdated: outdated(1)}, "actions: 1 outdated"},
+		{"with SHA pins", actions.CheckResult{Outdated: outdated(2), SHAPinned: make([]actions.SHAPinnedAction, 2)}, "actions: 4 outdated"},
+		{"unchecked", actions.CheckResult{Unchecked: make([]actions.UncheckedAction, 1)}, "actions: unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc struct {
+				Title string `xml:"title"`
+			}
+			if err := xml.Unmarshal(Result(tt.result), &doc); err != nil {
+				t.Fatalf("badge is not valid XML: %v", err)
+			}
+			if doc.Title != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, doc.Title)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package buildinfo says which build of aver is running: the version,
+// commit and date release builds set with -ldflags, or else what the Go
+// toolchain recorded, so that binaries from `go install` and `go build`
+// describe themselves too.
+package buildinfo
+
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// The values of Info fields a build didn't set
+const (
+	DevVersion  = "dev"
+	NoCommit    = "none"
+	UnknownDate = "unknown"
+)
+
+// Info identifies a build
+type Info struct {
+	Version string // e.g. 1.4.0, without a leading v
+	Commit  string // The full commit hash, with -dirty for uncommitted changes
+	Date    string // When the commit was made or the build ran, RFC 3339
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit: %s, built: %s)", i.Version, i.Commit, i.Date)
+}
+
+// Read fills in the fields of ldflags that are unset from the build
+// information embedded in the binary
+func Read(ldflags Info) Info {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ldflags
+	}
+	return fill(ldflags, bi)
+}
+
+// pseudoVersion matches the end of a Go pseudo-version, e.g.
+// v0.0.0-20250102150405-abcdef123456, which `go install module@main` gives
+var pseudoVersion = regexp.MustCompile(`(\d{14})-([0-9a-f]{12})(\+dirty)?$`)
+
+func fill(info Info, bi *debug.BuildInfo) Info {
+	settings := make(map[string]string)
+	for _, s := range bi.Settings {
+		settings[s.Key] = s.Value
+	}
+
+	if v := bi.Main.Version; info.Version == DevVersion && v != "" && v != "(devel)" {
+		info.Version = strings.TrimPrefix(v, "v")
+	}
+	// VCS information is only recorded when building in a checkout; module
+	// downloads have the commit and its time in a pseudo-version instead
+	if info.Commit == NoCommit {
+		if rev := settings["vcs.revision"]; rev != "" {
+			info.Commit = rev
+			if settings["vcs.modified"] == "true" {
+				info.Commit += "-dirty"
+			}
+		} else if m := pseudoVersion.FindStringSubmatch(bi.Main.Version); m != nil {
+			info.Commit = m[2]
+		}
+	}
+	if info.Date == UnknownDate {
+		if t := settings["vcs.time"]; t != "" {
+			info.Date = t
+		} else if m := pseudoVersion.FindStringSubmatch(bi.Main.Version); m != nil {
+			if t, err := time.Parse("20060102150405", m[1]); err == nil {
+				info.Date = t.Format(time.RFC3339)
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,64 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFill(t *testing.T) {
+	unset := Info{Version: DevVersion, Commit: NoCommit, Date: UnknownDate}
+	for _, tt := range []struct {
+		name    string
+		ldflags Info
+		bi      debug.BuildInfo
+		want    Info
+	}{
+		{
+			name:    "release build",
+			ldflags: Info{Version: "1.4.0", Commit: "abc123", Date: "2025-03-01T10:00:00Z"},
+			bi: debug.BuildInfo{Main: debug.Module{Version: "v1.3.0"}, Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "def456"},
+			}},
+			want: Info{Version: "1.4.0", Commit: "abc123", Date: "2025-03-01T10:00:00Z"},
+		},
+		{
+			name:    "go install of a tag",
+			ldflags: unset,
+			bi:      debug.BuildInfo{Main: debug.Module{Version: "v1.4.0"}},
+			want:    Info{Version: "1.4.0", Commit: NoCommit, Date: UnknownDate},
+		},
+		{
+			name:    "go install of a branch",
+			ldflags: unset,
+			bi:      debug.BuildInfo{Main: debug.Module{Version: "v1.4.1-0.20250302150405-abcdef123456"}},
+			want:    Info{Version: "1.4.1-0.20250302150405-abcdef123456", Commit: "abcdef123456", Date: "2025-03-02T15:04:05Z"},
+		},
+		{
+			name:    "go build in a checkout",
+			ldflags: unset,
+			bi: debug.BuildInfo{Main: debug.Module{Version: "(devel)"}, Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "0123456789abcdef"},
+				{Key: "vcs.time", Value: "2025-03-03T09:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			}},
+			want: Info{Version: DevVersion, Commit: "0123456789abcdef-dirty", Date: "2025-03-03T09:00:00Z"},
+		},
+		{
+			name:    "nothing recorded",
+			ldflags: unset,
+			bi:      debug.BuildInfo{},
+			want:    unset,
+		},
+	} {
+		if got := fill(tt.ldflags, &tt.bi); got != tt.want {
+			t.Errorf("%s: fill() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	info := Info{Version: "1.4.0", Commit: "abc123", Date: "2025-03-01T10:00:00Z"}
+	if got, want := info.String(), "1.4.0 (commit: abc123, built: 2025-03-01T10:00:00Z)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,203 @@
+// Package cache stores GitHub API responses on disk so repeated runs don't
+// spend rate limit on data that rarely changes.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long a cached response is considered fresh
+const DefaultTTL = time.Hour
+
+// Cache is a directory of API responses keyed by request URL
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// Entry is a cached response, stored on disk as JSON
+type Entry struct {
+	Key       string          `json:"key"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+	Link      string          `json:"link,omitempty"` // The response's Link header, for following pages
+}
+
+// New returns a cache rooted at dir. A ttl of zero uses DefaultTTL.
+func New(dir string, ttl time.Duration) *Cache {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// DefaultDir returns the per-user cache directory for aver
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aver"), nil
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry for key if it exists and is still fresh
+func (c *Cache) Get(key string) (Entry, bool) {
+	e, ok := c.Lookup(key)
+	if !ok || time.Since(e.FetchedAt) > c.TTL {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Lookup returns the entry for key however old it is
+func (c *Cache) Lookup(key string) (Entry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Put stores e under e.Key. e.Body must be valid JSON. A zero FetchedAt is
+// set to the current time.
+func (c *Cache) Put(e Entry) error {
+	if !json.Valid(e.Body) {
+		return errors.New("cache: body is not valid JSON")
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	if e.FetchedAt.IsZero() {
+		e.FetchedAt = time.Now()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so concurrent readers never see a
+	// partially written entry
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(e.Key))
+}
+
+// Stats summarizes the contents of a cache
+type Stats struct {
+	Entries int       `json:"entries"`
+	Fresh   int       `json:"fresh"` // Entries younger than the TTL
+	Bytes   int64     `json:"bytes"`
+	Oldest  time.Time `json:"oldest,omitzero"`
+	Newest  time.Time `json:"newest,omitzero"`
+}
+
+// Stats reads every entry in the cache. A missing cache directory is empty.
+func (c *Cache) Stats() (Stats, error) {
+	var stats Stats
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil // Not one of ours, or corrupt; Get ignores it too
+		}
+
+		stats.Entries++
+		stats.Bytes += info.Size()
+		if time.Since(e.FetchedAt) <= c.TTL {
+			stats.Fresh++
+		}
+		if stats.Oldest.IsZero() || e.FetchedAt.Before(stats.Oldest) {
+			stats.Oldest = e.FetchedAt
+		}
+		if e.FetchedAt.After(stats.Newest) {
+			stats.Newest = e.FetchedAt
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// Clear removes every entry from the cache and returns how many there were.
+// Other files in the directory are left alone.
+func (c *Cache) Clear() (int, error) {
+	removed := 0
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if strings.HasSuffix(path, ".json") {
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// walk calls fn for each entry and leftover temp file in the cache
+func (c *Cache) walk(fn func(path string, info fs.FileInfo) error) error {
+	files, err := os.ReadDir(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || (!isEntryName(name) && !strings.HasPrefix(name, ".tmp-")) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue // Removed since ReadDir
+		}
+		if err := fn(filepath.Join(c.Dir, name), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEntryName reports whether name looks like a file written by Put
+func isEntryName(name string) bool {
+	hash, ok := strings.CutSuffix(name, ".json")
+	if !ok || len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
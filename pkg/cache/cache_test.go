@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+
+	if _, ok := c.Get("https://api.github.com/repos/a/b/tags"); ok {
+		t.Error("expected miss on empty cache")
+	}
+
+	if err := c.Put(Entry{Key: "https://api.github.com/repos/a/b/tags", Body: []byte(`[{"name":"v1"}]`), Link: `<https://api.github.com/repos/a/b/tags?page=2>; rel="next"`}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	e, ok := c.Get("https://api.github.com/repos/a/b/tags")
+	if !ok || string(e.Body) != `[{"name":"v1"}]` || e.Link == "" {
+		t.Errorf("expected hit, got %+v, %v", e, ok)
+	}
+
+	if _, ok := c.Get("https://api.github.com/repos/a/c/tags"); ok {
+		t.Error("expected miss for a different key")
+	}
+
+	if err := c.Put(Entry{Key: "key", Body: []byte("not json")}); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := New(t.TempDir(), time.Nanosecond)
+
+	if err := c.Put(Entry{Key: "key", Body: []byte(`{}`)}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if _, ok := c.Get("key"); ok {
+		t.Error("expected expired entry to miss")
+	}
+	if e, ok := c.Lookup("key"); !ok || string(e.Body) != `{}` || e.FetchedAt.IsZero() {
+		t.Errorf("expected Lookup to return the expired entry, got %+v %v", e, ok)
+	}
+}
+
+func TestCacheNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 0)
+
+	if err := c.Put(Entry{Key: "key", Body: []byte(`{}`)}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+	if len(matches) != 0 {
+		t.Errorf("expected no temp files, found %v", matches)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected 1 cache file, found %d", len(entries))
+	}
+}
+
+func TestCacheStatsAndClear(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, time.Hour)
+
+	stats, err := New(filepath.Join(dir, "missing"), 0).Stats()
+	if err != nil || stats.Entries != 0 {
+		t.Errorf("expected an empty missing cache, got %+v, %v", stats, err)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(Entry{Key: key, Body: []byte(`{}`)}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep me"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = c.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Entries != 2 || stats.Fresh != 2 || stats.Bytes == 0 || stats.Oldest.After(stats.Newest) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	removed, err := c.Clear()
+	if err != nil || removed != 2 {
+		t.Errorf("expected 2 entries removed, got %d, %v", removed, err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a miss after Clear")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("expected unrelated files to survive Clear: %v", err)
+	}
+}
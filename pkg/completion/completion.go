@@ -0,0 +1,228 @@
+// Package completion writes shell completion scripts for a command
+// described by a Spec: its subcommands, its flags and what their values
+// are.
+package completion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shells are the shells Script writes completion for
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Arg is what a flag's value is, which decides how it's completed
+type Arg int
+
+const (
+	ArgNone   Arg = iota // A switch, without a value
+	ArgValue             // Free text, which isn't completed
+	ArgFile              // A file name
+	ArgDir               // A directory name
+	ArgChoice            // One of the flag's Choices
+	ArgAction            // An action used in the current project
+)
+
+// Flag is a command line flag
+type Flag struct {
+	Name    string // Without dashes, e.g. "group-by"; completed as --group-by
+	Short   string // Alias completed with a single dash, e.g. "v", if any
+	Help    string
+	Arg     Arg
+	Choices []string // The values of an ArgChoice flag
+}
+
+// Command is a subcommand
+type Command struct {
+	Name string
+	Help string
+	// Args are the words it takes, e.g. "stats", "clear" and "path"
+	Args []string
+}
+
+// Spec describes a program's command line
+type Spec struct {
+	Program  string
+	Commands []Command
+	Flags    []Flag
+	// Actions is a shell command printing the actions an ArgAction flag
+	// may name, one per line, e.g. "aver completion actions"
+	Actions string
+}
+
+// Script returns the completion script for shell
+func Script(shell string, spec Spec) (string, error) {
+	switch shell {
+	case "bash":
+		return bash(spec), nil
+	case "zsh":
+		return zsh(spec), nil
+	case "fish":
+		return fish(spec), nil
+	}
+	return "", fmt.Errorf("unknown shell %q; use one of %s", shell, strings.Join(Shells, ", "))
+}
+
+// spellings are the ways a flag is typed on the command line
+func (f Flag) spellings() []string {
+	names := []string{"--" + f.Name}
+	if f.Short != "" {
+		names = append(names, "-"+f.Short)
+	}
+	return names
+}
+
+// funcName turns the program name into part of a shell function name
+func (s Spec) funcName() string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(s.Program)
+}
+
+// quote quotes s for a POSIX shell
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func bash(s Spec) string {
+	var b strings.Builder
+	fn := "_" + s.funcName()
+	fmt.Fprintf(&b, "# bash completion for %s; load it with\n#   source <(%s completion bash)\n", s.Program, s.Program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+
+	// The word after a flag taking a value is that value
+	b.WriteString("\tcase \"$prev\" in\n")
+	for _, f := range s.Flags {
+		var reply string
+		switch f.Arg {
+		case ArgNone:
+			continue
+		case ArgValue:
+			reply = "COMPREPLY=()"
+		case ArgFile:
+			reply = `COMPREPLY=($(compgen -f -- "$cur"))`
+		case ArgDir:
+			reply = `COMPREPLY=($(compgen -d -- "$cur"))`
+		case ArgChoice:
+			reply = fmt.Sprintf(`COMPREPLY=($(compgen -W %s -- "$cur"))`, quote(strings.Join(f.Choices, " ")))
+		case ArgAction:
+			reply = fmt.Sprintf(`COMPREPLY=($(compgen -W "$(%s 2>/dev/null)" -- "$cur"))`, s.Actions)
+		}
+		fmt.Fprintf(&b, "\t%s)\n\t\t%s\n\t\treturn\n\t\t;;\n", strings.Join(f.spellings(), "|"), reply)
+	}
+	b.WriteString("\tesac\n\n")
+
+	// Then the words a subcommand takes
+	b.WriteString("\tif [[ $COMP_CWORD -eq 2 && \"$cur\" != -* ]]; then\n\t\tcase \"${COMP_WORDS[1]}\" in\n")
+	for _, c := range s.Commands {
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&b, "\t\t%s)\n\t\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n\t\t\treturn\n\t\t\t;;\n",
+				c.Name, quote(strings.Join(c.Args, " ")))
+		}
+	}
+	b.WriteString("\t\tesac\n\tfi\n\n")
+
+	var flags, commands []string
+	for _, f := range s.Flags {
+		flags = append(flags, f.spellings()...)
+	}
+	for _, c := range s.Commands {
+		commands = append(commands, c.Name)
+	}
+	fmt.Fprintf(&b, "\tif [[ \"$cur\" == -* ]]; then\n\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", quote(strings.Join(flags, " ")))
+	fmt.Fprintf(&b, "\telif [[ $COMP_CWORD -eq 1 ]]; then\n\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\") $(compgen -f -- \"$cur\"))\n", quote(strings.Join(commands, " ")))
+	b.WriteString("\telse\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\tfi\n}\n")
+	fmt.Fprintf(&b, "complete -o filenames -F %s %s\n", fn, s.Program)
+	return b.String()
+}
+
+// zshHelp escapes the brackets around an _arguments description
+func zshHelp(s string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(s)
+}
+
+// zshItem escapes the colon that ends a _describe item's name
+func zshItem(s string) string {
+	return strings.ReplaceAll(s, ":", `\:`)
+}
+
+func zsh(s Spec) string {
+	var b strings.Builder
+	fn := "_" + s.funcName()
+	fmt.Fprintf(&b, "#compdef %s\n# zsh completion for %s; load it with\n#   source <(%s completion zsh)\n\n", s.Program, s.Program, s.Program)
+
+	fmt.Fprintf(&b, "%s_actions() {\n\tlocal -a actions\n\tactions=(${(f)\"$(%s 2>/dev/null)\"})\n\tcompadd -a actions\n}\n\n", fn, s.Actions)
+
+	// The first word is a subcommand or a project directory or file, and
+	// the second what the subcommand takes
+	fmt.Fprintf(&b, "%s_first() {\n\tlocal -a commands\n\tcommands=(\n", fn)
+	for _, c := range s.Commands {
+		fmt.Fprintf(&b, "\t\t%s\n", quote(zshItem(c.Name)+":"+c.Help))
+	}
+	b.WriteString("\t)\n\t_describe -t commands command commands\n\t_files\n}\n\n")
+	fmt.Fprintf(&b, "%s_second() {\n\tcase $words[2] in\n", fn)
+	for _, c := range s.Commands {
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&b, "\t%s)\n\t\tcompadd %s\n\t\t;;\n", c.Name, strings.Join(c.Args, " "))
+		}
+	}
+	b.WriteString("\t*)\n\t\t_files\n\t\t;;\n\tesac\n}\n\n")
+
+	fmt.Fprintf(&b, "%s() {\n\t_arguments \\\n", fn)
+	for _, f := range s.Flags {
+		var action string
+		switch f.Arg {
+		case ArgValue:
+			action = ":value: "
+		case ArgFile:
+			action = ":file:_files"
+		case ArgDir:
+			action = ":directory:_files -/"
+		case ArgChoice:
+			action = ":value:(" + strings.Join(f.Choices, " ") + ")"
+		case ArgAction:
+			action = ":action:" + fn + "_actions"
+		}
+		// Flags may be repeated, e.g. --exclude
+		for _, name := range f.spellings() {
+			fmt.Fprintf(&b, "\t\t%s \\\n", quote("*"+name+"["+zshHelp(f.Help)+"]"+action))
+		}
+	}
+	fmt.Fprintf(&b, "\t\t'1: :%s_first' \\\n\t\t'2: :%s_second' \\\n\t\t'*:file:_files'\n}\n\n", fn, fn)
+	fmt.Fprintf(&b, "compdef %s %s\n", fn, s.Program)
+	return b.String()
+}
+
+func fish(s Spec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s; load it with\n#   %s completion fish | source\n", s.Program, s.Program)
+	for _, c := range s.Commands {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", s.Program, c.Name, quote(c.Help))
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&b, "complete -c %s -n %s -f -a %s\n",
+				s.Program, quote("__fish_seen_subcommand_from "+c.Name), quote(strings.Join(c.Args, " ")))
+		}
+	}
+	for _, f := range s.Flags {
+		line := fmt.Sprintf("complete -c %s -l %s", s.Program, f.Name)
+		switch {
+		case len(f.Short) == 1:
+			line += " -s " + f.Short
+		case f.Short != "":
+			line += " -o " + f.Short
+		}
+		switch f.Arg {
+		case ArgValue:
+			line += " -x"
+		case ArgFile:
+			line += " -r -F"
+		case ArgDir:
+			line += " -x -a '(__fish_complete_directories)'"
+		case ArgChoice:
+			line += " -x -a " + quote(strings.Join(f.Choices, " "))
+		case ArgAction:
+			line += " -x -a " + quote("("+s.Actions+" 2>/dev/null)")
+		}
+		b.WriteString(line + " -d " + quote(f.Help) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package completion
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var spec = Spec{
+	Program: "aver",
+	Commands: []Command{
+		{Name: "cache", Help: "Inspect or clear the response cache", Args: []string{"stats", "clear", "path"}},
+		{Name: "doctor", Help: "Check the token, API, rate limit, workflows and cache"},
+	},
+	Flags: []Flag{
+		{Name: "json", Help: "Output results as JSON"},
+		{Name: "verbose", Short: "v", Help: "Also log skipped actions"},
+		{Name: "debug", Short: "vv", Help: "Also log API requests"},
+		{Name: "group-by", Help: "Group the tables [by file]", Arg: ArgChoice, Choices: []string{"file", "action", "owner"}},
+		{Name: "state", Help: "Where to remember tag commits", Arg: ArgFile},
+		{Name: "workflow-dir", Help: "Also check the workflows in DIR", Arg: ArgDir},
+		{Name: "api-url", Help: "GitHub API root", Arg: ArgValue},
+		{Name: "only", Help: "Only check the action's pins", Arg: ArgAction},
+	},
+	Actions: `printf 'actions/checkout\nactions/setup-go\n'`,
+}
+
+func TestScript(t *testing.T) {
+	wants := map[string][]string{
+		"bash": {
+			"--group-by)\n\t\tCOMPREPLY=($(compgen -W 'file action owner' -- \"$cur\"))",
+			"'--json --verbose -v --debug -vv --group-by",
+			"cache)\n\t\t\tCOMPREPLY=($(compgen -W 'stats clear path'",
+			"complete -o filenames -F _aver aver\n",
+		},
+		"zsh": {
+			"#compdef aver\n",
+			`'*--group-by[Group the tables \[by file\]]:value:(file action owner)'`,
+			`'*-vv[Also log API requests]'`,
+			`'*--workflow-dir[Also check the workflows in DIR]:directory:_files -/'`,
+			`'*--only[Only check the action'\''s pins]:action:_aver_actions'`,
+			"'cache:Inspect or clear the response cache'",
+		},
+		"fish": {
+			"complete -c aver -n __fish_use_subcommand -a cache -d 'Inspect or clear the response cache'\n",
+			"complete -c aver -n '__fish_seen_subcommand_from cache' -f -a 'stats clear path'\n",
+			"complete -c aver -l verbose -s v -d 'Also log skipped actions'\n",
+			"complete -c aver -l debug -o vv -d",
+			"complete -c aver -l state -r -F -d",
+			`complete -c aver -l only -x -a '(printf '\''actions/checkout\nactions/setup-go\n'\'' 2>/dev/null)'`,
+		},
+	}
+	for _, shell := range Shells {
+		script, err := Script(shell, spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants[shell] {
+			if !strings.Contains(script, want) {
+				t.Errorf("%s: expected %q in:\n%s", shell, want, script)
+			}
+		}
+
+		// The script must at least parse
+		if sh, err := exec.LookPath(shell); err == nil {
+			if out, err := exec.Command(sh, "-n", "-c", script).CombinedOutput(); err != nil {
+				t.Errorf("%s: invalid script: %v\n%s", shell, err, out)
+			}
+		}
+	}
+
+	if _, err := Script("powershell", spec); err == nil {
+		t.Error("expected an error for an unknown shell")
+	}
+}
+
+func TestBashCompletes(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	script, err := Script("bash", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for line, want := range map[string]string{
+		"aver --group-by o":     "owner",
+		"aver --only actions/s": "actions/setup-go",
+		"aver cache c":          "clear",
+		"aver --js":             "--json",
+		"aver doc":              "doctor",
+		"aver --api-url ":       "",
+	} {
+		// An empty last word is the one being started
+		words := strings.Fields(line)
+		if strings.HasSuffix(line, " ") {
+			words = append(words, "")
+		}
+		quoted := make([]string, len(words))
+		for i, w := range words {
+			quoted[i] = quote(w)
+		}
+		cmd := script + "COMP_WORDS=(" + strings.Join(quoted, " ") + ")\n" +
+			"COMP_CWORD=" + strconv.Itoa(len(words)-1) + "\n" +
+			"_aver\necho \"${COMPREPLY[*]}\"\n"
+		out, err := exec.Command(bash, "-c", cmd).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%q: %v\n%s", line, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != want {
+			t.Errorf("%q completes to %q, want %q", line, got, want)
+		}
+	}
+}
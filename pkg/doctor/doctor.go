@@ -0,0 +1,197 @@
+// Package doctor checks that aver's environment works: credentials, the
+// API, the rate limit, the project's workflows and the cache, suggesting a
+// fix for each problem it finds.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"aver/pkg/actions"
+)
+
+// Status is how a check went
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn" // Works, but with limits worth knowing
+	Fail Status = "fail"
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string // What to do about a warning or failure
+}
+
+// Env is what the checks look at
+type Env struct {
+	// Host is the API host, e.g. api.github.com
+	Host string
+	// TokenSource says where the token came from, or is empty without one
+	TokenSource string
+	// Offline skips the checks that need the API
+	Offline bool
+	// TokenInfo asks the API about the token
+	TokenInfo func(context.Context) (actions.TokenInfo, error)
+	// References finds the project's action references
+	References func() ([]actions.ActionReference, error)
+	// CacheDir is where responses are cached, or empty if there's nowhere
+	CacheDir string
+	// Now is the time, for rate limit resets; defaults to time.Now
+	Now func() time.Time
+}
+
+// lowRate is the share of the rate limit below which it's a warning
+const lowRate = 0.1
+
+// Run runs every check in order
+func Run(ctx context.Context, env Env) []Result {
+	if env.Now == nil {
+		env.Now = time.Now
+	}
+	results := []Result{token(env)}
+	if env.Offline {
+		results = append(results, Result{Name: "API", Status: Warn,
+			Detail: "not checked in offline mode", Fix: "run aver doctor without --offline"})
+	} else {
+		results = append(results, api(ctx, env)...)
+	}
+	return append(results, workflows(env), cacheDir(env))
+}
+
+// Failed reports whether any check failed
+func Failed(results []Result) bool {
+	return slices.ContainsFunc(results, func(r Result) bool { return r.Status == Fail })
+}
+
+func token(env Env) Result {
+	if env.TokenSource == "" {
+		return Result{Name: "Token", Status: Warn,
+			Detail: "none found, so requests are limited to 60 an hour",
+			Fix:    "log in with `gh auth login` or set GITHUB_TOKEN"}
+	}
+	return Result{Name: "Token", Status: OK, Detail: "from " + env.TokenSource}
+}
+
+// api checks that the API answers, the token is accepted and has the
+// scopes aver needs, and how much of the rate limit is left
+func api(ctx context.Context, env Env) []Result {
+	info, err := env.TokenInfo(ctx)
+	switch {
+	case errors.Is(err, &actions.ErrUnauthorized{}):
+		return []Result{{Name: "API", Status: Fail,
+			Detail: fmt.Sprintf("%s rejected the token from %s", env.Host, env.TokenSource),
+			Fix:    "the token has expired or been revoked; create a new one or run `gh auth refresh`"}}
+	case errors.Is(err, &actions.ErrRateLimited{}):
+		var limited *actions.ErrRateLimited
+		errors.As(err, &limited)
+		return []Result{{Name: "API", Status: Fail,
+			Detail: err.Error(),
+			Fix:    resetFix(limited.Reset, env)}}
+	case err != nil:
+		return []Result{{Name: "API", Status: Fail,
+			Detail: fmt.Sprintf("can't reach %s: %v", env.Host, err),
+			Fix:    "check the network and HTTPS_PROXY; use --api-url for GitHub Enterprise Server and --ca-cert for a proxy's certificate"}}
+	}
+
+	results := []Result{{Name: "API", Status: OK, Detail: env.Host + " is reachable"}}
+	if env.TokenSource != "" {
+		results = append(results, scopes(info))
+	}
+	return append(results, rate(info.Rate, env))
+}
+
+// scopes checks that a classic token can read private repositories. Other
+// tokens don't report their permissions.
+func scopes(info actions.TokenInfo) Result {
+	if !info.Classic {
+		return Result{Name: "Scopes", Status: OK,
+			Detail: "not reported for fine-grained and App tokens; they need Contents: read on private repositories"}
+	}
+	detail := "none"
+	if len(info.Scopes) > 0 {
+		detail = strings.Join(info.Scopes, ", ")
+	}
+	if !slices.Contains(info.Scopes, "repo") {
+		return Result{Name: "Scopes", Status: Warn, Detail: detail,
+			Fix: "add the repo scope to check actions and workflows in private repositories"}
+	}
+	return Result{Name: "Scopes", Status: OK, Detail: detail}
+}
+
+func rate(r actions.RateLimit, env Env) Result {
+	if r.Limit == 0 {
+		return Result{Name: "Rate limit", Status: OK, Detail: "not limited"}
+	}
+	detail := fmt.Sprintf("%d of %d left", r.Remaining, r.Limit)
+	switch {
+	case r.Remaining == 0:
+		return Result{Name: "Rate limit", Status: Fail, Detail: detail, Fix: resetFix(r.Reset, env)}
+	case float64(r.Remaining) < lowRate*float64(r.Limit):
+		return Result{Name: "Rate limit", Status: Warn, Detail: detail, Fix: resetFix(r.Reset, env)}
+	}
+	return Result{Name: "Rate limit", Status: OK, Detail: detail}
+}
+
+// resetFix suggests waiting for the rate limit to reset, or a token
+func resetFix(reset time.Time, env Env) string {
+	fix := "wait for the rate limit to reset"
+	if !reset.IsZero() {
+		fix = fmt.Sprintf("wait %s for the rate limit to reset", reset.Sub(env.Now()).Round(time.Minute))
+	}
+	if env.TokenSource == "" {
+		fix += ", or use a token for 5000 requests an hour"
+	}
+	return fix
+}
+
+func workflows(env Env) Result {
+	refs, err := env.References()
+	if err != nil {
+		return Result{Name: "Workflows", Status: Fail, Detail: err.Error(),
+			Fix: "run aver in a project with .github/workflows, or check another repository with --repo"}
+	}
+	if len(refs) == 0 {
+		return Result{Name: "Workflows", Status: Warn, Detail: "no actions are used",
+			Fix: "add workflows that use actions, or pass --workflow-dir for workflows elsewhere"}
+	}
+	files := make(map[string]bool)
+	for _, ref := range refs {
+		files[ref.File] = true
+	}
+	detail := fmt.Sprintf("%d action references in %d files", len(refs), len(files))
+	if len(files) == 1 {
+		detail = strings.TrimSuffix(detail, "s")
+	}
+	return Result{Name: "Workflows", Status: OK, Detail: detail}
+}
+
+// cacheDir checks that the cache can be written by writing a file to it
+func cacheDir(env Env) Result {
+	if env.CacheDir == "" {
+		return Result{Name: "Cache", Status: Warn, Detail: "no cache directory, so every run uses the API",
+			Fix: "set XDG_CACHE_HOME or HOME, or pass --cache-dir"}
+	}
+	err := os.MkdirAll(env.CacheDir, 0o755)
+	if err == nil {
+		var f *os.File
+		if f, err = os.CreateTemp(env.CacheDir, ".doctor-*"); err == nil {
+			_ = f.Close()
+			err = os.Remove(f.Name())
+		}
+	}
+	if err != nil {
+		return Result{Name: "Cache", Status: Fail, Detail: fmt.Sprintf("%s isn't writable: %v", env.CacheDir, err),
+			Fix: "fix the directory's permissions or pass --cache-dir"}
+	}
+	return Result{Name: "Cache", Status: OK, Detail: env.CacheDir + " is writable"}
+}
@@ -0,0 +1,108 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aver/pkg/actions"
+)
+
+func TestRun(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	env := Env{
+		Host:        "github.com",
+		TokenSource: "GITHUB_TOKEN",
+		TokenInfo: func(context.Context) (actions.TokenInfo, error) {
+			return actions.TokenInfo{
+				Classic: true,
+				Scopes:  []string{"read:org"},
+				Rate:    actions.RateLimit{Limit: 5000, Remaining: 100, Reset: now.Add(30 * time.Minute)},
+			}, nil
+		},
+		References: func() ([]actions.ActionReference, error) {
+			return []actions.ActionReference{
+				{Name: "actions/checkout", Version: "v4", File: "ci.yml"},
+				{Name: "actions/setup-go", Version: "v5", File: "ci.yml"},
+			}, nil
+		},
+		CacheDir: filepath.Join(t.TempDir(), "cache"),
+		Now:      func() time.Time { return now },
+	}
+
+	want := []Result{
+		{Name: "Token", Status: OK, Detail: "from GITHUB_TOKEN"},
+		{Name: "API", Status: OK, Detail: "github.com is reachable"},
+		{Name: "Scopes", Status: Warn, Detail: "read:org",
+			Fix: "add the repo scope to check actions and workflows in private repositories"},
+		{Name: "Rate limit", Status: Warn, Detail: "100 of 5000 left", Fix: "wait 30m0s for the rate limit to reset"},
+		{Name: "Workflows", Status: OK, Detail: "2 action references in 1 file"},
+		{Name: "Cache", Status: OK, Detail: env.CacheDir + " is writable"},
+	}
+	got := Run(context.Background(), env)
+	if len(got) != len(want) {
+		t.Fatalf("Run() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if Failed(got) {
+		t.Error("expected warnings not to fail")
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	readOnly := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(readOnly, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := Env{
+		Host:        "github.com",
+		TokenSource: "gh CLI",
+		TokenInfo: func(context.Context) (actions.TokenInfo, error) {
+			return actions.TokenInfo{}, &actions.ErrUnauthorized{Host: "api.github.com"}
+		},
+		References: func() ([]actions.ActionReference, error) {
+			return nil, errors.New("no workflows found")
+		},
+		// A file can't hold the cache
+		CacheDir: readOnly,
+	}
+
+	results := Run(context.Background(), env)
+	statuses := make(map[string]Status)
+	for _, r := range results {
+		statuses[r.Name] = r.Status
+		if r.Status != OK && r.Fix == "" {
+			t.Errorf("expected a fix for %+v", r)
+		}
+	}
+	for _, name := range []string{"API", "Workflows", "Cache"} {
+		if statuses[name] != Fail {
+			t.Errorf("expected %s to fail, got %+v", name, results)
+		}
+	}
+	if _, ok := statuses["Rate limit"]; ok {
+		t.Error("expected no rate limit check when the API fails")
+	}
+	if !Failed(results) {
+		t.Error("expected Failed to report the failures")
+	}
+}
+
+func TestRunOffline(t *testing.T) {
+	results := Run(context.Background(), Env{
+		Offline:    true,
+		TokenInfo:  func(context.Context) (actions.TokenInfo, error) { panic("the API was asked offline") },
+		References: func() ([]actions.ActionReference, error) { return nil, nil },
+	})
+	if results[0].Name != "Token" || results[0].Status != Warn ||
+		results[1].Name != "API" || results[1].Status != Warn {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
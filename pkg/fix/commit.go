@@ -0,0 +1,217 @@
+package fix
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Commit is a git commit of the changes to one action
+type Commit struct {
+	// SHA is empty for the commits a dry run would make
+	SHA     string   `json:"sha,omitempty"`
+	Name    string   `json:"action"`
+	Files   []string `json:"files"`
+	Message string   `json:"message"`
+}
+
+// CommitEach makes edits to the files under root one action at a time,
+// committing each action's changes, in every file, to the git repository
+// that holds root before moving on to the next. If branch isn't empty, it's
+// created at the current commit and checked out first; nothing is pushed.
+// With dryRun it only works out the changes and the commits they'd make.
+// Files with changes that aren't committed are an error, since they'd end
+// up in aver's commits. If a commit fails, the changes not yet committed
+// are undone and the original branch is checked out again, deleting branch
+// if nothing was committed to it.
+func CommitEach(root string, edits []Edit, branch string, dryRun bool) (Result, error) {
+	if dryRun {
+		result, err := Apply(root, edits, true)
+		result.Commits = commits(result.Changes)
+		return result, err
+	}
+
+	var files []string
+	for _, e := range edits {
+		if !slices.Contains(files, e.File) {
+			files = append(files, e.File)
+		}
+	}
+	if len(files) > 0 {
+		status, err := git(root, append([]string{"status", "--porcelain", "--"}, files...)...)
+		if err != nil {
+			return Result{}, err
+		}
+		if status != "" {
+			return Result{}, fmt.Errorf("workflows to update have changes that aren't committed; commit or stash them first:\n%s", status)
+		}
+	}
+	var original []string
+	if len(files) > 0 && branch != "" {
+		var err error
+		if original, err = switchBack(root); err != nil {
+			return Result{}, err
+		}
+		if _, err := git(root, "switch", "--quiet", "--create", branch); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var result Result
+	undo := func(err error) (Result, error) {
+		if _, restoreErr := git(root, append([]string{"restore", "--staged", "--worktree", "--"}, files...)...); restoreErr != nil {
+			return result, errors.Join(err, restoreErr)
+		}
+		if branch == "" {
+			return result, err
+		}
+		if _, switchErr := git(root, original...); switchErr != nil {
+			return result, errors.Join(err, switchErr)
+		}
+		if len(result.Commits) == 0 {
+			if _, deleteErr := git(root, "branch", "--quiet", "--delete", "--force", branch); deleteErr != nil {
+				return result, errors.Join(err, deleteErr)
+			}
+		}
+		return result, err
+	}
+	for _, group := range byAction(edits) {
+		applied, err := Apply(root, group, false)
+		result.Changes = append(result.Changes, applied.Changes...)
+		result.Skipped = append(result.Skipped, applied.Skipped...)
+		if err != nil {
+			return undo(err)
+		}
+		for _, c := range commits(applied.Changes) {
+			if _, err := git(root, append([]string{"add", "--"}, c.Files...)...); err != nil {
+				return undo(err)
+			}
+			if _, err := git(root, append([]string{"commit", "--quiet", "--message", c.Message, "--"}, c.Files...)...); err != nil {
+				return undo(err)
+			}
+			if c.SHA, err = git(root, "rev-parse", "HEAD"); err != nil {
+				return undo(err)
+			}
+			result.Commits = append(result.Commits, c)
+		}
+	}
+	slices.SortFunc(result.Changes, func(a, b Change) int {
+		return cmp.Or(strings.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return result, nil
+}
+
+// byAction splits edits into one group per action, in the order of their
+// names
+func byAction(edits []Edit) [][]Edit {
+	groups := make(map[string][]Edit)
+	for _, e := range edits {
+		groups[e.Name] = append(groups[e.Name], e)
+	}
+	var split [][]Edit
+	for _, name := range slices.Sorted(maps.Keys(groups)) {
+		split = append(split, groups[name])
+	}
+	return split
+}
+
+// commits groups changes into the commit for each action they change
+func commits(changes []Change) []Commit {
+	byName := make(map[string][]Change)
+	var names []string
+	for _, c := range changes {
+		if byName[c.Name] == nil {
+			names = append(names, c.Name)
+		}
+		byName[c.Name] = append(byName[c.Name], c)
+	}
+	slices.Sort(names)
+	var grouped []Commit
+	for _, name := range names {
+		commit := Commit{Name: name, Message: Message(byName[name])}
+		for _, c := range byName[name] {
+			if !slices.Contains(commit.Files, c.File) {
+				commit.Files = append(commit.Files, c.File)
+			}
+		}
+		slices.Sort(commit.Files)
+		grouped = append(grouped, commit)
+	}
+	return grouped
+}
+
+// Message returns the commit message for changes to one action, in the
+// conventional commit style of Dependabot's so that changelog tools treat
+// both alike: "ci(deps): bump actions/checkout from v3 to v4", leaving out
+// the old versions if there are several, and a body listing the workflows
+// changed. Commits are named by the versions they pin, not by their SHAs.
+func Message(changes []Change) string {
+	var from, to, files []string
+	for _, c := range changes {
+		if v := cmp.Or(c.FromTag, c.From); !slices.Contains(from, v) {
+			from = append(from, v)
+		}
+		if v := cmp.Or(c.Tag, c.To); !slices.Contains(to, v) {
+			to = append(to, v)
+		}
+		if !slices.Contains(files, c.File) {
+			files = append(files, c.File)
+		}
+	}
+	slices.Sort(files)
+
+	name, versions := changes[0].Name, strings.Join(to, ", ")
+	var b strings.Builder
+	if len(from) == 1 {
+		fmt.Fprintf(&b, "ci(deps): bump %s from %s to %s\n\n", name, from[0], versions)
+	} else {
+		fmt.Fprintf(&b, "ci(deps): bump %s to %s\n\n", name, versions)
+	}
+	fmt.Fprintf(&b, "Bumps %s from %s to %s.\n\nWorkflows:\n", name, and(from), versions)
+	for _, file := range files {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+	return b.String()
+}
+
+// and joins words into a list: "v3", "v3 and v4", "v2, v3 and v4"
+func and(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// switchBack returns the git switch arguments that check out what's checked
+// out in dir now: its branch, or its commit if HEAD is detached
+func switchBack(dir string) ([]string, error) {
+	if name, err := git(dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		return []string{"switch", "--quiet", name}, nil
+	}
+	sha, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return []string{"switch", "--quiet", "--detach", sha}, nil
+}
+
+// git runs git in dir and returns its output, or an error with what it
+// printed to stderr
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,180 @@
+package fix
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a git repository with the given files committed
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "aver")
+	t.Setenv("GIT_AUTHOR_EMAIL", "aver@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "aver")
+	t.Setenv("GIT_COMMITTER_EMAIL", "aver@example.com")
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"init", "--quiet"}, {"add", "."}, {"commit", "--quiet", "--message", "Initial commit"}} {
+		if _, err := git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCommitEach(t *testing.T) {
+	root := gitRepo(t, map[string]string{
+		"ci.yml":      "steps:\n  - uses: actions/checkout@v3\n  - uses: actions/setup-go@v4\n",
+		"release.yml": "steps:\n  - uses: actions/checkout@v4\n",
+	})
+	edits := []Edit{
+		{File: "ci.yml", Line: 2, Name: "actions/checkout", From: "v3", To: "v5"},
+		{File: "ci.yml", Line: 3, Name: "actions/setup-go", From: "v4", To: "v5"},
+		{File: "release.yml", Line: 2, Name: "actions/checkout", From: "v4", To: "v5"},
+	}
+
+	preview, err := CommitEach(root, edits, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Commit{
+		{Name: "actions/checkout", Files: []string{"ci.yml", "release.yml"},
+			Message: "ci(deps): bump actions/checkout to v5\n\nBumps actions/checkout from v3 and v4 to v5.\n\nWorkflows:\n- ci.yml\n- release.yml\n"},
+		{Name: "actions/setup-go", Files: []string{"ci.yml"},
+			Message: "ci(deps): bump actions/setup-go from v4 to v5\n\nBumps actions/setup-go from v4 to v5.\n\nWorkflows:\n- ci.yml\n"},
+	}
+	if !reflect.DeepEqual(preview.Commits, want) {
+		t.Errorf("expected a dry run to plan\n%+v\ngot\n%+v", want, preview.Commits)
+	}
+	if log, _ := git(root, "log", "--format=%s"); log != "Initial commit" {
+		t.Errorf("a dry run committed:\n%s", log)
+	}
+
+	result, err := CommitEach(root, edits, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 3 || len(result.Commits) != 2 || result.Commits[1].SHA == "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	log, _ := git(root, "log", "--format=%s", "--name-only")
+	wantLog := "ci(deps): bump actions/setup-go from v4 to v5\n\nci.yml\nci(deps): bump actions/checkout to v5\n\nci.yml\nrelease.yml\nInitial commit\n\nci.yml\nrelease.yml"
+	if log != wantLog {
+		t.Errorf("expected the log\n%s\ngot\n%s", wantLog, log)
+	}
+	if status, _ := git(root, "status", "--porcelain"); status != "" {
+		t.Errorf("expected a clean tree, got\n%s", status)
+	}
+}
+
+func TestCommitEachUncommitted(t *testing.T) {
+	root := gitRepo(t, map[string]string{"ci.yml": "steps:\n  - uses: actions/checkout@v3\n"})
+	if err := os.WriteFile(filepath.Join(root, "ci.yml"), []byte("steps:\n  - uses: actions/checkout@v3 # mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := CommitEach(root, []Edit{{File: "ci.yml", Line: 2, Name: "actions/checkout", From: "v3", To: "v4"}}, "aver/updates", false)
+	if err == nil || !strings.Contains(err.Error(), "ci.yml") {
+		t.Errorf("expected an error about ci.yml, got %v", err)
+	}
+	if branch, _ := git(root, "branch", "--show-current"); branch == "aver/updates" {
+		t.Error("the branch was created although nothing could be committed")
+	}
+}
+
+func TestCommitEachBranch(t *testing.T) {
+	root := gitRepo(t, map[string]string{"ci.yml": "steps:\n  - uses: actions/checkout@v3\n"})
+	main, _ := git(root, "branch", "--show-current")
+	edits := []Edit{{File: "ci.yml", Line: 2, Name: "actions/checkout", From: "v3", To: "v4"}}
+
+	if _, err := CommitEach(root, edits, "aver/updates", true); err != nil {
+		t.Fatal(err)
+	}
+	if branch, _ := git(root, "branch", "--show-current"); branch != main {
+		t.Errorf("a dry run switched to %q", branch)
+	}
+
+	if _, err := CommitEach(root, edits, "aver/updates", false); err != nil {
+		t.Fatal(err)
+	}
+	if branch, _ := git(root, "branch", "--show-current"); branch != "aver/updates" {
+		t.Errorf("expected to be on aver/updates, got %q", branch)
+	}
+	if log, _ := git(root, "log", "--format=%s", main+"..aver/updates"); log != "ci(deps): bump actions/checkout from v3 to v4" {
+		t.Errorf("unexpected commits on the branch:\n%s", log)
+	}
+
+	// The branch exists now
+	if _, err := git(root, "switch", "--quiet", main); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CommitEach(root, edits, "aver/updates", false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an error about the existing branch, got %v", err)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	sha := strings.Repeat("b", 40)
+	changes := []Change{
+		{File: ".github/workflows/release.yml", Line: 9, Name: "actions/checkout", From: strings.Repeat("a", 40), To: sha, FromTag: "v4.1.0", Tag: "v4.2.2"},
+		{File: ".github/workflows/ci.yml", Line: 5, Name: "actions/checkout", From: strings.Repeat("a", 40), To: sha, FromTag: "v4.1.0", Tag: "v4.2.2"},
+	}
+	want := `ci(deps): bump actions/checkout from v4.1.0 to v4.2.2
+
+Bumps actions/checkout from v4.1.0 to v4.2.2.
+
+Workflows:
+- .github/workflows/ci.yml
+- .github/workflows/release.yml
+`
+	if got := Message(changes); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestAnd(t *testing.T) {
+	for words, want := range map[string]string{"": "", "v3": "v3", "v3 v4": "v3 and v4", "v2 v3 v4": "v2, v3 and v4"} {
+		if got := and(strings.Fields(words)); got != want {
+			t.Errorf("and(%q) = %q, want %q", words, got, want)
+		}
+	}
+}
+
+func TestCommitEachUndoesFailure(t *testing.T) {
+	root := gitRepo(t, map[string]string{"ci.yml": "steps:\n  - uses: actions/checkout@v3\n"})
+	main, _ := git(root, "branch", "--show-current")
+	hook := filepath.Join(root, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\necho rejected >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	edits := []Edit{{File: "ci.yml", Line: 2, Name: "actions/checkout", From: "v3", To: "v4"}}
+
+	_, err := CommitEach(root, edits, "aver/updates", false)
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("expected the hook's error, got %v", err)
+	}
+	if branch, _ := git(root, "branch", "--show-current"); branch != main {
+		t.Errorf("expected to be back on %s, got %q", main, branch)
+	}
+	if branches, _ := git(root, "branch", "--list", "aver/updates"); branches != "" {
+		t.Errorf("expected the empty branch to be deleted, got %q", branches)
+	}
+	if status, _ := git(root, "status", "--porcelain"); status != "" {
+		t.Errorf("expected the changes to be undone, got\n%s", status)
+	}
+}
@@ -0,0 +1,369 @@
+// Package fix rewrites the versions actions are pinned to in workflow
+// files, for `aver fix`. Files are never decoded and encoded again, which
+// would lose comments, anchors, quoting and key order: the text of each
+// uses: value's version is replaced where the YAML parser found it, and
+// everything else in the file is left as it was.
+package fix
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"aver/pkg/actions"
+)
+
+// Edit changes the version of an action in a file, on every uses: line
+// that pins it to From
+type Edit struct {
+	File string `json:"file"`
+	// Line is a line of File that pins the action to From, whose uses:
+	// value tells how the file spells the action's name
+	Line int    `json:"line"`
+	Name string `json:"action"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Tag is the version To is the commit of, when pinning by SHA. It's
+	// written in the line's comment, in place of FromTag (or From) if the
+	// comment names it, or as a new comment if there's none.
+	Tag     string `json:"tag,omitempty"`
+	FromTag string `json:"from_tag,omitempty"`
+}
+
+// Change is a line that was rewritten
+type Change struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Name string `json:"action"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Tag is the version a SHA in To is the commit of, and FromTag the
+	// version a SHA in From is
+	Tag     string `json:"tag,omitempty"`
+	FromTag string `json:"from_tag,omitempty"`
+}
+
+// Options choose the edits Plan makes
+type Options struct {
+	// Unify pins each action in version drift to one version
+	Unify bool
+	// PinSHA pins outdated tag pins to the commit of their latest version,
+	// with the version in a comment, instead of to its tag
+	PinSHA bool
+	// Strategy is the largest update made, one of Strategies; empty
+	// allows any. Versions that aren't semantic are always updated.
+	Strategy string
+}
+
+// How far Options.Strategy lets an update go
+const (
+	StrategyPatch = "patch"
+	StrategyMinor = "minor"
+	StrategyMajor = "major"
+)
+
+// Strategies are the values Options.Strategy accepts, smallest first
+var Strategies = []string{StrategyPatch, StrategyMinor, StrategyMajor}
+
+// IgnoreRule returns the rule that passes over versions further away than
+// strategy allows, so that a check run for Plan recommends the newest
+// version within reach rather than one Plan would leave alone. It returns
+// false if strategy allows any update.
+func IgnoreRule(strategy string) (actions.IgnoreRule, bool) {
+	i := slices.Index(Strategies, strategy)
+	if i < 0 || i == len(Strategies)-1 {
+		return actions.IgnoreRule{}, false
+	}
+	return actions.IgnoreRule{Name: "*", UpdateTypes: Strategies[i+1:]}, true
+}
+
+// allows reports whether strategy allows the update e makes
+func allows(strategy string, e Edit) bool {
+	update := actions.UpdateType(cmp.Or(e.FromTag, e.From), cmp.Or(e.Tag, e.To))
+	return strategy == "" || update == "" || slices.Index(Strategies, update) <= slices.Index(Strategies, strategy)
+}
+
+// Result is what applying a set of edits did
+type Result struct {
+	Changes []Change `json:"changes"`
+	// Skipped are the edits whose line isn't a uses: line pinning the
+	// action to From, like the orbs and pipes of other CI systems, or
+	// files that changed since they were read
+	Skipped []Edit `json:"skipped,omitempty"`
+	// Commits are the commits made by CommitEach
+	Commits []Commit `json:"commits,omitempty"`
+}
+
+// Plan returns the edits that update each outdated action in result to
+// its latest version, and each SHA pin compared by tag to the commit of
+// its latest release (see actions.WithSHACompare). With opts.Unify, every
+// reference in refs to an action pinned to several versions goes to one
+// version instead: the newest in use (see actions.VersionDrift), or what
+// that's updated to if it's outdated too. Actions whose versions can't be
+// compared are left alone, and so are updates larger than opts.Strategy.
+func Plan(result actions.CheckResult, refs []actions.ActionReference, opts Options) []Edit {
+	type key struct{ file, name, from string }
+	edits := make(map[key]Edit)
+	latest := make(map[string]string)
+	// The commit of each latest version, by name@version
+	commits := make(map[string]string)
+	for _, o := range result.Outdated {
+		if o.Repository != "" || o.File == actions.StdinName {
+			continue
+		}
+		edits[key{o.File, o.Name, o.CurrentVersion}] = pin(Edit{
+			File: o.File, Line: o.Line, Name: o.Name, From: o.CurrentVersion, To: o.LatestVersion,
+		}, o.LatestSHA, opts.PinSHA)
+		latest[o.Name+"@"+o.CurrentVersion] = o.LatestVersion
+		commits[o.Name+"@"+o.LatestVersion] = o.LatestSHA
+	}
+	for _, s := range result.SHAPinned {
+		if s.LatestTagSHA == "" || s.Repository != "" || s.File == actions.StdinName {
+			continue
+		}
+		edits[key{s.File, s.Name, s.CurrentSHA}] = Edit{
+			File: s.File, Line: s.Line, Name: s.Name, From: s.CurrentSHA, To: s.LatestTagSHA,
+			Tag: s.LatestTag, FromTag: s.CurrentTag,
+		}
+	}
+
+	if opts.Unify {
+		for _, d := range actions.FindDrift(refs) {
+			if d.Unified == "" || d.Repository != "" {
+				continue
+			}
+			to := cmp.Or(latest[d.Name+"@"+d.Unified], d.Unified)
+			for _, ref := range refs {
+				if ref.Name != d.Name || ref.Repository != "" || ref.Version == to || ref.File == actions.StdinName {
+					continue
+				}
+				edits[key{ref.File, ref.Name, ref.Version}] = pin(Edit{
+					File: ref.File, Line: ref.Line, Name: ref.Name, From: ref.Version, To: to,
+				}, commits[d.Name+"@"+to], opts.PinSHA)
+			}
+		}
+	}
+
+	planned := slices.Collect(maps.Values(edits))
+	planned = slices.DeleteFunc(planned, func(e Edit) bool { return !allows(opts.Strategy, e) })
+	slices.SortFunc(planned, func(a, b Edit) int {
+		return cmp.Or(strings.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), strings.Compare(a.Name, b.Name))
+	})
+	return planned
+}
+
+// pin makes e pin the commit sha of its version, if pinSHA is set and the
+// commit is known
+func pin(e Edit, sha string, pinSHA bool) Edit {
+	if pinSHA && sha != "" {
+		e.To, e.Tag = sha, e.To
+	}
+	return e
+}
+
+// Apply makes edits to the files under root they name, or only works out
+// what they'd change if dryRun is set
+func Apply(root string, edits []Edit, dryRun bool) (Result, error) {
+	var result Result
+	byFile := make(map[string][]Edit)
+	var files []string
+	for _, e := range edits {
+		if byFile[e.File] == nil {
+			files = append(files, e.File)
+		}
+		byFile[e.File] = append(byFile[e.File], e)
+	}
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return result, err
+		}
+		rewritten, changes, skipped := Rewrite(file, content, byFile[file])
+		result.Changes = append(result.Changes, changes...)
+		result.Skipped = append(result.Skipped, skipped...)
+		if dryRun || len(changes) == 0 {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return result, err
+		}
+		if err := os.WriteFile(path, rewritten, info.Mode().Perm()); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// retag updates the version in the comment at the end of a line, rest
+// being what follows the last uses: value on it: from is replaced with to
+// where the comment names it (# v4.1.0, # tag=v4.1.0), and a comment
+// naming to is added if there's none. Other comments are left alone.
+func retag(rest []byte, from, to string) []byte {
+	hash := comment.FindIndex(rest)
+	if hash == nil {
+		value := bytes.TrimRight(rest, " \t\r")
+		return slices.Concat(value, []byte(" # "+to), rest[len(value):])
+	}
+	version := regexp.MustCompile(`(^|[\s=])` + regexp.QuoteMeta(from) + `(\s|$)`)
+	return slices.Concat(rest[:hash[1]], version.ReplaceAll(rest[hash[1]:], []byte("${1}"+to+"${2}")))
+}
+
+// comment matches the start of a YAML comment, which needs whitespace
+// before its #
+var comment = regexp.MustCompile(`(^|\s)#`)
+
+// usesValue is where a uses: value is in a file, from its YAML node
+type usesValue struct {
+	line    int // 0-based
+	start   int // Byte offsets in the line of the name@version text
+	end     int
+	name    string // The name as the file spells it
+	version string
+}
+
+// usesValues finds every uses: value in content, in the order they appear.
+// Values whose text isn't exactly what they parse to, like double-quoted
+// strings with escapes or plain ones folded over several lines, are left
+// out so that they aren't edited blindly.
+func usesValues(content []byte, lines [][]byte) ([]usesValue, error) {
+	var values []usesValue
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		walk(&doc, func(node *yaml.Node) {
+			name, version, ok := strings.Cut(node.Value, "@")
+			if !ok || node.Line < 1 || node.Line > len(lines) {
+				return
+			}
+			line := lines[node.Line-1]
+			// Columns count characters, not bytes
+			start := len(line)
+			if runes := []rune(string(line)); node.Column-1 <= len(runes) {
+				start = len(string(runes[:node.Column-1]))
+			}
+			// The node starts at its anchor or tag, if it has one:
+			// &checkout actions/checkout@v3
+			for start < len(line) && (line[start] == '&' || line[start] == '!') {
+				for start < len(line) && line[start] != ' ' && line[start] != '\t' {
+					start++
+				}
+				for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+					start++
+				}
+			}
+			if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+				start++
+			}
+			if !bytes.HasPrefix(line[min(start, len(line)):], []byte(node.Value)) {
+				return
+			}
+			values = append(values, usesValue{
+				line: node.Line - 1, start: start, end: start + len(node.Value), name: name, version: version,
+			})
+		})
+	}
+	slices.SortFunc(values, func(a, b usesValue) int { return cmp.Or(cmp.Compare(a.line, b.line), cmp.Compare(a.start, b.start)) })
+	return values, nil
+}
+
+// walk calls f with the value of every uses: key under node
+func walk(node *yaml.Node, f func(*yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key, value := node.Content[i], node.Content[i+1]; key.Value == "uses" && value.Kind == yaml.ScalarNode {
+				f(value)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		walk(child, f)
+	}
+}
+
+// Rewrite applies the edits of one file to its content and returns the
+// result, the values it changed and the edits it couldn't make. file is
+// only reported. Edits go by where the YAML parser found each uses:
+// value, so only the versions change, and the comments on their lines for
+// SHA pins: flow mappings, quoting, anchors and several values on a line
+// are all kept as they were.
+func Rewrite(file string, content []byte, edits []Edit) ([]byte, []Change, []Edit) {
+	lines := bytes.Split(content, []byte("\n"))
+	values, err := usesValues(content, lines)
+	if err != nil {
+		return content, nil, edits
+	}
+
+	type replacement struct {
+		value usesValue
+		edit  Edit
+	}
+	// Each value is only edited once, so one edit's version isn't
+	// another's From
+	byValue := make(map[int]replacement)
+	var skipped []Edit
+	for _, e := range edits {
+		// The recorded line says how this file spells the action
+		var name string
+		for _, v := range values {
+			if v.line == e.Line-1 && v.version == e.From && (name == "" || v.name == e.Name || strings.HasSuffix(v.name, "/"+e.Name)) {
+				name = v.name
+			}
+		}
+		if name == "" {
+			skipped = append(skipped, e)
+			continue
+		}
+		for i, v := range values {
+			if _, ok := byValue[i]; !ok && v.name == name && v.version == e.From {
+				byValue[i] = replacement{v, e}
+			}
+		}
+	}
+
+	var changes []Change
+	byLine := make(map[int][]replacement)
+	for i := range values {
+		if r, ok := byValue[i]; ok {
+			byLine[r.value.line] = append(byLine[r.value.line], r)
+		}
+	}
+	for n, replacements := range byLine {
+		line := lines[n]
+		var rewritten []byte
+		at := 0
+		for _, r := range replacements {
+			versionAt := r.value.start + len(r.value.name) + 1
+			rewritten = slices.Concat(rewritten, line[at:versionAt], []byte(r.edit.To))
+			at = r.value.end
+		}
+		rest := line[at:]
+		for _, r := range replacements {
+			if r.edit.Tag != "" {
+				rest = retag(rest, cmp.Or(r.edit.FromTag, r.edit.From), r.edit.Tag)
+			}
+			e := r.edit
+			changes = append(changes, Change{File: file, Line: n + 1, Name: e.Name, From: e.From, To: e.To, Tag: e.Tag, FromTag: e.FromTag})
+		}
+		lines[n] = slices.Concat(rewritten, rest)
+	}
+	slices.SortStableFunc(changes, func(a, b Change) int { return cmp.Compare(a.Line, b.Line) })
+	return bytes.Join(lines, []byte("\n")), changes, skipped
+}
@@ -0,0 +1,337 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func TestPlan(t *testing.T) {
+	refs := []actions.ActionReference{
+		{Name: "actions/checkout", Version: "v3", File: "ci.yml", Line: 5},
+		{Name: "actions/checkout", Version: "v4", File: "release.yml", Line: 7},
+		{Name: "actions/setup-go", Version: "v4", File: "ci.yml", Line: 6},
+		{Name: "actions/setup-go", Version: "v5", File: "release.yml", Line: 8},
+		{Name: "actions/cache", Version: "v3", File: "ci.yml", Line: 9},
+		{Name: "actions/cache", Version: "main", File: "release.yml", Line: 9},
+	}
+	result := actions.CheckResult{Outdated: []actions.OutdatedAction{
+		{File: "ci.yml", Line: 5, Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v5"},
+		{File: "release.yml", Line: 7, Name: "actions/checkout", CurrentVersion: "v4", LatestVersion: "v5"},
+		{File: "ci.yml", Line: 9, Name: "actions/cache", CurrentVersion: "v3", LatestVersion: "v4"},
+		{Repository: "owner/other", File: "ci.yml", Line: 1, Name: "actions/cache", CurrentVersion: "v2", LatestVersion: "v4"},
+	}}
+
+	updates := []Edit{
+		{File: "ci.yml", Line: 5, Name: "actions/checkout", From: "v3", To: "v5"},
+		{File: "ci.yml", Line: 9, Name: "actions/cache", From: "v3", To: "v4"},
+		{File: "release.yml", Line: 7, Name: "actions/checkout", From: "v4", To: "v5"},
+	}
+	if got := Plan(result, refs, Options{}); !reflect.DeepEqual(got, updates) {
+		t.Errorf("expected\n%+v\ngot\n%+v", updates, got)
+	}
+
+	// setup-go isn't outdated but drifts; cache's versions can't be compared
+	unified := []Edit{
+		{File: "ci.yml", Line: 5, Name: "actions/checkout", From: "v3", To: "v5"},
+		{File: "ci.yml", Line: 6, Name: "actions/setup-go", From: "v4", To: "v5"},
+		{File: "ci.yml", Line: 9, Name: "actions/cache", From: "v3", To: "v4"},
+		{File: "release.yml", Line: 7, Name: "actions/checkout", From: "v4", To: "v5"},
+	}
+	if got := Plan(result, refs, Options{Unify: true}); !reflect.DeepEqual(got, unified) {
+		t.Errorf("expected\n%+v\ngot\n%+v", unified, got)
+	}
+}
+
+func TestPlanPinSHA(t *testing.T) {
+	old, v410, v422 := strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("c", 40)
+	refs := []actions.ActionReference{
+		{Name: "actions/checkout", Version: "v3", File: "ci.yml", Line: 5},
+		{Name: "actions/cache", Version: "v3", File: "ci.yml", Line: 6},
+		{Name: "actions/setup-go", Version: old, File: "ci.yml", Line: 7},
+		{Name: "actions/setup-node", Version: old, File: "ci.yml", Line: 8},
+	}
+	result := actions.CheckResult{
+		Outdated: []actions.OutdatedAction{
+			{File: "ci.yml", Line: 5, Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4", LatestSHA: v422},
+			// Forges that don't say which commit a tag is keep the tag
+			{File: "ci.yml", Line: 6, Name: "actions/cache", CurrentVersion: "v3", LatestVersion: "v4"},
+		},
+		SHAPinned: []actions.SHAPinnedAction{
+			{File: "ci.yml", Line: 7, Name: "actions/setup-go", CurrentSHA: old, LatestSHA: v422,
+				CurrentTag: "v4.1.0", LatestTag: "v4.2.2", LatestTagSHA: v422},
+			// Compared with the default branch only
+			{File: "ci.yml", Line: 8, Name: "actions/setup-node", CurrentSHA: old, LatestSHA: v410, DefaultBranch: "main"},
+		},
+	}
+
+	sha := Edit{File: "ci.yml", Line: 7, Name: "actions/setup-go", From: old, To: v422, Tag: "v4.2.2", FromTag: "v4.1.0"}
+	want := []Edit{
+		{File: "ci.yml", Line: 5, Name: "actions/checkout", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 6, Name: "actions/cache", From: "v3", To: "v4"},
+		sha,
+	}
+	if got := Plan(result, refs, Options{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%+v\ngot\n%+v", want, got)
+	}
+	want[0] = Edit{File: "ci.yml", Line: 5, Name: "actions/checkout", From: "v3", To: v422, Tag: "v4"}
+	if got := Plan(result, refs, Options{PinSHA: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%+v\ngot\n%+v", want, got)
+	}
+}
+
+func TestPlanStrategy(t *testing.T) {
+	old, sha := strings.Repeat("a", 40), strings.Repeat("b", 40)
+	refs := []actions.ActionReference{
+		{Name: "actions/checkout", Version: "v4.1.0", File: "ci.yml", Line: 1},
+		{Name: "actions/cache", Version: "v4.1.0", File: "ci.yml", Line: 2},
+		{Name: "actions/setup-go", Version: "v4.1.0", File: "ci.yml", Line: 3},
+		{Name: "owner/nightly", Version: "nightly-2025-01-01", File: "ci.yml", Line: 4},
+		{Name: "actions/setup-node", Version: old, File: "ci.yml", Line: 5},
+		// Unifying would make these a major update
+		{Name: "owner/tool", Version: "v1", File: "ci.yml", Line: 6},
+		{Name: "owner/tool", Version: "v2", File: "release.yml", Line: 6},
+	}
+	result := actions.CheckResult{
+		Outdated: []actions.OutdatedAction{
+			{File: "ci.yml", Line: 1, Name: "actions/checkout", CurrentVersion: "v4.1.0", LatestVersion: "v4.1.2"},
+			{File: "ci.yml", Line: 2, Name: "actions/cache", CurrentVersion: "v4.1.0", LatestVersion: "v4.3.0"},
+			{File: "ci.yml", Line: 3, Name: "actions/setup-go", CurrentVersion: "v4.1.0", LatestVersion: "v5.0.0"},
+			{File: "ci.yml", Line: 4, Name: "owner/nightly", CurrentVersion: "nightly-2025-01-01", LatestVersion: "nightly-2025-02-01"},
+		},
+		SHAPinned: []actions.SHAPinnedAction{
+			{File: "ci.yml", Line: 5, Name: "actions/setup-node", CurrentSHA: old, CurrentTag: "v4.1.0", LatestTag: "v4.2.0", LatestTagSHA: sha},
+		},
+	}
+	names := func(edits []Edit) []string {
+		var names []string
+		for _, e := range edits {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		{StrategyPatch, []string{"actions/checkout", "owner/nightly"}},
+		{StrategyMinor, []string{"actions/checkout", "actions/cache", "owner/nightly", "actions/setup-node"}},
+		{StrategyMajor, []string{"actions/checkout", "actions/cache", "actions/setup-go", "owner/nightly", "actions/setup-node", "owner/tool"}},
+		{"", []string{"actions/checkout", "actions/cache", "actions/setup-go", "owner/nightly", "actions/setup-node", "owner/tool"}},
+	}
+	for _, tt := range tests {
+		if got := names(Plan(result, refs, Options{Unify: true, Strategy: tt.strategy})); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.strategy, tt.want, got)
+		}
+	}
+}
+
+func TestIgnoreRule(t *testing.T) {
+	if rule, ok := IgnoreRule(StrategyPatch); !ok || rule.Name != "*" || !reflect.DeepEqual(rule.UpdateTypes, []string{"minor", "major"}) {
+		t.Errorf("unexpected rule for patch: %+v", rule)
+	}
+	if rule, ok := IgnoreRule(StrategyMinor); !ok || !reflect.DeepEqual(rule.UpdateTypes, []string{"major"}) {
+		t.Errorf("unexpected rule for minor: %+v", rule)
+	}
+	for _, strategy := range []string{StrategyMajor, ""} {
+		if rule, ok := IgnoreRule(strategy); ok {
+			t.Errorf("expected no rule for %q, got %+v", strategy, rule)
+		}
+	}
+}
+
+func TestRetag(t *testing.T) {
+	tests := []struct{ rest, want string }{
+		{"", " # v4.2.2"},
+		{"\r", " # v4.2.2\r"},
+		{`"`, `" # v4.2.2`},
+		{" # v4.1.0", " # v4.2.2"},
+		{"  #v4.1.0 (pinned)", "  #v4.2.2 (pinned)"},
+		{" # tag=v4.1.0", " # tag=v4.2.2"},
+		{" # v4.1.0.1", " # v4.1.0.1"},
+		{" # keep this", " # keep this"},
+	}
+	for _, tt := range tests {
+		if got := string(retag([]byte(tt.rest), "v4.1.0", "v4.2.2")); got != tt.want {
+			t.Errorf("retag(%q) = %q, want %q", tt.rest, got, tt.want)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	workflow := `jobs:
+  build:
+    steps:
+      # Check out first
+      - uses: actions/checkout@v3 # pinned for now
+      - uses: "actions/checkout@v3"
+      - name: Go
+        uses: 'actions/setup-go@v4'
+        with:
+          go-version: stable
+      - uses: https://github.com/actions/cache@v3
+      - uses: actions/checkout@v3.1.0
+      - run: |
+          echo "uses: actions/checkout@v3"
+`
+	edits := []Edit{
+		{File: "ci.yml", Line: 5, Name: "actions/checkout", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 8, Name: "actions/setup-go", From: "v4", To: "v5"},
+		{File: "ci.yml", Line: 11, Name: "actions/cache", From: "v3", To: "v4"},
+		// The file changed since it was read
+		{File: "ci.yml", Line: 7, Name: "owner/gone", From: "v1", To: "v2"},
+	}
+	got, changes, skipped := Rewrite("ci.yml", []byte(workflow), edits)
+	want := `jobs:
+  build:
+    steps:
+      # Check out first
+      - uses: actions/checkout@v4 # pinned for now
+      - uses: "actions/checkout@v4"
+      - name: Go
+        uses: 'actions/setup-go@v5'
+        with:
+          go-version: stable
+      - uses: https://github.com/actions/cache@v4
+      - uses: actions/checkout@v3.1.0
+      - run: |
+          echo "uses: actions/checkout@v3"
+`
+	if string(got) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	wantChanges := []Change{
+		{File: "ci.yml", Line: 5, Name: "actions/checkout", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 6, Name: "actions/checkout", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 8, Name: "actions/setup-go", From: "v4", To: "v5"},
+		{File: "ci.yml", Line: 11, Name: "actions/cache", From: "v3", To: "v4"},
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("expected changes\n%+v\ngot\n%+v", wantChanges, changes)
+	}
+	if len(skipped) != 1 || skipped[0].Name != "owner/gone" {
+		t.Errorf("expected owner/gone to be skipped, got %+v", skipped)
+	}
+}
+
+func TestRewritePositions(t *testing.T) {
+	workflow := "# Steps in every style\r\n" +
+		"defaults: &defaults\r\n" +
+		"  - uses: &checkout actions/checkout@v3 # checkout\r\n" +
+		"jobs:\r\n" +
+		"  build:\r\n" +
+		"    steps:\r\n" +
+		"      - *checkout\r\n" +
+		"      - {uses: actions/setup-go@v4, with: {go-version: stable}}   # go\r\n" +
+		"      - {name: \"café\", uses: \"actions/cache@v3\"}\r\n" +
+		"      - uses: !!str 'actions/cache@v3'\r\n" +
+		"      - uses: \"actions/cache@\\x76\\x33\"\r\n" +
+		"    services: [{uses: owner/a@v1}, {uses: owner/a@v1}]\r\n" +
+		"---\r\n" +
+		"steps: [{uses: actions/setup-go@v4}]\r\n"
+	edits := []Edit{
+		{File: "ci.yml", Line: 3, Name: "actions/checkout", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 8, Name: "actions/setup-go", From: "v4", To: "v5"},
+		{File: "ci.yml", Line: 9, Name: "actions/cache", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 12, Name: "owner/a", From: "v1", To: "v2"},
+	}
+	want := "# Steps in every style\r\n" +
+		"defaults: &defaults\r\n" +
+		"  - uses: &checkout actions/checkout@v4 # checkout\r\n" +
+		"jobs:\r\n" +
+		"  build:\r\n" +
+		"    steps:\r\n" +
+		"      - *checkout\r\n" +
+		"      - {uses: actions/setup-go@v5, with: {go-version: stable}}   # go\r\n" +
+		"      - {name: \"café\", uses: \"actions/cache@v4\"}\r\n" +
+		"      - uses: !!str 'actions/cache@v4'\r\n" +
+		// Escapes aren't worth the risk of editing the wrong text
+		"      - uses: \"actions/cache@\\x76\\x33\"\r\n" +
+		"    services: [{uses: owner/a@v2}, {uses: owner/a@v2}]\r\n" +
+		"---\r\n" +
+		"steps: [{uses: actions/setup-go@v5}]\r\n"
+	got, changes, skipped := Rewrite("ci.yml", []byte(workflow), edits)
+	if string(got) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	var lines []int
+	for _, c := range changes {
+		lines = append(lines, c.Line)
+	}
+	if want := []int{3, 8, 9, 10, 12, 12, 14}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected changes on lines %v, got %+v", want, changes)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("unexpected skipped edits %+v", skipped)
+	}
+
+	// Several SHA pins on a line share its comment
+	old, sha := strings.Repeat("a", 40), strings.Repeat("b", 40)
+	line := "steps: [{uses: owner/a@" + old + "}, {uses: owner/b@" + old + "}]\n"
+	got, _, _ = Rewrite("ci.yml", []byte(line), []Edit{
+		{File: "ci.yml", Line: 1, Name: "owner/a", From: old, To: sha, Tag: "v2", FromTag: "v1"},
+		{File: "ci.yml", Line: 1, Name: "owner/b", From: old, To: sha, Tag: "v2", FromTag: "v1"},
+	})
+	if want := "steps: [{uses: owner/a@" + sha + "}, {uses: owner/b@" + sha + "}] # v2\n"; string(got) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	if _, changes, skipped := Rewrite("ci.yml", []byte("steps: [\n"), edits); len(changes) != 0 || len(skipped) != len(edits) {
+		t.Errorf("expected a file that doesn't parse to be left alone, got %+v and %+v", changes, skipped)
+	}
+}
+
+func TestRewriteOnce(t *testing.T) {
+	workflow := "steps:\n  - uses: actions/checkout@v3\n  - uses: actions/checkout@v4\n"
+	edits := []Edit{
+		{File: "ci.yml", Line: 2, Name: "actions/checkout", From: "v3", To: "v4"},
+		{File: "ci.yml", Line: 3, Name: "actions/checkout", From: "v4", To: "v5"},
+	}
+	got, changes, _ := Rewrite("ci.yml", []byte(workflow), edits)
+	if want := "steps:\n  - uses: actions/checkout@v4\n  - uses: actions/checkout@v5\n"; string(got) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected 2 changes, got %+v", changes)
+	}
+}
+
+func TestApply(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(".github", "workflows", "ci.yml")
+	original := "steps:\n  - uses: actions/checkout@v3\n"
+	if err := os.WriteFile(filepath.Join(root, file), []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	edits := []Edit{{File: file, Line: 2, Name: "actions/checkout", From: "v3", To: "v4"}}
+
+	result, err := Apply(root, edits, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 {
+		t.Errorf("expected a change, got %+v", result)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, file)); string(data) != original {
+		t.Errorf("a dry run changed the file:\n%s", data)
+	}
+
+	if _, err := Apply(root, edits, false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, file))
+	if want := "steps:\n  - uses: actions/checkout@v4\n"; string(data) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, data)
+	}
+	if info, _ := os.Stat(filepath.Join(root, file)); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the file's mode to be kept, got %v", info.Mode())
+	}
+}
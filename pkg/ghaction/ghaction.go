@@ -0,0 +1,168 @@
+// Package ghaction lets aver run as a GitHub Action: it reads inputs from
+// INPUT_* variables and reports through workflow commands, step outputs and
+// the job summary.
+package ghaction
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Running reports whether aver is running in an Actions job that accepts
+// step outputs
+func Running() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true" && os.Getenv("GITHUB_OUTPUT") != ""
+}
+
+// event is the part of the webhook payload that triggered the workflow
+// aver reads
+type event struct {
+	PullRequest struct {
+		Number int `json:"number"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+func readEvent() (event, bool) {
+	var e event
+	data, err := os.ReadFile(os.Getenv("GITHUB_EVENT_PATH"))
+	if err != nil || json.Unmarshal(data, &e) != nil {
+		return event{}, false
+	}
+	return e, true
+}
+
+// PullRequest returns the repository and number of the pull request that
+// triggered the workflow, from $GITHUB_REPOSITORY and the event payload. ok
+// is false for events other than pull_request and pull_request_target.
+func PullRequest() (repo string, number int, ok bool) {
+	repo = os.Getenv("GITHUB_REPOSITORY")
+	e, ok := readEvent()
+	if repo == "" || !ok || e.PullRequest.Number == 0 {
+		return "", 0, false
+	}
+	return repo, e.PullRequest.Number, true
+}
+
+// HeadSHA returns the commit the workflow checks: the head of the pull
+// request that triggered it, since $GITHUB_SHA is then a merge commit, or
+// else $GITHUB_SHA
+func HeadSHA() string {
+	if e, ok := readEvent(); ok && e.PullRequest.Head.SHA != "" {
+		return e.PullRequest.Head.SHA
+	}
+	return os.Getenv("GITHUB_SHA")
+}
+
+// Input returns the value of an action input, e.g. "ignore-sha"
+func Input(name string) string {
+	name = strings.ToUpper(strings.ReplaceAll(name, " ", "_"))
+	return strings.TrimSpace(os.Getenv("INPUT_" + name))
+}
+
+// Args turns action inputs into command-line flags: each of bools that is
+// "true" becomes --name, and each of values that is set becomes --name value
+func Args(bools, values []string) []string {
+	var args []string
+	for _, name := range bools {
+		if strings.EqualFold(Input(name), "true") {
+			args = append(args, "--"+name)
+		}
+	}
+	for _, name := range values {
+		if value := Input(name); value != "" {
+			args = append(args, "--"+name, value)
+		}
+	}
+	return args
+}
+
+// Annotation is a warning or error shown on a line of a file in the
+// workflow run and pull request
+type Annotation struct {
+	Level   string // "error", "warning" or "notice"
+	File    string
+	Line    int
+	Title   string
+	Message string
+}
+
+// String formats the annotation as a workflow command
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+	}
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+	cmd := "::" + a.Level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+// Error formats a failure message as a workflow command
+func Error(message string) string {
+	return Annotation{Level: "error", Message: message}.String()
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// SetOutput sets a step output by appending to $GITHUB_OUTPUT. Values may
+// span lines.
+func SetOutput(name, value string) error {
+	delimiter, err := randomDelimiter()
+	if err != nil {
+		return err
+	}
+	return appendEnvFile("GITHUB_OUTPUT", fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter))
+}
+
+// AppendSummary adds Markdown to the job summary in $GITHUB_STEP_SUMMARY
+func AppendSummary(markdown string) error {
+	return appendEnvFile("GITHUB_STEP_SUMMARY", markdown)
+}
+
+// appendEnvFile appends to the file named by an environment variable. It
+// does nothing outside Actions, where the variable isn't set.
+func appendEnvFile(name, content string) error {
+	path := os.Getenv(name)
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// randomDelimiter returns a heredoc delimiter that can't appear in a value
+func randomDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "aver_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,125 @@
+package ghaction
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestRunning(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_OUTPUT", "")
+	if Running() {
+		t.Error("expected no step outputs without $GITHUB_OUTPUT")
+	}
+	t.Setenv("GITHUB_OUTPUT", filepath.Join(t.TempDir(), "output"))
+	if !Running() {
+		t.Error("expected to be running in Actions")
+	}
+}
+
+func TestPullRequest(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GITHUB_REPOSITORY", "owner/app")
+
+	for _, tt := range []struct {
+		event  string
+		number int
+		ok     bool
+	}{
+		{`{"action": "opened", "number": 7, "pull_request": {"number": 7}}`, 7, true},
+		{`{"ref": "refs/heads/main"}`, 0, false},
+		{`not json`, 0, false},
+	} {
+		path := filepath.Join(dir, "event.json")
+		if err := os.WriteFile(path, []byte(tt.event), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("GITHUB_EVENT_PATH", path)
+		repo, number, ok := PullRequest()
+		if ok != tt.ok || number != tt.number || (ok && repo != "owner/app") {
+			t.Errorf("%s: got %s, %d, %v", tt.event, repo, number, ok)
+		}
+	}
+}
+
+func TestHeadSHA(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.json")
+	t.Setenv("GITHUB_EVENT_PATH", path)
+	t.Setenv("GITHUB_SHA", "merge123")
+
+	if got := HeadSHA(); got != "merge123" {
+		t.Errorf("expected $GITHUB_SHA without an event, got %q", got)
+	}
+	if err := os.WriteFile(path, []byte(`{"pull_request": {"number": 7, "head": {"sha": "head456"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := HeadSHA(); got != "head456" {
+		t.Errorf("expected the pull request head, got %q", got)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	t.Setenv("INPUT_IGNORE-SHA", "true")
+	t.Setenv("INPUT_RELEASES", "false")
+	t.Setenv("INPUT_MIN-RELEASE-AGE", " 7d ")
+	t.Setenv("INPUT_BASELINE", "")
+
+	got := Args([]string{"ignore-sha", "releases", "notes"}, []string{"min-release-age", "baseline"})
+	want := []string{"--ignore-sha", "--min-release-age", "7d"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestAnnotation(t *testing.T) {
+	tests := []struct {
+		annotation Annotation
+		expected   string
+	}{
+		{
+			Annotation{Level: "warning", File: ".github/workflows/ci.yml", Line: 12, Title: "Outdated action", Message: "actions/checkout@v4 can be updated to v5"},
+			"::warning file=.github/workflows/ci.yml,line=12,title=Outdated action::actions/checkout@v4 can be updated to v5",
+		},
+		{
+			Annotation{Level: "warning", File: "a,b.yml", Title: "x: y", Message: "100%\nsure"},
+			"::warning file=a%2Cb.yml,title=x%3A y::100%25%0Asure",
+		},
+		{Annotation{Level: "error", Message: "2 actions are outdated"}, "::error::2 actions are outdated"},
+	}
+	for _, tt := range tests {
+		if got := tt.annotation.String(); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	if err := SetOutput("count", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetOutput("json", "{\n}"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := regexp.MustCompile(`^count<<(aver_[0-9a-f]{32})\n3\n(aver_[0-9a-f]{32})\njson<<(aver_[0-9a-f]{32})\n\{\n\}\n(aver_[0-9a-f]{32})\n$`)
+	m := pattern.FindStringSubmatch(string(data))
+	if m == nil || m[1] != m[2] || m[3] != m[4] {
+		t.Errorf("unexpected output file:\n%s", data)
+	}
+}
+
+func TestAppendSummaryOutsideActions(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	if err := AppendSummary("## aver\n"); err != nil {
+		t.Errorf("expected no error outside Actions, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package ghaction
+
+import (
+	"fmt"
+	"strings"
+
+	"aver/pkg/actions"
+)
+
+// Markdown renders a result as a Markdown report for the job summary.
+// repoURL links an action to its repository.
+func Markdown(result actions.CheckResult, repoURL func(action string) string) string {
+	var b strings.Builder
+	b.WriteString("## aver\n\n")
+
+	if result.UpToDate() && len(result.Unchecked) == 0 && len(result.Dynamic) == 0 {
+		b.WriteString("All actions are up to date.\n")
+		return b.String()
+	}
+
+	if len(result.Outdated) > 0 {
+		b.WriteString("### Outdated actions\n\n")
+		b.WriteString("| File | Action | Current | Latest |\n| --- | --- | --- | --- |\n")
+		for _, o := range result.Outdated {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				location(o.File, o.Line), link(o.Name, repoURL(o.Name)), code(o.CurrentVersion), code(o.LatestVersion))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.SHAPinned) > 0 {
+		b.WriteString("### SHA-pinned actions behind default branch\n\n")
+		b.WriteString("| File | Action | Behind |\n| --- | --- | --- |\n")
+		for _, s := range result.SHAPinned {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", location(s.File, s.Line), link(s.Name, repoURL(s.Name)), cell(s.Describe()))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Inputs) > 0 {
+		b.WriteString("### Step inputs\n\n")
+		b.WriteString("| File | Action | Input | Problem |\n| --- | --- | --- | --- |\n")
+		for _, f := range result.Inputs {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				location(f.File, f.Line), link(f.Name, repoURL(f.Name)), code(f.Input), cell(f.Message))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Commands) > 0 {
+		b.WriteString("### Deprecated workflow commands\n\n")
+		b.WriteString("| File | Command | Use instead |\n| --- | --- | --- |\n")
+		for _, d := range result.Commands {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", location(d.File, d.Line), code("::"+d.Command), code(d.UseInstead))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Unchecked) > 0 {
+		b.WriteString("### Not checked\n\n")
+		b.WriteString("| File | Action | Version | Reason |\n| --- | --- | --- | --- |\n")
+		for _, u := range result.Unchecked {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", cell(u.File), link(u.Name, repoURL(u.Name)), code(u.Version), cell(u.Reason))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Dynamic) > 0 {
+		b.WriteString("### Dynamic refs\n\n")
+		b.WriteString("These are expressions, so any version can run without the workflow changing.\n\n")
+		b.WriteString("| File | Action | Ref |\n| --- | --- | --- |\n")
+		for _, d := range result.Dynamic {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", location(d.File, d.Line), code(d.Name), code(d.Ref))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func location(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", cell(file), line)
+	}
+	return cell(file)
+}
+
+func link(text, url string) string {
+	return fmt.Sprintf("[%s](%s)", cell(text), url)
+}
+
+func code(s string) string {
+	return "`" + cell(s) + "`"
+}
+
+// cell keeps text from breaking out of a table cell
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,46 @@
+package ghaction
+
+import (
+	"strings"
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func repoURL(action string) string {
+	return "https://github.com/" + action
+}
+
+func TestMarkdown(t *testing.T) {
+	result := actions.CheckResult{
+		Outdated: []actions.OutdatedAction{
+			{File: ".github/workflows/ci.yml", Line: 12, Name: "actions/checkout", CurrentVersion: "v4", LatestVersion: "v5"},
+		},
+		SHAPinned: []actions.SHAPinnedAction{
+			{File: "release.yml", Name: "owner/tool", CurrentSHA: "0123456789abcdef", LatestSHA: "fedcba9876543210", CommitsBehind: 3, DefaultBranch: "main"},
+			{File: "deploy.yml", Name: "owner/tool", CurrentSHA: "0123456789abcdef", LatestSHA: "fedcba9876543210", CommitsBehind: 3,
+				CurrentTag: "v1.2.0", LatestTag: "v1.3.1"},
+		},
+	}
+
+	got := Markdown(result, repoURL)
+	for _, want := range []string{
+		"| .github/workflows/ci.yml:12 | [actions/checkout](https://github.com/actions/checkout) | `v4` | `v5` |\n",
+		"| release.yml | [owner/tool](https://github.com/owner/tool) | owner/tool@0123456 is 3 commits behind main (fedcba9) |\n",
+		"| deploy.yml | [owner/tool](https://github.com/owner/tool) | owner/tool@0123456 (v1.2.0) can be updated to v1.3.1 |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	if got := Markdown(actions.CheckResult{}, repoURL); !strings.Contains(got, "All actions are up to date") {
+		t.Errorf("unexpected report for a clean result:\n%s", got)
+	}
+}
+
+func TestCell(t *testing.T) {
+	if got := cell("a|b\nc"); got != `a\|b c` {
+		t.Errorf("unexpected cell %q", got)
+	}
+}
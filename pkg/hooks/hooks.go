@@ -0,0 +1,77 @@
+// Package hooks installs git hooks that run aver before changes leave the
+// machine.
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// marker identifies hooks written by aver, which may be replaced freely
+const marker = "# Installed by aver install-hooks"
+
+// Names are the hooks aver can install
+var Names = []string{"pre-commit", "pre-push"}
+
+// Script returns the hook script for name. Both check only workflow files
+// by name, so aver trusts cached data for longer and stays quick: pre-commit
+// the staged ones, pre-push all of them.
+func Script(name string) (string, error) {
+	var files string
+	switch name {
+	case "pre-commit":
+		files = `git diff --cached --name-only --diff-filter=ACMR -z -- '.github/workflows/*.yml' '.github/workflows/*.yaml'`
+	case "pre-push":
+		files = `git ls-files -z -- '.github/workflows/*.yml' '.github/workflows/*.yaml'`
+	default:
+		return "", fmt.Errorf("unknown hook %q; use one of %s", name, strings.Join(Names, ", "))
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s: fail the %s if workflows use outdated
+# actions. Skip it once with --no-verify.
+if ! command -v aver >/dev/null 2>&1; then
+	echo "aver not found; skipping the workflow check" >&2
+	exit 0
+fi
+files=$(%s | tr '\0' '\n')
+[ -n "$files" ] || exit 0
+%s | xargs -0 aver --quiet
+`, marker, strings.TrimPrefix(name, "pre-"), files, files), nil
+}
+
+// ErrExists is returned by Install when a hook that aver didn't write is
+// in the way
+var ErrExists = errors.New("hook already exists")
+
+// Install writes the hook called name into hooksDir and returns its path.
+// A hook written by aver is replaced; any other is only replaced if force
+// is set.
+func Install(hooksDir, name string, force bool) (string, error) {
+	script, err := Script(name)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(hooksDir, name)
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return "", err
+	case !force && !strings.Contains(string(existing), marker):
+		return "", fmt.Errorf("%w: %s", ErrExists, path)
+	}
+
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", err
+	}
+	// WriteFile keeps the mode of an existing file
+	return path, os.Chmod(path, 0o755)
+}
@@ -0,0 +1,66 @@
+package hooks
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	for _, name := range Names {
+		script, err := Script(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(script, "#!/bin/sh\n") || !strings.Contains(script, "xargs -0 aver --quiet") {
+			t.Errorf("%s: unexpected script:\n%s", name, script)
+		}
+
+		// The script must at least parse
+		if sh, err := exec.LookPath("sh"); err == nil {
+			if out, err := exec.Command(sh, "-n", "-c", script).CombinedOutput(); err != nil {
+				t.Errorf("%s: invalid shell: %v\n%s", name, err, out)
+			}
+		}
+	}
+
+	if _, err := Script("post-merge"); err == nil {
+		t.Error("expected an error for an unknown hook")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+
+	path, err := Install(dir, "pre-push", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected an executable hook, got %v, %v", info, err)
+	}
+
+	// aver's own hook is replaced without --force
+	if _, err := Install(dir, "pre-push", false); err != nil {
+		t.Errorf("expected to replace aver's hook, got %v", err)
+	}
+
+	other := filepath.Join(dir, "pre-commit")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\nmake lint\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Install(dir, "pre-commit", false); !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+	if _, err := Install(dir, "pre-commit", true); err != nil {
+		t.Fatalf("expected --force to replace the hook, got %v", err)
+	}
+	info, err = os.Stat(other)
+	if err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("expected the replaced hook to be executable, got %v, %v", info, err)
+	}
+}
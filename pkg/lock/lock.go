@@ -0,0 +1,77 @@
+// Package lock reads and writes aver.lock, which records the commit every
+// tag and branch used in a project's workflows pointed at when it was locked.
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// File is the name of the lockfile in the project root
+const File = "aver.lock"
+
+// version is the lockfile format written by this version of aver
+const version = 1
+
+// Lockfile maps "owner/repo@ref" to the commit SHA it resolved to
+type Lockfile struct {
+	Version int               `json:"version"`
+	Refs    map[string]string `json:"refs"`
+}
+
+// New returns a lockfile recording refs
+func New(refs map[string]string) *Lockfile {
+	return &Lockfile{Version: version, Refs: refs}
+}
+
+// Load reads the lockfile at path
+func Load(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Lockfile
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("invalid lockfile %s: %w", path, err)
+	}
+	if l.Version > version {
+		return nil, fmt.Errorf("lockfile %s has version %d; upgrade aver to read it", path, l.Version)
+	}
+	if l.Refs == nil {
+		l.Refs = make(map[string]string)
+	}
+	return &l, nil
+}
+
+// Save writes the lockfile to path. Keys are sorted, so the file diffs well.
+func (l *Lockfile) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Mismatch is a ref that resolves differently than when it was locked. An
+// empty Locked means the ref isn't in the lockfile at all.
+type Mismatch struct {
+	Ref     string `json:"ref"`
+	Locked  string `json:"locked,omitempty"`
+	Current string `json:"current"`
+}
+
+// Verify compares the commits refs resolve to now against the lockfile,
+// returning the mismatches sorted by ref
+func (l *Lockfile) Verify(current map[string]string) []Mismatch {
+	var mismatches []Mismatch
+	for ref, sha := range current {
+		if locked := l.Refs[ref]; locked != sha {
+			mismatches = append(mismatches, Mismatch{Ref: ref, Locked: locked, Current: sha})
+		}
+	}
+	slices.SortFunc(mismatches, func(a, b Mismatch) int { return strings.Compare(a.Ref, b.Ref) })
+	return mismatches
+}
@@ -0,0 +1,65 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+	if err := New(map[string]string{"actions/checkout@v4": "abc123"}).Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Version != version || l.Refs["actions/checkout@v4"] != "abc123" {
+		t.Errorf("unexpected lockfile: %+v", l)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, File)); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"invalid": "{",
+		"newer":   `{"version": 99, "refs": {}}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	l := New(map[string]string{
+		"actions/checkout@v4": "abc123",
+		"actions/cache@v4":    "def456",
+		"owner/unused@v1":     "999999",
+	})
+	mismatches := l.Verify(map[string]string{
+		"actions/checkout@v4": "abc123",
+		"actions/cache@v4":    "fff000",
+		"owner/new@main":      "123456",
+	})
+
+	if len(mismatches) != 2 {
+		t.Fatalf("expected 2 mismatches, got %+v", mismatches)
+	}
+	if m := mismatches[0]; m.Ref != "actions/cache@v4" || m.Locked != "def456" || m.Current != "fff000" {
+		t.Errorf("unexpected mismatch: %+v", m)
+	}
+	if m := mismatches[1]; m.Ref != "owner/new@main" || m.Locked != "" {
+		t.Errorf("expected owner/new@main to be unlocked, got %+v", m)
+	}
+}
@@ -0,0 +1,103 @@
+// Package notify sends summaries of check results to chat services and
+// webhooks, for scheduled scans that nobody is watching.
+package notify
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"aver/pkg/actions"
+)
+
+// Report is what a notifier is told about a run
+type Report struct {
+	Project string // A name for the checked project, e.g. its directory
+	Result  actions.CheckResult
+}
+
+// Findings counts outdated tag pins and SHA pins behind their default branch
+func (r Report) Findings() int {
+	return len(r.Result.Outdated) + len(r.Result.SHAPinned)
+}
+
+// Notifier delivers a report somewhere
+type Notifier interface {
+	Notify(ctx context.Context, report Report) error
+}
+
+// Changed reports whether after has different findings than before: an
+// action became outdated, was updated, or a newer version came out. SHA pins
+// falling further behind their default branch are not a change.
+func Changed(before, after actions.CheckResult) bool {
+	return !slices.Equal(findings(before), findings(after))
+}
+
+// findings lists a result's findings in a stable order
+func findings(result actions.CheckResult) []string {
+	var keys []string
+	for _, o := range result.Outdated {
+		keys = append(keys, fmt.Sprintf("%s %s %s@%s -> %s", o.Repository, o.File, o.Name, o.CurrentVersion, o.LatestVersion))
+	}
+	for _, p := range result.SHAPinned {
+		keys = append(keys, fmt.Sprintf("%s %s %s@%s", p.Repository, p.File, p.Name, p.CurrentSHA))
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Offender is one finding, for listing the worst of them
+type Offender struct {
+	Name   string // The action, e.g. actions/checkout
+	File   string
+	From   string // The pinned version or short SHA
+	To     string // The latest version, or the default branch of a SHA pin not compared by tag
+	Behind string // e.g. "400 days, 6 releases" or "12 commits"
+}
+
+// Worst returns up to n findings, the outdated tag pins furthest behind
+// first and then the SHA pins with the most commits to catch up on
+func (r Report) Worst(n int) []Offender {
+	outdated := slices.SortedStableFunc(slices.Values(r.Result.Outdated), func(a, b actions.OutdatedAction) int {
+		return cmp.Or(cmp.Compare(b.DaysBehind, a.DaysBehind), cmp.Compare(b.ReleasesBehind, a.ReleasesBehind))
+	})
+	pinned := slices.SortedStableFunc(slices.Values(r.Result.SHAPinned), func(a, b actions.SHAPinnedAction) int {
+		return cmp.Compare(b.CommitsBehind, a.CommitsBehind)
+	})
+
+	var worst []Offender
+	for _, o := range outdated {
+		var behind string
+		switch {
+		case o.DaysBehind > 0 && o.ReleasesBehind > 0:
+			behind = fmt.Sprintf("%s, %s", plural(o.DaysBehind, "day"), plural(o.ReleasesBehind, "release"))
+		case o.DaysBehind > 0:
+			behind = plural(o.DaysBehind, "day")
+		case o.ReleasesBehind > 0:
+			behind = plural(o.ReleasesBehind, "release")
+		}
+		worst = append(worst, Offender{Name: o.Name, File: o.File, From: o.CurrentVersion, To: o.LatestVersion, Behind: behind})
+	}
+	for _, p := range pinned {
+		worst = append(worst, Offender{
+			Name: p.Name, File: p.File, From: shortSHA(p.CurrentSHA), To: cmp.Or(p.LatestTag, p.DefaultBranch),
+			Behind: plural(p.CommitsBehind, "commit"),
+		})
+	}
+	return worst[:min(n, len(worst))]
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
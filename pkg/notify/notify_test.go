@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func TestChanged(t *testing.T) {
+	base := testReport().Result
+
+	// Order and how far SHA pins are behind don't matter
+	same := testReport().Result
+	same.Outdated[0], same.Outdated[1] = same.Outdated[1], same.Outdated[0]
+	same.SHAPinned[0].CommitsBehind = 20
+	same.SHAPinned[0].LatestSHA = "fedcba9876543210"
+	if Changed(base, same) {
+		t.Error("expected reordered findings to be unchanged")
+	}
+
+	newer := testReport().Result
+	newer.Outdated[0].LatestVersion = "v7"
+	fixed := testReport().Result
+	fixed.SHAPinned = nil
+	for name, after := range map[string]actions.CheckResult{"newer version": newer, "fixed": fixed, "clean": {}} {
+		if !Changed(base, after) {
+			t.Errorf("%s: expected a change", name)
+		}
+	}
+	if Changed(actions.CheckResult{}, actions.CheckResult{}) {
+		t.Error("expected two clean results to be unchanged")
+	}
+}
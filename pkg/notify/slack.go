@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxOffenders is how many findings a Slack message lists
+const maxOffenders = 5
+
+// Slack posts a summary to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	// Threshold is the number of findings needed to post; below it Notify
+	// does nothing. Zero means 1, so clean runs stay quiet.
+	Threshold int
+	// RepoURL links an action to its repository. Nil links to github.com.
+	RepoURL func(action string) string
+	Client  *http.Client // Nil uses a client with a 10 second timeout
+}
+
+// Notify posts the report if it has at least Threshold findings
+func (s *Slack) Notify(ctx context.Context, report Report) error {
+	if report.Findings() < max(s.Threshold, 1) {
+		return nil
+	}
+	body, err := json.Marshal(s.message(report))
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.WebhookURL, nil, body)
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"` // Shown in notifications and old clients
+	Blocks []slackBlock `json:"blocks"`
+}
+
+func (s *Slack) message(report Report) slackMessage {
+	result := report.Result
+	summary := fmt.Sprintf("aver found %s in %s", plural(report.Findings(), "outdated action"), report.Project)
+
+	var counts []string
+	if n := len(result.Outdated); n > 0 {
+		counts = append(counts, plural(n, "outdated version pin"))
+	}
+	if n := len(result.SHAPinned); n > 0 {
+		counts = append(counts, plural(n, "SHA pin")+" behind the default branch")
+	}
+
+	var lines []string
+	for _, o := range report.Worst(maxOffenders) {
+		line := fmt.Sprintf("• <%s|%s> %s → %s", s.repoURL(o.Name), escape(o.Name), escape(o.From), escape(o.To))
+		if o.Behind != "" {
+			line += fmt.Sprintf(" (%s behind)", o.Behind)
+		}
+		lines = append(lines, line+fmt.Sprintf(" in `%s`", escape(o.File)))
+	}
+
+	msg := slackMessage{
+		Text: summary,
+		Blocks: []slackBlock{
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", escape(summary), strings.Join(counts, ", "))}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*Furthest behind*\n" + strings.Join(lines, "\n")}},
+		},
+	}
+	if more := report.Findings() - len(lines); more > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("and %d more; run `aver` for the full list", more)}},
+		})
+	}
+	return msg
+}
+
+func (s *Slack) repoURL(action string) string {
+	if s.RepoURL != nil {
+		return s.RepoURL(action)
+	}
+	parts := strings.SplitN(action, "/", 3)
+	return "https://github.com/" + strings.Join(parts[:min(2, len(parts))], "/")
+}
+
+// escape makes text safe for Slack's mrkdwn, where &, < and > are control
+// characters
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// post sends a body to url, as JSON unless header says otherwise, failing
+// on any non-2xx response
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func testReport() Report {
+	return Report{
+		Project: "app",
+		Result: actions.CheckResult{
+			Outdated: []actions.OutdatedAction{
+				{File: "ci.yml", Name: "actions/setup-go", CurrentVersion: "v5", LatestVersion: "v6", DaysBehind: 30, ReleasesBehind: 1},
+				{File: "ci.yml", Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v5", DaysBehind: 400, ReleasesBehind: 6},
+			},
+			SHAPinned: []actions.SHAPinnedAction{
+				{File: "release.yml", Name: "owner/tool", CurrentSHA: "0123456789abcdef", DefaultBranch: "main", CommitsBehind: 12},
+			},
+		},
+	}
+}
+
+func TestWorst(t *testing.T) {
+	worst := testReport().Worst(2)
+	if len(worst) != 2 {
+		t.Fatalf("expected 2 offenders, got %d", len(worst))
+	}
+	want := Offender{Name: "actions/checkout", File: "ci.yml", From: "v3", To: "v5", Behind: "400 days, 6 releases"}
+	if worst[0] != want {
+		t.Errorf("expected %+v first, got %+v", want, worst[0])
+	}
+
+	worst = testReport().Worst(10)
+	if len(worst) != 3 || worst[2].From != "0123456" || worst[2].Behind != "12 commits" {
+		t.Errorf("unexpected offenders: %+v", worst)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var posts []slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		var msg slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Error(err)
+		}
+		posts = append(posts, msg)
+	}))
+	defer server.Close()
+
+	slack := &Slack{WebhookURL: server.URL, Threshold: 3}
+	if err := slack.Notify(context.Background(), testReport()); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posts))
+	}
+	msg := posts[0]
+	if msg.Text != "aver found 3 outdated actions in app" {
+		t.Errorf("unexpected text %q", msg.Text)
+	}
+	offenders := msg.Blocks[1].Text.Text
+	if !strings.Contains(offenders, "• <https://github.com/actions/checkout|actions/checkout> v3 → v5 (400 days, 6 releases behind) in `ci.yml`") {
+		t.Errorf("unexpected offenders:\n%s", offenders)
+	}
+
+	// Below the threshold nothing is posted
+	slack.Threshold = 4
+	if err := slack.Notify(context.Background(), testReport()); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Slack{WebhookURL: server.URL}).Notify(context.Background(), Report{Project: "clean"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Errorf("expected no more posts, got %d", len(posts))
+	}
+}
+
+func TestSlackError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := (&Slack{WebhookURL: server.URL}).Notify(context.Background(), testReport())
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a 403 error, got %v", err)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("<a & b>"); got != "&lt;a &amp; b&gt;" {
+		t.Errorf("unexpected escape %q", got)
+	}
+}
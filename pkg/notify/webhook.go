@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"text/template"
+
+	"aver/pkg/actions"
+)
+
+// Webhook posts a report to an arbitrary URL, either as JSON or rendered
+// through a template, e.g. for Microsoft Teams, Discord or internal systems
+type Webhook struct {
+	URL    string
+	Header http.Header // Sent with every request, e.g. Authorization
+	// Template renders the request body from the Report. Nil posts the
+	// same JSON as aver --json, plus the project name.
+	Template *template.Template
+	// Threshold is the number of findings needed to post; zero posts every
+	// report, including clean ones
+	Threshold int
+	Client    *http.Client // Nil uses a client with a 10 second timeout
+}
+
+// payload is the default body of a webhook
+type payload struct {
+	Project   string                    `json:"project"`
+	Outdated  []actions.OutdatedAction  `json:"outdated"`
+	SHAPinned []actions.SHAPinnedAction `json:"sha_pinned"`
+	Unchecked []actions.UncheckedAction `json:"unchecked,omitempty"`
+}
+
+// ParseTemplate parses a webhook body template. Templates see a Report, so
+// {{.Project}}, {{.Findings}}, {{.Result.Outdated}} and {{range .Worst 5}}
+// all work, and {{json .Project}} quotes a value for use inside JSON.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+}
+
+// Notify posts the report if it has at least Threshold findings
+func (w *Webhook) Notify(ctx context.Context, report Report) error {
+	if report.Findings() < w.Threshold {
+		return nil
+	}
+
+	var body []byte
+	if w.Template != nil {
+		var buf bytes.Buffer
+		if err := w.Template.Execute(&buf, report); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	} else {
+		p := payload{
+			Project:   report.Project,
+			Outdated:  report.Result.Outdated,
+			SHAPinned: report.Result.SHAPinned,
+			Unchecked: report.Result.Unchecked,
+		}
+		if p.Outdated == nil {
+			p.Outdated = []actions.OutdatedAction{}
+		}
+		if p.SHAPinned == nil {
+			p.SHAPinned = []actions.SHAPinnedAction{}
+		}
+		var err error
+		if body, err = json.Marshal(p); err != nil {
+			return err
+		}
+	}
+	return post(ctx, w.Client, w.URL, w.Header, body)
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// recorder is a webhook endpoint that keeps what it's sent
+type recorder struct {
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.requests = append(rec.requests, r)
+	rec.bodies = append(rec.bodies, string(body))
+}
+
+func TestWebhookJSON(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL, Header: http.Header{"Authorization": {"Bearer secret"}}}
+	if err := webhook.Notify(context.Background(), testReport()); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(rec.requests))
+	}
+	r := rec.requests[0]
+	if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers: %v", r.Header)
+	}
+
+	var p payload
+	if err := json.Unmarshal([]byte(rec.bodies[0]), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Project != "app" || len(p.Outdated) != 2 || len(p.SHAPinned) != 1 {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+
+	// Without a threshold clean runs are posted too
+	if err := webhook.Notify(context.Background(), Report{Project: "clean"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.bodies) != 2 || rec.bodies[1] != `{"project":"clean","outdated":[],"sha_pinned":[]}` {
+		t.Errorf("unexpected clean payload: %v", rec.bodies)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	tmpl, err := ParseTemplate(`{"text": {{json (printf "%d outdated in %s" .Findings .Project)}}, "worst": [{{range $i, $o := .Worst 1}}{{json $o.Name}}{{end}}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook := &Webhook{
+		URL:       server.URL,
+		Header:    http.Header{"Content-Type": {"application/vnd.custom+json"}},
+		Template:  tmpl,
+		Threshold: 3,
+	}
+	if err := webhook.Notify(context.Background(), testReport()); err != nil {
+		t.Fatal(err)
+	}
+	if err := webhook.Notify(context.Background(), Report{Project: "clean"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(rec.bodies))
+	}
+	if want := `{"text": "3 outdated in app", "worst": ["actions/checkout"]}`; rec.bodies[0] != want {
+		t.Errorf("expected %s, got %s", want, rec.bodies[0])
+	}
+	if ct := rec.requests[0].Header.Get("Content-Type"); ct != "application/vnd.custom+json" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+}
+
+func TestParseTemplateInvalid(t *testing.T) {
+	if _, err := ParseTemplate("{{.Project"); err == nil {
+		t.Error("expected an error for an unterminated action")
+	}
+}
@@ -0,0 +1,121 @@
+// Package rdjson reports findings in reviewdog's Diagnostic Format
+// (rdjson), so that reviewdog can post them as pull request review
+// comments next to those of linters like actionlint:
+//
+//	aver --format rdjson | reviewdog -f=rdjson -reporter=github-pr-review
+package rdjson
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"aver/pkg/actions"
+)
+
+// Severities of a Diagnostic
+const (
+	SeverityWarning = "WARNING"
+	SeverityInfo    = "INFO"
+)
+
+// Result is a set of diagnostics from one tool
+type Result struct {
+	Source      *Source      `json:"source,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Source names the tool that produced the diagnostics
+type Source struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Diagnostic is a finding at a location in a file
+type Diagnostic struct {
+	Message  string   `json:"message"`
+	Location Location `json:"location"`
+	Severity string   `json:"severity,omitempty"`
+	Code     *Code    `json:"code,omitempty"`
+}
+
+// Location is a file and, if known, the lines in it
+type Location struct {
+	Path  string `json:"path"`
+	Range *Range `json:"range,omitempty"`
+}
+
+// Range is where in a file a diagnostic is. Lines start at 1.
+type Range struct {
+	Start Position `json:"start"`
+}
+
+// Position is a line, and optionally a column, in a file
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+// Code identifies the kind of finding, e.g. outdated-action
+type Code struct {
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+// New turns the findings of a check into diagnostics: outdated actions, SHA
+// pins behind their default branch, problems with step inputs, deprecated
+// workflow commands, typosquats and suspicious forks as warnings, and
+// dynamic refs as information, since they don't fail a run. Unchecked
+// actions aren't findings, so they're left out. source names the tool.
+func New(result actions.CheckResult, source Source) Result {
+	r := Result{Source: &source, Diagnostics: []Diagnostic{}}
+	add := func(file string, line int, severity, code, message string) {
+		r.Diagnostics = append(r.Diagnostics, Diagnostic{
+			Message:  message,
+			Location: location(file, line),
+			Severity: severity,
+			Code:     &Code{Value: code},
+		})
+	}
+	for _, o := range result.Outdated {
+		add(o.File, o.Line, SeverityWarning, "outdated-action",
+			fmt.Sprintf("%s@%s can be updated to %s", o.Name, o.CurrentVersion, o.LatestVersion))
+	}
+	for _, s := range result.SHAPinned {
+		add(s.File, s.Line, SeverityWarning, "sha-behind", s.Describe())
+	}
+	for _, f := range result.Inputs {
+		add(f.File, f.Line, SeverityWarning, "input-"+f.Problem, f.Message)
+	}
+	for _, d := range result.Commands {
+		add(d.File, d.Line, SeverityWarning, "deprecated-command", d.Message())
+	}
+	for _, t := range result.Typosquats {
+		add(t.File, t.Line, SeverityWarning, "typosquat", t.Message())
+	}
+	for _, f := range result.Forks {
+		add(f.File, f.Line, SeverityWarning, "suspicious-fork", f.Message())
+	}
+	for _, d := range result.Dynamic {
+		add(d.File, d.Line, SeverityInfo, "dynamic-ref",
+			fmt.Sprintf("%s@%s is an expression, so any version can run without the workflow changing", d.Name, d.Ref))
+	}
+	return r
+}
+
+// JSON encodes r as indented JSON
+func (r Result) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// location is a file with its line, if known
+func location(file string, line int) Location {
+	loc := Location{Path: file}
+	if line > 0 {
+		loc.Range = &Range{Start: Position{Line: line}}
+	}
+	return loc
+}
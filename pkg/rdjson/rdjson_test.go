@@ -0,0 +1,70 @@
+package rdjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+func TestNew(t *testing.T) {
+	result := actions.CheckResult{
+		Outdated: []actions.OutdatedAction{{File: ".github/workflows/ci.yml", Line: 12, Name: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4"}},
+		SHAPinned: []actions.SHAPinnedAction{{File: ".github/workflows/ci.yml", Line: 14, Name: "actions/cache",
+			CurrentSHA: "8e5e7e5ab8b370d6c329ec480221332ada57f0ab", CommitsBehind: 3, DefaultBranch: "main"}},
+		Inputs: []actions.InputFinding{{File: ".github/workflows/ci.yml", Line: 16, Problem: actions.InputUnknown,
+			Message: `actions/checkout@v4 has no input "fetch_depth"; did you mean "fetch-depth"?`}},
+		Commands:   []actions.DeprecatedCommand{{File: ".github/workflows/ci.yml", Line: 20, Command: "set-output", UseInstead: "$GITHUB_OUTPUT"}},
+		Typosquats: []actions.Typosquat{{File: ".github/workflows/ci.yml", Line: 21, Name: "actions/chekout", Resembles: "actions/checkout"}},
+		Forks:      []actions.SuspiciousFork{{File: ".github/workflows/ci.yml", Line: 22, Name: "evil/checkout", Resembles: "actions/checkout", Parent: "actions/checkout"}},
+		Dynamic:    []actions.DynamicRef{{File: "action.yml", Name: "owner/tool", Ref: "${{ inputs.version }}"}},
+		Unchecked:  []actions.UncheckedAction{{File: ".github/workflows/ci.yml", Name: "actions/setup-go", Version: "v5"}},
+	}
+	got := New(result, Source{Name: "aver"})
+	want := []struct {
+		line           int
+		severity, code string
+		message        string
+	}{
+		{12, SeverityWarning, "outdated-action", "actions/checkout@v3 can be updated to v4"},
+		{14, SeverityWarning, "sha-behind", "actions/cache@8e5e7e5 is 3 commits behind main"},
+		{16, SeverityWarning, "input-unknown", `actions/checkout@v4 has no input "fetch_depth"; did you mean "fetch-depth"?`},
+		{20, SeverityWarning, "deprecated-command", "the set-output command is deprecated; write to $GITHUB_OUTPUT instead"},
+		{21, SeverityWarning, "typosquat", "actions/chekout is named like the popular action actions/checkout; make sure it's the action you meant"},
+		{22, SeverityWarning, "suspicious-fork", "evil/checkout is a fork of actions/checkout; review what it changes, or use actions/checkout"},
+		{0, SeverityInfo, "dynamic-ref", "owner/tool@${{ inputs.version }} is an expression, so any version can run without the workflow changing"},
+	}
+	if len(got.Diagnostics) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %+v", len(want), got.Diagnostics)
+	}
+	for i, w := range want {
+		d := got.Diagnostics[i]
+		if d.Severity != w.severity || d.Code.Value != w.code || d.Message != w.message {
+			t.Errorf("diagnostic %d: expected %s %s %q, got %+v", i, w.severity, w.code, w.message, d)
+		}
+		switch {
+		case w.line == 0 && d.Location.Range != nil:
+			t.Errorf("diagnostic %d: expected no range without a line, got %+v", i, d.Location.Range)
+		case w.line > 0 && (d.Location.Range == nil || d.Location.Range.Start.Line != w.line):
+			t.Errorf("diagnostic %d: expected line %d, got %+v", i, w.line, d.Location)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := New(actions.CheckResult{}, Source{Name: "aver", URL: "https://github.com/llimllib/aver"}).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	// reviewdog expects a list, even an empty one
+	if diagnostics, ok := decoded["diagnostics"].([]any); !ok || len(diagnostics) != 0 {
+		t.Errorf("expected an empty diagnostics list, got %s", data)
+	}
+	if source := decoded["source"].(map[string]any); source["name"] != "aver" {
+		t.Errorf("expected aver as the source, got %s", data)
+	}
+}
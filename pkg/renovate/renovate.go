@@ -0,0 +1,123 @@
+// Package renovate builds a Renovate configuration for workflow actions
+// from aver's settings.
+package renovate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// File is the Renovate configuration file at a repository's root
+const File = "renovate.json"
+
+// Manager is Renovate's name for its workflow actions manager
+const Manager = "github-actions"
+
+// schemaURL lets editors validate the configuration
+const schemaURL = "https://docs.renovatebot.com/renovate-schema.json"
+
+// pinPreset pins actions to commit digests, keeping the version in a comment
+const pinPreset = "helpers:pinGitHubActionDigests"
+
+// Settings are the parts of aver's configuration and findings that
+// Renovate can express
+type Settings struct {
+	// Pin pins actions to commit SHAs, as most of the project's already are
+	Pin bool
+	// MinReleaseAge holds back versions published more recently, like
+	// aver's min_release_age
+	MinReleaseAge time.Duration
+	// IgnoreMinor only updates to new major versions, like --ignore-minor
+	IgnoreMinor bool
+	// Prereleases lists owners or repositories whose prereleases may be
+	// proposed, like include_prereleases
+	Prereleases []string
+}
+
+// Config is a Renovate configuration fragment
+type Config struct {
+	Schema       string        `json:"$schema"`
+	Extends      []string      `json:"extends,omitempty"`
+	PackageRules []PackageRule `json:"packageRules,omitempty"`
+}
+
+// PackageRule applies settings to the dependencies it matches
+type PackageRule struct {
+	Description       string   `json:"description,omitempty"`
+	MatchManagers     []string `json:"matchManagers"`
+	MatchPackageNames []string `json:"matchPackageNames,omitempty"`
+	MatchUpdateTypes  []string `json:"matchUpdateTypes,omitempty"`
+	MinimumReleaseAge string   `json:"minimumReleaseAge,omitempty"`
+	IgnoreUnstable    *bool    `json:"ignoreUnstable,omitempty"`
+	Enabled           *bool    `json:"enabled,omitempty"`
+}
+
+// New returns the configuration for s. Every rule only matches the
+// github-actions manager, so the fragment can be merged into a
+// configuration that updates other dependencies too.
+func New(s Settings) Config {
+	cfg := Config{Schema: schemaURL}
+	if s.Pin {
+		cfg.Extends = append(cfg.Extends, pinPreset)
+	}
+	if s.MinReleaseAge > 0 {
+		cfg.PackageRules = append(cfg.PackageRules, PackageRule{
+			Description:       "Wait for new actions to settle, like aver's min_release_age",
+			MatchManagers:     []string{Manager},
+			MinimumReleaseAge: releaseAge(s.MinReleaseAge),
+		})
+	}
+	if s.IgnoreMinor {
+		disabled := false
+		cfg.PackageRules = append(cfg.PackageRules, PackageRule{
+			Description:      "Only update actions to new major versions, like aver --ignore-minor",
+			MatchManagers:    []string{Manager},
+			MatchUpdateTypes: []string{"minor", "patch"},
+			Enabled:          &disabled,
+		})
+	}
+	if len(s.Prereleases) > 0 {
+		unstable := false
+		names := make([]string, len(s.Prereleases))
+		for i, name := range s.Prereleases {
+			if !strings.Contains(name, "/") {
+				name += "/*" // Every repository of an owner
+			}
+			names[i] = name
+		}
+		cfg.PackageRules = append(cfg.PackageRules, PackageRule{
+			Description:       "Propose prereleases, like aver's include_prereleases",
+			MatchManagers:     []string{Manager},
+			MatchPackageNames: names,
+			IgnoreUnstable:    &unstable,
+		})
+	}
+	return cfg
+}
+
+// Marshal returns the configuration for s as indented JSON
+func Marshal(s Settings) ([]byte, error) {
+	data, err := json.MarshalIndent(New(s), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// releaseAge formats d the way Renovate's minimumReleaseAge reads it, in
+// whole days where possible and otherwise rounded up to hours
+func releaseAge(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+	return plural(int((d+time.Hour-1)/time.Hour), "hour")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,80 @@
+package renovate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarshal(t *testing.T) {
+	got, err := Marshal(Settings{
+		Pin:           true,
+		MinReleaseAge: 7 * 24 * time.Hour,
+		IgnoreMinor:   true,
+		Prereleases:   []string{"acme", "actions/checkout"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "https://docs.renovatebot.com/renovate-schema.json",
+  "extends": [
+    "helpers:pinGitHubActionDigests"
+  ],
+  "packageRules": [
+    {
+      "description": "Wait for new actions to settle, like aver's min_release_age",
+      "matchManagers": [
+        "github-actions"
+      ],
+      "minimumReleaseAge": "7 days"
+    },
+    {
+      "description": "Only update actions to new major versions, like aver --ignore-minor",
+      "matchManagers": [
+        "github-actions"
+      ],
+      "matchUpdateTypes": [
+        "minor",
+        "patch"
+      ],
+      "enabled": false
+    },
+    {
+      "description": "Propose prereleases, like aver's include_prereleases",
+      "matchManagers": [
+        "github-actions"
+      ],
+      "matchPackageNames": [
+        "acme/*",
+        "actions/checkout"
+      ],
+      "ignoreUnstable": false
+    }
+  ]
+}
+`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+
+	empty, err := Marshal(Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(empty) != "{\n  \"$schema\": \"https://docs.renovatebot.com/renovate-schema.json\"\n}\n" {
+		t.Errorf("Marshal(Settings{}) = %s", empty)
+	}
+}
+
+func TestReleaseAge(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		24 * time.Hour:      "1 day",
+		14 * 24 * time.Hour: "14 days",
+		90 * time.Minute:    "2 hours",
+		time.Hour:           "1 hour",
+	} {
+		if got := releaseAge(d); got != want {
+			t.Errorf("releaseAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
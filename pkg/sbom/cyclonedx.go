@@ -0,0 +1,124 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// cycloneDXVersion is the version of the CycloneDX specification written
+const cycloneDXVersion = "1.5"
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	PURL               string           `json:"purl,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX encodes the BOM as CycloneDX JSON. The project is the
+// metadata component and depends on every dependency; resolved commits and
+// the files using each dependency are "aver:commit" and "aver:file"
+// properties.
+func (b BOM) CycloneDX() ([]byte, error) {
+	serial, err := uuid()
+	if err != nil {
+		return nil, err
+	}
+	project := cdxComponent{Type: "application", BOMRef: "project", Name: b.Project}
+	doc := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXVersion,
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: b.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: b.Tool, Version: b.ToolVersion}}},
+			Component: project,
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: project.BOMRef, DependsOn: []string{}}},
+	}
+	for _, c := range b.Components {
+		component := cdxComponent{
+			Type:    "library",
+			BOMRef:  c.PURL,
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+		if c.Container {
+			component.Type = "container"
+		}
+		if c.Source != "" {
+			kind := "website"
+			if c.Repository {
+				kind = "vcs"
+			}
+			component.ExternalReferences = []cdxExternalRef{{Type: kind, URL: c.Source}}
+		}
+		if c.Commit != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: "aver:commit", Value: c.Commit})
+		}
+		for _, file := range c.Files {
+			component.Properties = append(component.Properties, cdxProperty{Name: "aver:file", Value: file})
+		}
+		doc.Components = append(doc.Components, component)
+		doc.Dependencies[0].DependsOn = append(doc.Dependencies[0].DependsOn, component.BOMRef)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// uuid returns a random (version 4) UUID
+func uuid() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
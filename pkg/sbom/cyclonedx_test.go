@@ -0,0 +1,38 @@
+package sbom
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestCycloneDX(t *testing.T) {
+	data, err := testBOM().CycloneDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc cdxBOM
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" {
+		t.Errorf("bomFormat, specVersion = %q, %q", doc.BOMFormat, doc.SpecVersion)
+	}
+	if !regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(doc.SerialNumber) {
+		t.Errorf("serialNumber = %q, want a random UUID", doc.SerialNumber)
+	}
+	if doc.Metadata.Component.Name != "acme/widget" || doc.Metadata.Tools.Components[0].Version != "1.0.0" {
+		t.Errorf("metadata = %+v", doc.Metadata)
+	}
+	if len(doc.Components) != 3 || len(doc.Dependencies) != 1 || len(doc.Dependencies[0].DependsOn) != 3 {
+		t.Fatalf("components = %+v, dependencies = %+v", doc.Components, doc.Dependencies)
+	}
+	setupGo := doc.Components[1]
+	if setupGo.PURL != "pkg:githubactions/actions/setup-go@v5" || setupGo.Type != "library" ||
+		len(setupGo.Properties) != 3 || setupGo.Properties[0].Name != "aver:commit" {
+		t.Errorf("components[1] = %+v", setupGo)
+	}
+	if doc.Components[2].Type != "container" {
+		t.Errorf("components[2].type = %q, want container", doc.Components[2].Type)
+	}
+}
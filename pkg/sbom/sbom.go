@@ -0,0 +1,133 @@
+// Package sbom describes the actions, components, orbs, pipes and tasks a
+// project's CI depends on as a software bill of materials, in the formats
+// SBOM tooling ingests.
+package sbom
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"aver/pkg/actions"
+)
+
+// BOM is the format-independent bill of materials the encoders share
+type BOM struct {
+	// Project names what the BOM describes, e.g. the project directory or
+	// owner/repo
+	Project string
+	// Tool and ToolVersion name what produced it
+	Tool        string
+	ToolVersion string
+	Created     time.Time
+	Components  []Component
+}
+
+// Component is one dependency at one version
+type Component struct {
+	// Name is the name aver reports, e.g. actions/checkout or
+	// orb:circleci/node
+	Name    string
+	Version string
+	// Commit is the commit Version resolved to during the check, if known
+	Commit string
+	// PURL is the package URL identifying the dependency
+	PURL string
+	// Source is the web page of its repository or registry entry
+	Source string
+	// Repository is set if Source is a git repository, rather than a
+	// registry page like those of orbs, images and tasks
+	Repository bool
+	// Container is set for Docker images
+	Container bool
+	// Files are the workflow and pipeline files that use it
+	Files []string
+}
+
+// New builds the BOM of refs, which were checked with result. source
+// returns the web page of a dependency. Each name and version appears once,
+// sorted by name.
+func New(project string, refs []actions.ActionReference, result actions.CheckResult, source func(name string) string) BOM {
+	bom := BOM{Project: project}
+	index := make(map[string]int)
+	for _, ref := range refs {
+		key := ref.Name + "@" + ref.Version
+		file := ref.File
+		if ref.Repository != "" {
+			file = ref.Repository + ":" + file
+		}
+		if i, ok := index[key]; ok {
+			if !slices.Contains(bom.Components[i].Files, file) {
+				bom.Components[i].Files = append(bom.Components[i].Files, file)
+			}
+			continue
+		}
+		index[key] = len(bom.Components)
+		bom.Components = append(bom.Components, Component{
+			Name:       ref.Name,
+			Version:    ref.Version,
+			Commit:     result.Commit(ref),
+			PURL:       PURL(ref.Name, ref.Version),
+			Source:     source(ref.Name),
+			Repository: !actions.IsOrb(ref.Name) && !actions.IsDockerImage(ref.Name) && !actions.IsAzureTask(ref.Name),
+			Container:  actions.IsDockerImage(ref.Name),
+			Files:      []string{file},
+		})
+	}
+	slices.SortStableFunc(bom.Components, func(a, b Component) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Version, b.Version))
+	})
+	return bom
+}
+
+// PURL returns the package URL of a dependency at version. Actions on
+// GitHub are pkg:githubactions (with the action's subdirectory as the
+// subpath), pipes pkg:bitbucket and images pkg:docker; everything else is
+// pkg:generic, with the repository as its vcs_url where there is one.
+func PURL(name, version string) string {
+	v := "@" + escape(version)
+	switch {
+	case actions.IsDockerImage(name):
+		image := strings.TrimPrefix(name, actions.DockerPrefix)
+		if !strings.Contains(image, "/") {
+			image = "library/" + image
+		}
+		return "pkg:docker/" + escapePath(image) + v
+	case actions.IsPipe(name):
+		return "pkg:bitbucket/" + escapePath(strings.TrimPrefix(name, actions.PipePrefix)) + v
+	case actions.IsOrb(name):
+		return "pkg:generic/" + escapePath(strings.TrimPrefix(name, actions.OrbPrefix)) + v
+	case actions.IsAzureTask(name):
+		return "pkg:generic/azure-pipelines-tasks/" + escape(strings.TrimPrefix(name, actions.TaskPrefix)) + v
+	}
+	if repoURL, ok := actions.ForgeRepoURL(name); ok {
+		base := name[strings.LastIndex(name, "/")+1:]
+		return "pkg:generic/" + escape(base) + v + "?vcs_url=" + escape("git+"+repoURL+"@"+version)
+	}
+
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return "pkg:generic/" + escape(name) + v
+	}
+	purl := "pkg:githubactions/" + escape(parts[0]) + "/" + escape(parts[1]) + v
+	if len(parts) == 3 {
+		purl += "#" + escapePath(parts[2])
+	}
+	return purl
+}
+
+// escape percent-encodes a purl segment, version or qualifier value
+func escape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// escapePath escapes each segment of a slash-separated path
+func escapePath(s string) string {
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
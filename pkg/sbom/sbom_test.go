@@ -0,0 +1,58 @@
+package sbom
+
+import (
+	"testing"
+
+	"aver/pkg/actions"
+)
+
+const checkoutSHA = "8e5e7e5ab8b370d6c329ec480221332ada57f0ab"
+
+// testBOM is a BOM of a few kinds of dependency
+func testBOM() BOM {
+	refs := []actions.ActionReference{
+		{Name: "actions/setup-go", Version: "v5", File: ".github/workflows/ci.yml"},
+		{Name: "actions/checkout", Version: checkoutSHA, File: ".github/workflows/ci.yml"},
+		{Name: "actions/setup-go", Version: "v5", File: ".github/workflows/release.yml"},
+		{Name: "docker://alpine", Version: "3", File: "bitbucket-pipelines.yml"},
+	}
+	result := actions.CheckResult{Resolved: map[string]string{"actions/setup-go@v5": "0aaccfd150d50ccaeb58ebd88d36e91967a5f35b"}}
+	bom := New("acme/widget", refs, result, func(name string) string { return "https://example.com/" + name })
+	bom.Tool, bom.ToolVersion = "aver", "1.0.0"
+	return bom
+}
+
+func TestNew(t *testing.T) {
+	bom := testBOM()
+	if len(bom.Components) != 3 {
+		t.Fatalf("Components = %+v, want one per name and version", bom.Components)
+	}
+	checkout, setupGo, alpine := bom.Components[0], bom.Components[1], bom.Components[2]
+	if checkout.Name != "actions/checkout" || checkout.Commit != checkoutSHA {
+		t.Errorf("Components[0] = %+v, want checkout at its own SHA", checkout)
+	}
+	if setupGo.Commit != "0aaccfd150d50ccaeb58ebd88d36e91967a5f35b" || len(setupGo.Files) != 2 {
+		t.Errorf("Components[1] = %+v, want setup-go's tag commit and both files", setupGo)
+	}
+	if !alpine.Container || alpine.Commit != "" {
+		t.Errorf("Components[2] = %+v, want a container without a commit", alpine)
+	}
+}
+
+func TestPURL(t *testing.T) {
+	for _, tt := range []struct{ name, version, want string }{
+		{"actions/checkout", "v4", "pkg:githubactions/actions/checkout@v4"},
+		{"github/codeql-action/init", "v3", "pkg:githubactions/github/codeql-action@v3#init"},
+		{"owner/mono/component-a", "component-a/v1.0.0", "pkg:githubactions/owner/mono@component-a%2Fv1.0.0#component-a"},
+		{"docker://alpine", "3", "pkg:docker/library/alpine@3"},
+		{"docker://acme/deploy-pipe", "2.0.0", "pkg:docker/acme/deploy-pipe@2.0.0"},
+		{"pipe:atlassian/aws-s3-deploy", "1.1.0", "pkg:bitbucket/atlassian/aws-s3-deploy@1.1.0"},
+		{"orb:circleci/node", "5.2.0", "pkg:generic/circleci/node@5.2.0"},
+		{"task:AzureCLI", "2", "pkg:generic/azure-pipelines-tasks/AzureCLI@2"},
+		{"https://code.forgejo.org/actions/checkout", "v4", "pkg:generic/checkout@v4?vcs_url=git+https:%2F%2Fcode.forgejo.org%2Factions%2Fcheckout%40v4"},
+	} {
+		if got := PURL(tt.name, tt.version); got != tt.want {
+			t.Errorf("PURL(%q, %q) = %q, want %q", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
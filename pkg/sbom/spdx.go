@@ -0,0 +1,119 @@
+package sbom
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// spdxVersion is the version of the SPDX specification written
+const spdxVersion = "SPDX-2.3"
+
+// noAssertion is SPDX for "not known"
+const noAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Homepage              string            `json:"homepage,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX encodes the BOM as SPDX 2.3 JSON. The document describes a package
+// for the project, which depends on a package per dependency. Dependencies
+// with a repository are downloaded from it at their resolved commit (or
+// version), as git+https://host/owner/repo@commit; the files using each
+// are listed in its comment.
+func (b BOM) SPDX() ([]byte, error) {
+	id, err := uuid()
+	if err != nil {
+		return nil, err
+	}
+	project := spdxPackage{
+		Name:             b.Project,
+		SPDXID:           "SPDXRef-Project",
+		DownloadLocation: noAssertion,
+	}
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              b.Project,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", url.PathEscape(b.Tool), id),
+		CreationInfo: spdxCreationInfo{
+			Created:  b.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + b.Tool + "-" + b.ToolVersion},
+		},
+		Packages: []spdxPackage{project},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: project.SPDXID},
+		},
+	}
+	for i, c := range b.Components {
+		pkg := spdxPackage{
+			Name:                  c.Name,
+			SPDXID:                fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			VersionInfo:           c.Version,
+			DownloadLocation:      noAssertion,
+			Homepage:              c.Source,
+			PrimaryPackagePurpose: "LIBRARY",
+			ExternalRefs: []spdxExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PURL},
+			},
+			Comment: "Used by " + strings.Join(c.Files, ", "),
+		}
+		if c.Repository && c.Source != "" {
+			pkg.DownloadLocation = "git+" + c.Source + "@" + cmp.Or(c.Commit, c.Version)
+		}
+		if c.Container {
+			pkg.PrimaryPackagePurpose = "CONTAINER"
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      project.SPDXID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,47 @@
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSPDX(t *testing.T) {
+	data, err := testBOM().SPDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DataLicense != "CC0-1.0" || doc.Name != "acme/widget" {
+		t.Errorf("document = %+v", doc)
+	}
+	if !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/aver-") {
+		t.Errorf("documentNamespace = %q", doc.DocumentNamespace)
+	}
+	if len(doc.CreationInfo.Creators) != 1 || doc.CreationInfo.Creators[0] != "Tool: aver-1.0.0" {
+		t.Errorf("creators = %q", doc.CreationInfo.Creators)
+	}
+
+	// The project and one package per dependency, which it depends on
+	if len(doc.Packages) != 4 || len(doc.Relationships) != 4 {
+		t.Fatalf("packages = %+v, relationships = %+v", doc.Packages, doc.Relationships)
+	}
+	if r := doc.Relationships[0]; r.RelationshipType != "DESCRIBES" || r.RelatedSPDXElement != doc.Packages[0].SPDXID {
+		t.Errorf("relationships[0] = %+v, want the document to describe the project", r)
+	}
+	setupGo := doc.Packages[2]
+	if setupGo.DownloadLocation != "git+https://example.com/actions/setup-go@0aaccfd150d50ccaeb58ebd88d36e91967a5f35b" ||
+		setupGo.ExternalRefs[0].ReferenceLocator != "pkg:githubactions/actions/setup-go@v5" ||
+		setupGo.Comment != "Used by .github/workflows/ci.yml, .github/workflows/release.yml" {
+		t.Errorf("packages[2] = %+v", setupGo)
+	}
+	if alpine := doc.Packages[3]; alpine.DownloadLocation != "NOASSERTION" || alpine.PrimaryPackagePurpose != "CONTAINER" {
+		t.Errorf("packages[3] = %+v, want an image without a download location", alpine)
+	}
+	if r := doc.Relationships[3]; r.RelationshipType != "DEPENDS_ON" || r.RelatedSPDXElement != "SPDXRef-Package-3" {
+		t.Errorf("relationships[3] = %+v", r)
+	}
+}
@@ -0,0 +1,311 @@
+// Package selfupdate replaces the running aver with the latest release:
+// it finds the release on GitHub, downloads the archive for the platform,
+// checks it against the release's checksums and swaps the executable.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Repo is where aver is released
+const Repo = "llimllib/aver"
+
+// checksumsName is the asset listing the SHA-256 of every archive
+const checksumsName = "checksums.txt"
+
+// maxDownload bounds what's read of an asset, well above any binary's size
+const maxDownload = 200 << 20
+
+// Release is a published release of aver
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version is the release's version, without the leading v
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Updater fetches releases from the GitHub API
+type Updater struct {
+	BaseURL string       // API root; defaults to https://api.github.com
+	Token   string       // Optional, for a higher rate limit
+	Client  *http.Client // Defaults to http.DefaultClient
+}
+
+func (u *Updater) client() *http.Client {
+	if u.Client == nil {
+		return http.DefaultClient
+	}
+	return u.Client
+}
+
+// get fetches url, failing on any status but 200
+func (u *Updater) get(ctx context.Context, url string, api bool) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if api {
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		if u.Token != "" {
+			req.Header.Set("Authorization", "token "+u.Token)
+		}
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDownload))
+}
+
+// Latest returns the latest release, which excludes drafts and prereleases
+func (u *Updater) Latest(ctx context.Context) (Release, error) {
+	base := u.BaseURL
+	if base == "" {
+		base = "https://api.github.com"
+	}
+	body, err := u.get(ctx, base+"/repos/"+Repo+"/releases/latest", true)
+	if err != nil {
+		return Release{}, err
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return Release{}, fmt.Errorf("could not parse the latest release: %w", err)
+	}
+	return release, nil
+}
+
+// ArchiveName is the name of the release archive for a platform, as
+// .goreleaser.yaml names it
+func ArchiveName(version, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("aver_%s_%s_%s%s", version, goos, goarch, ext)
+}
+
+// Download fetches the aver binary for a platform from release, verifying
+// its archive against the release's checksums
+func (u *Updater) Download(ctx context.Context, release Release, goos, goarch string) ([]byte, error) {
+	name := ArchiveName(release.Version(), goos, goarch)
+	archive, sums := release.asset(name), release.asset(checksumsName)
+	if archive == nil {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", release.Tag, goos, goarch)
+	}
+	if sums == nil {
+		return nil, fmt.Errorf("release %s has no %s to verify the download with", release.Tag, checksumsName)
+	}
+
+	checksums, err := u.get(ctx, sums.URL, false)
+	if err != nil {
+		return nil, err
+	}
+	want, err := checksum(checksums, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := u.get(ctx, archive.URL, false)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+
+	if goos == "windows" {
+		return fromZip(data, "aver.exe")
+	}
+	return fromTarGz(data, "aver")
+}
+
+func (r Release) asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// checksum finds the SHA-256 of name in a checksums file, whose lines are
+// "<hex digest>  <file name>"
+func checksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", checksumsName, name)
+}
+
+func fromTarGz(data []byte, binary string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("archive has no %s", binary)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binary {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+func fromZip(data []byte, binary string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().Mode().IsRegular() && filepath.Base(f.Name) == binary {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = rc.Close() }()
+			return io.ReadAll(io.LimitReader(rc, maxDownload))
+		}
+	}
+	return nil, fmt.Errorf("archive has no %s", binary)
+}
+
+// Replace writes binary over the executable at path, keeping its
+// permissions. The new file is written alongside and renamed into place,
+// so a failure leaves the old executable working. Windows won't replace a
+// running executable, so it's moved aside first (see replaceAside).
+func Replace(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".aver-update-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(binary); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		return replaceAside(tmp.Name(), path)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// replaceAside renames the executable at path to path.old, which Windows
+// allows while it runs, and then renames the new file to path. If that
+// fails, the old executable is moved back, so path is never left missing.
+// path.old stays behind until the next update, since it can't be deleted
+// while it runs.
+func replaceAside(newPath, path string) error {
+	old := path + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, path); err != nil {
+		if restoreErr := os.Rename(old, path); restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("restoring %s: %w", path, restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// Newer reports whether version a is newer than b. Both must be releases,
+// like 1.4.0; it returns false if either isn't.
+func Newer(a, b string) bool {
+	pa, pb := parts(a), parts(b)
+	if pa == nil || pb == nil {
+		return false
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i]
+		}
+	}
+	return false
+}
+
+// IsRelease reports whether version is a release's, like 1.4.0, rather
+// than a development build's
+func IsRelease(version string) bool {
+	return parts(version) != nil
+}
+
+func parts(version string) []int {
+	fields := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(fields) != 3 {
+		return nil
+	}
+	nums := make([]int, 3)
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil
+		}
+		nums[i] = n
+	}
+	return nums
+}
+
+// Managed returns how to update the executable at path if a package
+// manager installed it, which would lose track of a replaced file, or ""
+func Managed(path string) string {
+	slashed := filepath.ToSlash(path)
+	switch {
+	case strings.Contains(slashed, "/Cellar/"), strings.Contains(slashed, "/Caskroom/"):
+		return "brew upgrade aver"
+	case strings.Contains(slashed, "/nix/store/"):
+		return "your Nix configuration"
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownload(t *testing.T) {
+	assets := map[string][]byte{
+		"aver_1.5.0_linux_amd64.tar.gz":  tarGz(t, map[string]string{"README.md": "docs", "aver": "linux binary"}),
+		"aver_1.5.0_windows_arm64.zip":   zipped(t, map[string]string{"LICENSE": "MIT", "aver.exe": "windows binary"}),
+		"aver_1.5.0_darwin_arm64.tar.gz": tarGz(t, map[string]string{"aver": "tampered"}),
+	}
+	var sums strings.Builder
+	for name, data := range assets {
+		sum := sha256.Sum256(data)
+		if strings.Contains(name, "darwin") {
+			sum = sha256.Sum256([]byte("the original"))
+		}
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	assets[checksumsName] = []byte(sums.String())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/"+Repo+"/releases/latest" {
+			if r.Header.Get("Authorization") != "token secret" {
+				t.Errorf("expected the token on API requests")
+			}
+			var list []string
+			for name := range assets {
+				list = append(list, fmt.Sprintf(`{"name": %q, "browser_download_url": %q}`, name, server.URL+"/download/"+name))
+			}
+			fmt.Fprintf(w, `{"tag_name": "v1.5.0", "assets": [%s]}`, strings.Join(list, ","))
+			return
+		}
+		data, ok := assets[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	u := &Updater{BaseURL: server.URL, Token: "secret"}
+	release, err := u.Latest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version() != "1.5.0" {
+		t.Errorf("expected version 1.5.0, got %s", release.Version())
+	}
+
+	for platform, want := range map[[2]string]string{
+		{"linux", "amd64"}:   "linux binary",
+		{"windows", "arm64"}: "windows binary",
+	} {
+		binary, err := u.Download(context.Background(), release, platform[0], platform[1])
+		if err != nil {
+			t.Fatalf("%v: %v", platform, err)
+		}
+		if string(binary) != want {
+			t.Errorf("%v: got %q, want %q", platform, binary, want)
+		}
+	}
+
+	if _, err := u.Download(context.Background(), release, "darwin", "arm64"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+	if _, err := u.Download(context.Background(), release, "freebsd", "amd64"); err == nil || !strings.Contains(err.Error(), "no build for freebsd/amd64") {
+		t.Errorf("expected no build for freebsd, got %v", err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aver")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Fatalf("expected the new binary, got %q, %v", data, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("expected the old permissions, got %v, %v", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected no leftover files, got %v", entries)
+	}
+}
+
+func TestReplaceAside(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "aver.exe")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed rename puts the old executable back
+	if err := replaceAside(filepath.Join(dir, "missing"), path); err == nil {
+		t.Fatal("expected an error for a missing new file")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old" {
+		t.Fatalf("expected the old binary back, got %q, %v", data, err)
+	}
+
+	newPath := filepath.Join(dir, ".aver-update")
+	if err := os.WriteFile(newPath, []byte("new"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceAside(newPath, path); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "new" {
+		t.Errorf("expected the new binary, got %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(path + ".old"); err != nil || string(data) != "old" {
+		t.Errorf("expected the old binary moved aside, got %q, %v", data, err)
+	}
+}
+
+func TestNewer(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"1.5.0", "1.4.9", true},
+		{"v1.10.0", "1.9.0", true},
+		{"1.4.0", "1.4.0", false},
+		{"1.3.0", "1.4.0", false},
+		{"1.5.0", "dev", false},
+		{"1.5.0", "0.0.0-20250101000000-abcdef123456", false},
+	} {
+		if got := Newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestManaged(t *testing.T) {
+	if got := Managed("/opt/homebrew/Caskroom/aver/1.4.0/aver"); got != "brew upgrade aver" {
+		t.Errorf("expected Homebrew, got %q", got)
+	}
+	if got := Managed("/home/me/go/bin/aver"); got != "" {
+		t.Errorf("expected no package manager, got %q", got)
+	}
+}
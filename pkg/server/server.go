@@ -0,0 +1,191 @@
+// Package server exposes aver's checks over HTTP, for dashboards and other
+// services that want results without shelling out.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"aver/pkg/actions"
+	"aver/pkg/badge"
+)
+
+// maxBody caps the size of POST /check requests
+const maxBody = 1 << 20
+
+// Report is the JSON body of a successful check
+type Report struct {
+	Outdated  []actions.OutdatedAction  `json:"outdated"`
+	SHAPinned []actions.SHAPinnedAction `json:"sha_pinned"`
+	Unchecked []actions.UncheckedAction `json:"unchecked,omitempty"`
+	Dynamic   []actions.DynamicRef      `json:"dynamic,omitempty"`
+	Warnings  []string                  `json:"warnings,omitempty"`
+}
+
+// CheckRequest is the JSON body of POST /check. Exactly one of Repo and
+// Workflow must be set.
+type CheckRequest struct {
+	Repo     string `json:"repo"`     // "owner/repo"
+	Ref      string `json:"ref"`      // The branch, tag or commit of Repo; defaults to its default branch
+	Workflow string `json:"workflow"` // The YAML of a single workflow
+}
+
+// Server answers check requests with a shared Checker, so its cache and
+// routes apply to every request
+type Server struct {
+	Checker *actions.Checker
+	Logger  *slog.Logger
+}
+
+// Handler returns the HTTP API:
+//
+//	POST /check                          check {"repo": ...} or {"workflow": ...}, or a raw YAML body
+//	GET  /repos/{owner}/{repo}           check a repository's workflows, at ?ref= if given
+//	GET  /repos/{owner}/{repo}/badge.svg a badge of the same check
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /check", s.handleCheck)
+	mux.HandleFunc("GET /repos/{owner}/{repo}", s.handleRepo)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/badge.svg", s.handleBadge)
+	return mux
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		s.error(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+
+	// A JSON body names a repository or carries a workflow; anything else
+	// is the workflow itself
+	req := CheckRequest{Workflow: string(body)}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		req = CheckRequest{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var refs []actions.ActionReference
+	var warnings []string
+	switch {
+	case (req.Repo == "") == (req.Workflow == ""):
+		s.error(w, http.StatusBadRequest, errors.New(`set exactly one of "repo" and "workflow"`))
+		return
+	case req.Repo != "":
+		if strings.Count(req.Repo, "/") != 1 {
+			s.error(w, http.StatusBadRequest, errors.New(`"repo" must look like owner/repo`))
+			return
+		}
+		refs, warnings, err = s.repoReferences(r.Context(), req.Repo, req.Ref)
+	default:
+		refs, err = actions.ParseWorkflow("workflow.yml", []byte(req.Workflow))
+	}
+	if err != nil {
+		s.error(w, statusFor(err), err)
+		return
+	}
+	s.check(r.Context(), w, refs, warnings)
+}
+
+func (s *Server) handleRepo(w http.ResponseWriter, r *http.Request) {
+	refs, warnings, err := s.repoReferences(r.Context(), r.PathValue("owner")+"/"+r.PathValue("repo"), r.URL.Query().Get("ref"))
+	if err != nil {
+		s.error(w, statusFor(err), err)
+		return
+	}
+	s.check(r.Context(), w, refs, warnings)
+}
+
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	refs, _, err := s.repoReferences(r.Context(), r.PathValue("owner")+"/"+r.PathValue("repo"), r.URL.Query().Get("ref"))
+	if err != nil {
+		s.error(w, statusFor(err), err)
+		return
+	}
+	result, err := s.Checker.Check(r.Context(), refs)
+	if err != nil {
+		s.error(w, statusFor(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "max-age=300")
+	_, _ = w.Write(badge.Result(result))
+}
+
+// repoReferences reads the action references in a repository's workflows.
+// Workflows that can't be parsed are skipped with a warning each, like the
+// CLI does, so one bad file doesn't fail the whole repository.
+func (s *Server) repoReferences(ctx context.Context, repo, ref string) ([]actions.ActionReference, []string, error) {
+	refs, err := s.Checker.RepoReferences(ctx, repo, ref)
+	var unparsed *actions.ErrUnparsed
+	if !errors.As(err, &unparsed) {
+		return refs, nil, err
+	}
+	warnings := make([]string, len(unparsed.Errs))
+	for i, e := range unparsed.Errs {
+		warnings[i] = fmt.Sprintf("skipping %s: %v", e.Source, e.Err)
+	}
+	return refs, warnings, nil
+}
+
+// check runs the Checker and writes a Report, with warnings ahead of the
+// check's own
+func (s *Server) check(ctx context.Context, w http.ResponseWriter, refs []actions.ActionReference, warnings []string) {
+	result, err := s.Checker.Check(ctx, refs)
+	if err != nil {
+		s.error(w, statusFor(err), err)
+		return
+	}
+	report := Report{
+		Outdated:  result.Outdated,
+		SHAPinned: result.SHAPinned,
+		Unchecked: result.Unchecked,
+		Dynamic:   result.Dynamic,
+		Warnings:  append(warnings, result.Warnings...),
+	}
+	if report.Outdated == nil {
+		report.Outdated = []actions.OutdatedAction{}
+	}
+	if report.SHAPinned == nil {
+		report.SHAPinned = []actions.SHAPinnedAction{}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// statusFor maps a check error to an HTTP status
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, &actions.ErrRepoNotAccessible{}):
+		return http.StatusNotFound
+	case errors.Is(err, &actions.ErrParse{}):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, &actions.ErrRateLimited{}):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
+
+func (s *Server) error(w http.ResponseWriter, status int, err error) {
+	if s.Logger != nil {
+		s.Logger.Debug("request failed", "status", status, "error", err)
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"aver/pkg/actions"
+)
+
+// fakeClient serves one repository with an outdated actions/checkout pin
+type fakeClient struct{}
+
+func (fakeClient) Tags(ctx context.Context, repo string) ([]actions.GitHubTag, error) {
+	if repo != "actions/checkout" {
+		return nil, &actions.ErrRepoNotAccessible{Repo: repo, Status: 404}
+	}
+	return []actions.GitHubTag{{Name: "v4"}, {Name: "v5"}}, nil
+}
+
+func (fakeClient) Releases(ctx context.Context, repo string) ([]actions.GitHubRelease, error) {
+	return nil, nil
+}
+
+func (fakeClient) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return "main", nil
+}
+
+func (fakeClient) BranchHead(ctx context.Context, repo, branch string) (string, error) {
+	return "", nil
+}
+
+func (fakeClient) CompareCommits(ctx context.Context, repo, base, head string) (int, error) {
+	return 0, nil
+}
+
+func (fakeClient) CommitDate(ctx context.Context, repo, ref string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (fakeClient) Workflows(ctx context.Context, repo, ref string) ([]actions.WorkflowFile, error) {
+	ci := actions.WorkflowFile{
+		Path:    ".github/workflows/ci.yml",
+		Content: []byte("steps:\n  - uses: actions/checkout@v4\n"),
+	}
+	switch repo {
+	case "owner/app":
+		return []actions.WorkflowFile{ci}, nil
+	case "owner/broken":
+		return []actions.WorkflowFile{ci, {Path: ".github/workflows/bad.yml", Content: []byte("steps: [")}}, nil
+	}
+	return nil, &actions.ErrRepoNotAccessible{Repo: repo, Status: 404}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := &Server{Checker: actions.NewChecker(actions.WithClient(fakeClient{}))}
+	server := httptest.NewServer(s.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheck(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		file        string
+	}{
+		{"repo", "application/json", `{"repo": "owner/app"}`, http.StatusOK, ".github/workflows/ci.yml"},
+		{"workflow JSON", "application/json", `{"workflow": "steps:\n  - uses: actions/checkout@v4\n"}`, http.StatusOK, "workflow.yml"},
+		{"raw YAML", "application/yaml", "steps:\n  - uses: actions/checkout@v4\n", http.StatusOK, "workflow.yml"},
+		{"both", "application/json", `{"repo": "owner/app", "workflow": "x"}`, http.StatusBadRequest, ""},
+		{"bad repo", "application/json", `{"repo": "app"}`, http.StatusBadRequest, ""},
+		{"missing repo", "application/json", `{"repo": "owner/missing"}`, http.StatusNotFound, ""},
+		{"invalid YAML", "text/plain", "steps: [", http.StatusUnprocessableEntity, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/check", tt.contentType, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var report Report
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Outdated) != 1 || report.Outdated[0].LatestVersion != "v5" || report.Outdated[0].File != tt.file {
+				t.Errorf("unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestRepo(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/repos/owner/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(report.Outdated) != 1 {
+		t.Errorf("unexpected response %d: %+v", resp.StatusCode, report)
+	}
+
+	resp, err = http.Get(server.URL + "/repos/owner/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing repo, got %d", resp.StatusCode)
+	}
+}
+
+func TestRepoUnparsed(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/repos/owner/broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(report.Outdated) != 1 {
+		t.Fatalf("expected the good workflow to be checked, got %d: %+v", resp.StatusCode, report)
+	}
+	if len(report.Warnings) != 1 || !strings.HasPrefix(report.Warnings[0], "skipping .github/workflows/bad.yml: ") {
+		t.Errorf("expected a warning about bad.yml, got %q", report.Warnings)
+	}
+
+	resp, err = http.Get(server.URL + "/repos/owner/broken/badge.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected a badge despite the bad workflow, got %d", resp.StatusCode)
+	}
+}
+
+func TestBadge(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/repos/owner/app/badge.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get("Content-Type") != "image/svg+xml" || !strings.Contains(string(body), "1 outdated") {
+		t.Errorf("unexpected badge %s: %s", resp.Header.Get("Content-Type"), body)
+	}
+}
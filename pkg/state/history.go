@@ -0,0 +1,104 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Run summarizes the findings of one aver run, for tracking trends
+type Run struct {
+	Time     time.Time `json:"time"`
+	Project  string    `json:"project"`  // The project root
+	Refs     int       `json:"refs"`     // Action references checked
+	Outdated int       `json:"outdated"` // Tag pins with a newer version
+	Behind   int       `json:"behind"`   // SHA pins behind the default branch
+	Unpinned int       `json:"unpinned"` // References not pinned to a SHA
+}
+
+// DefaultHistoryPath returns history.jsonl next to the default state file
+func DefaultHistoryPath() (string, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "history.jsonl"), nil
+}
+
+// MaxRuns is how many runs a history file keeps, across all projects.
+// AppendRun drops the oldest beyond it.
+const MaxRuns = 10000
+
+// AppendRun adds a run to the history file at path, one JSON object per
+// line, so recording a run doesn't rewrite earlier ones until the file
+// holds more than MaxRuns
+func AppendRun(path string, run Run) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return trimRuns(path, MaxRuns)
+}
+
+// trimRuns rewrites the history file at path with only its last keep runs,
+// if it has more
+func trimRuns(path string, keep int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	extra := bytes.Count(data, []byte("\n")) - keep
+	if extra <= 0 {
+		return nil
+	}
+	for range extra {
+		data = data[bytes.IndexByte(data, '\n')+1:]
+	}
+	return writeFile(path, data)
+}
+
+// LoadRuns returns the recorded runs of a project, oldest first. A missing
+// history file has no runs.
+func LoadRuns(path, project string) ([]Run, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("invalid history file %s, line %d: %w", path, line, err)
+		}
+		if run.Project == project {
+			runs = append(runs, run)
+		}
+	}
+	return runs, scanner.Err()
+}
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aver", "history.jsonl")
+
+	runs, err := LoadRuns(path, "/src/app")
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("expected no runs before any are recorded, got %v, %v", runs, err)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, project := range []string{"/src/app", "/src/other", "/src/app"} {
+		run := Run{Time: start.AddDate(0, 0, i), Project: project, Refs: 10, Outdated: 5 - i}
+		if err := AppendRun(path, run); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	runs, err = LoadRuns(path, "/src/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Outdated != 5 || runs[1].Outdated != 3 {
+		t.Errorf("unexpected runs: %+v", runs)
+	}
+}
+
+func TestTrimRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		if err := AppendRun(path, Run{Time: start.AddDate(0, 0, i), Project: "/src/app", Refs: i}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := trimRuns(path, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runs, err := LoadRuns(path, "/src/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 3 || runs[0].Refs != 2 || runs[2].Refs != 4 {
+		t.Errorf("expected the last 3 runs, got %+v", runs)
+	}
+}
+
+func TestLoadRunsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, []byte("{}\nnope\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRuns(path, ""); err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
@@ -0,0 +1,107 @@
+// Package table prints text tables whose columns line up in a terminal.
+// Text is measured by the columns it takes up rather than its bytes or
+// runes: East Asian wide characters and emoji take two, combining marks
+// and other zero-width characters none.
+package table
+
+import (
+	"io"
+	"strings"
+)
+
+// Cell is one cell of a row. Wrap, if set, decorates the padded text, e.g.
+// as a terminal hyperlink; what it adds doesn't count toward the width.
+type Cell struct {
+	Text string
+	Wrap func(string) string
+}
+
+// Text is a plain Cell
+func Text(s string) Cell {
+	return Cell{Text: s}
+}
+
+// Table is a header row, a rule of dashes under each header and the rows,
+// with columns separated by two spaces
+type Table struct {
+	headers []string
+	rows    [][]Cell
+}
+
+// New starts a table with the given column headers
+func New(headers ...string) *Table {
+	return &Table{headers: headers}
+}
+
+// Row adds a row of plain text cells
+func (t *Table) Row(cells ...string) {
+	row := make([]Cell, len(cells))
+	for i, s := range cells {
+		row[i] = Text(s)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Cells adds a row of cells, some of which may be decorated
+func (t *Table) Cells(cells ...Cell) {
+	t.rows = append(t.rows, cells)
+}
+
+// Write writes the table to w. The last column isn't padded, so lines
+// don't end in spaces.
+func (t *Table) Write(w io.Writer) error {
+	widths := make([]int, len(t.headers))
+	for i, h := range t.headers {
+		widths[i] = Width(h)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], Width(cell.Text))
+			}
+		}
+	}
+
+	var b strings.Builder
+	line := func(cells []Cell) {
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			text := cell.Text
+			if i < len(widths)-1 {
+				text = Pad(text, widths[i])
+			}
+			if cell.Wrap != nil {
+				text = cell.Wrap(text)
+			}
+			b.WriteString(text)
+		}
+		b.WriteString("\n")
+	}
+
+	header := make([]Cell, len(t.headers))
+	rule := make([]Cell, len(t.headers))
+	for i, h := range t.headers {
+		header[i] = Text(h)
+		rule[i] = Text(strings.Repeat("-", widths[i]))
+	}
+	line(header)
+	line(rule)
+	for _, row := range t.rows {
+		line(row)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Pad adds spaces to the end of s until it takes up width columns
+func Pad(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
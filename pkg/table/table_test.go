@@ -0,0 +1,64 @@
+package table
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWidth(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want int
+	}{
+		{"actions/checkout", 16},
+		{"", 0},
+		{"café", 4},
+		{"cafe\u0301", 4}, // e and a combining acute accent
+		{"日本語", 6},
+		{"한국어", 6},
+		{"ｆｕｌｌ", 8},
+		{"🚀 deploy", 9},
+		{"a\u200db", 2}, // zero-width joiner
+		{"tab\there", 7},
+	} {
+		if got := Width(tt.s); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWide(t *testing.T) {
+	for i := 1; i < len(wide); i++ {
+		if wide[i][0] <= wide[i-1][1] || wide[i][0] > wide[i][1] {
+			t.Errorf("wide ranges out of order at %U-%U", wide[i][0], wide[i][1])
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tab := New("File", "Action", "Version")
+	tab.Row(".github/workflows/ci.yml", "actions/checkout", "v4")
+	tab.Row(".github/workflows/デプロイ.yml", "acme/🚀", "v1")
+	tab.Cells(Text("short.yml"), Cell{Text: "x/y", Wrap: func(s string) string { return "<" + s + ">" }}, Text("v2"))
+
+	var b strings.Builder
+	if err := tab.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `File                            Action            Version
+------------------------------  ----------------  -------
+.github/workflows/ci.yml        actions/checkout  v4
+.github/workflows/デプロイ.yml  acme/🚀           v1
+short.yml                       <x/y             >  v2
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	// Every line's Version column starts at the same display column
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")[:4] {
+		if col := Width(line[:strings.LastIndex(line, "  ")+2]); col != 50 {
+			t.Errorf("last column of %q starts at %d, want 50", line, col)
+		}
+	}
+}
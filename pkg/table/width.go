@@ -0,0 +1,131 @@
+package table
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Width is how many terminal columns s takes up
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// RuneWidth is how many terminal columns r takes up: 0 for control
+// characters, combining marks and format characters such as zero-width
+// joiners, 2 for wide and fullwidth characters, 1 for the rest
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x300:
+		// Latin and its punctuation, the common case
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// wide are the ranges of East Asian Wide (W) and Fullwidth (F) characters
+// in Unicode's EastAsianWidth.txt, which include emoji presented as such
+var wide = [][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo initial consonants
+	{0x231a, 0x231b},   // Watch, hourglass
+	{0x2329, 0x232a},   // Angle brackets
+	{0x23e9, 0x23ec},   // Media buttons
+	{0x23f0, 0x23f0},   // Alarm clock
+	{0x23f3, 0x23f3},   // Hourglass with flowing sand
+	{0x25fd, 0x25fe},   // Medium small squares
+	{0x2614, 0x2615},   // Umbrella with rain drops, hot beverage
+	{0x2648, 0x2653},   // Zodiac signs
+	{0x267f, 0x267f},   // Wheelchair symbol
+	{0x2693, 0x2693},   // Anchor
+	{0x26a1, 0x26a1},   // High voltage
+	{0x26aa, 0x26ab},   // Medium circles
+	{0x26bd, 0x26be},   // Soccer ball, baseball
+	{0x26c4, 0x26c5},   // Snowman, sun behind cloud
+	{0x26ce, 0x26ce},   // Ophiuchus
+	{0x26d4, 0x26d4},   // No entry
+	{0x26ea, 0x26ea},   // Church
+	{0x26f2, 0x26f3},   // Fountain, flag in hole
+	{0x26f5, 0x26f5},   // Sailboat
+	{0x26fa, 0x26fa},   // Tent
+	{0x26fd, 0x26fd},   // Fuel pump
+	{0x2705, 0x2705},   // Check mark button
+	{0x270a, 0x270b},   // Raised fist and hand
+	{0x2728, 0x2728},   // Sparkles
+	{0x274c, 0x274c},   // Cross mark
+	{0x274e, 0x274e},   // Cross mark button
+	{0x2753, 0x2755},   // Question and exclamation marks
+	{0x2757, 0x2757},   // Heavy exclamation mark
+	{0x2795, 0x2797},   // Heavy plus, minus and division signs
+	{0x27b0, 0x27b0},   // Curly loop
+	{0x27bf, 0x27bf},   // Double curly loop
+	{0x2b1b, 0x2b1c},   // Large squares
+	{0x2b50, 0x2b50},   // Star
+	{0x2b55, 0x2b55},   // Heavy large circle
+	{0x2e80, 0x303e},   // CJK radicals, Kangxi radicals, CJK symbols and punctuation
+	{0x3041, 0x33ff},   // Hiragana, Katakana, Bopomofo, Hangul compatibility Jamo, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK unified ideographs extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended A
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // Vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms, small form variants
+	{0xff00, 0xff60},   // Fullwidth forms
+	{0xffe0, 0xffe6},   // Fullwidth signs
+	{0x16fe0, 0x16fe4}, // Ideographic symbols
+	{0x17000, 0x18cff}, // Tangut
+	{0x1b000, 0x1b2ff}, // Kana supplement and extensions, Nushu
+	{0x1f004, 0x1f004}, // Mahjong tile red dragon
+	{0x1f0cf, 0x1f0cf}, // Playing card black joker
+	{0x1f18e, 0x1f18e}, // Negative squared AB
+	{0x1f191, 0x1f19a}, // Squared CL to VS
+	{0x1f200, 0x1f251}, // Enclosed ideographic supplement
+	{0x1f300, 0x1f320}, // Weather and landscape emoji
+	{0x1f32d, 0x1f335},
+	{0x1f337, 0x1f37c},
+	{0x1f37e, 0x1f393},
+	{0x1f3a0, 0x1f3ca},
+	{0x1f3cf, 0x1f3d3},
+	{0x1f3e0, 0x1f3f0},
+	{0x1f3f4, 0x1f3f4},
+	{0x1f3f8, 0x1f43e},
+	{0x1f440, 0x1f440},
+	{0x1f442, 0x1f4fc},
+	{0x1f4ff, 0x1f53d},
+	{0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567},
+	{0x1f57a, 0x1f57a},
+	{0x1f595, 0x1f596},
+	{0x1f5a4, 0x1f5a4},
+	{0x1f5fb, 0x1f64f}, // Landmarks and emoticons
+	{0x1f680, 0x1f6c5}, // Transport and map symbols
+	{0x1f6cc, 0x1f6cc},
+	{0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7},
+	{0x1f6dc, 0x1f6df},
+	{0x1f6eb, 0x1f6ec},
+	{0x1f6f4, 0x1f6fc},
+	{0x1f7e0, 0x1f7eb}, // Colored circles and squares
+	{0x1f7f0, 0x1f7f0},
+	{0x1f90c, 0x1f93a}, // Supplemental symbols and pictographs
+	{0x1f93c, 0x1f945},
+	{0x1f947, 0x1f9ff},
+	{0x1fa70, 0x1faff}, // Symbols and pictographs extended A
+	{0x20000, 0x2fffd}, // CJK unified ideographs extensions B to F
+	{0x30000, 0x3fffd}, // CJK unified ideographs extensions G and H
+}
+
+func isWide(r rune) bool {
+	i := sort.Search(len(wide), func(i int) bool { return wide[i][1] >= r })
+	return i < len(wide) && wide[i][0] <= r
+}